@@ -0,0 +1,424 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"electrobot/apitoken"
+	"electrobot/archive"
+	"electrobot/database"
+	"electrobot/httpserver"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// FeatureName is the HTTP server feature name of the REST API.
+const FeatureName = "api"
+
+const (
+	defaultOutagesPeriod = 30 * 24 * time.Hour
+	maxImportSize        = 10 << 20
+	maxOutagesPeriod     = 366 * 24 * time.Hour
+	dateFormat           = "2006-01-02"
+	powerOn              = "on"
+	powerOff             = "off"
+	powerUnknown         = "unknown"
+	etagLength           = 16
+	formatJSON           = "json"
+	formatText           = "text"
+	// widgetMaxAge lets widgets and their apps reuse the response, they refresh every few minutes at best.
+	widgetMaxAge = 5 * time.Minute
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Storage provides outages and registered users.
+type Storage interface {
+	archive.OutageStorage
+	GetAllUsers() ([]int64, error)
+	ImportOutages(outages []database.Outage) (imported int, err error)
+}
+
+// StatusProvider provides the current power state.
+type StatusProvider interface {
+	// PowerState returns whether power is on, the time it is on or off since and the time of the last power check,
+	// zero if not checked yet.
+	PowerState() (on bool, since, lastCheck time.Time)
+	// NextPlannedOutage returns the planned outage in progress at now or the next one, false if none is known.
+	NextPlannedOutage(now time.Time) (start, end time.Time, ok bool)
+}
+
+// Outage structure with a single outage.
+type Outage struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds int64     `json:"duration_seconds"`
+	Cause           string    `json:"cause,omitempty"`
+}
+
+// Status structure with the current power state.
+type Status struct {
+	Power     string     `json:"power"`
+	Since     *time.Time `json:"since,omitempty"`
+	LastCheck *time.Time `json:"last_check,omitempty"`
+}
+
+// PlannedOutage structure with a planned outage.
+type PlannedOutage struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Widget structure with the power state and the next planned outage for home-screen widgets, fields are only added
+// to keep existing widgets working.
+type Widget struct {
+	Power      string         `json:"power"`
+	Since      *time.Time     `json:"since,omitempty"`
+	NextOutage *PlannedOutage `json:"next_outage,omitempty"`
+}
+
+// ImportResult structure with the number of imported outages.
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// UsersCount structure with the number of registered users.
+type UsersCount struct {
+	Count int `json:"count"`
+}
+
+// lastModified is implemented by responses which know when their data changed.
+type lastModified interface {
+	lastModified() time.Time
+}
+
+// plainText is implemented by responses which can be written as a single line of text for automations.
+type plainText interface {
+	plainText() string
+}
+
+// requestError is an error caused by invalid request parameters.
+type requestError struct {
+	message string
+}
+
+// API serves outage data stored by the bot.
+type API struct {
+	storage Storage
+	status  StatusProvider
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates REST API.
+func New(storage Storage, status StatusProvider) *API {
+	return &API{storage: storage, status: status}
+}
+
+// Routes returns versioned API routes.
+func (api *API) Routes() []httpserver.Route {
+	return []httpserver.Route{
+		{Pattern: "/api/v1/outages", Handler: handler(api.outages)},
+		{Pattern: "/api/v1/status", Handler: handler(api.powerStatus)},
+		{Pattern: "/api/v1/users/count", Handler: handler(api.usersCount)},
+		{Pattern: "/api/v1/widget", Handler: maxAge(widgetMaxAge, handler(api.widget))},
+		{Pattern: "/api/v1/outages/import", Handler: http.HandlerFunc(api.importOutages), Scope: apitoken.ScopeAdmin},
+	}
+}
+
+// Error returns the error message.
+func (err *requestError) Error() string {
+	return err.message
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handler serves GET requests with the response returned by get in the format query parameter, JSON by default.
+// Errors are returned as {"error": "..."} with the status code of the error.
+func handler(get func(r *http.Request) (response interface{}, err error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+
+		response, err := get(r)
+		if err == nil {
+			err = checkFormat(format, response)
+		}
+
+		if err != nil {
+			status := http.StatusInternalServerError
+
+			var requestErr *requestError
+			if errors.As(err, &requestErr) {
+				status = http.StatusBadRequest
+			} else {
+				log.WithField("path", r.URL.Path).Errorf("Failed to handle API request: %s", err)
+
+				err = errors.New("internal error")
+			}
+
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+
+			return
+		}
+
+		writeContent(w, r, response, format)
+	}
+}
+
+// checkFormat returns an error if the response can't be written in the format.
+func checkFormat(format string, response interface{}) error {
+	switch format {
+	case "", formatJSON:
+		return nil
+
+	case formatText:
+		if _, ok := response.(plainText); !ok {
+			return &requestError{"format text is not supported"}
+		}
+
+		return nil
+
+	default:
+		return &requestError{fmt.Sprintf("invalid format: %s", format)}
+	}
+}
+
+// maxAge allows clients to cache successful responses for the duration.
+func maxAge(duration time.Duration, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(duration.Seconds())))
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// outages returns outages overlapping [from, to), both are RFC 3339 times or dates, default is the last 30 days.
+func (api *API) outages(r *http.Request) (response interface{}, err error) {
+	to := time.Now()
+
+	if value := r.URL.Query().Get("to"); value != "" {
+		if to, err = parseTime(value); err != nil {
+			return nil, &requestError{fmt.Sprintf("invalid to: %s", err)}
+		}
+	}
+
+	from := to.Add(-defaultOutagesPeriod)
+
+	if value := r.URL.Query().Get("from"); value != "" {
+		if from, err = parseTime(value); err != nil {
+			return nil, &requestError{fmt.Sprintf("invalid from: %s", err)}
+		}
+	}
+
+	if !from.Before(to) {
+		return nil, &requestError{"from must be before to"}
+	}
+
+	if to.Sub(from) > maxOutagesPeriod {
+		return nil, &requestError{"period must not exceed 366 days"}
+	}
+
+	storedOutages, err := archive.Outages(api.storage, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	outages := make([]Outage, 0, len(storedOutages))
+
+	for _, outage := range storedOutages {
+		outages = append(outages, Outage{
+			Start: outage.Start.UTC(), End: outage.End.UTC(), DurationSeconds: int64(outage.Duration().Seconds()),
+			Cause: outage.Cause,
+		})
+	}
+
+	return outages, nil
+}
+
+// importOutages adds historical outages posted as CSV read by archive.ReadCSV, times without offset are in the
+// timezone parameter, UTC by default.
+func (api *API) importOutages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	location, err := time.LoadLocation(r.URL.Query().Get("timezone"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid timezone: %s", err)})
+
+		return
+	}
+
+	outages, err := archive.ReadCSV(http.MaxBytesReader(w, r.Body, maxImportSize), location)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid outages: %s", err)})
+
+		return
+	}
+
+	imported, err := api.storage.ImportOutages(outages)
+	if err != nil {
+		log.WithField("path", r.URL.Path).Errorf("Failed to import outages: %s", err)
+
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+
+		return
+	}
+
+	log.WithFields(log.Fields{"imported": imported, "outages": len(outages)}).Info("Outages imported")
+
+	writeJSON(w, http.StatusOK, ImportResult{Imported: imported, Skipped: len(outages) - imported})
+}
+
+func (api *API) powerStatus(*http.Request) (response interface{}, err error) {
+	on, since, lastCheck := api.status.PowerState()
+
+	// power state is unknown until the power monitor finishes its startup check
+	if lastCheck.IsZero() {
+		return Status{Power: powerUnknown}, nil
+	}
+
+	since, lastCheck = since.UTC(), lastCheck.UTC()
+
+	power := powerOn
+	if !on {
+		power = powerOff
+	}
+
+	return Status{Power: power, Since: &since, LastCheck: &lastCheck}, nil
+}
+
+// plainText returns only the power state, so automations can compare the response with "on".
+func (status Status) plainText() string {
+	return status.Power
+}
+
+// lastModified returns the last power check time, the status does not change between checks.
+func (status Status) lastModified() time.Time {
+	if status.LastCheck == nil {
+		return time.Time{}
+	}
+
+	return *status.LastCheck
+}
+
+// widget returns the power state and the planned outage in progress or the next one.
+func (api *API) widget(*http.Request) (response interface{}, err error) {
+	on, since, lastCheck := api.status.PowerState()
+
+	widget := Widget{Power: powerUnknown}
+
+	if !lastCheck.IsZero() {
+		since = since.UTC()
+		widget.Power, widget.Since = powerOff, &since
+
+		if on {
+			widget.Power = powerOn
+		}
+	}
+
+	if start, end, ok := api.status.NextPlannedOutage(time.Now()); ok {
+		widget.NextOutage = &PlannedOutage{Start: start.UTC(), End: end.UTC()}
+	}
+
+	return widget, nil
+}
+
+func (api *API) usersCount(*http.Request) (response interface{}, err error) {
+	users, err := api.storage.GetAllUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	return UsersCount{Count: len(users)}, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if date, err := time.Parse(dateFormat, value); err == nil {
+		return date, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// writeContent writes the response in the format with an ETag of its content and Last-Modified if the response
+// provides it, conditional requests get 304 Not Modified if the response is unchanged.
+func writeContent(w http.ResponseWriter, r *http.Request, response interface{}, format string) {
+	contentType := "application/json"
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		log.WithField("path", r.URL.Path).Errorf("Failed to encode API response: %s", err)
+
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+
+		return
+	}
+
+	if response, ok := response.(plainText); ok && format == formatText {
+		contentType, body = "text/plain; charset=utf-8", []byte(response.plainText())
+	}
+
+	body = append(body, '\n')
+	hash := sha256.Sum256(body)
+
+	var modified time.Time
+
+	if response, ok := response.(lastModified); ok {
+		modified = response.lastModified()
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(hash[:etagLength])+`"`)
+
+	http.ServeContent(w, r, "", modified, bytes.NewReader(body))
+}
+
+func writeJSON(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Errorf("Failed to write API response: %s", err)
+	}
+}
@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type testStatus struct {
+	on        bool
+	since     time.Time
+	lastCheck time.Time
+	planned   *PlannedOutage
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestConditionalRequests(t *testing.T) {
+	lastCheck := time.Date(2024, 10, 14, 12, 0, 0, 0, time.UTC)
+	status := Status{Power: powerOn, Since: &lastCheck, LastCheck: &lastCheck}
+
+	serve := handler(func(*http.Request) (response interface{}, err error) { return status, nil })
+
+	recorder := httptest.NewRecorder()
+
+	serve(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+
+	etag := recorder.Header().Get("ETag")
+
+	if recorder.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Wrong response: %d, ETag %q", recorder.Code, etag)
+	}
+
+	if modified := recorder.Header().Get("Last-Modified"); modified != lastCheck.Format(http.TimeFormat) {
+		t.Errorf("Wrong Last-Modified: %s", modified)
+	}
+
+	testData := []struct {
+		name   string
+		header string
+		value  string
+		status int
+	}{
+		{name: "same ETag", header: "If-None-Match", value: etag, status: http.StatusNotModified},
+		{name: "other ETag", header: "If-None-Match", value: `"other"`, status: http.StatusOK},
+		{
+			name: "not modified", header: "If-Modified-Since", value: lastCheck.Format(http.TimeFormat),
+			status: http.StatusNotModified,
+		},
+		{
+			name: "modified", header: "If-Modified-Since", value: lastCheck.Add(-time.Minute).Format(http.TimeFormat),
+			status: http.StatusOK,
+		},
+	}
+
+	for _, item := range testData {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+		request.Header.Set(item.header, item.value)
+
+		recorder := httptest.NewRecorder()
+
+		serve(recorder, request)
+
+		if recorder.Code != item.status {
+			t.Errorf("Wrong %s status: %d", item.name, recorder.Code)
+		}
+
+		if item.status == http.StatusNotModified && recorder.Body.Len() != 0 {
+			t.Errorf("Body is sent with %s: %s", item.name, recorder.Body)
+		}
+	}
+
+	// a later power check changes the response
+	lastCheck = lastCheck.Add(time.Minute)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+	request.Header.Set("If-None-Match", etag)
+
+	recorder = httptest.NewRecorder()
+
+	serve(recorder, request)
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("ETag") == etag {
+		t.Errorf("Changed status is not sent: %d, ETag %q", recorder.Code, recorder.Header().Get("ETag"))
+	}
+}
+
+func TestWidget(t *testing.T) {
+	since := time.Date(2024, 10, 14, 12, 0, 0, 0, time.UTC)
+	planned := &PlannedOutage{Start: since.Add(time.Hour), End: since.Add(3 * time.Hour)}
+
+	testData := []struct {
+		name   string
+		status testStatus
+		widget string
+	}{
+		{name: "unknown", status: testStatus{}, widget: `{"power":"unknown"}`},
+		{
+			name: "off", status: testStatus{since: since, lastCheck: since},
+			widget: `{"power":"off","since":"2024-10-14T12:00:00Z"}`,
+		},
+		{
+			name: "on", status: testStatus{on: true, since: since, lastCheck: since, planned: planned},
+			widget: `{"power":"on","since":"2024-10-14T12:00:00Z",` +
+				`"next_outage":{"start":"2024-10-14T13:00:00Z","end":"2024-10-14T15:00:00Z"}}`,
+		},
+	}
+
+	for _, item := range testData {
+		status := item.status
+		api := New(nil, &status)
+
+		response, err := api.widget(httptest.NewRequest(http.MethodGet, "/api/v1/widget", nil))
+		if err != nil {
+			t.Fatalf("Can't get %s widget: %s", item.name, err)
+		}
+
+		if widget, err := json.Marshal(response); err != nil || string(widget) != item.widget {
+			t.Errorf("Wrong %s widget: %s", item.name, widget)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+
+	api := New(nil, &testStatus{})
+
+	maxAge(widgetMaxAge, handler(api.widget)).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/widget",
+		nil))
+
+	if cacheControl := recorder.Header().Get("Cache-Control"); cacheControl != "private, max-age=300" {
+		t.Errorf("Wrong widget Cache-Control: %s", cacheControl)
+	}
+}
+
+func TestResponseFormat(t *testing.T) {
+	since := time.Date(2024, 10, 14, 12, 0, 0, 0, time.UTC)
+	api := New(nil, &testStatus{on: true, since: since, lastCheck: since})
+
+	testData := []struct {
+		target      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			target: "/api/v1/status?format=text", status: http.StatusOK, contentType: "text/plain; charset=utf-8",
+			body: "on\n",
+		},
+		{
+			target: "/api/v1/status?format=json", status: http.StatusOK, contentType: "application/json",
+			body: `{"power":"on","since":"2024-10-14T12:00:00Z","last_check":"2024-10-14T12:00:00Z"}` + "\n",
+		},
+		{
+			target: "/api/v1/status?format=xml", status: http.StatusBadRequest, contentType: "application/json",
+			body: `{"error":"invalid format: xml"}` + "\n",
+		},
+		{
+			target: "/api/v1/widget?format=text", status: http.StatusBadRequest, contentType: "application/json",
+			body: `{"error":"format text is not supported"}` + "\n",
+		},
+	}
+
+	routes := map[string]http.HandlerFunc{
+		"/api/v1/status": handler(api.powerStatus), "/api/v1/widget": handler(api.widget),
+	}
+
+	for _, item := range testData {
+		request := httptest.NewRequest(http.MethodGet, item.target, nil)
+		recorder := httptest.NewRecorder()
+
+		routes[request.URL.Path](recorder, request)
+
+		if recorder.Code != item.status || recorder.Header().Get("Content-Type") != item.contentType ||
+			recorder.Body.String() != item.body {
+			t.Errorf("Wrong %s response: %d %s %q", item.target, recorder.Code, recorder.Header().Get("Content-Type"),
+				recorder.Body)
+		}
+	}
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (status *testStatus) PowerState() (on bool, since, lastCheck time.Time) {
+	return status.on, status.since, status.lastCheck
+}
+
+func (status *testStatus) NextPlannedOutage(time.Time) (start, end time.Time, ok bool) {
+	if status.planned == nil {
+		return start, end, false
+	}
+
+	return status.planned.Start, status.planned.End, true
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Token scopes.
+const (
+	ScopeRead  Scope = "read"
+	ScopeAdmin Scope = "admin"
+)
+
+const tokenSize = 32
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Scope defines what a token is allowed to access.
+type Scope string
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// ParseScope validates scope name.
+func ParseScope(name string) (scope Scope, err error) {
+	switch scope = Scope(name); scope {
+	case ScopeRead, ScopeAdmin:
+		return scope, nil
+
+	default:
+		return "", fmt.Errorf("unknown token scope %q", name)
+	}
+}
+
+// Allows returns true if the scope grants access to routes requiring required scope.
+func (scope Scope) Allows(required Scope) bool {
+	return scope == ScopeAdmin || scope == required || required == ""
+}
+
+// Generate creates a new random token and returns it with its hash.
+func Generate() (token, hash string, err error) {
+	data := make([]byte, tokenSize)
+
+	if _, err = rand.Read(data); err != nil {
+		return "", "", err
+	}
+
+	token = hex.EncodeToString(data)
+
+	return token, Hash(token), nil
+}
+
+// Hash returns token hash as it is stored in the database.
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,298 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/database"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultCheckInterval = 24 * time.Hour
+	fileTimeFormat       = "20060102T150405Z"
+	// causeColumn is the index of the outage cause column in archive files.
+	causeColumn = 3
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with archiver configuration.
+type Config struct {
+	// Dir is the directory of archive files.
+	Dir string
+	// MaxAge is the age after which outages are moved to archive files.
+	MaxAge        time.Duration
+	CheckInterval time.Duration
+}
+
+// Storage provides outages to archive.
+type Storage interface {
+	ArchiveOutages(before time.Time, write func(outages []database.Outage) (fileName string, err error)) (int, error)
+}
+
+// OutageStorage provides outages still kept in the database and the list of archive files.
+type OutageStorage interface {
+	GetOutagesBetween(from, to time.Time) ([]database.Outage, error)
+	GetOutageArchives(from, to time.Time) ([]database.OutageArchive, error)
+}
+
+// Archiver periodically moves old outages from the database into gzip-compressed CSV files.
+type Archiver struct {
+	config     Config
+	storage    Storage
+	cancelFunc context.CancelFunc
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates and starts archiver.
+func New(config Config, storage Storage) (archiver *Archiver, err error) {
+	if config.MaxAge <= 0 {
+		return nil, fmt.Errorf("invalid archive max age %s", config.MaxAge)
+	}
+
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaultCheckInterval
+	}
+
+	if err = os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	archiver = &Archiver{config: config, storage: storage}
+
+	ctx, cancelFunction := context.WithCancel(context.Background())
+	archiver.cancelFunc = cancelFunction
+
+	go archiver.run(ctx)
+
+	return archiver, nil
+}
+
+// Close stops archiver.
+func (archiver *Archiver) Close() {
+	archiver.cancelFunc()
+}
+
+// Outages returns outages overlapping [from, to) from archive files followed by outages kept in the database.
+func Outages(storage OutageStorage, from, to time.Time) (outages []database.Outage, err error) {
+	archives, err := storage.GetOutageArchives(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, outageArchive := range archives {
+		archived, err := ReadFile(outageArchive.FileName)
+		if err != nil {
+			// a lost archive file shouldn't make all outages unavailable
+			log.WithField("file", outageArchive.FileName).Errorf("Failed to read archived outages: %s", err)
+
+			continue
+		}
+
+		for _, outage := range archived {
+			if outage.End.After(from) && outage.Start.Before(to) {
+				outages = append(outages, outage)
+			}
+		}
+	}
+
+	recent, err := storage.GetOutagesBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(outages, recent...), nil
+}
+
+// ReadFile reads outages from an archive file written by the archiver.
+func ReadFile(fileName string) (outages []database.Outage, err error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	return ReadCSV(gzipReader, time.UTC)
+}
+
+// ReadCSV reads outages from CSV with start and end columns, the fourth column is the outage cause as written to
+// archive files, further columns are ignored and the header row is optional. Times are RFC 3339 or
+// "2006-01-02 15:04[:05]" in the location, so archive files and manually kept spreadsheets can be read.
+func ReadCSV(reader io.Reader, location *time.Location) (outages []database.Outage, err error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, record := range records {
+		if len(record) < 2 { //nolint:gomnd // start and end columns
+			return nil, fmt.Errorf("invalid outage record %q on line %d", record, i+1)
+		}
+
+		var outage database.Outage
+
+		if outage.Start, err = parseCSVTime(record[0], location); err != nil {
+			// the first row may be a header
+			if i == 0 {
+				continue
+			}
+
+			return nil, fmt.Errorf("invalid start on line %d: %w", i+1, err)
+		}
+
+		if outage.End, err = parseCSVTime(record[1], location); err != nil {
+			return nil, fmt.Errorf("invalid end on line %d: %w", i+1, err)
+		}
+
+		if len(record) > causeColumn {
+			outage.Cause = strings.TrimSpace(record[causeColumn])
+		}
+
+		outages = append(outages, outage)
+	}
+
+	return outages, nil
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (archiver *Archiver) run(ctx context.Context) {
+	ticker := time.NewTicker(archiver.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		archiver.archive()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (archiver *Archiver) archive() {
+	now := time.Now().UTC()
+	fileName := filepath.Join(archiver.config.Dir, "outages-"+now.Format(fileTimeFormat)+".csv.gz")
+	written := false
+
+	count, err := archiver.storage.ArchiveOutages(now.Add(-archiver.config.MaxAge),
+		func(outages []database.Outage) (string, error) {
+			written = true
+
+			return fileName, writeFile(fileName, outages)
+		})
+	if err != nil {
+		log.Errorf("Failed to archive outages: %s", err)
+
+		// the file is not referenced by the database if archiving failed
+		if written {
+			os.Remove(fileName)
+		}
+
+		return
+	}
+
+	if count > 0 {
+		log.WithFields(log.Fields{"file": fileName, "outages": count}).Info("Outages archived")
+	}
+}
+
+// writeFile writes outages as CSV with start, end, duration in seconds and cause, times are RFC 3339 in UTC.
+func writeFile(fileName string, outages []database.Outage) (err error) {
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(file)
+	csvWriter := csv.NewWriter(gzipWriter)
+
+	if err = csvWriter.Write([]string{"start", "end", "duration_seconds", "cause"}); err != nil {
+		return err
+	}
+
+	for _, outage := range outages {
+		if err = csvWriter.Write([]string{
+			outage.Start.UTC().Format(time.RFC3339), outage.End.UTC().Format(time.RFC3339),
+			strconv.Itoa(int(outage.Duration().Seconds())), outage.Cause,
+		}); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	if err = csvWriter.Error(); err != nil {
+		return err
+	}
+
+	if err = gzipWriter.Close(); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+func parseCSVTime(value string, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05"} {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultKeep    = 7
+	filePrefix     = "electrobot-"
+	fileSuffix     = ".db"
+	fileTimeFormat = "20060102T150405Z"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with scheduled backup configuration.
+type Config struct {
+	Dir      string
+	Interval time.Duration
+	// Keep is the number of latest backups kept in Dir.
+	Keep int
+}
+
+// Storage makes database snapshots.
+type Storage interface {
+	Backup(fileName string) error
+}
+
+// Backuper periodically writes database snapshots to the backup directory and removes old ones.
+type Backuper struct {
+	config     Config
+	storage    Storage
+	cancelFunc context.CancelFunc
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates and starts backuper.
+func New(config Config, storage Storage) (backuper *Backuper, err error) {
+	if config.Interval <= 0 {
+		return nil, errors.New("backup interval is not set")
+	}
+
+	if config.Keep <= 0 {
+		config.Keep = defaultKeep
+	}
+
+	if err = os.MkdirAll(config.Dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	backuper = &Backuper{config: config, storage: storage}
+
+	ctx, cancelFunction := context.WithCancel(context.Background())
+	backuper.cancelFunc = cancelFunction
+
+	go backuper.run(ctx)
+
+	return backuper, nil
+}
+
+// Close stops backuper.
+func (backuper *Backuper) Close() {
+	backuper.cancelFunc()
+}
+
+// Create writes a database snapshot into the directory and returns its file name.
+func Create(storage Storage, dir string) (fileName string, err error) {
+	fileName = filepath.Join(dir, filePrefix+time.Now().UTC().Format(fileTimeFormat)+fileSuffix)
+
+	if err = storage.Backup(fileName); err != nil {
+		// don't leave a partial snapshot behind
+		os.Remove(fileName)
+
+		return "", err
+	}
+
+	return fileName, nil
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (backuper *Backuper) run(ctx context.Context) {
+	ticker := time.NewTicker(backuper.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			backuper.backup()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (backuper *Backuper) backup() {
+	fileName, err := Create(backuper.storage, backuper.config.Dir)
+	if err != nil {
+		log.Errorf("Failed to back up database: %s", err)
+
+		return
+	}
+
+	log.WithField("file", fileName).Info("Database backed up")
+
+	if err = backuper.rotate(); err != nil {
+		log.Errorf("Failed to remove old backups: %s", err)
+	}
+}
+
+// rotate removes all but the latest Keep backups, file names sort by creation time.
+func (backuper *Backuper) rotate() error {
+	fileNames, err := filepath.Glob(filepath.Join(backuper.config.Dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(fileNames)
+
+	for len(fileNames) > backuper.config.Keep {
+		if err = os.Remove(fileNames[0]); err != nil {
+			return err
+		}
+
+		log.WithField("file", fileNames[0]).Debug("Old backup removed")
+
+		fileNames = fileNames[1:]
+	}
+
+	return nil
+}
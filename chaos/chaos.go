@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chaos injects faults to verify crash safety of the outbox and heartbeat persistence. Faults are compiled
+// in only with the chaos build tag and configured with the ELECTROBOT_CHAOS environment variable, e.g.
+//
+//	go build -tags chaos && ELECTROBOT_CHAOS=database=0.001,telegram=0.1,crash=0.01,seed=42 ./electrobot
+//
+// Each value is the probability of the fault at every injection point, the optional seed makes runs repeatable.
+// Regular builds contain no-op hooks.
+package chaos
+
+import (
+	"errors"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Injectable faults.
+const (
+	// Database fails a database operation like a transient I/O or locking error.
+	Database Fault = "database"
+	// Telegram fails Bot API calls as if the network is down.
+	Telegram Fault = "telegram"
+	// Crash kills the process with SIGKILL like a power loss.
+	Crash Fault = "crash"
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// ErrInjected is returned by operations failed by an injected fault.
+var ErrInjected = errors.New("chaos: injected fault")
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Fault is the kind of injected fault.
+type Fault string
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !chaos
+
+package chaos
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Enabled is true in builds with fault injection.
+const Enabled = false
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Fire returns true if the fault should be injected now, never in regular builds.
+func Fire(Fault) bool {
+	return false
+}
+
+// CrashPoint kills the process if the crash fault fires, no-op in regular builds.
+func CrashPoint(string) {}
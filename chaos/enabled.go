@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build chaos
+
+package chaos
+
+import (
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Enabled is true in builds with fault injection.
+const Enabled = true
+
+const (
+	configEnv = "ELECTROBOT_CHAOS"
+	seedKey   = "seed"
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//nolint:gochecknoglobals
+var (
+	mutex         sync.Mutex
+	random        *rand.Rand
+	probabilities map[Fault]float64
+)
+
+/***********************************************************************************************************************
+ * Init
+ **********************************************************************************************************************/
+
+func init() {
+	probabilities = make(map[Fault]float64)
+	seed := time.Now().UnixNano()
+
+	for _, item := range strings.Split(os.Getenv(configEnv), ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+
+		if key == seedKey {
+			if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+				seed = parsed
+			} else {
+				log.Errorf("Invalid chaos seed %q: %s", value, err)
+			}
+
+			continue
+		}
+
+		switch fault := Fault(key); fault {
+		case Database, Telegram, Crash:
+			probability, err := strconv.ParseFloat(value, 64)
+			if err != nil || probability < 0 || probability > 1 {
+				log.Errorf("Invalid chaos %s probability %q", fault, value)
+
+				continue
+			}
+
+			probabilities[fault] = probability
+
+		default:
+			log.Errorf("Unknown chaos fault %q", key)
+		}
+	}
+
+	random = rand.New(rand.NewSource(seed)) //nolint:gosec // faults don't need secure randomness
+
+	log.WithFields(log.Fields{"faults": probabilities, "seed": seed}).Warn("Chaos fault injection is enabled")
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Fire returns true if the fault should be injected now.
+func Fire(fault Fault) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	probability := probabilities[fault]
+
+	return probability > 0 && random.Float64() < probability
+}
+
+// CrashPoint kills the process if the crash fault fires, point names the place in logs.
+func CrashPoint(point string) {
+	if !Fire(Crash) {
+		return
+	}
+
+	log.WithField("point", point).Warn("Chaos: killing the process")
+
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = process.Kill()
+	}
+
+	if err != nil {
+		log.Errorf("Chaos: failed to kill the process: %s", err)
+	}
+}
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !minimal && !nocharts
+
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Enabled is true in builds with charts.
+const Enabled = true
+
+const (
+	width        = 800
+	height       = 400
+	marginLeft   = 40
+	marginRight  = 10
+	marginTop    = 10
+	marginBottom = 30
+	barGap       = 2
+	// glyph pixels are scaled to keep labels readable on phones.
+	glyphScale  = 2
+	glyphWidth  = 3
+	glyphHeight = 5
+	labelGap    = 6
+	maxGridRows = 8
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//nolint:gochecknoglobals
+var (
+	background = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	gridColor  = color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+	axisColor  = color.RGBA{R: 0x60, G: 0x60, B: 0x60, A: 0xff}
+	barColor   = color.RGBA{R: 0x1f, G: 0x3a, B: 0x93, A: 0xff}
+)
+
+// digits is a 3x5 bitmap font, the standard library has no fonts and labels are numbers only.
+//
+//nolint:gochecknoglobals
+var digits = [10][glyphHeight]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{".#.", "##.", ".#.", ".#.", "###"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", "###", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", "..#", "..#", "..#"},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Render draws bars as a PNG chart with the hours scale on the left.
+func Render(bars []Bar) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: background}, image.Point{}, draw.Src)
+
+	plot := image.Rect(marginLeft, marginTop, width-marginRight, height-marginBottom)
+
+	var longest time.Duration
+
+	for _, bar := range bars {
+		longest = max(longest, bar.Value)
+	}
+
+	// the scale is in whole hours with at most maxGridRows grid lines
+	hours := max(1, int(math.Ceil(longest.Hours())))
+	step := (hours + maxGridRows - 1) / maxGridRows
+	hours = (hours + step - 1) / step * step
+
+	for hour := 0; hour <= hours; hour += step {
+		y := plot.Max.Y - hour*plot.Dy()/hours
+
+		fill(img, image.Rect(plot.Min.X, y, plot.Max.X, y+1), gridColor)
+		drawNumber(img, hour, plot.Min.X-labelGap, y-glyphHeight*glyphScale/2)
+	}
+
+	if len(bars) != 0 {
+		slot := plot.Dx() / len(bars)
+
+		for i, bar := range bars {
+			x := plot.Min.X + i*slot
+			barHeight := int(bar.Value.Hours() / float64(hours) * float64(plot.Dy()))
+
+			fill(img, image.Rect(x+barGap, plot.Max.Y-barHeight, x+slot-barGap, plot.Max.Y), barColor)
+			drawNumber(img, bar.Label, x+slot/2+numberWidth(bar.Label)/2, plot.Max.Y+labelGap)
+		}
+	}
+
+	fill(img, image.Rect(plot.Min.X, plot.Min.Y, plot.Min.X+1, plot.Max.Y), axisColor)
+	fill(img, image.Rect(plot.Min.X, plot.Max.Y, plot.Max.X, plot.Max.Y+1), axisColor)
+
+	var buffer bytes.Buffer
+
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func fill(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+func numberWidth(number int) int {
+	length := len(strconv.Itoa(number))
+
+	return length*(glyphWidth+1)*glyphScale - glyphScale
+}
+
+// drawNumber draws the number ending at x with its top at y.
+func drawNumber(img *image.RGBA, number, x, y int) {
+	x -= numberWidth(number)
+
+	for _, char := range strconv.Itoa(number) {
+		glyph := digits[char-'0']
+
+		for row, line := range glyph {
+			for column, pixel := range line {
+				if pixel != '#' {
+					continue
+				}
+
+				left, top := x+column*glyphScale, y+row*glyphScale
+
+				fill(img, image.Rect(left, top, left+glyphScale, top+glyphScale), axisColor)
+			}
+		}
+
+		x += (glyphWidth + 1) * glyphScale
+	}
+}
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build minimal || nocharts
+
+package chart
+
+import "errors"
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Enabled is true in builds with charts.
+const Enabled = false
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// ErrDisabled is returned by Render in builds without charts.
+var ErrDisabled = errors.New("charts are not included in this build")
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Render always fails in builds without charts, image encoding is left out of them.
+func Render([]Bar) ([]byte, error) {
+	return nil, ErrDisabled
+}
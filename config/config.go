@@ -0,0 +1,595 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// TelegramTokenCredential is the systemd credential name of the bot token, it keeps the token out of the config file
+// and the service environment.
+const TelegramTokenCredential = "telegram-token"
+
+const (
+	// credentialsDirEnv is set by systemd for services with LoadCredential.
+	credentialsDirEnv      = "CREDENTIALS_DIRECTORY"
+	defaultWorkingDir      = "/var/electrobot"
+	defaultLogLevel        = "debug"
+	defaultPollTimeout     = 60
+	defaultAliveInterval   = 5 * time.Second
+	defaultOutageThreshold = time.Minute
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Duration is time.Duration which is (un)marshaled from/to JSON as a string like "5s".
+type Duration struct {
+	time.Duration
+}
+
+// TelegramConfig Telegram bot configuration.
+type TelegramConfig struct {
+	Token                   string   `json:"token" secret:"true"`
+	APIEndpoint             string   `json:"apiEndpoint"`
+	PollTimeout             int      `json:"pollTimeout"`
+	PollLimit               int      `json:"pollLimit"`
+	AllowedUpdates          []string `json:"allowedUpdates"`
+	LowBandwidth            bool     `json:"lowBandwidth"`
+	LowBandwidthPollTimeout int      `json:"lowBandwidthPollTimeout"`
+	SendAttempts            int      `json:"sendAttempts"`
+	Channels                []string `json:"channels"`
+	ChannelFlapWindow       Duration `json:"channelFlapWindow"`
+}
+
+// UplinkConfig uplink monitor configuration.
+type UplinkConfig struct {
+	BackupInterfaces []string `json:"backupInterfaces"`
+	CheckInterval    Duration `json:"checkInterval"`
+}
+
+// DDNSConfig status page DNS failover configuration, enabled when the provider is set.
+type DDNSConfig struct {
+	Provider      string   `json:"provider"`
+	URL           string   `json:"url"`
+	Token         string   `json:"token" secret:"true"`
+	Domain        string   `json:"domain"`
+	ZoneID        string   `json:"zoneId"`
+	RecordID      string   `json:"recordId"`
+	Primary       string   `json:"primary"`
+	Failover      string   `json:"failover"`
+	RetryInterval Duration `json:"retryInterval"`
+}
+
+// HostMonitorConfig monitored premises host probing configuration, enabled when hosts are set.
+type HostMonitorConfig struct {
+	Location string   `json:"location"`
+	Hosts    []string `json:"hosts"`
+	Quorum   int      `json:"quorum"`
+	Interval Duration `json:"interval"`
+	Timeout  Duration `json:"timeout"`
+	Failures int      `json:"failures"`
+}
+
+// HeartbeatConfig location heartbeat receiver configuration, the receiver is the heartbeat HTTP feature.
+type HeartbeatConfig struct {
+	Threshold     Duration `json:"threshold"`
+	CheckInterval Duration `json:"checkInterval"`
+	ClockSkew     Duration `json:"clockSkew"`
+	LowBattery    int      `json:"lowBattery"`
+	// MinVoltage and MaxVoltage bound the normal mains voltage, VoltageHysteresis volts within the bounds end an alert.
+	MinVoltage        int `json:"minVoltage"`
+	MaxVoltage        int `json:"maxVoltage"`
+	VoltageHysteresis int `json:"voltageHysteresis"`
+	// Services lists local services sharing the host which report their liveness, see heartbeat.Config.
+	Services []string `json:"services"`
+}
+
+// ArchiveConfig outage archival configuration.
+type ArchiveConfig struct {
+	Dir    string   `json:"dir"`
+	MaxAge Duration `json:"maxAge"`
+}
+
+// RetentionConfig event retention configuration, enabled when event max age is set.
+type RetentionConfig struct {
+	EventMaxAge    Duration `json:"eventMaxAge"`
+	VacuumInterval Duration `json:"vacuumInterval"`
+}
+
+// BackupConfig scheduled database backup configuration, enabled when interval is set.
+type BackupConfig struct {
+	Dir      string   `json:"dir"`
+	Interval Duration `json:"interval"`
+	Keep     int      `json:"keep"`
+}
+
+// UpdateConfig self-update release source configuration, enabled when url is set.
+type UpdateConfig struct {
+	URL       string `json:"url"`
+	PublicKey string `json:"publicKey"`
+}
+
+// OpenDataConfig anonymized outage dataset publishing configuration.
+type OpenDataConfig struct {
+	Region   string   `json:"region"`
+	Days     int      `json:"days"`
+	Interval Duration `json:"interval"`
+}
+
+// DashboardConfig web dashboard configuration.
+type DashboardConfig struct {
+	Secret string `json:"secret" secret:"true"`
+	Weeks  int    `json:"weeks"`
+}
+
+// ScheduleImportConfig planned outage schedule import configuration, enabled when group is set.
+type ScheduleImportConfig struct {
+	URL           string   `json:"url"`
+	Region        string   `json:"region"`
+	Group         string   `json:"group"`
+	CheckInterval Duration `json:"checkInterval"`
+}
+
+// TLSConfig HTTP server TLS configuration.
+type TLSConfig struct {
+	CertFile     string         `json:"certFile"`
+	KeyFile      string         `json:"keyFile"`
+	ClientCAFile string         `json:"clientCaFile"`
+	Autocert     AutocertConfig `json:"autocert"`
+}
+
+// AutocertConfig Let's Encrypt automatic TLS configuration.
+type AutocertConfig struct {
+	Domains         []string `json:"domains"`
+	Email           string   `json:"email"`
+	CacheDir        string   `json:"cacheDir"`
+	ChallengeListen string   `json:"challengeListen"`
+}
+
+// HTTPFeatureConfig per-feature HTTP configuration.
+type HTTPFeatureConfig struct {
+	Enabled    bool     `json:"enabled"`
+	Listen     string   `json:"listen"`
+	Auth       bool     `json:"auth"`
+	AuthToken  string   `json:"authToken" secret:"true"`
+	ClientCert bool     `json:"clientCert"`
+	AllowIPs   []string `json:"allowIps"`
+	// WebhookSecret verifies inbound webhooks of the feature.
+	WebhookSecret string `json:"webhookSecret" secret:"true"`
+	// RateLimit limits requests per minute from a single IP to the feature.
+	RateLimit int      `json:"rateLimit"`
+	CacheTTL  Duration `json:"cacheTtl"`
+}
+
+// FirewallConfig HTTP request filtering configuration.
+type FirewallConfig struct {
+	AllowIPs     []string `json:"allowIps"`
+	DenyIPs      []string `json:"denyIps"`
+	MaxBodySize  int64    `json:"maxBodySize"`
+	MaxURLLength int      `json:"maxUrlLength"`
+	RateLimit    int      `json:"rateLimit"`
+}
+
+// HTTPConfig embedded HTTP server configuration.
+type HTTPConfig struct {
+	Listen    string                       `json:"listen"`
+	TLS       TLSConfig                    `json:"tls"`
+	AuthToken string                       `json:"authToken" secret:"true"`
+	Firewall  FirewallConfig               `json:"firewall"`
+	Features  map[string]HTTPFeatureConfig `json:"features"`
+}
+
+// Config instance.
+type Config struct {
+	WorkingDir           string               `json:"workingDir"`
+	LogLevel             string               `json:"logLevel"`
+	OwnerChatID          int64                `json:"ownerChatId"`
+	AdminIDs             []int64              `json:"adminIds"`
+	AliveInterval        Duration             `json:"aliveInterval"`
+	OutageThreshold      Duration             `json:"outageThreshold"`
+	RestoreAdvisoryDelay Duration             `json:"restoreAdvisoryDelay"`
+	WideOutageWindow     Duration             `json:"wideOutageWindow"`
+	OutageUpdateInterval Duration             `json:"outageUpdateInterval"`
+	NotificationBatch    Duration             `json:"notificationBatchWindow"`
+	SelfTestFailFast     bool                 `json:"selfTestFailFast"`
+	DefaultLanguage      string               `json:"defaultLanguage"`
+	DefaultTimezone      string               `json:"defaultTimezone"`
+	ScheduleOCRCommand   string               `json:"scheduleOcrCommand"`
+	Telegram             TelegramConfig       `json:"telegram"`
+	Uplink               UplinkConfig         `json:"uplink"`
+	DDNS                 DDNSConfig           `json:"ddns"`
+	HostMonitor          HostMonitorConfig    `json:"hostMonitor"`
+	Heartbeat            HeartbeatConfig      `json:"heartbeat"`
+	Archive              ArchiveConfig        `json:"archive"`
+	Backup               BackupConfig         `json:"backup"`
+	Retention            RetentionConfig      `json:"retention"`
+	OpenData             OpenDataConfig       `json:"openData"`
+	Dashboard            DashboardConfig      `json:"dashboard"`
+	ScheduleImport       ScheduleImportConfig `json:"scheduleImport"`
+	Update               UpdateConfig         `json:"update"`
+	HTTP                 HTTPConfig           `json:"http"`
+
+	// fileName and positions locate options in the loaded file for validation errors.
+	fileName  string
+	positions map[string]position
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates config from JSON file with optional // comments, applies env variable overrides and validates
+// the result.
+// Missing file is not an error if optional is set, defaults and env variables are used then.
+// Invalid options are reported together as *ValidationError with their file positions.
+func New(fileName string, optional bool) (config *Config, err error) {
+	return newConfig(fileName, optional, true)
+}
+
+// Inspect creates config like New but doesn't require the bot token, it may be available to the service only as
+// a systemd credential.
+func Inspect(fileName string, optional bool) (config *Config, err error) {
+	return newConfig(fileName, optional, false)
+}
+
+// MarshalJSON marshals duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON unmarshals duration from a string like "5s" or from a number of seconds, empty string is zero.
+func (d *Duration) UnmarshalJSON(b []byte) (err error) {
+	var value interface{}
+
+	if err = json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case float64:
+		d.Duration = time.Duration(value * float64(time.Second))
+
+		return nil
+
+	case string:
+		if value == "" {
+			d.Duration = 0
+
+			return nil
+		}
+
+		if d.Duration, err = time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("invalid duration %s", string(b))
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func newConfig(fileName string, optional, requireToken bool) (config *Config, err error) {
+	config = &Config{
+		WorkingDir:      defaultWorkingDir,
+		LogLevel:        defaultLogLevel,
+		AliveInterval:   Duration{defaultAliveInterval},
+		OutageThreshold: Duration{defaultOutageThreshold},
+		Telegram:        TelegramConfig{PollTimeout: defaultPollTimeout},
+	}
+
+	if fileName != "" {
+		if err = config.load(fileName, optional); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = config.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err = config.validate(requireToken); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (config *Config) load(fileName string, optional bool) error {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		if optional && errors.Is(err, os.ErrNotExist) {
+			log.WithField("file", fileName).Debug("Config file not found, using defaults")
+
+			return nil
+		}
+
+		return err
+	}
+
+	config.fileName = fileName
+	data = stripComments(data)
+
+	var errs []FieldError
+
+	// schema errors are reported with positions instead of the first error of json.Unmarshal
+	if config.positions, errs = scanSchema(data); len(errs) != 0 {
+		return config.validationError(errs)
+	}
+
+	if err = json.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", fileName, err)
+	}
+
+	return nil
+}
+
+func (config *Config) applyEnv() (err error) {
+	if err = overrideCredential(&config.Telegram.Token, TelegramTokenCredential); err != nil {
+		return err
+	}
+
+	overrideString(&config.Telegram.Token, "TELEGRAM_BOT_TOKEN")
+	overrideString(&config.WorkingDir, "ELECTROBOT_WORKING_DIR")
+	overrideString(&config.LogLevel, "ELECTROBOT_LOG_LEVEL")
+	overrideString(&config.DefaultLanguage, "ELECTROBOT_DEFAULT_LANGUAGE")
+	overrideString(&config.DefaultTimezone, "ELECTROBOT_DEFAULT_TIMEZONE")
+	overrideString(&config.ScheduleOCRCommand, "ELECTROBOT_SCHEDULE_OCR_COMMAND")
+	overrideList(&config.Telegram.AllowedUpdates, "TELEGRAM_ALLOWED_UPDATES")
+	overrideList(&config.Telegram.Channels, "TELEGRAM_CHANNELS")
+	overrideString(&config.HTTP.Listen, "ELECTROBOT_HTTP_LISTEN")
+	overrideString(&config.HTTP.AuthToken, "ELECTROBOT_HTTP_AUTH_TOKEN")
+	overrideList(&config.HTTP.TLS.Autocert.Domains, "ELECTROBOT_AUTOCERT_DOMAINS")
+	overrideString(&config.HTTP.TLS.Autocert.Email, "ELECTROBOT_AUTOCERT_EMAIL")
+	overrideList(&config.HTTP.Firewall.AllowIPs, "ELECTROBOT_HTTP_ALLOW_IPS")
+	overrideList(&config.HTTP.Firewall.DenyIPs, "ELECTROBOT_HTTP_DENY_IPS")
+	overrideList(&config.Uplink.BackupInterfaces, "ELECTROBOT_BACKUP_INTERFACES")
+	overrideString(&config.DDNS.Token, "ELECTROBOT_DDNS_TOKEN")
+	overrideString(&config.HostMonitor.Location, "ELECTROBOT_MONITOR_LOCATION")
+	overrideList(&config.HostMonitor.Hosts, "ELECTROBOT_MONITOR_HOSTS")
+	overrideString(&config.OpenData.Region, "ELECTROBOT_OPEN_DATA_REGION")
+	overrideString(&config.Dashboard.Secret, "ELECTROBOT_DASHBOARD_SECRET")
+	overrideString(&config.ScheduleImport.Region, "ELECTROBOT_SCHEDULE_IMPORT_REGION")
+	overrideString(&config.ScheduleImport.Group, "ELECTROBOT_SCHEDULE_IMPORT_GROUP")
+	overrideString(&config.Update.URL, "ELECTROBOT_UPDATE_URL")
+	overrideString(&config.Update.PublicKey, "ELECTROBOT_UPDATE_PUBLIC_KEY")
+
+	if err = overrideInt(&config.Telegram.PollTimeout, "TELEGRAM_POLL_TIMEOUT"); err != nil {
+		return err
+	}
+
+	if err = overrideInt(&config.Telegram.PollLimit, "TELEGRAM_POLL_LIMIT"); err != nil {
+		return err
+	}
+
+	if err = overrideInt(&config.Telegram.SendAttempts, "TELEGRAM_SEND_ATTEMPTS"); err != nil {
+		return err
+	}
+
+	if err = overrideInt64(&config.OwnerChatID, "ELECTROBOT_OWNER_CHAT_ID"); err != nil {
+		return err
+	}
+
+	if err = overrideInt64List(&config.AdminIDs, "ELECTROBOT_ADMIN_IDS"); err != nil {
+		return err
+	}
+
+	if err = overrideBool(&config.Telegram.LowBandwidth, "ELECTROBOT_LOW_BANDWIDTH"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.Telegram.ChannelFlapWindow, "TELEGRAM_CHANNEL_FLAP_WINDOW"); err != nil {
+		return err
+	}
+
+	if err = overrideBool(&config.SelfTestFailFast, "ELECTROBOT_SELFTEST_FAIL_FAST"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.AliveInterval, "ELECTROBOT_ALIVE_INTERVAL"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.OutageThreshold, "ELECTROBOT_OUTAGE_THRESHOLD"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.Heartbeat.Threshold, "ELECTROBOT_HEARTBEAT_THRESHOLD"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.Heartbeat.ClockSkew, "ELECTROBOT_HEARTBEAT_CLOCK_SKEW"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.Archive.MaxAge, "ELECTROBOT_ARCHIVE_MAX_AGE"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.Retention.EventMaxAge, "ELECTROBOT_EVENT_MAX_AGE"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.Backup.Interval, "ELECTROBOT_BACKUP_INTERVAL"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.RestoreAdvisoryDelay, "ELECTROBOT_RESTORE_ADVISORY_DELAY"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.WideOutageWindow, "ELECTROBOT_WIDE_OUTAGE_WINDOW"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.OutageUpdateInterval, "ELECTROBOT_OUTAGE_UPDATE_INTERVAL"); err != nil {
+		return err
+	}
+
+	if err = overrideDuration(&config.NotificationBatch, "ELECTROBOT_NOTIFICATION_BATCH_WINDOW"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// overrideCredential reads the value from the systemd credential, missing credentials are ignored.
+func overrideCredential(value *string, name string) error {
+	dir, ok := os.LookupEnv(credentialsDirEnv)
+	if !ok || dir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read %s credential: %w", name, err)
+	}
+
+	*value = strings.TrimSpace(string(data))
+
+	return nil
+}
+
+func overrideString(value *string, name string) {
+	if env, ok := os.LookupEnv(name); ok {
+		*value = env
+	}
+}
+
+func overrideList(value *[]string, name string) {
+	env, ok := os.LookupEnv(name)
+	if !ok {
+		return
+	}
+
+	*value = nil
+
+	for _, item := range strings.Split(env, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*value = append(*value, item)
+		}
+	}
+}
+
+func overrideInt(value *int, name string) error {
+	env, ok := os.LookupEnv(name)
+	if !ok || env == "" {
+		return nil
+	}
+
+	result, err := strconv.Atoi(env)
+	if err != nil {
+		return fmt.Errorf("invalid %s env variable value %q: %w", name, env, err)
+	}
+
+	*value = result
+
+	return nil
+}
+
+func overrideInt64(value *int64, name string) error {
+	env, ok := os.LookupEnv(name)
+	if !ok || env == "" {
+		return nil
+	}
+
+	result, err := strconv.ParseInt(env, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s env variable value %q: %w", name, env, err)
+	}
+
+	*value = result
+
+	return nil
+}
+
+func overrideInt64List(value *[]int64, name string) error {
+	if _, ok := os.LookupEnv(name); !ok {
+		return nil
+	}
+
+	var items []string
+
+	overrideList(&items, name)
+
+	*value = nil
+
+	for _, item := range items {
+		result, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s env variable value %q: %w", name, item, err)
+		}
+
+		*value = append(*value, result)
+	}
+
+	return nil
+}
+
+func overrideBool(value *bool, name string) error {
+	env, ok := os.LookupEnv(name)
+	if !ok || env == "" {
+		return nil
+	}
+
+	result, err := strconv.ParseBool(env)
+	if err != nil {
+		return fmt.Errorf("invalid %s env variable value %q: %w", name, env, err)
+	}
+
+	*value = result
+
+	return nil
+}
+
+func overrideDuration(value *Duration, name string) error {
+	env, ok := os.LookupEnv(name)
+	if !ok || env == "" {
+		return nil
+	}
+
+	result, err := time.ParseDuration(env)
+	if err != nil {
+		return fmt.Errorf("invalid %s env variable value %q: %w", name, env, err)
+	}
+
+	value.Duration = result
+
+	return nil
+}
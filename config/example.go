@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	_ "embed"
+	"reflect"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// redactedValue replaces secrets in printed configs.
+const redactedValue = "<redacted>"
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//go:embed example.json
+var example []byte
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Example returns the commented example config with all options.
+func Example() []byte {
+	return append([]byte(nil), example...)
+}
+
+// Redacted returns a copy of the config with secrets replaced, used to print the effective config.
+func (config *Config) Redacted() *Config {
+	redactedConfig := redact(reflect.ValueOf(*config)).Interface().(Config) //nolint:forcetypeassert
+
+	return &redactedConfig
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// redact returns a copy of the value with non-empty string fields tagged secret:"true" replaced, maps are copied
+// so the original config is not changed.
+func redact(value reflect.Value) reflect.Value {
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+
+			switch {
+			case !field.IsExported():
+			case field.Tag.Get("secret") == "true" && field.Type.Kind() == reflect.String:
+				if !value.Field(i).IsZero() {
+					copied.Field(i).SetString(redactedValue)
+				}
+			default:
+				copied.Field(i).Set(redact(value.Field(i)))
+			}
+		}
+
+		return copied
+
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+
+		for iter := value.MapRange(); iter.Next(); {
+			copied.SetMapIndex(iter.Key(), redact(iter.Value()))
+		}
+
+		return copied
+
+	default:
+		return value
+	}
+}
+
+// stripComments blanks out // comments outside of strings, the rest of the data keeps its positions.
+func stripComments(data []byte) []byte {
+	result := append([]byte(nil), data...)
+	inString, escaped := false, false
+
+	for i := 0; i < len(result); i++ {
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case result[i] == '\\':
+				escaped = true
+			case result[i] == '"':
+				inString = false
+			}
+
+		case result[i] == '"':
+			inString = true
+
+		case result[i] == '/' && i+1 < len(result) && result[i+1] == '/':
+			for ; i < len(result) && result[i] != '\n'; i++ {
+				result[i] = ' '
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,682 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"electrobot/selfupdate"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	// maxPollLimit is the Telegram limit of updates per getUpdates call.
+	maxPollLimit = 100
+	// maxSuggestionDistance is the edit distance of unknown keys to known ones offered as "did you mean".
+	maxSuggestionDistance = 2
+	// maxHeartbeatClockSkew is the webhook timestamp window, older heartbeats are rejected anyway.
+	maxHeartbeatClockSkew = 5 * time.Minute
+	// maxPercent bounds percentage options.
+	maxPercent = 100
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//nolint:gochecknoglobals
+var durationType = reflect.TypeOf(Duration{})
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// FieldError is a problem with a single config option.
+type FieldError struct {
+	// Path is the dotted JSON path of the option, e.g. telegram.pollTimeout.
+	Path string
+	// Line and Column locate the option in the config file, zero if it is not set in the file.
+	Line    int
+	Column  int
+	Message string
+}
+
+// ValidationError lists all problems found in the config.
+type ValidationError struct {
+	File   string
+	Errors []FieldError
+}
+
+// position is the location of a JSON value in the config file.
+type position struct {
+	line   int
+	column int
+}
+
+// schemaScanner checks the config file structure against Config fields and records option positions.
+type schemaScanner struct {
+	data      []byte
+	decoder   *json.Decoder
+	positions map[string]position
+	errors    []FieldError
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Error returns one line per problem prefixed with the file location when it is known.
+func (err *ValidationError) Error() string {
+	lines := make([]string, 0, len(err.Errors)+1)
+	lines = append(lines, fmt.Sprintf("invalid config, %d problem(s) found:", len(err.Errors)))
+
+	for _, fieldErr := range err.Errors {
+		line := fieldErr.Message
+
+		if fieldErr.Path != "" {
+			line = fieldErr.Path + ": " + line
+		}
+
+		if fieldErr.Line > 0 {
+			line = fmt.Sprintf("%s:%d:%d: %s", err.File, fieldErr.Line, fieldErr.Column, line)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// CheckFeatures reports HTTP features which are configured but unknown to the application, usually misspelled.
+func (config *Config) CheckFeatures(known ...string) error {
+	var errs []FieldError
+
+	names := make([]string, 0, len(config.HTTP.Features))
+
+	for name := range config.HTTP.Features {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if contains(known, name) {
+			continue
+		}
+
+		message := "unknown HTTP feature, known features are " + strings.Join(known, ", ")
+
+		if suggestion := suggest(name, known); suggestion != "" {
+			message = fmt.Sprintf("unknown HTTP feature, did you mean %q?", suggestion)
+		}
+
+		errs = append(errs, config.fieldError("http.features."+name, message))
+	}
+
+	return config.validationError(errs)
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// scanSchema reports syntax errors, unknown keys and values of wrong type with their file positions.
+func scanSchema(data []byte) (positions map[string]position, errs []FieldError) {
+	scanner := &schemaScanner{
+		data: data, decoder: json.NewDecoder(bytes.NewReader(data)), positions: make(map[string]position),
+	}
+
+	scanner.decoder.UseNumber()
+
+	if err := scanner.scanValue("", reflect.TypeOf(Config{})); err != nil {
+		var syntaxErr *json.SyntaxError
+
+		offset := scanner.decoder.InputOffset()
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset
+		}
+
+		pos := scanner.position(offset)
+
+		scanner.errors = append(scanner.errors, FieldError{
+			Line: pos.line, Column: pos.column, Message: "invalid JSON: " + strings.TrimPrefix(err.Error(), "json: "),
+		})
+	}
+
+	return scanner.positions, scanner.errors
+}
+
+// scanValue checks the next JSON value against the Go type, errors are collected and only malformed JSON is returned.
+func (scanner *schemaScanner) scanValue(path string, valueType reflect.Type) error {
+	pos := scanner.position(scanner.decoder.InputOffset())
+
+	if path != "" {
+		scanner.positions[path] = pos
+	}
+
+	token, err := scanner.decoder.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+
+		return err
+	}
+
+	// null keeps the default of any option
+	if token == nil {
+		return nil
+	}
+
+	if valueType == durationType {
+		return scanner.checkDuration(path, pos, token)
+	}
+
+	delim, isDelim := token.(json.Delim)
+
+	switch valueType.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		if delim != '{' {
+			return scanner.mismatch(path, pos, token, "an object")
+		}
+
+		return scanner.scanObject(path, func(key string) (reflect.Type, string, bool) {
+			return structField(valueType, key)
+		})
+
+	case reflect.Map:
+		if delim != '{' {
+			return scanner.mismatch(path, pos, token, "an object")
+		}
+
+		return scanner.scanObject(path, func(key string) (reflect.Type, string, bool) {
+			return valueType.Elem(), key, true
+		})
+
+	case reflect.Slice:
+		if delim != '[' {
+			return scanner.mismatch(path, pos, token, "an array")
+		}
+
+		for i := 0; scanner.decoder.More(); i++ {
+			if err = scanner.scanValue(fmt.Sprintf("%s[%d]", path, i), valueType.Elem()); err != nil {
+				return err
+			}
+		}
+
+		_, err = scanner.decoder.Token()
+
+		return err
+
+	case reflect.String:
+		if _, ok := token.(string); !ok {
+			return scanner.mismatch(path, pos, token, "a string")
+		}
+
+	case reflect.Bool:
+		if _, ok := token.(bool); !ok {
+			return scanner.mismatch(path, pos, token, "true or false")
+		}
+
+	case reflect.Int, reflect.Int64:
+		number, ok := token.(json.Number)
+		if !ok {
+			return scanner.mismatch(path, pos, token, "a number")
+		}
+
+		if _, err = number.Int64(); err != nil {
+			scanner.add(path, pos, fmt.Sprintf("expected an integer, got %s", number))
+		}
+
+	default:
+		if isDelim {
+			return scanner.skip(delim)
+		}
+	}
+
+	return nil
+}
+
+// scanObject checks object keys, field returns the value type and canonical key name of known keys.
+func (scanner *schemaScanner) scanObject(path string,
+	field func(key string) (valueType reflect.Type, name string, ok bool),
+) error {
+	for scanner.decoder.More() {
+		pos := scanner.position(scanner.decoder.InputOffset())
+
+		token, err := scanner.decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := token.(string)
+		keyPath := joinPath(path, key)
+
+		valueType, name, ok := field(key)
+		if !ok {
+			scanner.add(keyPath, pos, "unknown option"+scanner.suggestion(path, key))
+
+			if err = scanner.skipValue(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err = scanner.scanValue(joinPath(path, name), valueType); err != nil {
+			return err
+		}
+	}
+
+	_, err := scanner.decoder.Token()
+
+	return err
+}
+
+func (scanner *schemaScanner) checkDuration(path string, pos position, token json.Token) error {
+	switch value := token.(type) {
+	case json.Number:
+		return nil
+
+	case string:
+		if _, err := time.ParseDuration(value); err != nil && value != "" {
+			scanner.add(path, pos, fmt.Sprintf("invalid duration %q, use a number of seconds or a value like "+
+				"\"30s\", \"5m\" or \"1h30m\"", value))
+		}
+
+		return nil
+
+	default:
+		return scanner.mismatch(path, pos, token, "a duration")
+	}
+}
+
+// mismatch records the wrong value type and skips the value.
+func (scanner *schemaScanner) mismatch(path string, pos position, token json.Token, expected string) error {
+	got := "a string"
+
+	switch value := token.(type) {
+	case json.Delim:
+		got = "an object"
+		if value == '[' {
+			got = "an array"
+		}
+	case json.Number:
+		got = "a number"
+	case bool:
+		got = "a boolean"
+	}
+
+	scanner.add(path, pos, fmt.Sprintf("expected %s, got %s", expected, got))
+
+	if delim, ok := token.(json.Delim); ok {
+		return scanner.skip(delim)
+	}
+
+	return nil
+}
+
+func (scanner *schemaScanner) skipValue() error {
+	token, err := scanner.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := token.(json.Delim); ok {
+		return scanner.skip(delim)
+	}
+
+	return nil
+}
+
+// skip skips the rest of the object or array opened by delim.
+func (scanner *schemaScanner) skip(delim json.Delim) error {
+	if delim != '{' && delim != '[' {
+		return nil
+	}
+
+	for depth := 1; depth > 0; {
+		token, err := scanner.decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+
+	return nil
+}
+
+func (scanner *schemaScanner) add(path string, pos position, message string) {
+	scanner.errors = append(scanner.errors, FieldError{Path: path, Line: pos.line, Column: pos.column,
+		Message: message})
+}
+
+// suggestion returns " (did you mean ...?)" for keys close to an option of the parent object.
+func (scanner *schemaScanner) suggestion(path, key string) string {
+	parentType := reflect.TypeOf(Config{})
+
+	if path != "" {
+		for _, part := range strings.Split(path, ".") {
+			fieldType, _, ok := structField(parentType, part)
+			if !ok || fieldType.Kind() != reflect.Struct {
+				return ""
+			}
+
+			parentType = fieldType
+		}
+	}
+
+	names := make([]string, 0, parentType.NumField())
+
+	for i := 0; i < parentType.NumField(); i++ {
+		names = append(names, jsonName(parentType.Field(i)))
+	}
+
+	if suggestion := suggest(key, names); suggestion != "" {
+		return fmt.Sprintf(", did you mean %q?", suggestion)
+	}
+
+	return ""
+}
+
+// position converts the byte offset to the line and column of the next token, whitespace and separators are skipped.
+func (scanner *schemaScanner) position(offset int64) position {
+	for offset < int64(len(scanner.data)) && strings.ContainsRune(" \t\r\n,:", rune(scanner.data[offset])) {
+		offset++
+	}
+
+	pos := position{line: 1, column: 1}
+
+	for _, b := range scanner.data[:min(offset, int64(len(scanner.data)))] {
+		if b == '\n' {
+			pos.line++
+			pos.column = 1
+		} else {
+			pos.column++
+		}
+	}
+
+	return pos
+}
+
+// validate checks option values and conflicting options after env variables are applied, the bot token is checked
+// only if required.
+func (config *Config) validate(requireToken bool) error {
+	var errs []FieldError
+
+	check := func(failed bool, path, message string) {
+		if failed {
+			errs = append(errs, config.fieldError(path, message))
+		}
+	}
+
+	errs = append(errs, config.checkNegative("", reflect.ValueOf(*config))...)
+
+	check(requireToken && config.Telegram.Token == "", "telegram.token", "bot token is required, set it here or with "+
+		"TELEGRAM_BOT_TOKEN")
+	check(config.Telegram.PollLimit > maxPollLimit, "telegram.pollLimit",
+		fmt.Sprintf("must not exceed %d", maxPollLimit))
+
+	for i, channel := range config.Telegram.Channels {
+		check(!strings.HasPrefix(channel, "@") && !isInteger(channel), fmt.Sprintf("telegram.channels[%d]", i),
+			fmt.Sprintf("invalid channel %q, expected @username or chat ID", channel))
+	}
+
+	_, err := log.ParseLevel(config.LogLevel)
+	check(err != nil, "logLevel", fmt.Sprintf("invalid log level %q, expected one of trace, debug, info, warning, "+
+		"error, fatal, panic", config.LogLevel))
+
+	if config.DefaultTimezone != "" {
+		_, err = time.LoadLocation(config.DefaultTimezone)
+		check(err != nil, "defaultTimezone", fmt.Sprintf("unknown timezone %q, use an IANA name like "+
+			"Europe/Kyiv", config.DefaultTimezone))
+	}
+
+	check(config.AliveInterval.Duration == 0, "aliveInterval", "must be positive")
+	check(config.OutageThreshold.Duration != 0 && config.OutageThreshold.Duration <= config.AliveInterval.Duration,
+		"outageThreshold", fmt.Sprintf("must be longer than aliveInterval %s, otherwise every heartbeat delay is "+
+			"an outage", config.AliveInterval))
+
+	ddns := config.DDNS
+	check(ddns.Provider != "" && ddns.Provider != "cloudflare" && ddns.Provider != "duckdns", "ddns.provider",
+		fmt.Sprintf("unknown provider %q, expected cloudflare or duckdns", ddns.Provider))
+	check(ddns.Provider != "" && len(config.Uplink.BackupInterfaces) == 0, "uplink.backupInterfaces",
+		"required by ddns, the failover follows the backup uplink")
+	check(ddns.Provider != "" && ddns.Token == "", "ddns.token", "required, set it here or with ELECTROBOT_DDNS_TOKEN")
+	check(ddns.Provider != "" && ddns.Domain == "", "ddns.domain", "required")
+	check(ddns.Provider != "" && ddns.Failover == "", "ddns.failover", "required")
+	check(ddns.Provider == "cloudflare" && (ddns.ZoneID == "" || ddns.RecordID == ""), "ddns.zoneId",
+		"cloudflare requires zoneId and recordId")
+	check(ddns.Provider == "cloudflare" && ddns.Primary == "", "ddns.primary", "required by cloudflare")
+
+	hostMonitor := config.HostMonitor
+	check(hostMonitor.Quorum > len(hostMonitor.Hosts), "hostMonitor.quorum",
+		fmt.Sprintf("must not exceed the number of hosts %d", len(hostMonitor.Hosts)))
+
+	for i, host := range hostMonitor.Hosts {
+		check(strings.TrimSpace(host) == "", fmt.Sprintf("hostMonitor.hosts[%d]", i), "must not be empty")
+	}
+
+	check(hostMonitor.Location != "" && len(hostMonitor.Hosts) == 0, "hostMonitor.location",
+		"requires hostMonitor.hosts, the host monitor is disabled without hosts")
+
+	heartbeat := config.Heartbeat
+	check(heartbeat.Threshold.Duration != 0 && heartbeat.Threshold.Duration <= heartbeat.CheckInterval.Duration,
+		"heartbeat.threshold", fmt.Sprintf("must be longer than heartbeat.checkInterval %s", heartbeat.CheckInterval))
+	check(heartbeat.ClockSkew.Duration < 0 || heartbeat.ClockSkew.Duration > maxHeartbeatClockSkew,
+		"heartbeat.clockSkew", fmt.Sprintf("must be between 0 and %s", maxHeartbeatClockSkew))
+	check(heartbeat.LowBattery < 0 || heartbeat.LowBattery >= maxPercent, "heartbeat.lowBattery",
+		fmt.Sprintf("must be a percentage below %d", maxPercent))
+	check(heartbeat.MinVoltage < 0 || heartbeat.MaxVoltage < 0 || heartbeat.VoltageHysteresis < 0,
+		"heartbeat.minVoltage", "voltage settings can't be negative")
+	check(heartbeat.MinVoltage > 0 && heartbeat.MaxVoltage > 0 &&
+		heartbeat.MinVoltage+2*heartbeat.VoltageHysteresis >= heartbeat.MaxVoltage, "heartbeat.maxVoltage",
+		"must be above heartbeat.minVoltage by more than twice heartbeat.voltageHysteresis")
+
+	for i, service := range heartbeat.Services {
+		check(service == "" || strings.ContainsAny(service, "/?# ") || contains(heartbeat.Services[:i], service),
+			fmt.Sprintf("heartbeat.services[%d]", i), fmt.Sprintf("invalid service %q, names must be unique URL path "+
+				"elements", service))
+	}
+
+	tls := config.HTTP.TLS
+	check((tls.CertFile == "") != (tls.KeyFile == ""), "http.tls.certFile", "certFile and keyFile must be set together")
+	check(tls.CertFile != "" && len(tls.Autocert.Domains) != 0, "http.tls.autocert.domains",
+		"conflicts with http.tls.certFile, use either a certificate file or Let's Encrypt")
+	check(tls.ClientCAFile != "" && tls.CertFile == "" && len(tls.Autocert.Domains) == 0, "http.tls.clientCaFile",
+		"requires TLS, set http.tls.certFile or http.tls.autocert.domains")
+
+	check(config.Update.URL != "" && config.Update.PublicKey == "", "update.publicKey",
+		"required to verify releases downloaded from update.url")
+
+	if config.Update.PublicKey != "" {
+		_, err = selfupdate.ParsePublicKey(config.Update.PublicKey)
+		check(err != nil, "update.publicKey", fmt.Sprintf("invalid Ed25519 public key: %s", err))
+	}
+
+	names := make([]string, 0, len(config.HTTP.Features))
+
+	for name := range config.HTTP.Features {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		feature := config.HTTP.Features[name]
+		path := "http.features." + name
+
+		if !feature.Enabled {
+			continue
+		}
+
+		check(feature.Listen == "" && config.HTTP.Listen == "", path+".listen",
+			"enabled feature needs a listen address, set it here or in http.listen")
+		check(feature.ClientCert && tls.ClientCAFile == "", path+".clientCert", "requires http.tls.clientCaFile")
+	}
+
+	return config.validationError(errs)
+}
+
+// checkNegative reports negative durations and counts, chat IDs may be negative and are not checked.
+func (config *Config) checkNegative(path string, value reflect.Value) (errs []FieldError) {
+	switch {
+	case value.Type() == durationType:
+		if value.Interface().(Duration).Duration < 0 { //nolint:forcetypeassert
+			errs = append(errs, config.fieldError(path, "must not be negative"))
+		}
+
+	case value.Kind() == reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			errs = append(errs, config.checkNegative(joinPath(path, jsonName(value.Type().Field(i))), value.Field(i))...)
+		}
+
+	case value.Kind() == reflect.Map:
+		keys := value.MapKeys()
+
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		for _, key := range keys {
+			errs = append(errs, config.checkNegative(joinPath(path, key.String()), value.MapIndex(key))...)
+		}
+
+	case value.Kind() == reflect.Int:
+		if value.Int() < 0 {
+			errs = append(errs, config.fieldError(path, "must not be negative"))
+		}
+	}
+
+	return errs
+}
+
+func (config *Config) fieldError(path, message string) FieldError {
+	pos := config.positions[path]
+
+	return FieldError{Path: path, Line: pos.line, Column: pos.column, Message: message}
+}
+
+func (config *Config) validationError(errs []FieldError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &ValidationError{File: config.fileName, Errors: errs}
+}
+
+// structField finds the struct field by JSON key, keys match case-insensitively like in encoding/json.
+func structField(structType reflect.Type, key string) (valueType reflect.Type, name string, ok bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		if name = jsonName(field); field.IsExported() && strings.EqualFold(name, key) {
+			return field.Type, name, true
+		}
+	}
+
+	return nil, "", false
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// suggest returns the closest name within maxSuggestionDistance edits, empty if there is none.
+func suggest(key string, names []string) (suggestion string) {
+	best := maxSuggestionDistance + 1
+
+	for _, name := range names {
+		if distance := editDistance(strings.ToLower(key), strings.ToLower(name)); distance < best {
+			best, suggestion = distance, name
+		}
+	}
+
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance of the strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isInteger(value string) bool {
+	_, err := json.Number(value).Int64()
+
+	return err == nil
+}
@@ -0,0 +1,279 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestScanSchema(t *testing.T) {
+	testData := []struct {
+		name string
+		data string
+		// expected error as path:line:column, empty means the file is valid
+		err string
+	}{
+		{name: "valid", data: "{\"telegram\": {\"token\": \"x\"}, \"aliveInterval\": \"1m\"}"},
+		{name: "unknown option", data: "{\n  \"telegram\": {\"tokn\": \"x\"}\n}", err: "telegram.tokn:2:16"},
+		{name: "invalid duration", data: "{\n  \"aliveInterval\": \"5 minutes\"\n}", err: "aliveInterval:2:20"},
+		{
+			name: "wrong type", data: "{\n  \"telegram\": {\"pollTimeout\": \"30\"}\n}",
+			err: "telegram.pollTimeout:2:31",
+		},
+		{name: "syntax error", data: "{\n  \"logLevel\": \"info\",\n}", err: ":3:1"},
+	}
+
+	for _, item := range testData {
+		_, errs := scanSchema([]byte(item.data))
+
+		if item.err == "" {
+			if len(errs) != 0 {
+				t.Errorf("Unexpected %s errors: %v", item.name, errs)
+			}
+
+			continue
+		}
+
+		if len(errs) != 1 {
+			t.Errorf("Wrong %s errors: %v", item.name, errs)
+
+			continue
+		}
+
+		if location := fmt.Sprintf("%s:%d:%d", errs[0].Path, errs[0].Line, errs[0].Column); location != item.err {
+			t.Errorf("Wrong %s error location: %s", item.name, location)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testData := []struct {
+		name         string
+		modify       func(config *Config)
+		requireToken bool
+		// paths of expected errors, none means the config is valid
+		paths []string
+	}{
+		{name: "defaults", requireToken: true},
+		{
+			name: "token required", requireToken: true, paths: []string{"telegram.token"},
+			modify: func(config *Config) { config.Telegram.Token = "" },
+		},
+		{
+			name: "token from credential", requireToken: false,
+			modify: func(config *Config) { config.Telegram.Token = "" },
+		},
+		{
+			name: "negative duration", paths: []string{"archive.maxAge"},
+			modify: func(config *Config) { config.Archive.MaxAge.Duration = -time.Hour },
+		},
+		{
+			name: "negative count", paths: []string{"backup.keep"},
+			modify: func(config *Config) { config.Backup.Keep = -1 },
+		},
+		{
+			name: "poll limit", paths: []string{"telegram.pollLimit"},
+			modify: func(config *Config) { config.Telegram.PollLimit = maxPollLimit + 1 },
+		},
+		{
+			name: "channels", paths: []string{"telegram.channels[1]"},
+			modify: func(config *Config) { config.Telegram.Channels = []string{"@news", "news", "-100123"} },
+		},
+		{
+			name: "log level", paths: []string{"logLevel"},
+			modify: func(config *Config) { config.LogLevel = "verbose" },
+		},
+		{
+			name: "timezone", paths: []string{"defaultTimezone"},
+			modify: func(config *Config) { config.DefaultTimezone = "Europe/Atlantis" },
+		},
+		{
+			name: "zero alive interval", paths: []string{"aliveInterval"},
+			modify: func(config *Config) { config.AliveInterval.Duration = 0 },
+		},
+		{
+			name: "outage threshold", paths: []string{"outageThreshold"},
+			modify: func(config *Config) { config.OutageThreshold.Duration = config.AliveInterval.Duration },
+		},
+		{
+			name: "host monitor", modify: func(config *Config) {
+				config.HostMonitor = HostMonitorConfig{
+					Location: "home", Hosts: []string{"192.0.2.1", "192.0.2.2:80"}, Quorum: 2,
+				}
+			},
+		},
+		{
+			name: "host monitor quorum", paths: []string{"hostMonitor.quorum"},
+			modify: func(config *Config) {
+				config.HostMonitor = HostMonitorConfig{Hosts: []string{"192.0.2.1"}, Quorum: 2}
+			},
+		},
+		{
+			name: "host monitor empty host", paths: []string{"hostMonitor.hosts[1]"},
+			modify: func(config *Config) { config.HostMonitor.Hosts = []string{"192.0.2.1", " "} },
+		},
+		{
+			name: "host monitor location without hosts", paths: []string{"hostMonitor.location"},
+			modify: func(config *Config) { config.HostMonitor.Location = "home" },
+		},
+		{
+			name: "ddns", paths: []string{"uplink.backupInterfaces", "ddns.token", "ddns.zoneId", "ddns.primary"},
+			modify: func(config *Config) {
+				config.DDNS = DDNSConfig{Provider: "cloudflare", Domain: "status.example.com", Failover: "192.0.2.1"}
+			},
+		},
+		{
+			name: "ddns provider", paths: []string{"ddns.provider"},
+			modify: func(config *Config) {
+				config.Uplink.BackupInterfaces = []string{"wwan0"}
+				config.DDNS = DDNSConfig{Provider: "noip", Token: "token", Domain: "status", Failover: "192.0.2.1"}
+			},
+		},
+		{
+			name: "heartbeat threshold", paths: []string{"heartbeat.threshold"},
+			modify: func(config *Config) {
+				config.Heartbeat = HeartbeatConfig{
+					Threshold: Duration{time.Minute}, CheckInterval: Duration{time.Minute},
+				}
+			},
+		},
+		{
+			name: "heartbeat clock skew", paths: []string{"heartbeat.clockSkew"},
+			modify: func(config *Config) { config.Heartbeat.ClockSkew = Duration{10 * time.Minute} },
+		},
+		{
+			name: "heartbeat low battery", paths: []string{"heartbeat.lowBattery"},
+			modify: func(config *Config) { config.Heartbeat.LowBattery = 100 },
+		},
+		{
+			name: "heartbeat voltage bounds", paths: []string{"heartbeat.maxVoltage"},
+			modify: func(config *Config) {
+				config.Heartbeat.MinVoltage, config.Heartbeat.MaxVoltage = 220, 225
+				config.Heartbeat.VoltageHysteresis = 5
+			},
+		},
+		{
+			name: "heartbeat duplicate service", paths: []string{"heartbeat.services[1]"},
+			modify: func(config *Config) { config.Heartbeat.Services = []string{"nas", "nas", "backup"} },
+		},
+		{
+			name: "TLS key without certificate", paths: []string{"http.tls.certFile"},
+			modify: func(config *Config) { config.HTTP.TLS.KeyFile = "key.pem" },
+		},
+		{
+			name: "TLS certificate and autocert", paths: []string{"http.tls.autocert.domains"},
+			modify: func(config *Config) {
+				config.HTTP.TLS = TLSConfig{
+					CertFile: "cert.pem", KeyFile: "key.pem", Autocert: AutocertConfig{Domains: []string{"example.com"}},
+				}
+			},
+		},
+		{
+			name: "client CA without TLS", paths: []string{"http.tls.clientCaFile"},
+			modify: func(config *Config) { config.HTTP.TLS.ClientCAFile = "ca.pem" },
+		},
+		{
+			name: "feature without listen address", paths: []string{"http.features.api.listen"},
+			modify: func(config *Config) {
+				config.HTTP.Features = map[string]HTTPFeatureConfig{"api": {Enabled: true}, "probes": {}}
+			},
+		},
+		{
+			name: "feature client certificate", paths: []string{"http.features.api.clientCert"},
+			modify: func(config *Config) {
+				config.HTTP.Listen = ":8080"
+				config.HTTP.Features = map[string]HTTPFeatureConfig{"api": {Enabled: true, ClientCert: true}}
+			},
+		},
+		{
+			// the negative threshold is also not longer than the alive interval
+			name:  "several problems",
+			paths: []string{"outageThreshold", "logLevel", "aliveInterval", "outageThreshold"},
+			modify: func(config *Config) {
+				config.LogLevel = ""
+				config.AliveInterval.Duration = 0
+				config.OutageThreshold.Duration = -time.Second
+			},
+		},
+	}
+
+	for _, item := range testData {
+		t.Run(item.name, func(t *testing.T) {
+			config := validConfig()
+
+			if item.modify != nil {
+				item.modify(config)
+			}
+
+			err := config.validate(item.requireToken)
+
+			var validationErr *ValidationError
+
+			if len(item.paths) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected validation error: %s", err)
+				}
+
+				return
+			}
+
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validation error expected, got: %v", err)
+			}
+
+			if len(validationErr.Errors) != len(item.paths) {
+				t.Fatalf("Wrong validation errors: %s", err)
+			}
+
+			for _, path := range item.paths {
+				if !hasPath(validationErr, path) {
+					t.Errorf("No error of %s: %s", path, err)
+				}
+			}
+		})
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func validConfig() *Config {
+	return &Config{
+		WorkingDir:      defaultWorkingDir,
+		LogLevel:        defaultLogLevel,
+		AliveInterval:   Duration{defaultAliveInterval},
+		OutageThreshold: Duration{defaultOutageThreshold},
+		Telegram:        TelegramConfig{Token: "token", PollTimeout: defaultPollTimeout},
+	}
+}
+
+func hasPath(err *ValidationError, path string) bool {
+	for _, fieldErr := range err.Errors {
+		if fieldErr.Path == path {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import "time"
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// FeatureName is the HTTP server feature name of the web dashboard.
+const FeatureName = "dashboard"
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with web dashboard configuration.
+type Config struct {
+	// Secret is the shared secret entered on the login page.
+	Secret string
+	// Timezone defines day boundaries, UTC is used if empty or invalid.
+	Timezone string
+	// Weeks is the number of weeks in the outage calendar.
+	Weeks int
+}
+
+// StatusProvider provides the current power state.
+type StatusProvider interface {
+	// PowerState returns whether power is on, the time it is on or off since and the time of the last power check,
+	// zero if not checked yet.
+	PowerState() (on bool, since, lastCheck time.Time)
+}
@@ -0,0 +1,350 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !minimal && !nodashboard
+
+package dashboard
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"electrobot/archive"
+	"electrobot/chart"
+	"electrobot/httpserver"
+	"electrobot/report"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Enabled is true in builds with the web dashboard.
+const Enabled = true
+
+const (
+	defaultWeeks    = 12
+	chartDays       = 30
+	daysInWeek      = 7
+	sessionCookie   = "electrobot_dashboard"
+	sessionMaxAge   = 30 * 24 * time.Hour
+	secretFormField = "secret"
+	timeFormat      = "2006-01-02 15:04"
+	dateFormat      = "2006-01-02"
+	// calendarLevels is the number of calendar cell shades for days with outages.
+	calendarLevels = 4
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//go:embed templates/*.html static/*
+var content embed.FS
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Dashboard serves the web UI with power state, outage calendar and statistics.
+type Dashboard struct {
+	config    Config
+	location  *time.Location
+	storage   archive.OutageStorage
+	status    StatusProvider
+	templates *template.Template
+	// session is the cookie value of logged in browsers, it is derived from the secret and changes with it.
+	session string
+}
+
+type calendarDay struct {
+	Date    string
+	Outages int
+	Minutes int
+	Level   int
+	Future  bool
+}
+
+type monthBar struct {
+	Name    string
+	Hours   float64
+	Percent int
+}
+
+type pageData struct {
+	PowerKnown bool
+	PowerOn    bool
+	Since      string
+	For        string
+	LastCheck  string
+	Weeks      [][]calendarDay
+	Year       int
+	Count      int
+	Total      string
+	Longest    string
+	Months     []monthBar
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates web dashboard.
+func New(config Config, storage archive.OutageStorage, status StatusProvider) (dashboard *Dashboard, err error) {
+	if config.Secret == "" {
+		return nil, errors.New("dashboard secret is not configured")
+	}
+
+	if config.Weeks <= 0 {
+		config.Weeks = defaultWeeks
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		log.Warnf("Invalid dashboard timezone %q, using UTC: %s", config.Timezone, err)
+
+		location = time.UTC
+	}
+
+	templates, err := template.ParseFS(content, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, []byte(config.Secret))
+	mac.Write([]byte(sessionCookie))
+
+	return &Dashboard{
+		config: config, location: location, storage: storage, status: status, templates: templates,
+		session: hex.EncodeToString(mac.Sum(nil)),
+	}, nil
+}
+
+// Routes returns dashboard page, login, chart and static file routes.
+func (dashboard *Dashboard) Routes() []httpserver.Route {
+	static, _ := fs.Sub(content, "static")
+
+	return []httpserver.Route{
+		{Pattern: "/dashboard/", Handler: dashboard.authorized(http.HandlerFunc(dashboard.handleIndex))},
+		{Pattern: "/dashboard/chart.png", Handler: dashboard.authorized(http.HandlerFunc(dashboard.handleChart))},
+		{Pattern: "/dashboard/login", Handler: http.HandlerFunc(dashboard.handleLogin)},
+		{Pattern: "/dashboard/static/", Handler: http.StripPrefix("/dashboard/static/", http.FileServer(http.FS(static)))},
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// authorized redirects browsers without a valid session cookie to the login page.
+func (dashboard *Dashboard) authorized(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(dashboard.session)) != 1 {
+			http.Redirect(w, r, "/dashboard/login", http.StatusSeeOther)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (dashboard *Dashboard) handleLogin(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		dashboard.render(w, "login.html", map[string]bool{"Failed": false})
+
+	case http.MethodPost:
+		secret := r.PostFormValue(secretFormField)
+
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(dashboard.config.Secret)) != 1 {
+			log.WithField("remote", r.RemoteAddr).Warn("Dashboard login failed")
+
+			w.WriteHeader(http.StatusUnauthorized)
+			dashboard.render(w, "login.html", map[string]bool{"Failed": true})
+
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name: sessionCookie, Value: dashboard.session, Path: "/dashboard/", MaxAge: int(sessionMaxAge.Seconds()),
+			HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteStrictMode,
+		})
+
+		http.Redirect(w, r, "/dashboard/", http.StatusSeeOther)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (dashboard *Dashboard) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/dashboard/" {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	data, err := dashboard.pageData(time.Now().In(dashboard.location))
+	if err != nil {
+		log.Errorf("Failed to build dashboard: %s", err)
+
+		http.Error(w, "failed to build dashboard", http.StatusInternalServerError)
+
+		return
+	}
+
+	dashboard.render(w, "index.html", data)
+}
+
+// handleChart draws hours without power for the last 30 days.
+func (dashboard *Dashboard) handleChart(w http.ResponseWriter, r *http.Request) {
+	days, err := report.Daily(dashboard.storage, time.Now().In(dashboard.location).AddDate(0, 0, 1-chartDays),
+		chartDays)
+	if err != nil {
+		log.Errorf("Failed to get daily outages: %s", err)
+
+		http.Error(w, "failed to build chart", http.StatusInternalServerError)
+
+		return
+	}
+
+	bars := make([]chart.Bar, 0, len(days))
+
+	for _, day := range days {
+		bars = append(bars, chart.Bar{Label: day.Date.Day(), Value: day.Total})
+	}
+
+	image, err := chart.Render(bars)
+	if err != nil {
+		log.Errorf("Failed to render chart: %s", err)
+
+		http.Error(w, "failed to build chart", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if _, err = w.Write(image); err != nil {
+		log.Errorf("Failed to write chart: %s", err)
+	}
+}
+
+func (dashboard *Dashboard) pageData(now time.Time) (data pageData, err error) {
+	on, since, lastCheck := dashboard.status.PowerState()
+
+	// power state is unknown until the power monitor finishes its startup check
+	if data.PowerKnown = !lastCheck.IsZero(); data.PowerKnown {
+		data.PowerOn = on
+		data.Since = since.In(dashboard.location).Format(timeFormat)
+		data.For = formatDuration(now.Sub(since))
+		data.LastCheck = lastCheck.In(dashboard.location).Format(timeFormat)
+	}
+
+	if data.Weeks, err = dashboard.calendar(now); err != nil {
+		return data, err
+	}
+
+	yearReport, err := report.Yearly(dashboard.storage, now.Year(), dashboard.location)
+	if err != nil {
+		return data, err
+	}
+
+	data.Year, data.Count = yearReport.Year, yearReport.Count()
+	data.Total, data.Longest = formatDuration(yearReport.Total()), formatDuration(yearReport.Longest.Duration())
+
+	var longestMonth time.Duration
+
+	for _, month := range yearReport.Months {
+		longestMonth = max(longestMonth, month.Total)
+	}
+
+	for i, month := range yearReport.Months {
+		bar := monthBar{Name: time.Month(i + 1).String()[:3], Hours: month.Total.Hours()}
+
+		if longestMonth > 0 {
+			bar.Percent = int(100 * month.Total / longestMonth) //nolint:gomnd
+		}
+
+		data.Months = append(data.Months, bar)
+	}
+
+	return data, nil
+}
+
+// calendar returns whole weeks from Monday ending with the current week, days are shaded by time without power.
+func (dashboard *Dashboard) calendar(now time.Time) (weeks [][]calendarDay, err error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, dashboard.location)
+	// Monday is the first day of the week
+	weekStart := today.AddDate(0, 0, -(int(today.Weekday())+daysInWeek-1)%daysInWeek)
+	from := weekStart.AddDate(0, 0, -daysInWeek*(dashboard.config.Weeks-1))
+
+	days, err := report.Daily(dashboard.storage, from, daysInWeek*dashboard.config.Weeks)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, day := range days {
+		if i%daysInWeek == 0 {
+			weeks = append(weeks, make([]calendarDay, 0, daysInWeek))
+		}
+
+		minutes := int(day.Total.Round(time.Minute).Minutes())
+
+		weeks[len(weeks)-1] = append(weeks[len(weeks)-1], calendarDay{
+			Date: day.Date.Format(dateFormat), Outages: day.Count, Minutes: minutes,
+			// each level is a quarter of the day, any outage gets at least the first one
+			Level:  min(calendarLevels, (minutes*calendarLevels+24*60-1)/(24*60)), //nolint:gomnd
+			Future: day.Date.After(today),
+		})
+	}
+
+	return weeks, nil
+}
+
+func (dashboard *Dashboard) render(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := dashboard.templates.ExecuteTemplate(w, name, data); err != nil {
+		log.Errorf("Failed to render dashboard template %s: %s", name, err)
+	}
+}
+
+// formatDuration formats duration as "1h 5m" rounded to minutes.
+func formatDuration(duration time.Duration) string {
+	duration = duration.Round(time.Minute)
+
+	return fmt.Sprintf("%dh %dm", int(duration.Hours()), int(duration.Minutes())%60) //nolint:gomnd
+}
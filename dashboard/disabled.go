@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build minimal || nodashboard
+
+package dashboard
+
+import (
+	"errors"
+
+	"electrobot/archive"
+	"electrobot/httpserver"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Enabled is true in builds with the web dashboard.
+const Enabled = false
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// ErrDisabled is returned by New in builds without the web dashboard.
+var ErrDisabled = errors.New("web dashboard is not included in this build")
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Dashboard is not available in builds without the web dashboard.
+type Dashboard struct{}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New always fails in builds without the web dashboard, templates and static files are left out of them.
+func New(Config, archive.OutageStorage, StatusProvider) (*Dashboard, error) {
+	return nil, ErrDisabled
+}
+
+// Routes returns no routes.
+func (*Dashboard) Routes() []httpserver.Route {
+	return nil
+}
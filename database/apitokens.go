@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// APIToken structure with API token information, the token itself is not stored.
+type APIToken struct {
+	Name      string
+	Scope     string
+	CreatedAt time.Time
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// AddAPIToken stores API token hash with its name and scope.
+func (db *Database) AddAPIToken(name, hash, scope string) error {
+	_, err := db.sql.Exec(`INSERT INTO api_tokens (name, hash, scope, created_at) VALUES (?, ?, ?, ?)`,
+		name, hash, scope, now())
+
+	return err
+}
+
+// GetAPITokenScope returns scope of the token with the hash, sql.ErrNoRows is returned if there is no such token.
+func (db *Database) GetAPITokenScope(hash string) (scope string, err error) {
+	err = db.sql.QueryRow(`SELECT scope FROM api_tokens WHERE hash = ?`, hash).Scan(&scope)
+
+	return scope, err
+}
+
+// GetAPITokens returns all API tokens in creation order.
+func (db *Database) GetAPITokens() (tokens []APIToken, err error) {
+	rows, err := db.sql.Query(`SELECT name, scope, created_at FROM api_tokens ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var token APIToken
+
+		if err = rows.Scan(&token.Name, &token.Scope, &token.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// RemoveAPIToken removes API token by name.
+func (db *Database) RemoveAPIToken(name string) error {
+	result, err := db.sql.Exec(`DELETE FROM api_tokens WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("API token %q not found", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// OutageArchive structure with information about archived outages file.
+type OutageArchive struct {
+	ID         int64
+	FileName   string
+	FirstStart time.Time
+	LastEnd    time.Time
+	Outages    int
+	CreatedAt  time.Time
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// ArchiveOutages passes outages ended before the time to write, oldest first, then removes their events and
+// records the archive file returned by write. Nothing is removed if write or any database operation fails.
+func (db *Database) ArchiveOutages(before time.Time, write func(outages []Outage) (fileName string, err error),
+) (count int, err error) {
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	rows, err := tx.Query(`SELECT start_at, end_at, id, off_id, cause FROM (`+outagesQuery+`)
+		WHERE julianday(end_at) < julianday(?) ORDER BY julianday(start_at), id`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		outages  []Outage
+		eventIDs []int64
+	)
+
+	for rows.Next() {
+		var (
+			outage      Outage
+			onID, offID int64
+		)
+
+		if err = rows.Scan(&outage.Start, &outage.End, &onID, &offID, &outage.Cause); err != nil {
+			rows.Close()
+
+			return 0, err
+		}
+
+		outages = append(outages, outage)
+		eventIDs = append(eventIDs, offID, onID)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil || len(outages) == 0 {
+		return 0, err
+	}
+
+	fileName, err := write(outages)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range eventIDs {
+		if _, err = tx.Exec(`DELETE FROM events WHERE id = ?`, id); err != nil {
+			return 0, err
+		}
+
+		// causes are kept in the archive file
+		if _, err = tx.Exec(`DELETE FROM outage_causes WHERE outage_id = ?`, id); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err = tx.Exec(`INSERT INTO outage_archives (file_name, first_start_at, last_end_at, outages, created_at)
+		VALUES (?, ?, ?, ?, ?)`, fileName, outages[0].Start.UTC(), outages[len(outages)-1].End.UTC(), len(outages),
+		now()); err != nil {
+		return 0, err
+	}
+
+	return len(outages), tx.Commit()
+}
+
+// GetOutageArchives returns archives with outages overlapping [from, to), oldest first.
+func (db *Database) GetOutageArchives(from, to time.Time) (archives []OutageArchive, err error) {
+	rows, err := db.sql.Query(`SELECT id, file_name, first_start_at, last_end_at, outages, created_at
+		FROM outage_archives
+		WHERE julianday(last_end_at) > julianday(?1) AND julianday(first_start_at) < julianday(?2) ORDER BY id`,
+		from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var archive OutageArchive
+
+		if err = rows.Scan(&archive.ID, &archive.FileName, &archive.FirstStart, &archive.LastEnd, &archive.Outages,
+			&archive.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		archives = append(archives, archive)
+	}
+
+	return archives, rows.Err()
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"fmt"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Checklist structure with the checklist sent to the chat before long planned outages. Items are separated by new
+// lines, empty for the default checklist, Group is the chat schedule group, empty if it is not set.
+type Checklist struct {
+	ChatID  int64
+	Group   string
+	Items   string
+	Enabled bool
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// GetUserChecklist returns the checklist of the user.
+func (db *Database) GetUserChecklist(userID int64) (checklist Checklist, err error) {
+	err = db.sql.QueryRow(`SELECT user_id, COALESCE(schedule_group, ''), COALESCE(checklist, ''), checklist_enabled
+		FROM tg_users WHERE user_id = ?`, userID).Scan(&checklist.ChatID, &checklist.Group, &checklist.Items,
+		&checklist.Enabled)
+
+	return checklist, err
+}
+
+// SetUserChecklist stores items of the user checklist and whether the user gets it.
+func (db *Database) SetUserChecklist(userID int64, items string, enabled bool) error {
+	result, err := db.sql.Exec(`UPDATE tg_users SET checklist = NULLIF(?, ''), checklist_enabled = ?
+		WHERE user_id = ?`, items, enabled, userID)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("user %d not found", userID)
+	}
+
+	return nil
+}
+
+// GetChecklists returns enabled checklists of all chats.
+func (db *Database) GetChecklists() (checklists []Checklist, err error) {
+	rows, err := db.sql.Query(`SELECT user_id, COALESCE(schedule_group, ''), COALESCE(checklist, ''),
+		checklist_enabled FROM tg_users WHERE checklist_enabled ORDER BY user_id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var checklist Checklist
+
+		if err = rows.Scan(&checklist.ChatID, &checklist.Group, &checklist.Items, &checklist.Enabled); err != nil {
+			return nil, err
+		}
+
+		checklists = append(checklists, checklist)
+	}
+
+	return checklists, rows.Err()
+}
@@ -20,8 +20,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"electrobot/chaos"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	_ "github.com/mattn/go-sqlite3" // ignore lint
 	log "github.com/sirupsen/logrus"
@@ -38,6 +41,23 @@ const (
 	syncMode    = "NORMAL"
 )
 
+// Telegram chat types stored with registered chats.
+const (
+	chatTypePrivate    = "private"
+	chatTypeSupergroup = "supergroup"
+)
+
+const (
+	// outagesQuery selects outages as pairs of power_on event and the preceding power_off event.
+	outagesQuery = `SELECT off.created_at AS start_at, power_on.created_at AS end_at, power_on.id AS id,
+			off.id AS off_id, COALESCE(outage_causes.cause, '') AS cause
+		FROM events power_on
+		LEFT JOIN outage_causes ON outage_causes.outage_id = power_on.id
+		JOIN events off ON off.id = (
+			SELECT MAX(id) FROM events WHERE event_type = 'power_off' AND id < power_on.id)
+		WHERE power_on.event_type = 'power_on'`
+)
+
 /***********************************************************************************************************************
  * Types
  **********************************************************************************************************************/
@@ -47,6 +67,79 @@ type Database struct {
 	sql *sql.DB
 }
 
+// User structure with registered user information.
+type User struct {
+	ID        int64
+	Username  string
+	FirstName string
+	LastName  string
+	// ChatType is the Telegram chat type: private, group, supergroup or channel.
+	ChatType  string
+	CreatedAt time.Time
+	// Note and Tags are set by admins.
+	Note string
+	Tags []string
+}
+
+// ElevatorWarning structure with the chat warned not to take the elevator Minutes before planned outages, Group is
+// the chat schedule group, empty if it is not set.
+type ElevatorWarning struct {
+	ChatID  int64
+	Group   string
+	Minutes int
+}
+
+// Utilities structure with utilities of the chat building depending on electricity and the note added to power
+// off notifications of the chat.
+type Utilities struct {
+	Water   bool
+	Heating bool
+	Note    string
+}
+
+// Stats structure with database statistics.
+type Stats struct {
+	SchemaVersion int
+	Users         int
+	Events        int
+	Reminders     int
+	APITokens     int
+	Size          int64
+}
+
+// Reminder structure with user reminder delivered when power returns, or Minutes before each planned outage if it is
+// recurring.
+type Reminder struct {
+	ID        int64
+	UserID    int64
+	Task      string
+	Minutes   int
+	CreatedAt time.Time
+}
+
+// RecurringReminder structure with the reminder sent before planned outages of Group, the chat schedule group, empty
+// if it is not set.
+type RecurringReminder struct {
+	Reminder
+	Group string
+}
+
+// Outage structure with power outage interval, ID is the power_on event ID and zero for archived outages.
+type Outage struct {
+	ID    int64
+	Start time.Time
+	End   time.Time
+	// Cause is annotated by admins, empty if unknown.
+	Cause string
+}
+
+// OutageStats structure with aggregated outage statistics.
+type OutageStats struct {
+	Count   int
+	Total   time.Duration
+	Longest time.Duration
+}
+
 // Config structure with database configuration.
 type Config struct {
 	WorkingDir string
@@ -76,7 +169,8 @@ func New(config Config) (db *Database, err error) {
 		return db, err
 	}
 
-	sqlite, err := sql.Open("sqlite3", fmt.Sprintf("%s?_busy_timeout=%d&_journal_mode=%s&_sync=%s",
+	// timestamps are stored in UTC and _loc makes the driver return them in UTC regardless of the stored offset
+	sqlite, err := sql.Open("sqlite3", fmt.Sprintf("%s?_busy_timeout=%d&_journal_mode=%s&_sync=%s&_loc=UTC",
 		dbFile, busyTimeout, journalMode, syncMode))
 	if err != nil {
 		log.WithField("dbPath", dbFile).Errorf("Failed to open database: %s", err)
@@ -92,19 +186,27 @@ func New(config Config) (db *Database, err error) {
 		}
 	}()
 
-	if err = db.createTGUsersTable(); err != nil {
-		log.Errorf("Failed to create tg_users table: %s", err)
+	if err = db.migrate(); err != nil {
+		log.Errorf("Failed to migrate database: %s", err)
 
 		return db, err
 	}
 
-	if err = db.createEventTable(); err != nil {
-		log.Errorf("Failed to create events table: %s", err)
+	return db, nil
+}
 
-		return db, err
+// Duration returns outage duration.
+func (outage Outage) Duration() time.Duration {
+	return outage.End.Sub(outage.Start)
+}
+
+// Average returns average outage duration.
+func (stats OutageStats) Average() time.Duration {
+	if stats.Count == 0 {
+		return 0
 	}
 
-	return db, nil
+	return (stats.Total / time.Duration(stats.Count)).Round(time.Second)
 }
 
 // Close the database.
@@ -114,42 +216,128 @@ func (db *Database) Close() {
 	}
 }
 
-func (db *Database) NewEvent(name, details string) error {
-	_, err := db.sql.Exec(`INSERT INTO events (name, details, created_at) VALUES (?, ?, ?)`,
-		name, details, time.Now().UTC())
+// Backup writes a consistent snapshot of the database to the file, the file must not exist.
+func (db *Database) Backup(fileName string) error {
+	_, err := db.sql.Exec(`VACUUM INTO ?`, fileName)
 
 	return err
 }
 
-func (db *Database) UpdateEvent(name, details string) error {
-	result, err := db.sql.Exec(`UPDATE events SET details = ?, created_at = ? WHERE name = ?`,
-		details, time.Now().UTC(), name)
+// PruneEvents removes events created before the time except events of types to keep and returns their number.
+func (db *Database) PruneEvents(before time.Time, keep []EventType) (count int64, err error) {
+	args := []interface{}{before.UTC()}
 
-	count, err := result.RowsAffected()
-	if err != nil {
-		return err
+	for _, eventType := range keep {
+		args = append(args, eventType)
 	}
 
-	if count == 0 {
-		return fmt.Errorf("event %s not found", name)
+	result, err := db.sql.Exec(`DELETE FROM events WHERE julianday(created_at) < julianday(?)
+		AND event_type NOT IN (''`+strings.Repeat(", ?", len(keep))+`)`, args...)
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return result.RowsAffected()
 }
 
-func (db *Database) GetLatestEventDateTime(eventType string) (dateTime time.Time, err error) {
-	err = db.sql.QueryRow(`SELECT created_at FROM events WHERE name = ? ORDER BY id DESC LIMIT 1`, eventType).Scan(&dateTime)
+// Vacuum rebuilds the database file to return space of deleted rows to the file system.
+func (db *Database) Vacuum() error {
+	_, err := db.sql.Exec(`VACUUM`)
 
-	return dateTime, err
+	return err
 }
 
-func (db *Database) StoreUserInfo(message tgbotapi.Message) error {
-	_, err := db.sql.Exec(`INSERT INTO tg_users (user_id, username, first_name, last_name) VALUES (?, ?, ?, ?)`,
-		message.Chat.ID, message.Chat.UserName, message.Chat.FirstName, message.Chat.LastName)
+// CheckWritable verifies that the database accepts writes without leaving any data behind.
+func (db *Database) CheckWritable() error {
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	_, err = tx.Exec(`INSERT INTO events (event_type, details, created_at) VALUES (?, ?, ?)`,
+		EventStartup, "Self-test", now())
 
 	return err
 }
 
+// StoreUserInfo registers the chat of the message subscribed to the main location, group titles are stored as
+// the first name.
+func (db *Database) StoreUserInfo(message tgbotapi.Message) error {
+	firstName, chatType := message.Chat.FirstName, message.Chat.Type
+
+	if firstName == "" {
+		firstName = message.Chat.Title
+	}
+
+	if chatType == "" {
+		chatType = chatTypePrivate
+	}
+
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.Exec(`INSERT INTO tg_users (user_id, username, first_name, last_name, chat_type, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, message.Chat.ID, message.Chat.UserName, firstName, message.Chat.LastName, chatType,
+		now()); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`INSERT OR IGNORE INTO subscriptions (chat_id, location_id, created_at) VALUES (?, ?, ?)`,
+		message.Chat.ID, MainLocationID, now()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MigrateChat moves the registration, subscriptions, reminders and queued messages of the group to its new
+// supergroup chat ID.
+func (db *Database) MigrateChat(oldChatID, newChatID int64) error {
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	// the supergroup may have been registered already, the migrated group settings win
+	if _, err = tx.Exec(`UPDATE OR REPLACE tg_users SET user_id = ?, chat_type = ? WHERE user_id = ?`,
+		newChatID, chatTypeSupergroup, oldChatID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`UPDATE reminders SET user_id = ? WHERE user_id = ?`, newChatID, oldChatID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`UPDATE pending_messages SET chat_id = ? WHERE chat_id = ?`, newChatID, oldChatID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`UPDATE OR REPLACE subscriptions SET chat_id = ? WHERE chat_id = ?`, newChatID,
+		oldChatID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`UPDATE OR REPLACE user_tags SET user_id = ? WHERE user_id = ?`, newChatID,
+		oldChatID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`UPDATE OR REPLACE silent_notifications SET user_id = ? WHERE user_id = ?`, newChatID,
+		oldChatID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (db *Database) GetAllUsers() (users []int64, err error) {
 	rows, err := db.sql.Query(`SELECT user_id FROM tg_users`)
 	if err != nil {
@@ -176,6 +364,57 @@ func (db *Database) GetAllUsers() (users []int64, err error) {
 	return users, nil
 }
 
+// GetUsers returns registered users in registration order.
+func (db *Database) GetUsers() (users []User, err error) {
+	rows, err := db.sql.Query(`SELECT user_id, COALESCE(username, ''), COALESCE(first_name, ''),
+		COALESCE(last_name, ''), chat_type, created_at, COALESCE(admin_note, ''),
+		COALESCE((SELECT GROUP_CONCAT(tag, ' ') FROM user_tags WHERE user_tags.user_id = tg_users.user_id), '')
+		FROM tg_users ORDER BY created_at, user_id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			user User
+			tags string
+		)
+
+		if err = rows.Scan(&user.ID, &user.Username, &user.FirstName, &user.LastName, &user.ChatType,
+			&user.CreatedAt, &user.Note, &tags); err != nil {
+			return nil, err
+		}
+
+		// tags have no spaces
+		user.Tags = strings.Fields(tags)
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// GetStats returns row counts of the main tables and the database size.
+func (db *Database) GetStats() (stats Stats, err error) {
+	if stats.SchemaVersion, err = db.SchemaVersion(); err != nil {
+		return stats, err
+	}
+
+	if err = db.sql.QueryRow(`SELECT
+		(SELECT COUNT(*) FROM tg_users),
+		(SELECT COUNT(*) FROM events),
+		(SELECT COUNT(*) FROM reminders),
+		(SELECT COUNT(*) FROM api_tokens),
+		(SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size())`).Scan(
+		&stats.Users, &stats.Events, &stats.Reminders, &stats.APITokens, &stats.Size); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
 func (db *Database) UserExists(userID int64) (exists bool) {
 	exists = false
 
@@ -187,8 +426,363 @@ func (db *Database) UserExists(userID int64) (exists bool) {
 	return exists
 }
 
+// RemoveUserInfo unregisters the user with their subscriptions, reminders and queued messages.
 func (db *Database) RemoveUserInfo(userID int64) error {
-	_, err := db.sql.Exec(`DELETE FROM tg_users WHERE user_id = ?`, userID)
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.Exec(`DELETE FROM tg_users WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM subscriptions WHERE chat_id = ?`, userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM reminders WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM pending_messages WHERE chat_id = ?`, userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM dialogs WHERE chat_id = ?`, userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM user_tags WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM silent_notifications WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetUserLanguage returns user language, empty string is returned if it is not chosen yet.
+func (db *Database) GetUserLanguage(userID int64) (language string, err error) {
+	var value sql.NullString
+
+	if err = db.sql.QueryRow(`SELECT language FROM tg_users WHERE user_id = ?`, userID).Scan(&value); err != nil {
+		return "", err
+	}
+
+	return value.String, nil
+}
+
+// SetUserLanguage stores user language.
+func (db *Database) SetUserLanguage(userID int64, language string) error {
+	return db.updateUser(userID, `UPDATE tg_users SET language = ? WHERE user_id = ?`, language)
+}
+
+// GetUserTimezone returns user timezone name, empty string is returned if it is not set.
+func (db *Database) GetUserTimezone(userID int64) (timezone string, err error) {
+	var value sql.NullString
+
+	if err = db.sql.QueryRow(`SELECT timezone FROM tg_users WHERE user_id = ?`, userID).Scan(&value); err != nil {
+		return "", err
+	}
+
+	return value.String, nil
+}
+
+// SetUserTimezone stores user timezone name.
+func (db *Database) SetUserTimezone(userID int64, timezone string) error {
+	return db.updateUser(userID, `UPDATE tg_users SET timezone = ? WHERE user_id = ?`, timezone)
+}
+
+// GetUserPlainText returns true if the user prefers plain text messages.
+func (db *Database) GetUserPlainText(userID int64) (enabled bool, err error) {
+	err = db.sql.QueryRow(`SELECT plain_text FROM tg_users WHERE user_id = ?`, userID).Scan(&enabled)
+
+	return enabled, err
+}
+
+// SetUserPlainText stores user plain text preference.
+func (db *Database) SetUserPlainText(userID int64, enabled bool) error {
+	return db.updateUser(userID, `UPDATE tg_users SET plain_text = ? WHERE user_id = ?`, enabled)
+}
+
+// GetUserScheduleGroup returns the outage schedule group of the user, empty string is returned if it is not set.
+func (db *Database) GetUserScheduleGroup(userID int64) (group string, err error) {
+	var value sql.NullString
+
+	if err = db.sql.QueryRow(`SELECT schedule_group FROM tg_users WHERE user_id = ?`, userID).Scan(&value); err != nil {
+		return "", err
+	}
+
+	return value.String, nil
+}
+
+// SetUserScheduleGroup stores the outage schedule group of the user, empty group removes it.
+func (db *Database) SetUserScheduleGroup(userID int64, group string) error {
+	return db.updateUser(userID, `UPDATE tg_users SET schedule_group = NULLIF(?, '') WHERE user_id = ?`, group)
+}
+
+// SetUserElevatorWarning stores the elevator warning lead time of the user in minutes, 0 turns warnings off.
+func (db *Database) SetUserElevatorWarning(userID int64, minutes int) error {
+	return db.updateUser(userID, `UPDATE tg_users SET elevator_warning = ? WHERE user_id = ?`, minutes)
+}
+
+// GetElevatorWarnings returns chats warned not to take the elevator before planned outages.
+func (db *Database) GetElevatorWarnings() (warnings []ElevatorWarning, err error) {
+	rows, err := db.sql.Query(`SELECT user_id, COALESCE(schedule_group, ''), elevator_warning FROM tg_users
+		WHERE elevator_warning > 0 ORDER BY user_id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var warning ElevatorWarning
+
+		if err = rows.Scan(&warning.ChatID, &warning.Group, &warning.Minutes); err != nil {
+			return nil, err
+		}
+
+		warnings = append(warnings, warning)
+	}
+
+	return warnings, rows.Err()
+}
+
+// GetUserUtilities returns utilities of the user building depending on electricity.
+func (db *Database) GetUserUtilities(userID int64) (utilities Utilities, err error) {
+	err = db.sql.QueryRow(`SELECT water_depends, heating_depends, COALESCE(outage_note, '') FROM tg_users
+		WHERE user_id = ?`, userID).Scan(&utilities.Water, &utilities.Heating, &utilities.Note)
+
+	return utilities, err
+}
+
+// SetUserUtilities stores utilities of the user building depending on electricity.
+func (db *Database) SetUserUtilities(userID int64, utilities Utilities) error {
+	result, err := db.sql.Exec(`UPDATE tg_users SET water_depends = ?, heating_depends = ?,
+		outage_note = NULLIF(?, '') WHERE user_id = ?`, utilities.Water, utilities.Heating, utilities.Note, userID)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("user %d not found", userID)
+	}
+
+	return nil
+}
+
+// GetOutages returns up to limit latest outages built from power_off/power_on event pairs, newest first.
+func (db *Database) GetOutages(limit int) (outages []Outage, err error) {
+	rows, err := db.sql.Query(`SELECT id, start_at, end_at, cause FROM (`+outagesQuery+`)
+		ORDER BY julianday(start_at) DESC, id DESC LIMIT ?`,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var outage Outage
+
+		if err = rows.Scan(&outage.ID, &outage.Start, &outage.End, &outage.Cause); err != nil {
+			return nil, err
+		}
+
+		outages = append(outages, outage)
+	}
+
+	return outages, rows.Err()
+}
+
+// GetOutage returns outage by its ID, sql.ErrNoRows is returned if there is no such outage.
+func (db *Database) GetOutage(id int64) (outage Outage, err error) {
+	err = db.sql.QueryRow(`SELECT id, start_at, end_at, cause FROM (`+outagesQuery+`) WHERE id = ?`, id).Scan(
+		&outage.ID, &outage.Start, &outage.End, &outage.Cause)
+
+	return outage, err
+}
+
+// GetOutagesBetween returns outages overlapping [from, to), oldest first.
+func (db *Database) GetOutagesBetween(from, to time.Time) (outages []Outage, err error) {
+	rows, err := db.sql.Query(`SELECT id, start_at, end_at, cause FROM (`+outagesQuery+`)
+		WHERE julianday(end_at) > julianday(?1) AND julianday(start_at) < julianday(?2)
+		ORDER BY julianday(start_at), id`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var outage Outage
+
+		if err = rows.Scan(&outage.ID, &outage.Start, &outage.End, &outage.Cause); err != nil {
+			return nil, err
+		}
+
+		outages = append(outages, outage)
+	}
+
+	return outages, rows.Err()
+}
+
+// GetOutageStats returns statistics of outages within [from, to), outages are clipped to the interval.
+func (db *Database) GetOutageStats(from, to time.Time) (stats OutageStats, err error) {
+	var total, longest float64
+
+	err = db.sql.QueryRow(`SELECT COUNT(*), COALESCE(SUM(duration), 0), COALESCE(MAX(duration), 0) FROM (
+		SELECT (MIN(julianday(end_at), julianday(?2)) - MAX(julianday(start_at), julianday(?1))) * 86400.0 AS duration
+		FROM (`+outagesQuery+`)
+		WHERE julianday(end_at) > julianday(?1) AND julianday(start_at) < julianday(?2))`,
+		from.UTC(), to.UTC()).Scan(&stats.Count, &total, &longest)
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Total = time.Duration(total * float64(time.Second)).Round(time.Second)
+	stats.Longest = time.Duration(longest * float64(time.Second)).Round(time.Second)
+
+	return stats, nil
+}
+
+// GetSetting returns setting value, sql.ErrNoRows is returned if the setting is not set.
+func (db *Database) GetSetting(key string) (value string, err error) {
+	err = db.sql.QueryRow(`SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+
+	return value, err
+}
+
+// SetSetting stores setting value.
+func (db *Database) SetSetting(key, value string) error {
+	_, err := db.sql.Exec(`INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, key, value)
+
+	return err
+}
+
+// DeleteSetting removes setting value, removing a setting which is not set is not an error.
+func (db *Database) DeleteSetting(key string) error {
+	_, err := db.sql.Exec(`DELETE FROM settings WHERE key = ?`, key)
+
+	return err
+}
+
+// AddReminder stores user reminder and returns its ID.
+func (db *Database) AddReminder(userID int64, task string) (id int64, err error) {
+	result, err := db.sql.Exec(`INSERT INTO reminders (user_id, task, created_at) VALUES (?, ?, ?)`,
+		userID, task, now())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// AddRecurringReminder stores user reminder sent minutes before each planned outage and returns its ID.
+func (db *Database) AddRecurringReminder(userID int64, task string, minutes int) (id int64, err error) {
+	result, err := db.sql.Exec(`INSERT INTO reminders (user_id, task, lead_minutes, created_at) VALUES (?, ?, ?, ?)`,
+		userID, task, minutes, now())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// GetReminders returns user reminders in creation order.
+func (db *Database) GetReminders(userID int64) (reminders []Reminder, err error) {
+	rows, err := db.sql.Query(`SELECT id, user_id, task, lead_minutes, created_at FROM reminders WHERE user_id = ?
+		ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var reminder Reminder
+
+		if err = rows.Scan(&reminder.ID, &reminder.UserID, &reminder.Task, &reminder.Minutes,
+			&reminder.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+// GetRecurringReminders returns reminders sent before planned outages with schedule groups of their chats.
+func (db *Database) GetRecurringReminders() (reminders []RecurringReminder, err error) {
+	rows, err := db.sql.Query(`SELECT reminders.id, reminders.user_id, task, lead_minutes, reminders.created_at,
+		COALESCE(schedule_group, '') FROM reminders JOIN tg_users ON tg_users.user_id = reminders.user_id
+		WHERE lead_minutes > 0 ORDER BY reminders.id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var reminder RecurringReminder
+
+		if err = rows.Scan(&reminder.ID, &reminder.UserID, &reminder.Task, &reminder.Minutes, &reminder.CreatedAt,
+			&reminder.Group); err != nil {
+			return nil, err
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+// RemoveReminder removes user reminder by ID.
+func (db *Database) RemoveReminder(userID, id int64) error {
+	result, err := db.sql.Exec(`DELETE FROM reminders WHERE user_id = ? AND id = ?`, userID, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("reminder %d not found", id)
+	}
+
+	return nil
+}
+
+// RemoveReminders removes user reminders by IDs, unknown IDs are ignored.
+func (db *Database) RemoveReminders(userID int64, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := []interface{}{userID}
+
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	_, err := db.sql.Exec(`DELETE FROM reminders WHERE user_id = ?
+		AND id IN (`+strings.TrimPrefix(strings.Repeat(", ?", len(ids)), ", ")+`)`, args...)
 
 	return err
 }
@@ -197,25 +791,38 @@ func (db *Database) RemoveUserInfo(userID int64) error {
  * Private
  **********************************************************************************************************************/
 
-func (db *Database) createTGUsersTable() error {
-	_, err := db.sql.Exec(`CREATE TABLE IF NOT EXISTS tg_users (
-		user_id INTEGER PRIMARY KEY NOT NULL,
-		username TEXT,
-		first_name TEXT,
-		last_name TEXT,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	)`)
+// now returns current time in UTC, all timestamps are stored in UTC and converted to user timezone on display.
+func now() time.Time {
+	return time.Now().UTC()
+}
 
-	return err
+// updateUser executes single-value user update query, an error is returned if the user doesn't exist.
+func (db *Database) updateUser(userID int64, query string, value interface{}) error {
+	result, err := db.sql.Exec(query, value, userID)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("user %d not found", userID)
+	}
+
+	return nil
 }
 
-func (db *Database) createEventTable() error {
-	_, err := db.sql.Exec(`CREATE TABLE IF NOT EXISTS events (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		details TEXT,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	)`)
+// injectFault fails the operation when the chaos database fault fires, the connection stays usable so following
+// operations may succeed like after a transient I/O or locking error.
+func (db *Database) injectFault() error {
+	if !chaos.Fire(chaos.Database) {
+		return nil
+	}
 
-	return err
+	log.Warn("Chaos: failing database operation")
+
+	return chaos.ErrInjected
 }
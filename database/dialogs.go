@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import "time"
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Dialog structure with the conversation step of the chat.
+type Dialog struct {
+	Name      string
+	Step      string
+	UpdatedAt time.Time
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// GetDialog returns the conversation of the chat, sql.ErrNoRows is returned if there is no conversation.
+func (db *Database) GetDialog(chatID int64) (dialog Dialog, err error) {
+	err = db.sql.QueryRow(`SELECT dialog, step, updated_at FROM dialogs WHERE chat_id = ?`, chatID).Scan(
+		&dialog.Name, &dialog.Step, &dialog.UpdatedAt)
+
+	return dialog, err
+}
+
+// SetDialog stores the conversation step of the chat replacing the previous conversation.
+func (db *Database) SetDialog(chatID int64, name, step string) error {
+	_, err := db.sql.Exec(`INSERT INTO dialogs (chat_id, dialog, step, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(chat_id) DO UPDATE SET dialog = excluded.dialog, step = excluded.step,
+		updated_at = excluded.updated_at`, chatID, name, step, now())
+
+	return err
+}
+
+// RemoveDialog ends the conversation of the chat, removing a missing conversation is not an error.
+func (db *Database) RemoveDialog(chatID int64) error {
+	_, err := db.sql.Exec(`DELETE FROM dialogs WHERE chat_id = ?`, chatID)
+
+	return err
+}
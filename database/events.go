@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Event types, they match the event_type column constraint.
+const (
+	// EventHeartbeat is a single event updated in place by the power monitor.
+	EventHeartbeat  EventType = "heartbeat"
+	EventPowerOff   EventType = "power_off"
+	EventPowerOn    EventType = "power_on"
+	EventStartup    EventType = "startup"
+	EventUplink     EventType = "uplink_changed"
+	EventUnregister EventType = "user_unregistered"
+	// EventLegacy marks events recorded before typed events with names unknown to the typed API.
+	EventLegacy EventType = "legacy"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// EventType is the type of stored event.
+type EventType string
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// RecordHeartbeat updates the heartbeat event time, the event is created on the first call.
+func (db *Database) RecordHeartbeat() error {
+	if err := db.injectFault(); err != nil {
+		return err
+	}
+
+	result, err := db.sql.Exec(`UPDATE events SET created_at = ? WHERE event_type = ?`, now(), EventHeartbeat)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count != 0 {
+		return nil
+	}
+
+	return db.recordEvent(EventHeartbeat, "", now())
+}
+
+// GetLastHeartbeat returns the heartbeat event time, sql.ErrNoRows is returned if there was no heartbeat yet.
+func (db *Database) GetLastHeartbeat() (dateTime time.Time, err error) {
+	err = db.sql.QueryRow(`SELECT created_at FROM events WHERE event_type = ? ORDER BY id DESC LIMIT 1`,
+		EventHeartbeat).Scan(&dateTime)
+
+	return dateTime, err
+}
+
+// RecordPowerOff stores the outage as a pair of power_off and power_on events.
+func (db *Database) RecordPowerOff(start, end time.Time) error {
+	if err := db.injectFault(); err != nil {
+		return err
+	}
+
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.Exec(`INSERT INTO events (event_type, details, created_at) VALUES (?, NULL, ?)`,
+		EventPowerOff, start.UTC()); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`INSERT INTO events (event_type, details, created_at) VALUES (?, ?, ?)`,
+		EventPowerOn, end.Sub(start).Round(time.Second).String(), end.UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ImportOutages stores historical outages as power_off and power_on event pairs, outages ending before they start
+// or overlapping stored, archived or previously imported ones are skipped.
+func (db *Database) ImportOutages(outages []Outage) (imported int, err error) {
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, outage := range outages {
+		if !outage.End.After(outage.Start) {
+			continue
+		}
+
+		var overlapping int
+
+		// archived outages are only known by the archive periods
+		if err = tx.QueryRow(`SELECT
+			(SELECT COUNT(*) FROM (`+outagesQuery+`)
+				WHERE julianday(end_at) > julianday(?1) AND julianday(start_at) < julianday(?2)) +
+			(SELECT COUNT(*) FROM outage_archives
+				WHERE julianday(last_end_at) > julianday(?1) AND julianday(first_start_at) < julianday(?2))`,
+			outage.Start.UTC(), outage.End.UTC()).Scan(&overlapping); err != nil {
+			return 0, err
+		}
+
+		if overlapping > 0 {
+			continue
+		}
+
+		if _, err = tx.Exec(`INSERT INTO events (event_type, details, created_at) VALUES (?, NULL, ?)`,
+			EventPowerOff, outage.Start.UTC()); err != nil {
+			return 0, err
+		}
+
+		if _, err = tx.Exec(`INSERT INTO events (event_type, details, created_at) VALUES (?, ?, ?)`,
+			EventPowerOn, outage.Duration().Round(time.Second).String(), outage.End.UTC()); err != nil {
+			return 0, err
+		}
+
+		imported++
+	}
+
+	return imported, tx.Commit()
+}
+
+// RecordStartup stores the bot startup with its reason.
+func (db *Database) RecordStartup(reason string) error {
+	return db.recordEvent(EventStartup, reason, now())
+}
+
+// RecordUplinkChange stores the uplink change, empty interface means no uplink.
+func (db *Database) RecordUplinkChange(iface string, backup bool) error {
+	details := iface
+	if details == "" {
+		details = "none"
+	}
+
+	if backup {
+		details += " (backup)"
+	}
+
+	return db.recordEvent(EventUplink, details, now())
+}
+
+// RecordUserUnregistered stores the user removal with its reason.
+func (db *Database) RecordUserUnregistered(userID int64, reason string) error {
+	return db.recordEvent(EventUnregister, fmt.Sprintf("%d: %s", userID, reason), now())
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (db *Database) recordEvent(eventType EventType, details string, createdAt time.Time) error {
+	_, err := db.sql.Exec(`INSERT INTO events (event_type, details, created_at) VALUES (?, NULLIF(?, ''), ?)`,
+		eventType, details, createdAt.UTC())
+
+	return err
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestImportOutages(t *testing.T) {
+	db, err := New(Config{WorkingDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Can't create database: %s", err)
+	}
+	defer db.Close()
+
+	recent := time.Date(2024, 3, 10, 18, 0, 0, 0, time.UTC)
+
+	if err = db.RecordPowerOff(recent, recent.Add(time.Hour)); err != nil {
+		t.Fatalf("Can't record outage: %s", err)
+	}
+
+	old := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+
+	imported, err := db.ImportOutages([]Outage{
+		{Start: old, End: old.Add(2 * time.Hour)},
+		// overlaps the previous imported outage
+		{Start: old.Add(time.Hour), End: old.Add(3 * time.Hour)},
+		// overlaps the recorded outage
+		{Start: recent.Add(30 * time.Minute), End: recent.Add(2 * time.Hour)},
+		{Start: old.Add(24 * time.Hour), End: old.Add(24 * time.Hour)},
+		{Start: old.Add(48 * time.Hour), End: old.Add(49 * time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Can't import outages: %s", err)
+	}
+
+	if imported != 2 {
+		t.Errorf("Wrong imported outages count: %d", imported)
+	}
+
+	// imported outages are ordered by time, not by insertion
+	outages, err := db.GetOutages(10)
+	if err != nil {
+		t.Fatalf("Can't get outages: %s", err)
+	}
+
+	expected := []time.Time{recent, old.Add(48 * time.Hour), old}
+
+	if len(outages) != len(expected) {
+		t.Fatalf("Wrong outages count: %d", len(outages))
+	}
+
+	for i, outage := range outages {
+		if !outage.Start.Equal(expected[i]) {
+			t.Errorf("Wrong outage %d start: %s", i, outage.Start)
+		}
+	}
+}
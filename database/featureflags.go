@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import "database/sql"
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// FeatureFlag structure with feature flag rollout.
+type FeatureFlag struct {
+	Name string
+	// Percent is the share of chats the feature is enabled for.
+	Percent int
+	// Chats the feature is enabled for regardless of the percent.
+	Chats []int64
+}
+
+// VariantStats structure with notifications sent in a wording variant and engaged with.
+type VariantStats struct {
+	Variant string
+	Sent    int
+	Engaged int
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// GetFeatureFlags returns all feature flags ordered by name.
+func (db *Database) GetFeatureFlags() (flags []FeatureFlag, err error) {
+	rows, err := db.sql.Query(`SELECT feature_flags.name, percent, chat_id FROM feature_flags
+		LEFT JOIN feature_flag_chats ON feature_flag_chats.name = feature_flags.name
+		ORDER BY feature_flags.name, chat_id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			flag   FeatureFlag
+			chatID sql.NullInt64
+		)
+
+		if err = rows.Scan(&flag.Name, &flag.Percent, &chatID); err != nil {
+			return nil, err
+		}
+
+		if len(flags) == 0 || flags[len(flags)-1].Name != flag.Name {
+			flags = append(flags, flag)
+		}
+
+		if chatID.Valid {
+			flags[len(flags)-1].Chats = append(flags[len(flags)-1].Chats, chatID.Int64)
+		}
+	}
+
+	return flags, rows.Err()
+}
+
+// SetFeatureFlag stores the percent of chats the feature is enabled for.
+func (db *Database) SetFeatureFlag(name string, percent int) error {
+	_, err := db.sql.Exec(`INSERT INTO feature_flags (name, percent) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET percent = excluded.percent`, name, percent)
+
+	return err
+}
+
+// AddFeatureFlagChat enables the feature for the chat, the flag must be set first.
+func (db *Database) AddFeatureFlagChat(name string, chatID int64) error {
+	_, err := db.sql.Exec(`INSERT OR IGNORE INTO feature_flag_chats (name, chat_id) VALUES (?, ?)`, name, chatID)
+
+	return err
+}
+
+// RemoveFeatureFlagChat removes the chat from the chats the feature is enabled for regardless of the percent.
+func (db *Database) RemoveFeatureFlagChat(name string, chatID int64) error {
+	_, err := db.sql.Exec(`DELETE FROM feature_flag_chats WHERE name = ? AND chat_id = ?`, name, chatID)
+
+	return err
+}
+
+// RecordVariantSent adds notifications sent in the wording variant.
+func (db *Database) RecordVariantSent(variant string, count int) error {
+	_, err := db.sql.Exec(`INSERT INTO notification_variants (variant, sent) VALUES (?, ?)
+		ON CONFLICT(variant) DO UPDATE SET sent = sent + excluded.sent`, variant, count)
+
+	return err
+}
+
+// RecordVariantEngaged counts engagement with a notification sent in the wording variant.
+func (db *Database) RecordVariantEngaged(variant string) error {
+	_, err := db.sql.Exec(`INSERT INTO notification_variants (variant, engaged) VALUES (?, 1)
+		ON CONFLICT(variant) DO UPDATE SET engaged = engaged + 1`, variant)
+
+	return err
+}
+
+// GetVariantStats returns stats of all wording variants ordered by variant.
+func (db *Database) GetVariantStats() (stats []VariantStats, err error) {
+	rows, err := db.sql.Query(`SELECT variant, sent, engaged FROM notification_variants ORDER BY variant`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var variant VariantStats
+
+		if err = rows.Scan(&variant.Variant, &variant.Sent, &variant.Engaged); err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, variant)
+	}
+
+	return stats, rows.Err()
+}
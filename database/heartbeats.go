@@ -0,0 +1,322 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Telemetry values of devices which haven't reported them.
+const (
+	UnknownBattery = -1
+	UnknownSignal  = 0
+)
+
+// heartbeatLocationsQuery selects heartbeat locations with their parent locations.
+const heartbeatLocationsQuery = `SELECT l.id, l.name, l.created_at, l.last_heartbeat, l.off_since,
+		l.last_heartbeat_sent, l.heartbeat_clock_skew, l.sensor_battery, l.sensor_signal, l.heartbeat_paused,
+		l.parent_id, p.name, p.off_since, l.phase
+	FROM locations l LEFT JOIN locations p ON p.id = l.parent_id`
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// HeartbeatLocation structure with heartbeat state of a location reported by a device on the premises.
+type HeartbeatLocation struct {
+	Location
+	// LastHeartbeat is zero if no heartbeat was received yet.
+	LastHeartbeat time.Time
+	// OffSince is the outage start, zero while power is present.
+	OffSince time.Time
+	// LastSent is the device timestamp of the last heartbeat, zero if heartbeats are not timestamped.
+	LastSent time.Time
+	// ClockSkew is the clock difference tolerated between the device and the bot, 0 means default.
+	ClockSkew time.Duration
+	// Battery is the device battery charge in percent, UnknownBattery if not reported.
+	Battery int
+	// Signal is the device signal strength in dBm, UnknownSignal if not reported.
+	Signal int
+	// Paused is true if the location power state doesn't follow the device heartbeats.
+	Paused bool
+	// ParentID is the location this one is a part or a phase of, 0 for independent locations.
+	ParentID int64
+	// ParentName is the name of the parent location.
+	ParentName string
+	// ParentOffSince is the outage start of the parent location, zero while any of its parts or phases has power.
+	ParentOffSince time.Time
+	// Phase is the phase of the parent the sensor monitors, e.g. B, empty if the location is a part of the parent.
+	Phase string
+}
+
+// rowScanner is implemented by both sql.Row and sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// SetHeartbeatToken replaces heartbeat token hash of the location, the previous token stops working.
+func (db *Database) SetHeartbeatToken(name, hash string) error {
+	result, err := db.sql.Exec(`UPDATE locations SET heartbeat_token_hash = ?, last_heartbeat = NULL, off_since = NULL,
+		last_heartbeat_sent = NULL, sensor_battery = NULL, sensor_signal = NULL, heartbeat_paused = 0 WHERE name = ?`,
+		hash, name)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("location %q not found", name)
+	}
+
+	return nil
+}
+
+// RemoveHeartbeatToken removes heartbeat token of the location with the device state, the location is kept.
+func (db *Database) RemoveHeartbeatToken(name string) error {
+	result, err := db.sql.Exec(`UPDATE locations SET heartbeat_token_hash = NULL, last_heartbeat = NULL,
+		off_since = NULL, last_heartbeat_sent = NULL, sensor_battery = NULL, sensor_signal = NULL, heartbeat_paused = 0
+		WHERE name = ? AND heartbeat_token_hash IS NOT NULL`, name)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("location %q with heartbeat token not found", name)
+	}
+
+	return nil
+}
+
+// SetLocationParent makes the sensor location a part of the parent one, e.g. an entrance of a building, or the sensor
+// a monitor of the parent phase if the phase is not empty. Empty parent makes the location independent again.
+// The main location has no parent, parents can't have heartbeat tokens or parents of their own.
+func (db *Database) SetLocationParent(name, parent, phase string) error {
+	if parent == "" {
+		result, err := db.sql.Exec(`UPDATE locations SET parent_id = NULL, phase = NULL
+			WHERE name = ? AND heartbeat_token_hash IS NOT NULL`, name)
+		if err != nil {
+			return err
+		}
+
+		return checkSensorUpdated(result, name)
+	}
+
+	var parentID int64
+
+	if err := db.sql.QueryRow(`SELECT id FROM locations
+		WHERE name = ? AND heartbeat_token_hash IS NULL AND parent_id IS NULL`, parent).Scan(&parentID); err != nil {
+		return fmt.Errorf("location %q without heartbeat token: %w", parent, err)
+	}
+
+	result, err := db.sql.Exec(`UPDATE locations SET parent_id = ?, phase = NULLIF(?, '')
+		WHERE name = ? AND heartbeat_token_hash IS NOT NULL AND id NOT IN (?, ?)
+		AND NOT EXISTS (SELECT 1 FROM locations children WHERE children.parent_id = locations.id)`,
+		parentID, phase, name, parentID, MainLocationID)
+	if err != nil {
+		return err
+	}
+
+	return checkSensorUpdated(result, name)
+}
+
+// SetHeartbeatPaused pauses or resumes power state tracking by the location heartbeats. Pausing drops the outage in
+// progress, resuming forgets the last heartbeat, so the location waits for the next one instead of reporting
+// an outage for the pause.
+func (db *Database) SetHeartbeatPaused(name string, paused bool) error {
+	result, err := db.sql.Exec(`UPDATE locations SET heartbeat_paused = ?, off_since = NULL,
+		last_heartbeat = CASE WHEN ? THEN last_heartbeat END WHERE name = ? AND heartbeat_token_hash IS NOT NULL`,
+		paused, paused, name)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("location %q with heartbeat token not found", name)
+	}
+
+	return nil
+}
+
+// SetHeartbeatClockSkew sets the clock skew tolerated for heartbeats of the location, 0 means default.
+func (db *Database) SetHeartbeatClockSkew(name string, skew time.Duration) error {
+	result, err := db.sql.Exec(`UPDATE locations SET heartbeat_clock_skew = ? WHERE name = ?`, int64(skew.Seconds()),
+		name)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("location %q not found", name)
+	}
+
+	return nil
+}
+
+// GetHeartbeatLocation returns the location with the heartbeat token hash, sql.ErrNoRows is returned if there is no
+// such location.
+func (db *Database) GetHeartbeatLocation(hash string) (location HeartbeatLocation, err error) {
+	row := db.sql.QueryRow(heartbeatLocationsQuery+` WHERE l.heartbeat_token_hash = ?`, hash)
+
+	return scanHeartbeatLocation(row)
+}
+
+// GetHeartbeatLocations returns all locations with heartbeat tokens.
+func (db *Database) GetHeartbeatLocations() (locations []HeartbeatLocation, err error) {
+	rows, err := db.sql.Query(heartbeatLocationsQuery + ` WHERE l.heartbeat_token_hash IS NOT NULL ORDER BY l.id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		location, err := scanHeartbeatLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		locations = append(locations, location)
+	}
+
+	return locations, rows.Err()
+}
+
+// RecordLocationHeartbeat stores the time of the last heartbeat of the location and its device timestamp, zero
+// timestamp keeps the previous one. The heartbeat time never goes back, a delayed heartbeat is dated by its device
+// timestamp and may be older than the one received before it.
+func (db *Database) RecordLocationHeartbeat(locationID int64, at, sentAt time.Time) error {
+	var sent sql.NullTime
+
+	if !sentAt.IsZero() {
+		sent = sql.NullTime{Time: sentAt.UTC(), Valid: true}
+	}
+
+	_, err := db.sql.Exec(`UPDATE locations SET last_heartbeat = CASE
+			WHEN last_heartbeat IS NULL OR julianday(last_heartbeat) < julianday(?1) THEN ?1 ELSE last_heartbeat END,
+		last_heartbeat_sent = COALESCE(?2, last_heartbeat_sent) WHERE id = ?3`, at.UTC(), sent, locationID)
+
+	return err
+}
+
+// RecordSensorTelemetry stores battery charge and signal strength reported by the location device, unknown values
+// keep the previous ones.
+func (db *Database) RecordSensorTelemetry(locationID int64, battery, signal int) error {
+	var batteryValue, signalValue sql.NullInt64
+
+	if battery != UnknownBattery {
+		batteryValue = sql.NullInt64{Int64: int64(battery), Valid: true}
+	}
+
+	if signal != UnknownSignal {
+		signalValue = sql.NullInt64{Int64: int64(signal), Valid: true}
+	}
+
+	_, err := db.sql.Exec(`UPDATE locations SET sensor_battery = COALESCE(?, sensor_battery),
+		sensor_signal = COALESCE(?, sensor_signal) WHERE id = ?`, batteryValue, signalValue, locationID)
+
+	return err
+}
+
+// SetLocationOffSince stores the outage start of the location, zero time means power is present.
+func (db *Database) SetLocationOffSince(locationID int64, offSince time.Time) error {
+	var value sql.NullTime
+
+	if !offSince.IsZero() {
+		value = sql.NullTime{Time: offSince.UTC(), Valid: true}
+	}
+
+	_, err := db.sql.Exec(`UPDATE locations SET off_since = ? WHERE id = ?`, value, locationID)
+
+	return err
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func checkSensorUpdated(result sql.Result, name string) error {
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("location %q with heartbeat token not found", name)
+	}
+
+	return nil
+}
+
+func scanHeartbeatLocation(row rowScanner) (location HeartbeatLocation, err error) {
+	var (
+		lastHeartbeat, offSince, lastSent, parentOffSince sql.NullTime
+		clockSkew                                         int64
+		battery, signal, parentID                         sql.NullInt64
+		parentName, phase                                 sql.NullString
+	)
+
+	if err = row.Scan(&location.ID, &location.Name, &location.CreatedAt, &lastHeartbeat, &offSince, &lastSent,
+		&clockSkew, &battery, &signal, &location.Paused, &parentID, &parentName, &parentOffSince, &phase); err != nil {
+		return location, err
+	}
+
+	location.ParentID, location.ParentName, location.Phase = parentID.Int64, parentName.String, phase.String
+	location.ParentOffSince = parentOffSince.Time
+
+	location.Battery, location.Signal = UnknownBattery, UnknownSignal
+
+	if battery.Valid {
+		location.Battery = int(battery.Int64)
+	}
+
+	if signal.Valid {
+		location.Signal = int(signal.Int64)
+	}
+
+	location.LastHeartbeat, location.OffSince, location.LastSent = lastHeartbeat.Time, offSince.Time, lastSent.Time
+	location.ClockSkew = time.Duration(clockSkew) * time.Second
+
+	return location, nil
+}
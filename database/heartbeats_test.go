@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestRecordLocationHeartbeat(t *testing.T) {
+	db, err := New(Config{WorkingDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Can't create database: %s", err)
+	}
+	defer db.Close()
+
+	const hash = "token-hash"
+
+	locationID, err := db.EnsureLocation("garage")
+	if err != nil {
+		t.Fatalf("Can't create location: %s", err)
+	}
+
+	if err = db.SetHeartbeatToken("garage", hash); err != nil {
+		t.Fatalf("Can't set heartbeat token: %s", err)
+	}
+
+	now := time.Date(2024, 3, 1, 10, 0, 0, 500000000, time.UTC)
+
+	testData := []struct {
+		at, sentAt    time.Time
+		lastHeartbeat time.Time
+		lastSent      time.Time
+	}{
+		{at: now, sentAt: now, lastHeartbeat: now, lastSent: now},
+		// delayed heartbeat dated by its device timestamp doesn't move the heartbeat time back
+		{at: now.Add(-time.Minute), sentAt: now.Add(time.Second), lastHeartbeat: now, lastSent: now.Add(time.Second)},
+		{at: now.Add(time.Minute), lastHeartbeat: now.Add(time.Minute), lastSent: now.Add(time.Second)},
+	}
+
+	for i, item := range testData {
+		if err = db.RecordLocationHeartbeat(locationID, item.at, item.sentAt); err != nil {
+			t.Fatalf("Can't record heartbeat: %s", err)
+		}
+
+		location, err := db.GetHeartbeatLocation(hash)
+		if err != nil {
+			t.Fatalf("Can't get heartbeat location: %s", err)
+		}
+
+		if !location.LastHeartbeat.Equal(item.lastHeartbeat) || !location.LastSent.Equal(item.lastSent) {
+			t.Errorf("Wrong heartbeat %d: last heartbeat %s, last sent %s", i, location.LastHeartbeat,
+				location.LastSent)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// MainLocationID is the location monitored by the bot itself, it can be renamed but not removed.
+const MainLocationID = 1
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Location structure with monitored location.
+type Location struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// GetLocations returns all locations, the main location first.
+func (db *Database) GetLocations() (locations []Location, err error) {
+	rows, err := db.sql.Query(`SELECT id, name, created_at FROM locations ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var location Location
+
+		if err = rows.Scan(&location.ID, &location.Name, &location.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		locations = append(locations, location)
+	}
+
+	return locations, rows.Err()
+}
+
+// GetLocation returns location by case-insensitive name, sql.ErrNoRows is returned if there is no such location.
+func (db *Database) GetLocation(name string) (location Location, err error) {
+	err = db.sql.QueryRow(`SELECT id, name, created_at FROM locations WHERE name = ?`, name).Scan(
+		&location.ID, &location.Name, &location.CreatedAt)
+
+	return location, err
+}
+
+// GetMainLocation returns the location monitored by the bot itself.
+func (db *Database) GetMainLocation() (location Location, err error) {
+	err = db.sql.QueryRow(`SELECT id, name, created_at FROM locations WHERE id = ?`, MainLocationID).Scan(
+		&location.ID, &location.Name, &location.CreatedAt)
+
+	return location, err
+}
+
+// AddLocation stores new location, names are unique regardless of case.
+func (db *Database) AddLocation(name string) (id int64, err error) {
+	result, err := db.sql.Exec(`INSERT INTO locations (name, created_at) VALUES (?, ?)`, name, now())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// EnsureLocation returns the location ID creating the location if it doesn't exist, used by power state sources.
+func (db *Database) EnsureLocation(name string) (id int64, err error) {
+	if _, err = db.sql.Exec(`INSERT OR IGNORE INTO locations (name, created_at) VALUES (?, ?)`, name,
+		now()); err != nil {
+		return 0, err
+	}
+
+	err = db.sql.QueryRow(`SELECT id FROM locations WHERE name = ?`, name).Scan(&id)
+
+	return id, err
+}
+
+// RenameLocation changes location name.
+func (db *Database) RenameLocation(name, newName string) error {
+	result, err := db.sql.Exec(`UPDATE locations SET name = ? WHERE name = ?`, newName, name)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("location %q not found", name)
+	}
+
+	return nil
+}
+
+// RemoveLocation removes the location with its subscriptions, the main location can't be removed.
+func (db *Database) RemoveLocation(name string) error {
+	location, err := db.GetLocation(name)
+	if err != nil {
+		return err
+	}
+
+	if location.ID == MainLocationID {
+		return errors.New("main location can't be removed")
+	}
+
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.Exec(`DELETE FROM subscriptions WHERE location_id = ?`, location.ID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`UPDATE locations SET parent_id = NULL, phase = NULL WHERE parent_id = ?`,
+		location.ID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM locations WHERE id = ?`, location.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Subscribe subscribes the chat to the location notifications, subscribing twice is not an error.
+func (db *Database) Subscribe(chatID, locationID int64) error {
+	_, err := db.sql.Exec(`INSERT OR IGNORE INTO subscriptions (chat_id, location_id, created_at) VALUES (?, ?, ?)`,
+		chatID, locationID, now())
+
+	return err
+}
+
+// Unsubscribe removes the chat subscription to the location.
+func (db *Database) Unsubscribe(chatID, locationID int64) error {
+	_, err := db.sql.Exec(`DELETE FROM subscriptions WHERE chat_id = ? AND location_id = ?`, chatID, locationID)
+
+	return err
+}
+
+// GetSubscriptions returns locations the chat is subscribed to.
+func (db *Database) GetSubscriptions(chatID int64) (locations []Location, err error) {
+	rows, err := db.sql.Query(`SELECT l.id, l.name, l.created_at FROM locations l
+		JOIN subscriptions s ON s.location_id = l.id WHERE s.chat_id = ? ORDER BY l.id`, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var location Location
+
+		if err = rows.Scan(&location.ID, &location.Name, &location.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		locations = append(locations, location)
+	}
+
+	return locations, rows.Err()
+}
+
+// GetSubscribers returns registered users subscribed to the location.
+func (db *Database) GetSubscribers(locationID int64) (users []int64, err error) {
+	rows, err := db.sql.Query(`SELECT u.user_id FROM tg_users u
+		JOIN subscriptions s ON s.chat_id = u.user_id WHERE s.location_id = ?`, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var user int64
+
+		if err = rows.Scan(&user); err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Priorities of queued messages.
+const (
+	PriorityDigest MessagePriority = iota
+	PriorityNormal
+	// PriorityCritical is for power state changes, they are delivered ahead of other messages.
+	PriorityCritical
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// MessagePriority orders queued messages, higher priorities are delivered first and messages to the chat with the
+// same priority keep their order.
+type MessagePriority int
+
+// PendingMessage structure with outgoing message waiting for delivery.
+type PendingMessage struct {
+	ID     int64
+	ChatID int64
+	Text   string
+	// ReplyMarkup is the inline keyboard as Bot API JSON, empty if there is none.
+	ReplyMarkup string
+	// Silent messages are sent without sound.
+	Silent    bool
+	Priority  MessagePriority
+	CreatedAt time.Time
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// AddPendingMessage queues outgoing message, the ID and creation time of the message are ignored.
+func (db *Database) AddPendingMessage(message PendingMessage) error {
+	if err := db.injectFault(); err != nil {
+		return err
+	}
+
+	_, err := db.sql.Exec(`INSERT INTO pending_messages (chat_id, text, reply_markup, silent, priority, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, message.ChatID, message.Text, message.ReplyMarkup, message.Silent,
+		message.Priority, now())
+
+	return err
+}
+
+// HasPendingMessages returns true if there are queued messages to the chat with the priority or higher.
+func (db *Database) HasPendingMessages(chatID int64, priority MessagePriority) (exists bool, err error) {
+	err = db.sql.QueryRow(`SELECT EXISTS(SELECT 1 FROM pending_messages WHERE chat_id = ? AND priority >= ?)`,
+		chatID, priority).Scan(&exists)
+
+	return exists, err
+}
+
+// GetPendingMessages returns up to limit queued messages in queue order: by priority, then oldest first.
+func (db *Database) GetPendingMessages(limit int) (messages []PendingMessage, err error) {
+	rows, err := db.sql.Query(`SELECT id, chat_id, text, reply_markup, silent, priority, created_at
+		FROM pending_messages ORDER BY priority DESC, id LIMIT ?`,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var message PendingMessage
+
+		if err = rows.Scan(&message.ID, &message.ChatID, &message.Text, &message.ReplyMarkup,
+			&message.Silent, &message.Priority, &message.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// RemovePendingMessage removes delivered message from the queue.
+func (db *Database) RemovePendingMessage(id int64) error {
+	if err := db.injectFault(); err != nil {
+		return err
+	}
+
+	_, err := db.sql.Exec(`DELETE FROM pending_messages WHERE id = ?`, id)
+
+	return err
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"testing"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestPendingMessagesPriority(t *testing.T) {
+	db, err := New(Config{WorkingDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Can't create database: %s", err)
+	}
+	defer db.Close()
+
+	const chatID = 42
+
+	for _, message := range []PendingMessage{
+		{ChatID: chatID, Text: "report", Priority: PriorityDigest},
+		{ChatID: chatID, Text: "power lost", Priority: PriorityCritical},
+		{ChatID: chatID, Text: "advisory", Priority: PriorityNormal},
+		{ChatID: chatID, Text: "power restored", Priority: PriorityCritical},
+	} {
+		if err = db.AddPendingMessage(message); err != nil {
+			t.Fatalf("Can't add pending message: %s", err)
+		}
+	}
+
+	messages, err := db.GetPendingMessages(10)
+	if err != nil {
+		t.Fatalf("Can't get pending messages: %s", err)
+	}
+
+	var texts []string
+
+	for _, message := range messages {
+		texts = append(texts, message.Text)
+	}
+
+	if len(texts) != 4 || texts[0] != "power lost" || texts[1] != "power restored" || texts[2] != "advisory" ||
+		texts[3] != "report" {
+		t.Errorf("Wrong queue order: %v", texts)
+	}
+
+	for _, message := range messages[:3] {
+		if err = db.RemovePendingMessage(message.ID); err != nil {
+			t.Fatalf("Can't remove pending message: %s", err)
+		}
+	}
+
+	for priority, expected := range map[MessagePriority]bool{
+		PriorityDigest: true, PriorityNormal: false, PriorityCritical: false,
+	} {
+		if pending, err := db.HasPendingMessages(chatID, priority); err != nil || pending != expected {
+			t.Errorf("Wrong pending messages of priority %d: %v, %v", priority, pending, err)
+		}
+	}
+}
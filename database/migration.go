@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const migrationsDir = "migrations"
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type migration struct {
+	version  int
+	fileName string
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// SchemaVersion returns current database schema version.
+func (db *Database) SchemaVersion() (version int, err error) {
+	err = db.sql.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+
+	return version, err
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// migrate applies embedded migrations newer than the current schema version, each in its own transaction.
+func (db *Database) migrate() error {
+	if _, err := db.sql.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return err
+	}
+
+	currentVersion, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	migrations, err := getMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if migration.version <= currentVersion {
+			continue
+		}
+
+		log.WithFields(log.Fields{"version": migration.version, "file": migration.fileName}).Info("Applying migration")
+
+		if err = db.applyMigration(migration); err != nil {
+			return fmt.Errorf("migration %s failed: %w", migration.fileName, err)
+		}
+	}
+
+	return nil
+}
+
+func (db *Database) applyMigration(migration migration) error {
+	script, err := migrationsFS.ReadFile(path.Join(migrationsDir, migration.fileName))
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.Exec(string(script)); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`INSERT INTO schema_version (version) VALUES (?)`, migration.version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// getMigrations returns embedded migrations sorted by version, file names must look like 0001_description.sql.
+func getMigrations() (migrations []migration, err error) {
+	entries, err := migrationsFS.ReadDir(migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		versionStr, _, found := strings.Cut(entry.Name(), "_")
+		if !found {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, migration{version: version, fileName: entry.Name()})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].version)
+		}
+	}
+
+	return migrations, nil
+}
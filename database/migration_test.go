@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// baselineSchema is the schema created before migrations were introduced.
+const baselineSchema = `
+CREATE TABLE tg_users (
+	user_id INTEGER PRIMARY KEY NOT NULL,
+	username TEXT,
+	first_name TEXT,
+	last_name TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	details TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);`
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestMigrateEmpty(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := New(Config{WorkingDir: dir})
+	if err != nil {
+		t.Fatalf("Can't create database: %s", err)
+	}
+
+	checkSchemaVersion(t, db)
+
+	const chatID = 42
+
+	if err = db.StoreUserInfo(tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID, Type: "private"}}); err != nil {
+		t.Fatalf("Can't store user: %s", err)
+	}
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	if err = db.RecordPowerOff(start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("Can't record outage: %s", err)
+	}
+
+	outages, err := db.GetOutages(1)
+	if err != nil {
+		t.Fatalf("Can't get outages: %s", err)
+	}
+
+	if len(outages) != 1 || !outages[0].Start.Equal(start) || outages[0].Duration() != time.Hour {
+		t.Errorf("Wrong outages: %v", outages)
+	}
+
+	if _, err = db.AddReminder(chatID, "turn on the boiler"); err != nil {
+		t.Fatalf("Can't add reminder: %s", err)
+	}
+
+	if err = db.AddPendingMessage(PendingMessage{ChatID: chatID, Text: "queued"}); err != nil {
+		t.Fatalf("Can't add pending message: %s", err)
+	}
+
+	if err = db.RemoveUserInfo(chatID); err != nil {
+		t.Fatalf("Can't remove user: %s", err)
+	}
+
+	checkCount(t, db, "reminders", 0)
+	checkCount(t, db, "pending_messages", 0)
+	checkCount(t, db, "subscriptions", 0)
+
+	db.Close()
+
+	// migrations are applied once
+	if db, err = New(Config{WorkingDir: dir}); err != nil {
+		t.Fatalf("Can't reopen database: %s", err)
+	}
+	defer db.Close()
+
+	checkSchemaVersion(t, db)
+}
+
+func TestMigrateBaseline(t *testing.T) {
+	dir := t.TempDir()
+
+	baseline, err := sql.Open("sqlite3", filepath.Join(dir, dbName))
+	if err != nil {
+		t.Fatalf("Can't open baseline database: %s", err)
+	}
+
+	// the baseline stored timestamps with the host offset
+	if _, err = baseline.Exec(baselineSchema + `
+		INSERT INTO tg_users (user_id, username, created_at) VALUES (42, 'user', '2024-03-01 09:00:00+02:00');
+		INSERT INTO events (name, details, created_at)
+			VALUES ('Bot is alive', 'Bot is alive', '2024-03-01 12:30:00.5+02:00');
+		INSERT INTO events (name, details, created_at) VALUES ('Bot started', NULL, '2024-03-01 08:00:00+00:00');
+	`); err != nil {
+		t.Fatalf("Can't create baseline database: %s", err)
+	}
+
+	baseline.Close()
+
+	db, err := New(Config{WorkingDir: dir})
+	if err != nil {
+		t.Fatalf("Can't migrate baseline database: %s", err)
+	}
+	defer db.Close()
+
+	checkSchemaVersion(t, db)
+
+	lastHeartbeat, err := db.GetLastHeartbeat()
+	if err != nil {
+		t.Fatalf("Can't get last heartbeat: %s", err)
+	}
+
+	if expected := time.Date(2024, 3, 1, 10, 30, 0, 500000000, time.UTC); !lastHeartbeat.Equal(expected) {
+		t.Errorf("Wrong last heartbeat: %s, expected %s", lastHeartbeat, expected)
+	}
+
+	var stored string
+
+	// the driver converts offsets by itself, check 0008 has rewritten the stored value
+	if err = db.sql.QueryRow(`SELECT substr(created_at, 1) FROM events WHERE event_type = 'heartbeat'`).Scan(
+		&stored); err != nil {
+		t.Fatalf("Can't get stored heartbeat: %s", err)
+	}
+
+	if stored != "2024-03-01 10:30:00.500" {
+		t.Errorf("Heartbeat is not stored in UTC: %s", stored)
+	}
+
+	users, err := db.GetUsers()
+	if err != nil {
+		t.Fatalf("Can't get users: %s", err)
+	}
+
+	if expected := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC); len(users) != 1 || users[0].ID != 42 ||
+		!users[0].CreatedAt.Equal(expected) {
+		t.Errorf("Wrong users: %v", users)
+	}
+
+	// existing users keep getting notifications about the main location
+	subscribers, err := db.GetSubscribers(MainLocationID)
+	if err != nil {
+		t.Fatalf("Can't get subscribers: %s", err)
+	}
+
+	if len(subscribers) != 1 || subscribers[0] != 42 {
+		t.Errorf("Wrong subscribers: %v", subscribers)
+	}
+
+	var details string
+
+	if err = db.sql.QueryRow(`SELECT details FROM events WHERE event_type = 'legacy'`).Scan(&details); err != nil {
+		t.Fatalf("Can't get legacy event: %s", err)
+	}
+
+	if details != "Bot started" {
+		t.Errorf("Wrong legacy event details: %s", details)
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func checkSchemaVersion(t *testing.T, db *Database) {
+	t.Helper()
+
+	migrations, err := getMigrations()
+	if err != nil {
+		t.Fatalf("Can't get migrations: %s", err)
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("Can't get schema version: %s", err)
+	}
+
+	if latest := migrations[len(migrations)-1].version; version != latest {
+		t.Errorf("Wrong schema version: %d, expected %d", version, latest)
+	}
+
+	var count int
+
+	if err = db.sql.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&count); err != nil {
+		t.Fatalf("Can't count applied migrations: %s", err)
+	}
+
+	if count != len(migrations) {
+		t.Errorf("Wrong applied migrations count: %d, expected %d", count, len(migrations))
+	}
+}
+
+func checkCount(t *testing.T, db *Database, table string, expected int) {
+	t.Helper()
+
+	var count int
+
+	if err := db.sql.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %s`, table)).Scan(&count); err != nil {
+		t.Fatalf("Can't count %s: %s", table, err)
+	}
+
+	if count != expected {
+		t.Errorf("Wrong %s count: %d, expected %d", table, count, expected)
+	}
+}
@@ -0,0 +1,30 @@
+-- Initial schema, IF NOT EXISTS keeps it compatible with databases created before migrations.
+
+CREATE TABLE IF NOT EXISTS tg_users (
+	user_id INTEGER PRIMARY KEY NOT NULL,
+	username TEXT,
+	first_name TEXT,
+	last_name TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	details TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS events_name_id ON events (name, id);
+
+CREATE TABLE IF NOT EXISTS settings (
+	key TEXT PRIMARY KEY NOT NULL,
+	value TEXT
+);
+
+CREATE TABLE IF NOT EXISTS reminders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	task TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- Per-user interface language, NULL means not chosen yet.
+
+ALTER TABLE tg_users ADD COLUMN language TEXT;
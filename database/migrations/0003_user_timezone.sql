@@ -0,0 +1,3 @@
+-- Per-user IANA timezone, NULL means the bot default.
+
+ALTER TABLE tg_users ADD COLUMN timezone TEXT;
@@ -0,0 +1,9 @@
+-- Scoped API tokens for machine HTTP endpoints, only SHA-256 hashes are stored.
+
+CREATE TABLE api_tokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	hash TEXT NOT NULL UNIQUE,
+	scope TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
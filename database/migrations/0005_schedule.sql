@@ -0,0 +1,13 @@
+-- Manually edited outage schedule. Rows are never updated, the latest row per group and weekday is the current
+-- schedule of that day and older rows are the audit history. Empty windows mean no planned outages.
+
+CREATE TABLE schedule_changes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	schedule_group TEXT NOT NULL,
+	weekday INTEGER NOT NULL,
+	windows TEXT NOT NULL,
+	changed_by INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX schedule_changes_day ON schedule_changes (schedule_group, weekday, id);
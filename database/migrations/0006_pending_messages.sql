@@ -0,0 +1,10 @@
+-- Outgoing messages waiting for delivery while Telegram is unreachable, delivered in id order.
+
+CREATE TABLE pending_messages (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	chat_id INTEGER NOT NULL,
+	text TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX pending_messages_chat ON pending_messages (chat_id);
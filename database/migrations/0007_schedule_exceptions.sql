@@ -0,0 +1,14 @@
+-- Date-specific schedule overrides for holidays and special grid situations. Like schedule_changes rows are never
+-- updated, the latest row per group and date wins. NULL windows restore the weekly schedule, group "*" means all.
+
+CREATE TABLE schedule_exceptions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	schedule_group TEXT NOT NULL,
+	date TEXT NOT NULL,
+	windows TEXT,
+	note TEXT NOT NULL DEFAULT '',
+	changed_by INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX schedule_exceptions_day ON schedule_exceptions (date, schedule_group, id);
@@ -0,0 +1,23 @@
+-- Convert timestamps stored with a non-UTC offset (e.g. by the host local time) to UTC.
+-- strftime returns UTC for values with an offset, the offset suffix is dropped as UTC is implied.
+
+UPDATE tg_users SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at)
+WHERE substr(created_at, -6) GLOB '[+-][0-9][0-9]:[0-9][0-9]' AND substr(created_at, -6) != '+00:00';
+
+UPDATE events SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at)
+WHERE substr(created_at, -6) GLOB '[+-][0-9][0-9]:[0-9][0-9]' AND substr(created_at, -6) != '+00:00';
+
+UPDATE reminders SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at)
+WHERE substr(created_at, -6) GLOB '[+-][0-9][0-9]:[0-9][0-9]' AND substr(created_at, -6) != '+00:00';
+
+UPDATE api_tokens SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at)
+WHERE substr(created_at, -6) GLOB '[+-][0-9][0-9]:[0-9][0-9]' AND substr(created_at, -6) != '+00:00';
+
+UPDATE schedule_changes SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at)
+WHERE substr(created_at, -6) GLOB '[+-][0-9][0-9]:[0-9][0-9]' AND substr(created_at, -6) != '+00:00';
+
+UPDATE schedule_exceptions SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at)
+WHERE substr(created_at, -6) GLOB '[+-][0-9][0-9]:[0-9][0-9]' AND substr(created_at, -6) != '+00:00';
+
+UPDATE pending_messages SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at)
+WHERE substr(created_at, -6) GLOB '[+-][0-9][0-9]:[0-9][0-9]' AND substr(created_at, -6) != '+00:00';
@@ -0,0 +1,10 @@
+-- Outages moved out of the events table into compressed archive files.
+
+CREATE TABLE outage_archives (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	file_name TEXT NOT NULL,
+	first_start_at TIMESTAMP NOT NULL,
+	last_end_at TIMESTAMP NOT NULL,
+	outages INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+-- Planned outage schedules fetched from the grid operator. Unlike schedule_changes these rows mirror an external
+-- source, so each fetch replaces windows of the group on the date.
+
+CREATE TABLE schedules (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	source TEXT NOT NULL,
+	schedule_group TEXT NOT NULL,
+	date TEXT NOT NULL,
+	windows TEXT NOT NULL,
+	fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (source, schedule_group, date)
+);
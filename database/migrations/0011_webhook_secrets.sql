@@ -0,0 +1,8 @@
+-- Shared secrets verifying inbound webhooks per source. Secrets are stored as is since HMAC signatures can't be
+-- verified against a hash.
+
+CREATE TABLE webhook_secrets (
+	source TEXT PRIMARY KEY,
+	secret TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
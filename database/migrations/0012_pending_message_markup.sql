@@ -0,0 +1,3 @@
+-- Inline keyboards of queued messages as Bot API JSON, empty for plain messages.
+
+ALTER TABLE pending_messages ADD COLUMN reply_markup TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,34 @@
+-- Typed events: free-form event names are replaced with the event_type enum column.
+-- Events with names unknown to the typed API are kept as legacy events with the name prepended to details.
+
+CREATE TABLE events_typed (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_type TEXT NOT NULL CHECK (event_type IN ('heartbeat', 'power_off', 'power_on', 'startup',
+		'uplink_changed', 'user_unregistered', 'legacy')),
+	details TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO events_typed (id, event_type, details, created_at)
+SELECT id,
+	CASE name
+		WHEN 'Bot is alive' THEN 'heartbeat'
+		WHEN 'power_off' THEN 'power_off'
+		WHEN 'power_on' THEN 'power_on'
+		WHEN 'Uplink changed' THEN 'uplink_changed'
+		WHEN 'User unregistered' THEN 'user_unregistered'
+		ELSE 'legacy'
+	END,
+	CASE
+		WHEN name = 'Bot is alive' THEN NULL
+		WHEN name IN ('power_off', 'power_on', 'Uplink changed', 'User unregistered') THEN details
+		ELSE name || COALESCE(': ' || details, '')
+	END,
+	created_at
+FROM events;
+
+DROP TABLE events;
+
+ALTER TABLE events_typed RENAME TO events;
+
+CREATE INDEX events_type_id ON events (event_type, id);
@@ -0,0 +1,3 @@
+-- Per-user plain text preference, messages are sent without emoji, formatting and keyboards.
+
+ALTER TABLE tg_users ADD COLUMN plain_text INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Telegram chat type of registered chats: private, group, supergroup or channel. Existing chats are private users.
+
+ALTER TABLE tg_users ADD COLUMN chat_type TEXT NOT NULL DEFAULT 'private';
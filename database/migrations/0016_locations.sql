@@ -0,0 +1,21 @@
+-- Monitored locations and user subscriptions to them. Location 1 is monitored by the bot itself, existing users are
+-- subscribed to it so they keep receiving the same notifications.
+
+CREATE TABLE locations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO locations (id, name) VALUES (1, 'home');
+
+CREATE TABLE subscriptions (
+	chat_id INTEGER NOT NULL,
+	location_id INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (chat_id, location_id)
+);
+
+CREATE INDEX subscriptions_location ON subscriptions (location_id);
+
+INSERT INTO subscriptions (chat_id, location_id) SELECT user_id, 1 FROM tg_users;
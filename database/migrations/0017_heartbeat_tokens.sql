@@ -0,0 +1,8 @@
+-- Heartbeat tokens of locations reported by devices on the premises, only token hashes are stored. Last heartbeat
+-- and outage start keep the receiver state across restarts.
+
+ALTER TABLE locations ADD COLUMN heartbeat_token_hash TEXT;
+ALTER TABLE locations ADD COLUMN last_heartbeat TIMESTAMP;
+ALTER TABLE locations ADD COLUMN off_since TIMESTAMP;
+
+CREATE UNIQUE INDEX locations_heartbeat_token ON locations (heartbeat_token_hash);
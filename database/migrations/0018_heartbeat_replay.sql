@@ -0,0 +1,5 @@
+-- Heartbeat replay protection: device timestamps of accepted heartbeats must grow, and the clock skew tolerated
+-- between the device and the bot can be set per location, 0 means the receiver default.
+
+ALTER TABLE locations ADD COLUMN last_heartbeat_sent TIMESTAMP;
+ALTER TABLE locations ADD COLUMN heartbeat_clock_skew INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Battery charge in percent and signal strength in dBm reported by heartbeat devices, NULL until reported.
+
+ALTER TABLE locations ADD COLUMN sensor_battery INTEGER;
+ALTER TABLE locations ADD COLUMN sensor_signal INTEGER;
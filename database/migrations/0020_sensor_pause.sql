@@ -0,0 +1,4 @@
+-- Paused heartbeat sensors are kept with their token, but neither their heartbeats nor their silence change
+-- the location power state.
+
+ALTER TABLE locations ADD COLUMN heartbeat_paused INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Outage schedule group of the chat and the lead time in minutes of the warning not to take the elevator before
+-- planned outages, 0 means no warning.
+
+ALTER TABLE tg_users ADD COLUMN schedule_group TEXT;
+ALTER TABLE tg_users ADD COLUMN elevator_warning INTEGER NOT NULL DEFAULT 0;
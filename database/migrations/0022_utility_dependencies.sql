@@ -0,0 +1,6 @@
+-- Utilities of the chat building depending on electricity and the chat note, they are added to power off
+-- notifications of the chat.
+
+ALTER TABLE tg_users ADD COLUMN water_depends INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tg_users ADD COLUMN heating_depends INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tg_users ADD COLUMN outage_note TEXT;
@@ -0,0 +1,13 @@
+-- Feature flags rolling experimental features out to a percent of chats, chats added to the flag get the feature
+-- regardless of the percent.
+
+CREATE TABLE feature_flags (
+	name TEXT PRIMARY KEY NOT NULL,
+	percent INTEGER NOT NULL
+);
+
+CREATE TABLE feature_flag_chats (
+	name TEXT NOT NULL,
+	chat_id INTEGER NOT NULL,
+	PRIMARY KEY (name, chat_id)
+);
@@ -0,0 +1,8 @@
+-- Notifications sent in each wording variant and how many of them were engaged with, e.g. by opening outage
+-- details.
+
+CREATE TABLE notification_variants (
+	variant TEXT PRIMARY KEY NOT NULL,
+	sent INTEGER NOT NULL DEFAULT 0,
+	engaged INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,8 @@
+-- Multi-step conversations in progress, a chat takes part in one conversation at a time.
+
+CREATE TABLE dialogs (
+	chat_id INTEGER PRIMARY KEY NOT NULL,
+	dialog TEXT NOT NULL,
+	step TEXT NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
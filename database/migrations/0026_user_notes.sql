@@ -0,0 +1,11 @@
+-- Admin notes and tags of chats, tags select broadcast recipients.
+
+ALTER TABLE tg_users ADD COLUMN admin_note TEXT;
+
+CREATE TABLE user_tags (
+	user_id INTEGER NOT NULL,
+	tag TEXT NOT NULL COLLATE NOCASE,
+	PRIMARY KEY (user_id, tag)
+);
+
+CREATE INDEX user_tags_tag ON user_tags (tag);
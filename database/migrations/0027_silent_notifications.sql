@@ -0,0 +1,11 @@
+-- Per-chat sound preference of minor notifications, rows override the default of the event type. Queued messages
+-- keep the sound preference they were queued with.
+
+CREATE TABLE silent_notifications (
+	user_id INTEGER NOT NULL,
+	event TEXT NOT NULL,
+	silent INTEGER NOT NULL,
+	PRIMARY KEY (user_id, event)
+);
+
+ALTER TABLE pending_messages ADD COLUMN silent INTEGER NOT NULL DEFAULT 0;
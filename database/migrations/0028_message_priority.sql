@@ -0,0 +1,3 @@
+-- Priority of queued messages, higher priorities are delivered first. Messages queued before priorities are normal.
+
+ALTER TABLE pending_messages ADD COLUMN priority INTEGER NOT NULL DEFAULT 1;
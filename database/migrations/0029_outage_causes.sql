@@ -0,0 +1,6 @@
+-- Outage causes annotated by admins, outage_id is the id of the power_on event ending the outage.
+
+CREATE TABLE outage_causes (
+	outage_id INTEGER PRIMARY KEY,
+	cause TEXT NOT NULL
+);
@@ -0,0 +1,4 @@
+-- Lead time in minutes of recurring reminders sent before each planned outage of the chat schedule group, 0 means the
+-- reminder is sent once when power returns.
+
+ALTER TABLE reminders ADD COLUMN lead_minutes INTEGER NOT NULL DEFAULT 0;
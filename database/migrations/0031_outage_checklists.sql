@@ -0,0 +1,5 @@
+-- Checklist sent to the chat before long planned outages, NULL means the default checklist, and whether the chat
+-- gets it.
+
+ALTER TABLE tg_users ADD COLUMN checklist TEXT;
+ALTER TABLE tg_users ADD COLUMN checklist_enabled INTEGER NOT NULL DEFAULT 1;
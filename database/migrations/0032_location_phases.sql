@@ -0,0 +1,7 @@
+-- Sensors monitoring single phases of a three-phase location. The parent location is off while all its phases are,
+-- its off_since is kept by the heartbeat receiver.
+
+ALTER TABLE locations ADD COLUMN parent_id INTEGER REFERENCES locations (id);
+ALTER TABLE locations ADD COLUMN phase TEXT;
+
+CREATE UNIQUE INDEX locations_phase ON locations (parent_id, phase);
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// SetOutageCause stores the cause of the outage, empty cause removes it. sql.ErrNoRows is returned if the outage
+// doesn't exist.
+func (db *Database) SetOutageCause(outageID int64, cause string) error {
+	if _, err := db.GetOutage(outageID); err != nil {
+		return err
+	}
+
+	if cause == "" {
+		_, err := db.sql.Exec(`DELETE FROM outage_causes WHERE outage_id = ?`, outageID)
+
+		return err
+	}
+
+	_, err := db.sql.Exec(`INSERT INTO outage_causes (outage_id, cause) VALUES (?, ?)
+		ON CONFLICT(outage_id) DO UPDATE SET cause = excluded.cause`, outageID, cause)
+
+	return err
+}
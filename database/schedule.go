@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// DateFormat is the format of schedule exception dates.
+const DateFormat = "2006-01-02"
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// ScheduleDay structure with outage schedule windows of a group on a weekday, Version is the change ID.
+type ScheduleDay struct {
+	Version   int64
+	Group     string
+	Weekday   time.Weekday
+	Windows   string
+	ChangedBy int64
+	CreatedAt time.Time
+}
+
+// ScheduleException structure with outage windows of a group on a specific date overriding the weekly schedule.
+type ScheduleException struct {
+	Group     string
+	Date      string
+	Windows   string
+	Note      string
+	ChangedBy int64
+	CreatedAt time.Time
+}
+
+// PlannedSchedule structure with outage windows of a group on a date fetched from an external source.
+type PlannedSchedule struct {
+	Source    string
+	Group     string
+	Date      string
+	Windows   string
+	FetchedAt time.Time
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// SetScheduleDay stores new schedule windows of the group on the weekday and returns the change version.
+func (db *Database) SetScheduleDay(group string, weekday time.Weekday, windows string, changedBy int64,
+) (version int64, err error) {
+	result, err := db.sql.Exec(`INSERT INTO schedule_changes (schedule_group, weekday, windows, changed_by, created_at)
+		VALUES (?, ?, ?, ?, ?)`, group, int(weekday), windows, changedBy, now())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// GetSchedule returns current schedule days with outage windows ordered by group and weekday.
+func (db *Database) GetSchedule() (days []ScheduleDay, err error) {
+	return db.querySchedule(`SELECT id, schedule_group, weekday, windows, changed_by, created_at
+		FROM schedule_changes
+		WHERE id IN (SELECT MAX(id) FROM schedule_changes GROUP BY schedule_group, weekday) AND windows != ''
+		ORDER BY schedule_group, (weekday + 6) % 7`)
+}
+
+// GetScheduleChanges returns up to limit latest schedule changes, newest first.
+func (db *Database) GetScheduleChanges(limit int) (changes []ScheduleDay, err error) {
+	return db.querySchedule(`SELECT id, schedule_group, weekday, windows, changed_by, created_at
+		FROM schedule_changes ORDER BY id DESC LIMIT ?`, limit)
+}
+
+// SetScheduleException stores schedule exception of the group on the date, nil windows remove the exception.
+func (db *Database) SetScheduleException(group, date string, windows *string, note string, changedBy int64) error {
+	_, err := db.sql.Exec(`INSERT INTO schedule_exceptions (schedule_group, date, windows, note, changed_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, group, date, windows, note, changedBy, now())
+
+	return err
+}
+
+// GetScheduleExceptions returns active schedule exceptions on dates not before from, ordered by date and group.
+func (db *Database) GetScheduleExceptions(from string) (exceptions []ScheduleException, err error) {
+	rows, err := db.sql.Query(`SELECT schedule_group, date, windows, note, changed_by, created_at
+		FROM schedule_exceptions
+		WHERE id IN (SELECT MAX(id) FROM schedule_exceptions WHERE date >= ? GROUP BY schedule_group, date)
+			AND windows IS NOT NULL
+		ORDER BY date, schedule_group`, from)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			exception ScheduleException
+			windows   sql.NullString
+		)
+
+		if err = rows.Scan(&exception.Group, &exception.Date, &windows, &exception.Note, &exception.ChangedBy,
+			&exception.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		exception.Windows = windows.String
+		exceptions = append(exceptions, exception)
+	}
+
+	return exceptions, rows.Err()
+}
+
+// SetPlannedSchedule stores outage windows of the group on the date fetched from the source.
+func (db *Database) SetPlannedSchedule(source, group, date, windows string) error {
+	_, err := db.sql.Exec(`INSERT INTO schedules (source, schedule_group, date, windows, fetched_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(source, schedule_group, date) DO UPDATE SET windows = excluded.windows, fetched_at = excluded.fetched_at`,
+		source, group, date, windows, now())
+
+	return err
+}
+
+// GetPlannedSchedules returns planned schedules on dates not before from, ordered by date and group.
+func (db *Database) GetPlannedSchedules(from string) (schedules []PlannedSchedule, err error) {
+	rows, err := db.sql.Query(`SELECT source, schedule_group, date, windows, fetched_at FROM schedules
+		WHERE date >= ? ORDER BY date, schedule_group, source`, from)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var schedule PlannedSchedule
+
+		if err = rows.Scan(&schedule.Source, &schedule.Group, &schedule.Date, &schedule.Windows,
+			&schedule.FetchedAt); err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (db *Database) querySchedule(query string, args ...interface{}) (days []ScheduleDay, err error) {
+	rows, err := db.sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			day     ScheduleDay
+			weekday int
+		)
+
+		if err = rows.Scan(&day.Version, &day.Group, &weekday, &day.Windows, &day.ChangedBy,
+			&day.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		day.Weekday = time.Weekday(weekday)
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// GetSilentNotifications returns sound preferences of the chat by event type, events without a preference are
+// missing.
+func (db *Database) GetSilentNotifications(userID int64) (silent map[string]bool, err error) {
+	rows, err := db.sql.Query(`SELECT event, silent FROM silent_notifications WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	silent = make(map[string]bool)
+
+	for rows.Next() {
+		var (
+			event       string
+			eventSilent bool
+		)
+
+		if err = rows.Scan(&event, &eventSilent); err != nil {
+			return nil, err
+		}
+
+		silent[event] = eventSilent
+	}
+
+	return silent, rows.Err()
+}
+
+// SetSilentNotification stores whether notifications of the event type are sent to the chat without sound.
+func (db *Database) SetSilentNotification(userID int64, event string, silent bool) error {
+	_, err := db.sql.Exec(`INSERT INTO silent_notifications (user_id, event, silent) VALUES (?, ?, ?)
+		ON CONFLICT(user_id, event) DO UPDATE SET silent = excluded.silent`, userID, event, silent)
+
+	return err
+}
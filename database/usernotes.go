@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import "fmt"
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// SetUserNote stores the admin note of the user, empty note removes it.
+func (db *Database) SetUserNote(userID int64, note string) error {
+	return db.updateUser(userID, `UPDATE tg_users SET admin_note = NULLIF(?, '') WHERE user_id = ?`, note)
+}
+
+// AddUserTag tags the user, tags are case-insensitive.
+func (db *Database) AddUserTag(userID int64, tag string) error {
+	if !db.UserExists(userID) {
+		return fmt.Errorf("user %d not found", userID)
+	}
+
+	_, err := db.sql.Exec(`INSERT OR IGNORE INTO user_tags (user_id, tag) VALUES (?, ?)`, userID, tag)
+
+	return err
+}
+
+// RemoveUserTag removes the tag of the user, removing a missing tag is not an error.
+func (db *Database) RemoveUserTag(userID int64, tag string) error {
+	_, err := db.sql.Exec(`DELETE FROM user_tags WHERE user_id = ? AND tag = ?`, userID, tag)
+
+	return err
+}
+
+// GetTaggedUsers returns registered users with the tag.
+func (db *Database) GetTaggedUsers(tag string) (users []int64, err error) {
+	rows, err := db.sql.Query(`SELECT user_tags.user_id FROM user_tags
+		JOIN tg_users ON tg_users.user_id = user_tags.user_id WHERE tag = ? ORDER BY user_tags.user_id`, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var user int64
+
+		if err = rows.Scan(&user); err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
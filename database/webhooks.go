@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// WebhookSecret structure with webhook secret information, the secret itself is not returned.
+type WebhookSecret struct {
+	Source    string
+	CreatedAt time.Time
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// SetWebhookSecret stores webhook secret of the source replacing the previous one.
+func (db *Database) SetWebhookSecret(source, secret string) error {
+	_, err := db.sql.Exec(`INSERT INTO webhook_secrets (source, secret, created_at) VALUES (?, ?, ?)
+		ON CONFLICT(source) DO UPDATE SET secret = excluded.secret, created_at = excluded.created_at`,
+		source, secret, now())
+
+	return err
+}
+
+// GetWebhookSecret returns webhook secret of the source, sql.ErrNoRows is returned if there is no secret.
+func (db *Database) GetWebhookSecret(source string) (secret string, err error) {
+	err = db.sql.QueryRow(`SELECT secret FROM webhook_secrets WHERE source = ?`, source).Scan(&secret)
+
+	return secret, err
+}
+
+// GetWebhookSecrets returns all webhook secrets ordered by source.
+func (db *Database) GetWebhookSecrets() (secrets []WebhookSecret, err error) {
+	rows, err := db.sql.Query(`SELECT source, created_at FROM webhook_secrets ORDER BY source`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var secret WebhookSecret
+
+		if err = rows.Scan(&secret.Source, &secret.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, rows.Err()
+}
+
+// RemoveWebhookSecret removes webhook secret of the source.
+func (db *Database) RemoveWebhookSecret(source string) error {
+	result, err := db.sql.Exec(`DELETE FROM webhook_secrets WHERE source = ?`, source)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("webhook secret of %q not found", source)
+	}
+
+	return nil
+}
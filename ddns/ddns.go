@@ -0,0 +1,346 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ddns points the dynamic DNS record of the public status page to a failover host, e.g. a peer instance or
+// a static "we're down" page, while the bot runs on the backup uplink, and back once the primary uplink returns.
+package ddns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Supported dynamic DNS providers.
+const (
+	ProviderCloudflare = "cloudflare"
+	ProviderDuckDNS    = "duckdns"
+)
+
+const (
+	defaultCloudflareURL = "https://api.cloudflare.com/client/v4"
+	defaultDuckDNSURL    = "https://www.duckdns.org/update"
+	defaultRetryInterval = time.Minute
+	requestTimeout       = 30 * time.Second
+	// recordTTL is the minimal Cloudflare TTL, resolvers should follow the failover quickly.
+	recordTTL     = 60
+	maxReplySize  = 64 * 1024
+	subsystemName = "ddns updater"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with dynamic DNS updater configuration.
+type Config struct {
+	// Provider is ProviderCloudflare or ProviderDuckDNS.
+	Provider string
+	// URL is the provider API endpoint, the provider default if empty.
+	URL   string
+	Token string
+	// Domain is the DuckDNS subdomain or the Cloudflare record name.
+	Domain string
+	// ZoneID and RecordID identify the Cloudflare record.
+	ZoneID   string
+	RecordID string
+	// Primary is the record address while the primary uplink is in use, DuckDNS detects it if empty.
+	Primary string
+	// Failover is the record address while the bot runs on the backup uplink.
+	Failover string
+	// RetryInterval is the period of failed update retries.
+	RetryInterval time.Duration
+	// Reporter receives update state, optional.
+	Reporter StateReporter
+}
+
+// StateReporter receives subsystem state changes.
+type StateReporter interface {
+	SetSubsystemState(name string, err error)
+}
+
+// Updater updates the record on uplink changes, it implements uplink.Listener.
+type Updater struct {
+	config     Config
+	client     *http.Client
+	targets    chan string
+	cancelFunc context.CancelFunc
+}
+
+type cloudflareRecord struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int    `json:"ttl"`
+	Proxied bool   `json:"proxied"`
+}
+
+type cloudflareResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates and starts dynamic DNS updater, the record is updated on the first uplink detection.
+func New(config Config) (updater *Updater, err error) {
+	if err = validate(config); err != nil {
+		return nil, err
+	}
+
+	if config.URL == "" {
+		config.URL = defaultDuckDNSURL
+
+		if config.Provider == ProviderCloudflare {
+			config.URL = defaultCloudflareURL
+		}
+	}
+
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaultRetryInterval
+	}
+
+	updater = &Updater{
+		config: config, client: &http.Client{Timeout: requestTimeout}, targets: make(chan string, 1),
+	}
+
+	ctx, cancelFunction := context.WithCancel(context.Background())
+	updater.cancelFunc = cancelFunction
+
+	go updater.run(ctx)
+
+	return updater, nil
+}
+
+// Close stops dynamic DNS updater.
+func (updater *Updater) Close() {
+	updater.cancelFunc()
+}
+
+// UplinkChanged points the record to the failover address on the backup uplink and to the primary one otherwise.
+// Without uplink the provider can't be reached, the record is left as is.
+func (updater *Updater) UplinkChanged(iface string, backup bool) {
+	if iface == "" {
+		return
+	}
+
+	target := updater.config.Primary
+	if backup {
+		target = updater.config.Failover
+	}
+
+	// only the latest target matters
+	select {
+	case <-updater.targets:
+	default:
+	}
+
+	select {
+	case updater.targets <- target:
+	default:
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func validate(config Config) error {
+	if config.Token == "" || config.Domain == "" || config.Failover == "" {
+		return errors.New("token, domain and failover address are required")
+	}
+
+	switch config.Provider {
+	case ProviderCloudflare:
+		if config.ZoneID == "" || config.RecordID == "" || config.Primary == "" {
+			return errors.New("cloudflare requires zone ID, record ID and primary address")
+		}
+
+	case ProviderDuckDNS:
+		if net.ParseIP(config.Failover) == nil || (config.Primary != "" && net.ParseIP(config.Primary) == nil) {
+			return errors.New("duckdns records accept IP addresses only")
+		}
+
+	default:
+		return fmt.Errorf("unknown provider %q", config.Provider)
+	}
+
+	return nil
+}
+
+// run applies the latest target, failed updates are retried until they succeed or a new target comes.
+func (updater *Updater) run(ctx context.Context) {
+	var (
+		target string
+		// nil channel never fires while there is nothing to retry
+		retry <-chan time.Time
+	)
+
+	for {
+		select {
+		case target = <-updater.targets:
+		case <-retry:
+		case <-ctx.Done():
+			return
+		}
+
+		err := updater.update(ctx, target)
+
+		if updater.config.Reporter != nil {
+			updater.config.Reporter.SetSubsystemState(subsystemName, err)
+		}
+
+		if err != nil {
+			log.WithField("target", target).Errorf("Failed to update dynamic DNS record: %s", err)
+
+			retry = time.After(updater.config.RetryInterval)
+
+			continue
+		}
+
+		retry = nil
+
+		log.WithFields(log.Fields{"domain": updater.config.Domain, "target": target}).Info("Dynamic DNS record updated")
+	}
+}
+
+func (updater *Updater) update(ctx context.Context, target string) error {
+	if updater.config.Provider == ProviderCloudflare {
+		return updater.updateCloudflare(ctx, target)
+	}
+
+	return updater.updateDuckDNS(ctx, target)
+}
+
+// updateDuckDNS sets the record address, empty address is detected by DuckDNS from the request.
+func (updater *Updater) updateDuckDNS(ctx context.Context, target string) error {
+	query := url.Values{"domains": {updater.config.Domain}, "token": {updater.config.Token}}
+
+	if ip := net.ParseIP(target); ip != nil && ip.To4() == nil {
+		query.Set("ipv6", target)
+	} else if target != "" {
+		query.Set("ip", target)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, updater.config.URL+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	reply, err := updater.do(request)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(string(reply)) != "OK" {
+		return fmt.Errorf("duckdns update rejected: %q", reply)
+	}
+
+	return nil
+}
+
+// updateCloudflare overwrites the record, hostnames are set as CNAME.
+func (updater *Updater) updateCloudflare(ctx context.Context, target string) error {
+	record := cloudflareRecord{Type: "CNAME", Name: updater.config.Domain, Content: target, TTL: recordTTL}
+
+	if ip := net.ParseIP(target); ip != nil {
+		record.Type = "AAAA"
+
+		if ip.To4() != nil {
+			record.Type = "A"
+		}
+	}
+
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, fmt.Sprintf("%s/zones/%s/dns_records/%s",
+		updater.config.URL, url.PathEscape(updater.config.ZoneID), url.PathEscape(updater.config.RecordID)),
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+updater.config.Token)
+	request.Header.Set("Content-Type", "application/json")
+
+	reply, err := updater.do(request)
+	if err != nil {
+		return err
+	}
+
+	var response cloudflareResponse
+
+	if err = json.Unmarshal(reply, &response); err != nil {
+		return fmt.Errorf("failed to decode cloudflare response: %w", err)
+	}
+
+	if !response.Success {
+		if len(response.Errors) != 0 {
+			return fmt.Errorf("cloudflare update rejected: %d %s", response.Errors[0].Code, response.Errors[0].Message)
+		}
+
+		return errors.New("cloudflare update rejected")
+	}
+
+	return nil
+}
+
+// do sends the request and returns the reply body of a successful response.
+func (updater *Updater) do(request *http.Request) (reply []byte, err error) {
+	response, err := updater.client.Do(request)
+	if err != nil {
+		// the DuckDNS token is a query parameter
+		var urlError *url.Error
+		if errors.As(err, &urlError) {
+			urlError.URL = updater.config.URL
+		}
+
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	reply, err = io.ReadAll(io.LimitReader(response.Body, maxReplySize))
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", response.Status)
+	}
+
+	return reply, nil
+}
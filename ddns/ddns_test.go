@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddns_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"electrobot/ddns"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	testToken    = "ddns-token"
+	waitTimeout  = 5 * time.Second
+	quietTimeout = 100 * time.Millisecond
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type cloudflareRecord struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestNew(t *testing.T) {
+	testData := []struct {
+		name   string
+		config ddns.Config
+		valid  bool
+	}{
+		{
+			name: "duckdns", valid: true,
+			config: ddns.Config{Provider: ddns.ProviderDuckDNS, Token: testToken, Domain: "home", Failover: "192.0.2.1"},
+		},
+		{
+			name:   "duckdns hostname",
+			config: ddns.Config{Provider: ddns.ProviderDuckDNS, Token: testToken, Domain: "home", Failover: "example.com"},
+		},
+		{
+			name: "cloudflare", valid: true,
+			config: ddns.Config{
+				Provider: ddns.ProviderCloudflare, Token: testToken, Domain: "status.example.com", ZoneID: "zone",
+				RecordID: "record", Primary: "home.example.com", Failover: "peer.example.com",
+			},
+		},
+		{
+			name: "cloudflare without record",
+			config: ddns.Config{
+				Provider: ddns.ProviderCloudflare, Token: testToken, Domain: "status.example.com", ZoneID: "zone",
+				Primary: "home.example.com", Failover: "peer.example.com",
+			},
+		},
+		{
+			name:   "without token",
+			config: ddns.Config{Provider: ddns.ProviderDuckDNS, Domain: "home", Failover: "192.0.2.1"},
+		},
+		{
+			name:   "unknown provider",
+			config: ddns.Config{Provider: "noip", Token: testToken, Domain: "home", Failover: "192.0.2.1"},
+		},
+	}
+
+	for _, item := range testData {
+		updater, err := ddns.New(item.config)
+		if err == nil {
+			updater.Close()
+		}
+
+		if (err == nil) != item.valid {
+			t.Errorf("Wrong %s validation: %v", item.name, err)
+		}
+	}
+}
+
+func TestDuckDNS(t *testing.T) {
+	queries := make(chan url.Values, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.Query()
+
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	updater, err := ddns.New(ddns.Config{
+		Provider: ddns.ProviderDuckDNS, URL: server.URL, Token: testToken, Domain: "home", Failover: "192.0.2.1",
+	})
+	if err != nil {
+		t.Fatalf("Can't create updater: %s", err)
+	}
+	defer updater.Close()
+
+	updater.UplinkChanged("wwan0", true)
+
+	if query := nextQuery(t, queries); query.Get("ip") != "192.0.2.1" || query.Get("domains") != "home" ||
+		query.Get("token") != testToken {
+		t.Errorf("Wrong failover query: %v", query)
+	}
+
+	// primary address is detected by DuckDNS
+	updater.UplinkChanged("eth0", false)
+
+	if query := nextQuery(t, queries); query.Has("ip") {
+		t.Errorf("Wrong primary query: %v", query)
+	}
+
+	updater.UplinkChanged("", false)
+
+	select {
+	case query := <-queries:
+		t.Errorf("Unexpected update without uplink: %v", query)
+
+	case <-time.After(quietTimeout):
+	}
+}
+
+func TestCloudflare(t *testing.T) {
+	records := make(chan cloudflareRecord, 10)
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/zones/zone/dns_records/record" ||
+			r.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(w, "wrong request", http.StatusBadRequest)
+
+			return
+		}
+
+		var record cloudflareRecord
+
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		records <- record
+
+		// the first update fails and is retried
+		if requests.Add(1) == 1 {
+			_, _ = w.Write([]byte(`{"success": false, "errors": [{"code": 1000, "message": "try later"}]}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	updater, err := ddns.New(ddns.Config{
+		Provider: ddns.ProviderCloudflare, URL: server.URL, Token: testToken, Domain: "status.example.com",
+		ZoneID: "zone", RecordID: "record", Primary: "home.example.com", Failover: "192.0.2.1",
+		RetryInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Can't create updater: %s", err)
+	}
+	defer updater.Close()
+
+	updater.UplinkChanged("wwan0", true)
+
+	failover := cloudflareRecord{Type: "A", Name: "status.example.com", Content: "192.0.2.1"}
+
+	if record := nextRecord(t, records); record != failover {
+		t.Errorf("Wrong failover record: %v", record)
+	}
+
+	if record := nextRecord(t, records); record != failover {
+		t.Errorf("Wrong retried failover record: %v", record)
+	}
+
+	updater.UplinkChanged("eth0", false)
+
+	if record := nextRecord(t, records); record != (cloudflareRecord{
+		Type: "CNAME", Name: "status.example.com", Content: "home.example.com",
+	}) {
+		t.Errorf("Wrong primary record: %v", record)
+	}
+
+	select {
+	case record := <-records:
+		t.Errorf("Unexpected update after success: %v", record)
+
+	case <-time.After(quietTimeout):
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func nextQuery(t *testing.T, queries <-chan url.Values) (query url.Values) {
+	t.Helper()
+
+	select {
+	case query = <-queries:
+	case <-time.After(waitTimeout):
+		t.Fatalf("Can't get DuckDNS update: timeout")
+	}
+
+	return query
+}
+
+func nextRecord(t *testing.T, records <-chan cloudflareRecord) (record cloudflareRecord) {
+	t.Helper()
+
+	select {
+	case record = <-records:
+	case <-time.After(waitTimeout):
+		t.Fatalf("Can't get Cloudflare update: timeout")
+	}
+
+	return record
+}
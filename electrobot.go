@@ -1,17 +1,118 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
+	"electrobot/apiserver"
+	"electrobot/archive"
+	"electrobot/backup"
+	"electrobot/config"
+	"electrobot/dashboard"
 	"electrobot/database"
+	"electrobot/ddns"
+	"electrobot/health"
+	"electrobot/heartbeat"
+	"electrobot/hostmonitor"
+	"electrobot/httpserver"
+	"electrobot/installer"
+	"electrobot/loadtest"
+	"electrobot/logtail"
+	"electrobot/metrics"
+	"electrobot/opendata"
+	"electrobot/powermonitor"
+	"electrobot/probes"
+	"electrobot/retention"
+	"electrobot/scheduler"
+	"electrobot/selftest"
+	"electrobot/selfupdate"
 	"electrobot/telegrambot"
+	"electrobot/uplink"
 
 	"github.com/coreos/go-systemd/daemon"
 	log "github.com/sirupsen/logrus"
 )
 
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultConfigFile = "/etc/electrobot/config.json"
+	autocertDir       = "autocert"
+	defaultArchiveDir = "archive"
+	defaultBackupDir  = "backup"
+	loadTestCommand   = "loadtest"
+	configCommand     = "config"
+	installCommand    = "install"
+	importCommand     = "import"
+	updateCommand     = "self-update"
+	// logTailSize is the number of latest log lines kept for /logs.
+	logTailSize = 1000
+	// maxHeartbeatThreshold bounds the heartbeat threshold changed with /config.
+	maxHeartbeatThreshold = 24 * time.Hour
+	// tokenFromCredential is shown by config show instead of the token missing from the config and environment.
+	tokenFromCredential = "<not set, expected from the " + config.TelegramTokenCredential + " credential>"
+)
+
+// Process exit codes.
+const (
+	exitCodeOK = iota
+	exitCodeDatabase
+	exitCodeConfig
+	exitCodeTelegram
+	exitCodeSelfTest
+	exitCodeMonitor
+	exitCodeLoadTest
+	exitCodeInstall
+	exitCodeImport
+	exitCodeUpdate
+	// exitCodeFailure is returned for errors without a specific code.
+	exitCodeFailure
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type exitError struct {
+	code int
+	err  error
+}
+
+// scheduleImport restarts the planned schedule import with the region and group chosen in the setup wizard.
+type scheduleImport struct {
+	sync.Mutex
+
+	config   scheduler.Config
+	storage  scheduler.Storage
+	importer *scheduler.Scheduler
+}
+
+// selfUpdater stages releases for /update, the restarted service installs them.
+type selfUpdater struct {
+	config selfupdate.Config
+}
+
+// processRestarter restarts the bot by stopping it, systemd starts it again.
+type processRestarter struct{}
+
+// uplinkListeners passes uplink changes to the bot and the status page DNS failover.
+type uplinkListeners []uplink.Listener
+
 /***********************************************************************************************************************
  * Init
  **********************************************************************************************************************/
@@ -31,29 +132,448 @@ func init() {
  **********************************************************************************************************************/
 
 func main() {
+	configFile := flag.String("c", defaultConfigFile, "path to config file")
+
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case loadTestCommand:
+		os.Exit(runLoadTest(flag.Args()[1:]))
+	case configCommand:
+		os.Exit(runConfigCommand(flag.Args()[1:], *configFile))
+	case installCommand:
+		os.Exit(runInstall(flag.Args()[1:], *configFile))
+	case importCommand:
+		os.Exit(runImport(flag.Args()[1:], *configFile))
+	case updateCommand:
+		os.Exit(runSelfUpdate(flag.Args()[1:], *configFile))
+	}
+
 	log.Info("Hello, World!")
 
-	db, err := database.New(database.Config{WorkingDir: "/var/electrobot"})
+	// config file is optional only when the default path is used
+	cfg, err := config.New(*configFile, *configFile == defaultConfigFile)
+	if err == nil {
+		err = cfg.CheckFeatures(probes.FeatureName, opendata.FeatureName, apiserver.FeatureName, dashboard.FeatureName,
+			heartbeat.FeatureName, metrics.FeatureName)
+	}
+
+	if err != nil {
+		printConfigError(err)
+
+		os.Exit(exitCodeConfig)
+	}
+
+	if level, err := log.ParseLevel(cfg.LogLevel); err != nil {
+		log.Warnf("Invalid log level %q: %s", cfg.LogLevel, err)
+	} else {
+		log.SetLevel(level)
+	}
+
+	if err := run(cfg); err != nil {
+		log.Errorf("Fatal error: %s", err)
+
+		os.Exit(exitCode(err))
+	}
+
+	os.Exit(exitCodeOK)
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func (importer *scheduleImport) SetScheduleImport(region, group string) error {
+	importer.Lock()
+	defer importer.Unlock()
+
+	if importer.importer != nil {
+		importer.importer.Close()
+		importer.importer = nil
+	}
+
+	if group == "" {
+		return nil
+	}
+
+	config := importer.config
+	config.Region, config.Group = region, group
+
+	scheduleImporter, err := scheduler.New(config, importer.storage)
+	if err != nil {
+		return err
+	}
+
+	importer.importer = scheduleImporter
+
+	return nil
+}
+
+func (importer *scheduleImport) Close() {
+	importer.Lock()
+	defer importer.Unlock()
+
+	if importer.importer != nil {
+		importer.importer.Close()
+	}
+}
+
+func (updater *selfUpdater) StageUpdate() error {
+	return selfupdate.Stage(context.Background(), updater.config)
+}
+
+// Restart stops the bot gracefully as on SIGTERM, systemd starts it again and installs the staged release if any.
+func (processRestarter) Restart() {
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		log.Errorf("Failed to restart: %s", err)
+	}
+}
+
+func (listeners uplinkListeners) UplinkChanged(iface string, backup bool) {
+	for _, listener := range listeners {
+		listener.UplinkChanged(iface, backup)
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func run(cfg *config.Config) (err error) {
+	logBuffer := logtail.New(logTailSize)
+	log.AddHook(logBuffer)
+
+	db, err := database.New(database.Config{WorkingDir: cfg.WorkingDir})
 	if err != nil {
-		log.Errorf("Failed to start bot due to DB error: %s", err)
+		err = &exitError{exitCodeDatabase, fmt.Errorf("failed to start bot due to DB error: %w", err)}
+
+		reportFatalError(cfg, 0, err)
 
-		os.Exit(1)
+		return err
 	}
+	defer db.Close()
 
-	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	if botToken == "" {
-		log.Error("TELEGRAM_BOT_TOKEN env variable is not set")
+	// the owner claimed with /claim is stored in the database, it is reported to before the database is closed
+	defer func() {
+		if err != nil {
+			reportFatalError(cfg, telegrambot.StoredOwnerChatID(db), err)
+		}
+	}()
 
-		os.Exit(2)
+	healthRegistry := health.New()
+
+	// systemd expects notifications within WATCHDOG_USEC, notify twice as often
+	watchdogInterval, err := daemon.SdWatchdogEnabled(false)
+	if err != nil {
+		log.Errorf("Failed to get systemd watchdog interval: %s", err)
 	}
 
-	bot, err := telegrambot.New(botToken, db)
+	scheduleLocation, err := time.LoadLocation(cfg.DefaultTimezone)
 	if err != nil {
-		log.Errorf("Failed to start bot due to Telegram error: %s", err)
+		log.Warnf("Invalid default timezone %q, using UTC: %s", cfg.DefaultTimezone, err)
 
-		os.Exit(3)
+		scheduleLocation = time.UTC
 	}
 
+	scheduleConfig := scheduler.Config{
+		URL: cfg.ScheduleImport.URL, Region: cfg.ScheduleImport.Region, Group: cfg.ScheduleImport.Group,
+		CheckInterval: cfg.ScheduleImport.CheckInterval.Duration, Location: scheduleLocation, Reporter: healthRegistry,
+	}
+
+	var setupScheduleImporter telegrambot.ScheduleImporter
+
+	// the import set in the config can't be changed by the setup wizard
+	if cfg.ScheduleImport.Group == "" {
+		setupImport := &scheduleImport{config: scheduleConfig, storage: db}
+		defer setupImport.Close()
+
+		setupScheduleImporter = setupImport
+	}
+
+	var updater telegrambot.Updater
+
+	if cfg.Update.URL != "" {
+		updater = &selfUpdater{config: selfupdate.Config{
+			URL: cfg.Update.URL, PublicKey: cfg.Update.PublicKey, Dir: cfg.WorkingDir,
+		}}
+	}
+
+	bot, err := telegrambot.New(telegrambot.Config{
+		Token:                   cfg.Telegram.Token,
+		APIEndpoint:             cfg.Telegram.APIEndpoint,
+		PollTimeout:             cfg.Telegram.PollTimeout,
+		PollLimit:               cfg.Telegram.PollLimit,
+		AllowedUpdates:          cfg.Telegram.AllowedUpdates,
+		LowBandwidth:            cfg.Telegram.LowBandwidth,
+		LowBandwidthPollTimeout: cfg.Telegram.LowBandwidthPollTimeout,
+		RestoreAdvisoryDelay:    cfg.RestoreAdvisoryDelay.Duration,
+		WideOutageWindow:        cfg.WideOutageWindow.Duration,
+		OutageUpdateInterval:    cfg.OutageUpdateInterval.Duration,
+		NotificationBatchWindow: cfg.NotificationBatch.Duration,
+		DefaultLanguage:         cfg.DefaultLanguage,
+		DefaultTimezone:         cfg.DefaultTimezone,
+		SendAttempts:            cfg.Telegram.SendAttempts,
+		ScheduleOCRCommand:      cfg.ScheduleOCRCommand,
+		WatchdogInterval:        watchdogInterval / 2, //nolint:gomnd
+		AliveInterval:           cfg.AliveInterval.Duration,
+		Admins:                  cfg.AdminIDs,
+		Channels:                cfg.Telegram.Channels,
+		ChannelFlapWindow:       cfg.Telegram.ChannelFlapWindow.Duration,
+		Health:                  healthRegistry,
+		ScheduleImporter:        setupScheduleImporter,
+		ScheduleGroup:           cfg.ScheduleImport.Group,
+		Updater:                 updater,
+		Restarter:               processRestarter{},
+		Logs:                    logBuffer,
+	}, db)
+	if err != nil {
+		return &exitError{exitCodeTelegram, fmt.Errorf("failed to start bot due to Telegram error: %w", err)}
+	}
+	defer bot.Close()
+
+	hostMonitor, mainLocationProbed, err := newHostMonitor(cfg.HostMonitor, db, bot, healthRegistry)
+	if err != nil {
+		return &exitError{exitCodeMonitor, fmt.Errorf("failed to start host monitor: %w", err)}
+	}
+
+	if hostMonitor != nil {
+		defer hostMonitor.Close()
+	}
+
+	// host probes or heartbeats decide on the main location power, bot downtime is not an outage then
+	powerMonitor, err := powermonitor.New(powermonitor.Config{
+		AliveInterval:   cfg.AliveInterval.Duration,
+		OutageThreshold: cfg.OutageThreshold.Duration,
+		Passive:         mainLocationProbed || mainLocationHeartbeats(cfg.HTTP, db),
+		Reporter:        healthRegistry,
+	}, db, bot)
+	if err != nil {
+		return &exitError{exitCodeMonitor, fmt.Errorf("failed to start power monitor: %w", err)}
+	}
+	defer powerMonitor.Close()
+
+	listeners := uplinkListeners{bot}
+
+	if cfg.DDNS.Provider != "" {
+		updater, err := ddns.New(ddns.Config{
+			Provider: cfg.DDNS.Provider, URL: cfg.DDNS.URL, Token: cfg.DDNS.Token, Domain: cfg.DDNS.Domain,
+			ZoneID: cfg.DDNS.ZoneID, RecordID: cfg.DDNS.RecordID, Primary: cfg.DDNS.Primary, Failover: cfg.DDNS.Failover,
+			RetryInterval: cfg.DDNS.RetryInterval.Duration, Reporter: healthRegistry,
+		})
+		if err != nil {
+			log.Errorf("Failed to start DNS failover: %s", err)
+		} else {
+			defer updater.Close()
+
+			listeners = append(listeners, updater)
+		}
+	}
+
+	uplinkMonitor, uplinkErr := uplink.New(uplink.Config{
+		BackupInterfaces: cfg.Uplink.BackupInterfaces,
+		CheckInterval:    cfg.Uplink.CheckInterval.Duration,
+		Reporter:         healthRegistry,
+	}, listeners)
+	if uplinkErr != nil {
+		log.Warnf("Uplink detection is disabled: %s", uplinkErr)
+	} else {
+		defer uplinkMonitor.Close()
+	}
+
+	if cfg.Archive.MaxAge.Duration > 0 {
+		archiveDir := cfg.Archive.Dir
+		if archiveDir == "" {
+			archiveDir = filepath.Join(cfg.WorkingDir, defaultArchiveDir)
+		}
+
+		archiver, err := archive.New(archive.Config{Dir: archiveDir, MaxAge: cfg.Archive.MaxAge.Duration}, db)
+		if err != nil {
+			log.Warnf("Outage archival is disabled: %s", err)
+		} else {
+			defer archiver.Close()
+		}
+	}
+
+	if cfg.Retention.EventMaxAge.Duration > 0 {
+		// outage events are moved out by the archiver, the heartbeat event is updated in place
+		pruner, err := retention.New(retention.Config{
+			MaxAge: cfg.Retention.EventMaxAge.Duration, VacuumInterval: cfg.Retention.VacuumInterval.Duration,
+			Keep: []database.EventType{database.EventHeartbeat, database.EventPowerOff, database.EventPowerOn},
+		}, db)
+		if err != nil {
+			log.Warnf("Event retention is disabled: %s", err)
+		} else {
+			defer pruner.Close()
+		}
+	}
+
+	if cfg.Backup.Interval.Duration > 0 {
+		backupDir := cfg.Backup.Dir
+		if backupDir == "" {
+			backupDir = filepath.Join(cfg.WorkingDir, defaultBackupDir)
+		}
+
+		backuper, err := backup.New(backup.Config{
+			Dir: backupDir, Interval: cfg.Backup.Interval.Duration, Keep: cfg.Backup.Keep,
+		}, db)
+		if err != nil {
+			log.Warnf("Scheduled backups are disabled: %s", err)
+		} else {
+			defer backuper.Close()
+		}
+	}
+
+	checks := []selftest.Check{
+		{Name: "database writable", Essential: true, Run: db.CheckWritable},
+		{Name: "telegram getMe", Essential: true, Run: bot.CheckTelegram},
+		{Name: "power monitor", Run: powerMonitor.CheckHeartbeat},
+		{Name: "uplink monitor", Run: func() error { return uplinkErr }},
+	}
+
+	if hostMonitor != nil {
+		checks = append(checks, selftest.Check{Name: "host monitor", Run: hostMonitor.CheckHosts})
+	}
+
+	var receiver *heartbeat.Receiver
+
+	if cfg.HTTP.Features[heartbeat.FeatureName].Enabled {
+		var receiverErr error
+
+		receiver, receiverErr = heartbeat.New(heartbeat.Config{
+			Threshold: cfg.Heartbeat.Threshold.Duration, CheckInterval: cfg.Heartbeat.CheckInterval.Duration,
+			ClockSkew: cfg.Heartbeat.ClockSkew.Duration, LowBattery: cfg.Heartbeat.LowBattery, Reporter: healthRegistry,
+			MinVoltage: cfg.Heartbeat.MinVoltage, MaxVoltage: cfg.Heartbeat.MaxVoltage,
+			VoltageHysteresis: cfg.Heartbeat.VoltageHysteresis, Services: cfg.Heartbeat.Services,
+		}, db, bot)
+		if receiverErr != nil {
+			log.Errorf("Failed to start heartbeat receiver: %s", receiverErr)
+		} else {
+			defer receiver.Close()
+
+			bot.RegisterTunable("heartbeat.threshold", telegrambot.Tunable{
+				Min: time.Second, Max: maxHeartbeatThreshold, Get: receiver.Threshold, Set: receiver.SetThreshold,
+			})
+
+			// services sharing the host get the heartbeat threshold to come back after outages
+			if len(cfg.Heartbeat.Services) != 0 {
+				bot.SetServiceMonitor(receiver, receiver.Threshold())
+			}
+		}
+
+		checks = append(checks, selftest.Check{Name: "heartbeat receiver", Run: func() error { return receiverErr }})
+	}
+
+	if cfg.ScheduleImport.Group != "" {
+		scheduleImporter, scheduleErr := scheduler.New(scheduleConfig, db)
+		if scheduleErr != nil {
+			log.Warnf("Planned schedule import is disabled: %s", scheduleErr)
+		} else {
+			defer scheduleImporter.Close()
+		}
+
+		checks = append(checks, selftest.Check{Name: "schedule source", Run: func() error {
+			if scheduleErr != nil {
+				return scheduleErr
+			}
+
+			return scheduleImporter.CheckSource()
+		}})
+	}
+
+	report := selftest.Run(checks)
+
+	log.Infof("Self-test summary:\n%s", report.Summary())
+	bot.SelfTestCompleted(report.Summary())
+
+	for _, result := range report {
+		healthRegistry.SetSubsystemState(result.Name, result.Err)
+	}
+
+	if err = report.Failed(cfg.SelfTestFailFast); err != nil {
+		return &exitError{exitCodeSelfTest, err}
+	}
+
+	httpServer := newHTTPServer(cfg.HTTP, cfg.WorkingDir, db, db)
+
+	if err = httpServer.Register(probes.FeatureName, probes.Routes(
+		[]probes.Check{
+			{Name: "update loop", Run: bot.CheckUpdateLoop},
+		},
+		[]probes.Check{
+			{Name: "database", Run: db.CheckWritable},
+			{Name: "telegram", Run: bot.CheckTelegramReachable},
+			{Name: "heartbeat", Run: powerMonitor.CheckHeartbeat, Details: func() string {
+				if lastWrite := powerMonitor.LastHeartbeatWrite(); !lastWrite.IsZero() {
+					return fmt.Sprintf("last write %s ago", time.Since(lastWrite).Round(time.Second))
+				}
+
+				return "never written"
+			}},
+		})); err != nil {
+		log.Errorf("Failed to register probes: %s", err)
+	}
+
+	if httpServer.Enabled(opendata.FeatureName) {
+		publisher, err := opendata.New(opendata.Config{
+			Region: cfg.OpenData.Region, Timezone: cfg.DefaultTimezone, Days: cfg.OpenData.Days,
+			Interval: cfg.OpenData.Interval.Duration,
+		}, db)
+		if err != nil {
+			log.Errorf("Failed to start open data publisher: %s", err)
+		} else {
+			defer publisher.Close()
+
+			if err = httpServer.Register(opendata.FeatureName, publisher.Routes()); err != nil {
+				log.Errorf("Failed to register open data: %s", err)
+			}
+		}
+	}
+
+	if httpServer.Enabled(apiserver.FeatureName) {
+		// the API exposes data of all users and must never be public
+		if !cfg.HTTP.Features[apiserver.FeatureName].Auth {
+			log.Errorf("HTTP feature %s requires auth, it is not registered", apiserver.FeatureName)
+		} else if err = httpServer.Register(apiserver.FeatureName, apiserver.New(db, bot).Routes()); err != nil {
+			log.Errorf("Failed to register API: %s", err)
+		}
+	}
+
+	if httpServer.Enabled(metrics.FeatureName) {
+		if err = httpServer.Register(metrics.FeatureName, metrics.New(db, bot).Routes()); err != nil {
+			log.Errorf("Failed to register metrics: %s", err)
+		}
+	}
+
+	if receiver != nil {
+		if err = httpServer.Register(heartbeat.FeatureName, receiver.Routes()); err != nil {
+			log.Errorf("Failed to register heartbeat receiver: %s", err)
+		}
+	}
+
+	if httpServer.Enabled(dashboard.FeatureName) {
+		webDashboard, err := dashboard.New(dashboard.Config{
+			Secret: cfg.Dashboard.Secret, Timezone: cfg.DefaultTimezone, Weeks: cfg.Dashboard.Weeks,
+		}, db, bot)
+		if err != nil {
+			log.Errorf("Failed to create dashboard: %s", err)
+		} else if err = httpServer.Register(dashboard.FeatureName, webDashboard.Routes()); err != nil {
+			log.Errorf("Failed to register dashboard: %s", err)
+		}
+	}
+
+	err = httpServer.Start()
+	if err != nil {
+		log.Errorf("Failed to start HTTP server: %s", err)
+	} else {
+		defer httpServer.Close()
+	}
+
+	healthRegistry.SetSubsystemState("http server", err)
+
 	// Notify systemd
 	if _, err = daemon.SdNotify(false, daemon.SdNotifyReady); err != nil {
 		log.Errorf("Can't notify systemd: %s", err)
@@ -65,5 +585,469 @@ func main() {
 	<-c
 
 	log.Info("Shutting down...")
-	bot.Close()
+
+	return nil
+}
+
+// newHostMonitor starts host probing if hosts are configured, mainLocation is set when it monitors the main location.
+func newHostMonitor(cfg config.HostMonitorConfig, db *database.Database, bot *telegrambot.ElectroBot,
+	reporter hostmonitor.StateReporter,
+) (monitor *hostmonitor.Monitor, mainLocation bool, err error) {
+	if len(cfg.Hosts) == 0 {
+		return nil, false, nil
+	}
+
+	main, err := db.GetMainLocation()
+	if err != nil {
+		return nil, false, err
+	}
+
+	location := cfg.Location
+	if location == "" {
+		location = main.Name
+	}
+
+	mainLocation = strings.EqualFold(location, main.Name)
+
+	monitor, err = hostmonitor.New(hostmonitor.Config{
+		Location: location, Hosts: cfg.Hosts, Quorum: cfg.Quorum, Interval: cfg.Interval.Duration,
+		Timeout: cfg.Timeout.Duration, Failures: cfg.Failures, RecordOutages: mainLocation, Reporter: reporter,
+	}, db, bot)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return monitor, mainLocation, nil
+}
+
+// mainLocationHeartbeats returns true if the heartbeat receiver is enabled and the main location has a heartbeat
+// token, tokens set later take effect after restart.
+func mainLocationHeartbeats(cfg config.HTTPConfig, db *database.Database) bool {
+	if !cfg.Features[heartbeat.FeatureName].Enabled {
+		return false
+	}
+
+	locations, err := db.GetHeartbeatLocations()
+	if err != nil {
+		log.Errorf("Failed to get heartbeat locations: %s", err)
+
+		return false
+	}
+
+	for _, location := range locations {
+		if location.ID == database.MainLocationID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newHTTPServer(cfg config.HTTPConfig, workingDir string, tokens httpserver.TokenStore,
+	webhookSecrets httpserver.WebhookSecretStore,
+) *httpserver.Server {
+	features := make(map[string]httpserver.FeatureConfig)
+
+	for name, feature := range cfg.Features {
+		features[name] = httpserver.FeatureConfig{
+			Enabled: feature.Enabled, Listen: feature.Listen, Auth: feature.Auth, AuthToken: feature.AuthToken,
+			ClientCert: feature.ClientCert, AllowIPs: feature.AllowIPs, WebhookSecret: feature.WebhookSecret,
+			RateLimit: feature.RateLimit, CacheTTL: feature.CacheTTL.Duration,
+		}
+	}
+
+	autocertCacheDir := cfg.TLS.Autocert.CacheDir
+	if autocertCacheDir == "" {
+		autocertCacheDir = filepath.Join(workingDir, autocertDir)
+	}
+
+	return httpserver.New(httpserver.Config{
+		Listen: cfg.Listen,
+		TLS: httpserver.TLSConfig{
+			CertFile: cfg.TLS.CertFile, KeyFile: cfg.TLS.KeyFile, ClientCAFile: cfg.TLS.ClientCAFile,
+			Autocert: httpserver.AutocertConfig{
+				Domains: cfg.TLS.Autocert.Domains, Email: cfg.TLS.Autocert.Email, CacheDir: autocertCacheDir,
+				ChallengeListen: cfg.TLS.Autocert.ChallengeListen,
+			},
+		},
+		AuthToken:      cfg.AuthToken,
+		Tokens:         tokens,
+		WebhookSecrets: webhookSecrets,
+		Firewall: httpserver.FirewallConfig{
+			AllowIPs: cfg.Firewall.AllowIPs, DenyIPs: cfg.Firewall.DenyIPs, MaxBodySize: cfg.Firewall.MaxBodySize,
+			MaxURLLength: cfg.Firewall.MaxURLLength, RateLimit: cfg.Firewall.RateLimit,
+		},
+		Features: features,
+	})
+}
+
+func exitCode(err error) int {
+	var exitErr *exitError
+
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+
+	return exitCodeFailure
+}
+
+// reportFatalError makes a last-gasp attempt to deliver the fatal error to the owner chat. The claimed owner is
+// preferred, ownerChatID from the config is used if the bot is not claimed or the database is not available.
+func reportFatalError(cfg *config.Config, ownerChatID int64, fatalErr error) {
+	if ownerChatID == 0 {
+		ownerChatID = cfg.OwnerChatID
+	}
+
+	if cfg.Telegram.Token == "" || ownerChatID == 0 {
+		return
+	}
+
+	hostname, _ := os.Hostname()
+
+	if err := telegrambot.NotifyChat(cfg.Telegram.Token, ownerChatID,
+		fmt.Sprintf("Electrobot on %s failed to start: %s", hostname, fatalErr)); err != nil {
+		log.Errorf("Failed to report fatal error to owner: %s", err)
+	}
+}
+
+// runLoadTest runs the notification pipeline load test with the fake Telegram server and prints the report.
+func runLoadTest(args []string) int {
+	flags := flag.NewFlagSet(loadTestCommand, flag.ExitOnError)
+
+	users := flags.Int("users", 1000, "number of simulated subscribers")                    //nolint:gomnd
+	events := flags.Int("events", 2, "number of power state changes in the burst")          //nolint:gomnd
+	latency := flags.Duration("latency", 50*time.Millisecond, "fake Bot API response time") //nolint:gomnd
+	failEvery := flags.Int("fail-every", 0, "fail every n-th sendMessage with flood control, 0 disables failures")
+
+	_ = flags.Parse(args)
+
+	// retried flood control failures are expected, only errors are worth reporting
+	log.SetLevel(log.ErrorLevel)
+
+	result, err := loadtest.Run(loadtest.Config{
+		Users: *users, Events: *events, Latency: *latency, FailEvery: *failEvery,
+	})
+
+	fmt.Println(result)
+
+	if err != nil {
+		log.Errorf("Load test failed: %s", err)
+
+		return exitCodeLoadTest
+	}
+
+	return exitCodeOK
+}
+
+// runConfigCommand writes the example config with "init" or prints the effective config with "show".
+func runConfigCommand(args []string, configFile string) int {
+	flags := flag.NewFlagSet(configCommand, flag.ExitOnError)
+
+	file := flags.String("c", configFile, "path to config file")
+	force := flags.Bool("force", false, "overwrite existing config file on init")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s config init|show [-c file] [-force]\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	if len(args) == 0 {
+		flags.Usage()
+
+		return exitCodeConfig
+	}
+
+	_ = flags.Parse(args[1:])
+
+	// log lines would mix with the printed config
+	log.SetLevel(log.WarnLevel)
+
+	switch args[0] {
+	case "init":
+		if _, err := os.Stat(*file); err == nil && !*force {
+			log.Errorf("Config file %s already exists, use -force to overwrite it", *file)
+
+			return exitCodeConfig
+		}
+
+		if err := os.MkdirAll(filepath.Dir(*file), 0o755); err != nil {
+			log.Errorf("Failed to create config directory: %s", err)
+
+			return exitCodeConfig
+		}
+
+		// the config will hold the bot token
+		if err := os.WriteFile(*file, config.Example(), 0o600); err != nil {
+			log.Errorf("Failed to write config: %s", err)
+
+			return exitCodeConfig
+		}
+
+		fmt.Printf("Example config written to %s, set telegram.token before starting the bot\n", *file)
+
+	case "show":
+		// the token of an installed service is usually readable by the service only
+		cfg, err := config.Inspect(*file, *file == defaultConfigFile)
+		if err != nil {
+			printConfigError(err)
+
+			return exitCodeConfig
+		}
+
+		redacted := cfg.Redacted()
+
+		if redacted.Telegram.Token == "" {
+			redacted.Telegram.Token = tokenFromCredential
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "\t")
+
+		if err = encoder.Encode(redacted); err != nil {
+			log.Errorf("Failed to print config: %s", err)
+
+			return exitCodeConfig
+		}
+
+	default:
+		flags.Usage()
+
+		return exitCodeConfig
+	}
+
+	return exitCodeOK
+}
+
+// runInstall deploys the bot as a systemd service, the bot token is asked for if it isn't given or stored yet.
+func runInstall(args []string, configFile string) int {
+	flags := flag.NewFlagSet(installCommand, flag.ExitOnError)
+
+	file := flags.String("c", configFile, "path to config file, the example config is written if it doesn't exist")
+	name := flags.String("name", "electrobot", "systemd service name")
+	binary := flags.String("bin", "/usr/local/bin/electrobot", "path to install the binary to")
+	dataDir := flags.String("data-dir", "/var/electrobot", "bot working directory")
+	serviceUser := flags.String("user", "electrobot", "user running the service, created if missing")
+	token := flags.String("token", "", "Telegram bot token stored as a systemd credential")
+	watchdog := flags.Duration("watchdog", time.Minute, "systemd watchdog timeout")
+	noStart := flags.Bool("no-start", false, "enable the service without starting it")
+	dryRun := flags.Bool("dry-run", false, "print the unit without installing anything")
+
+	_ = flags.Parse(args)
+
+	log.SetLevel(log.WarnLevel)
+
+	source, err := os.Executable()
+	if err != nil {
+		log.Errorf("Failed to find the running binary: %s", err)
+
+		return exitCodeInstall
+	}
+
+	installConfig := installer.Config{
+		Name: *name, Source: source, Binary: *binary, ConfigFile: *file, ExampleConfig: config.Example(),
+		DataDir: *dataDir, User: *serviceUser, Token: *token, TokenCredential: config.TelegramTokenCredential,
+		Watchdog: *watchdog, ConfigExitCode: exitCodeConfig, Start: !*noStart, Output: os.Stdout,
+	}
+
+	if *dryRun {
+		fmt.Print(installer.Unit(installConfig, true))
+
+		return exitCodeOK
+	}
+
+	if installConfig.Token == "" {
+		if _, err = os.Stat(installConfig.CredentialFile()); err != nil {
+			installConfig.Token = promptToken()
+		}
+	}
+
+	if err = installer.Install(installConfig); err != nil {
+		log.Errorf("Installation failed: %s", err)
+
+		return exitCodeInstall
+	}
+
+	return exitCodeOK
+}
+
+// runImport adds historical outages from a CSV file to the database.
+func runImport(args []string, configFile string) int {
+	flags := flag.NewFlagSet(importCommand, flag.ExitOnError)
+
+	file := flags.String("c", configFile, "path to config file")
+	timezone := flags.String("timezone", "", "IANA timezone of times without offset, defaultTimezone or UTC if empty")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s import [-c file] [-timezone zone] outages.csv\n",
+			filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+
+		return exitCodeImport
+	}
+
+	log.SetLevel(log.WarnLevel)
+
+	cfg, err := config.Inspect(*file, *file == defaultConfigFile)
+	if err != nil {
+		printConfigError(err)
+
+		return exitCodeConfig
+	}
+
+	if *timezone == "" {
+		*timezone = cfg.DefaultTimezone
+	}
+
+	location, err := time.LoadLocation(*timezone)
+	if err != nil {
+		log.Errorf("Invalid timezone %q: %s", *timezone, err)
+
+		return exitCodeImport
+	}
+
+	csvFile, err := os.Open(flags.Arg(0))
+	if err != nil {
+		log.Errorf("Failed to open outages: %s", err)
+
+		return exitCodeImport
+	}
+	defer csvFile.Close()
+
+	outages, err := archive.ReadCSV(csvFile, location)
+	if err != nil {
+		log.Errorf("Failed to read outages: %s", err)
+
+		return exitCodeImport
+	}
+
+	db, err := database.New(database.Config{WorkingDir: cfg.WorkingDir})
+	if err != nil {
+		log.Errorf("Failed to open database: %s", err)
+
+		return exitCodeDatabase
+	}
+	defer db.Close()
+
+	imported, err := db.ImportOutages(outages)
+	if err != nil {
+		log.Errorf("Failed to import outages: %s", err)
+
+		return exitCodeImport
+	}
+
+	fmt.Printf("Imported %d of %d outages, overlapping and invalid ones are skipped\n", imported, len(outages))
+
+	return exitCodeOK
+}
+
+// runSelfUpdate installs the release and restarts the service, with -staged it installs the release staged by
+// /update before the service starts.
+func runSelfUpdate(args []string, configFile string) int {
+	flags := flag.NewFlagSet(updateCommand, flag.ExitOnError)
+
+	file := flags.String("c", configFile, "path to config file")
+	staged := flags.Bool("staged", false, "install the release staged by /update, used by the service unit")
+	name := flags.String("name", "electrobot", "systemd service restarted after the update, empty skips the restart")
+
+	_ = flags.Parse(args)
+
+	log.SetLevel(log.InfoLevel)
+
+	cfg, err := config.Inspect(*file, *file == defaultConfigFile)
+	if err != nil {
+		printConfigError(err)
+
+		// a failing ExecStartPre would prevent the start, the bot reports config errors itself
+		if *staged {
+			return exitCodeOK
+		}
+
+		return exitCodeConfig
+	}
+
+	binary, err := os.Executable()
+	if err == nil {
+		binary, err = filepath.EvalSymlinks(binary)
+	}
+
+	if err != nil {
+		log.Errorf("Failed to find the running binary: %s", err)
+
+		return exitCodeUpdate
+	}
+
+	updateConfig := selfupdate.Config{URL: cfg.Update.URL, PublicKey: cfg.Update.PublicKey, Dir: cfg.WorkingDir}
+
+	if *staged {
+		installed, err := selfupdate.InstallStaged(updateConfig, binary)
+
+		switch {
+		case err != nil:
+			// the current binary is kept and started
+			log.Errorf("Failed to install staged release: %s", err)
+		case installed:
+			log.WithField("binary", binary).Info("Staged release installed")
+		}
+
+		return exitCodeOK
+	}
+
+	if cfg.Update.URL == "" {
+		log.Error("Self-update is not configured, set update.url and update.publicKey")
+
+		return exitCodeUpdate
+	}
+
+	if err = selfupdate.Install(context.Background(), updateConfig, binary); err != nil {
+		log.Errorf("Failed to update: %s", err)
+
+		return exitCodeUpdate
+	}
+
+	fmt.Printf("Release installed to %s\n", binary)
+
+	if *name == "" {
+		return exitCodeOK
+	}
+
+	if output, err := exec.Command("systemctl", "restart", *name).CombinedOutput(); err != nil {
+		log.Errorf("Failed to restart %s: %s: %s", *name, err, bytes.TrimSpace(output))
+
+		return exitCodeUpdate
+	}
+
+	fmt.Printf("Service %s restarted\n", *name)
+
+	return exitCodeOK
+}
+
+// promptToken asks for the bot token on the terminal, empty token is returned for non-interactive input.
+func promptToken() string {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return ""
+	}
+
+	fmt.Print("Telegram bot token from @BotFather (empty to set it in the config later): ")
+
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+	return strings.TrimSpace(line)
+}
+
+// printConfigError prints validation errors one per line to be readable, other errors are logged.
+func printConfigError(err error) {
+	var validationErr *config.ValidationError
+
+	if errors.As(err, &validationErr) {
+		fmt.Fprintln(os.Stderr, validationErr)
+	} else {
+		log.Errorf("Failed to load config: %s", err)
+	}
 }
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// SubsystemState describes the state of a single subsystem.
+type SubsystemState struct {
+	Name      string
+	Err       error
+	ChangedAt time.Time
+}
+
+// Registry keeps track of subsystem states.
+type Registry struct {
+	sync.Mutex
+
+	states map[string]SubsystemState
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates subsystem state registry.
+func New() *Registry {
+	return &Registry{states: make(map[string]SubsystemState)}
+}
+
+// SetSubsystemState marks subsystem as healthy (nil error) or degraded.
+func (registry *Registry) SetSubsystemState(name string, err error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	prev, exists := registry.states[name]
+	if exists && errorText(prev.Err) == errorText(err) {
+		return
+	}
+
+	registry.states[name] = SubsystemState{Name: name, Err: err, ChangedAt: time.Now()}
+
+	if err != nil {
+		log.WithField("subsystem", name).Warnf("Subsystem degraded: %s", err)
+	} else if exists {
+		log.WithField("subsystem", name).Info("Subsystem recovered")
+	}
+}
+
+// States returns states of all subsystems sorted by name.
+func (registry *Registry) States() []SubsystemState {
+	registry.Lock()
+	defer registry.Unlock()
+
+	states := make([]SubsystemState, 0, len(registry.states))
+
+	for _, state := range registry.states {
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+
+	return states
+}
+
+// Degraded returns true if any subsystem is not healthy.
+func (registry *Registry) Degraded() bool {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, state := range registry.states {
+		if state.Err != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,749 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package heartbeat receives heartbeats from devices on monitored premises over HTTP, so the bot may run elsewhere,
+// e.g. in the cloud. A location is without power when its device stops sending heartbeats for longer than
+// the threshold, power is back with the next heartbeat. Locations may be parts of a building, e.g. entrances, and
+// sensors may monitor single phases of a location. The parent location is off while all its parts and phases are,
+// outages of parts are reported to their subscribers only and losing a phase is reported separately.
+//
+// Devices may report their battery charge, signal strength and mains voltage in the heartbeat body, e.g.
+// {"battery": 87, "signal": -71, "voltage": 229.5}, admins are alerted when the battery runs low and subscribers
+// when the voltage is out of bounds. Local services sharing the bot host report their liveness to
+// /api/v1/heartbeat/service/<name> without changing the power state.
+package heartbeat
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"electrobot/apitoken"
+	"electrobot/database"
+	"electrobot/httpserver"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// FeatureName is the HTTP server feature name of the heartbeat receiver.
+const FeatureName = "heartbeat"
+
+const (
+	defaultThreshold     = 3 * time.Minute
+	defaultCheckInterval = 30 * time.Second
+	defaultClockSkew     = 30 * time.Second
+	defaultLowBattery    = 20
+	// default voltage bounds are 230 V ±10% of EN 50160.
+	defaultMinVoltage        = 207
+	defaultMaxVoltage        = 253
+	defaultVoltageHysteresis = 5
+	// maxTelemetrySize limits the heartbeat body, telemetry is a couple of numbers.
+	maxTelemetrySize = 1024
+	maxBattery       = 100
+	minSignal        = -150
+	maxVoltage       = 500
+	// unknownVoltage is used when the device doesn't report voltage, a device without power can't send heartbeats.
+	unknownVoltage = 0
+	// maxDelay matches the webhook timestamp window, older heartbeats are rejected as stale.
+	maxDelay      = 5 * time.Minute
+	routePrefix   = "/api/v1/heartbeat/"
+	servicePrefix = routePrefix + "service/"
+	subsystemName = "heartbeat receiver"
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+var (
+	errInvalidTimestamp = errors.New("invalid heartbeat timestamp")
+	errFutureTimestamp  = errors.New("heartbeat timestamp is in the future")
+	errStaleTimestamp   = errors.New("heartbeat is too old")
+	errReplayed         = errors.New("heartbeat is not newer than the previous one")
+	errInvalidTelemetry = errors.New("invalid heartbeat telemetry")
+	errShortThreshold   = errors.New("threshold must be longer than the check interval")
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with heartbeat receiver configuration.
+type Config struct {
+	// Threshold is the minimal heartbeat gap treated as a power outage.
+	Threshold time.Duration
+	// CheckInterval is the period of heartbeat gap checks.
+	CheckInterval time.Duration
+	// ClockSkew is the device clock difference tolerated by default, longer delays date heartbeats by the device
+	// timestamp.
+	ClockSkew time.Duration
+	// LowBattery is the battery charge in percent admins are alerted at.
+	LowBattery int
+	// MinVoltage and MaxVoltage are bounds of the normal mains voltage in volts.
+	MinVoltage int
+	MaxVoltage int
+	// VoltageHysteresis is how far within the bounds voltage has to return to be normal again, so readings around
+	// a bound don't repeat alerts.
+	VoltageHysteresis int
+	// Services lists local services sharing the bot host which report their liveness with heartbeats.
+	Services []string
+	// Reporter receives heartbeat receiver state, optional.
+	Reporter StateReporter
+}
+
+// Storage provides location heartbeat state persistence.
+type Storage interface {
+	GetHeartbeatLocation(hash string) (location database.HeartbeatLocation, err error)
+	GetHeartbeatLocations() (locations []database.HeartbeatLocation, err error)
+	RecordLocationHeartbeat(locationID int64, at, sentAt time.Time) error
+	RecordSensorTelemetry(locationID int64, battery, signal int) error
+	SetLocationOffSince(locationID int64, offSince time.Time) error
+	RecordPowerOff(start, end time.Time) error
+}
+
+// Listener is notified about power state transitions of locations.
+type Listener interface {
+	LocationPowerOff(name string, start time.Time)
+	LocationPowerOn(name string, start, end time.Time)
+	// PhasePowerOff is called when a phase of the location loses power while other phases still have it.
+	PhasePowerOff(name, phase string, start time.Time)
+	// PhasePowerOn is called when a phase of the location is back while the location has power.
+	PhasePowerOn(name, phase string, start, end time.Time)
+	SensorBatteryLow(name string, battery int)
+	// VoltageAbnormal is called when the location voltage leaves the bounds, high is true if it is above them.
+	VoltageAbnormal(name string, voltage float64, high bool)
+	// VoltageNormal is called when the location voltage is back within the bounds.
+	VoltageNormal(name string, voltage float64)
+}
+
+// StateReporter receives subsystem state changes.
+type StateReporter interface {
+	SetSubsystemState(name string, err error)
+}
+
+// telemetry is the optional heartbeat body.
+type telemetry struct {
+	// Battery is the charge in percent.
+	Battery *int `json:"battery"`
+	// Signal is the signal strength in dBm.
+	Signal *int `json:"signal"`
+	// Voltage is the mains voltage in volts.
+	Voltage *float64 `json:"voltage"`
+}
+
+// voltageState is the voltage of the location relative to the bounds.
+type voltageState int
+
+const (
+	voltageNormal voltageState = iota
+	voltageLow
+	voltageHigh
+)
+
+// Receiver accepts location heartbeats and detects gaps between them.
+type Receiver struct {
+	config   Config
+	storage  Storage
+	listener Listener
+	// started delays gap detection after the start, heartbeats sent while the bot was down are lost.
+	started time.Time
+	// threshold is changed at runtime, config.Threshold is the configured value.
+	threshold  atomic.Int64
+	wake       chan struct{}
+	cancelFunc context.CancelFunc
+
+	voltageMutex sync.Mutex
+	// voltageStates are kept in memory by location ID, a restart may repeat an alert.
+	voltageStates map[int64]voltageState
+
+	serviceMutex sync.Mutex
+	// services are last heartbeats of local services by name, zero if none since the start.
+	services map[string]time.Time
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates and starts heartbeat receiver.
+func New(config Config, storage Storage, listener Listener) (receiver *Receiver, err error) {
+	if config.Threshold <= 0 {
+		config.Threshold = defaultThreshold
+	}
+
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaultCheckInterval
+	}
+
+	if config.ClockSkew <= 0 {
+		config.ClockSkew = defaultClockSkew
+	}
+
+	if config.LowBattery <= 0 {
+		config.LowBattery = defaultLowBattery
+	}
+
+	if config.MinVoltage <= 0 {
+		config.MinVoltage = defaultMinVoltage
+	}
+
+	if config.MaxVoltage <= 0 {
+		config.MaxVoltage = defaultMaxVoltage
+	}
+
+	if config.VoltageHysteresis <= 0 {
+		config.VoltageHysteresis = defaultVoltageHysteresis
+	}
+
+	receiver = &Receiver{
+		config: config, storage: storage, listener: listener, started: time.Now().Round(0),
+		wake: make(chan struct{}, 1), voltageStates: make(map[int64]voltageState),
+		services: make(map[string]time.Time),
+	}
+
+	for _, name := range config.Services {
+		receiver.services[name] = time.Time{}
+	}
+
+	receiver.threshold.Store(int64(config.Threshold))
+
+	ctx, cancelFunction := context.WithCancel(context.Background())
+	receiver.cancelFunc = cancelFunction
+
+	go receiver.run(ctx)
+
+	return receiver, nil
+}
+
+// Close stops heartbeat receiver.
+func (receiver *Receiver) Close() {
+	receiver.cancelFunc()
+}
+
+// Threshold returns the heartbeat gap treated as a power outage.
+func (receiver *Receiver) Threshold() time.Duration {
+	return time.Duration(receiver.threshold.Load())
+}
+
+// SetThreshold changes the heartbeat gap treated as a power outage, the next gap check uses it.
+func (receiver *Receiver) SetThreshold(threshold time.Duration) error {
+	if threshold <= receiver.config.CheckInterval {
+		return errShortThreshold
+	}
+
+	receiver.threshold.Store(int64(threshold))
+
+	return nil
+}
+
+// Routes returns the heartbeat route, the location token is the last path element. Heartbeats are webhooks, so
+// a leaked location token alone is not enough to fake power events.
+func (receiver *Receiver) Routes() []httpserver.Route {
+	return []httpserver.Route{
+		{Pattern: routePrefix, Handler: http.HandlerFunc(receiver.handleHeartbeat), Webhook: true},
+		{Pattern: servicePrefix, Handler: http.HandlerFunc(receiver.handleServiceHeartbeat), Webhook: true},
+	}
+}
+
+// Services returns sorted names of configured local services with heartbeats since the time and without them.
+func (receiver *Receiver) Services(since time.Time) (alive, missing []string) {
+	receiver.serviceMutex.Lock()
+	defer receiver.serviceMutex.Unlock()
+
+	for name, lastHeartbeat := range receiver.services {
+		if lastHeartbeat.Before(since) {
+			missing = append(missing, name)
+		} else {
+			alive = append(alive, name)
+		}
+	}
+
+	sort.Strings(alive)
+	sort.Strings(missing)
+
+	return alive, missing
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleHeartbeat only stores the heartbeat, notifications are sent by the check loop so the device doesn't wait
+// for them.
+func (receiver *Receiver) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	token := strings.TrimPrefix(r.URL.Path, routePrefix)
+
+	location, err := receiver.storage.GetHeartbeatLocation(apitoken.Hash(token))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("Failed to get heartbeat location: %s", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		log.WithField("remoteAddr", r.RemoteAddr).Warn("Heartbeat with unknown token rejected")
+		http.NotFound(w, r)
+
+		return
+	}
+
+	// paused devices keep sending, e.g. during maintenance on the premises
+	if location.Paused {
+		log.WithField("location", location.Name).Debug("Heartbeat of paused sensor ignored")
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	battery, signal, voltage, err := readTelemetry(r.Body)
+	if err != nil {
+		rejectHeartbeat(w, r, location, err)
+
+		return
+	}
+
+	at, sentAt, err := receiver.heartbeatTime(location, r.Header.Get(httpserver.TimestampHeader), time.Now().Round(0))
+	if err != nil {
+		rejectHeartbeat(w, r, location, err)
+
+		return
+	}
+
+	if err = receiver.storage.RecordLocationHeartbeat(location.ID, at, sentAt); err != nil {
+		log.Errorf("Failed to store location heartbeat: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.WithField("location", location.Name).Debug("Heartbeat received")
+
+	if battery != database.UnknownBattery || signal != database.UnknownSignal {
+		receiver.recordTelemetry(location, battery, signal)
+	}
+
+	if voltage != unknownVoltage {
+		receiver.checkVoltage(location, voltage)
+	}
+
+	if !location.OffSince.IsZero() {
+		select {
+		case receiver.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleServiceHeartbeat records liveness of a local service, services don't change power state.
+func (receiver *Receiver) handleServiceHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, servicePrefix)
+
+	receiver.serviceMutex.Lock()
+	defer receiver.serviceMutex.Unlock()
+
+	if _, ok := receiver.services[name]; !ok {
+		log.WithFields(log.Fields{"service": name, "remoteAddr": r.RemoteAddr}).Warn(
+			"Heartbeat of unknown service rejected")
+		http.NotFound(w, r)
+
+		return
+	}
+
+	receiver.services[name] = time.Now().Round(0)
+
+	log.WithField("service", name).Debug("Service heartbeat received")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// heartbeatTime returns the time the heartbeat proves power at and its device timestamp. Device timestamps must
+// grow, so a heartbeat is accepted once even after the webhook replay cache forgets it. Heartbeats delayed longer
+// than the clock skew, e.g. buffered by a modem, are dated by the device timestamp, so they can't end an outage which
+// started after they were sent.
+func (receiver *Receiver) heartbeatTime(
+	location database.HeartbeatLocation, header string, now time.Time,
+) (at, sentAt time.Time, err error) {
+	timestamp, err := strconv.ParseInt(header, 10, 64)
+	if err != nil {
+		return at, sentAt, errInvalidTimestamp
+	}
+
+	sentAt = time.Unix(timestamp, 0)
+
+	skew := location.ClockSkew
+	if skew <= 0 {
+		skew = receiver.config.ClockSkew
+	}
+
+	switch {
+	case !location.LastSent.IsZero() && !sentAt.After(location.LastSent):
+		return at, sentAt, errReplayed
+
+	case sentAt.Sub(now) > skew:
+		return at, sentAt, errFutureTimestamp
+
+	case now.Sub(sentAt) > maxDelay:
+		return at, sentAt, errStaleTimestamp
+
+	case now.Sub(sentAt) > skew:
+		return sentAt, sentAt, nil
+	}
+
+	return now, sentAt, nil
+}
+
+// recordTelemetry stores the device telemetry and alerts admins once the battery drops to the low level, the alert
+// is repeated only after the battery is charged above it.
+func (receiver *Receiver) recordTelemetry(location database.HeartbeatLocation, battery, signal int) {
+	if err := receiver.storage.RecordSensorTelemetry(location.ID, battery, signal); err != nil {
+		log.Errorf("Failed to store sensor telemetry: %s", err)
+
+		return
+	}
+
+	if battery == database.UnknownBattery || battery > receiver.config.LowBattery ||
+		(location.Battery != database.UnknownBattery && location.Battery <= receiver.config.LowBattery) {
+		return
+	}
+
+	log.WithFields(log.Fields{"location": location.Name, "battery": battery}).Warn("Sensor battery is low")
+
+	// the device doesn't wait for notifications
+	go receiver.listener.SensorBatteryLow(location.Name, battery)
+}
+
+// checkVoltage notifies when the voltage leaves the bounds and when it returns within them by the hysteresis, readings
+// in between keep the state.
+func (receiver *Receiver) checkVoltage(location database.HeartbeatLocation, voltage float64) {
+	receiver.voltageMutex.Lock()
+	defer receiver.voltageMutex.Unlock()
+
+	previous := receiver.voltageStates[location.ID]
+	state := previous
+	hysteresis := float64(receiver.config.VoltageHysteresis)
+
+	switch {
+	case voltage < float64(receiver.config.MinVoltage):
+		state = voltageLow
+
+	case voltage > float64(receiver.config.MaxVoltage):
+		state = voltageHigh
+
+	case voltage >= float64(receiver.config.MinVoltage)+hysteresis &&
+		voltage <= float64(receiver.config.MaxVoltage)-hysteresis:
+		state = voltageNormal
+	}
+
+	if state == previous {
+		return
+	}
+
+	receiver.voltageStates[location.ID] = state
+
+	log.WithFields(log.Fields{"location": location.Name, "voltage": voltage, "state": state}).Warn("Voltage changed")
+
+	// the device doesn't wait for notifications
+	if state == voltageNormal {
+		go receiver.listener.VoltageNormal(location.Name, voltage)
+	} else {
+		go receiver.listener.VoltageAbnormal(location.Name, voltage, state == voltageHigh)
+	}
+}
+
+func (receiver *Receiver) run(ctx context.Context) {
+	ticker := time.NewTicker(receiver.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			receiver.check()
+
+		case <-receiver.wake:
+			receiver.check()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (receiver *Receiver) check() {
+	locations, err := receiver.storage.GetHeartbeatLocations()
+
+	receiver.reportState(err)
+
+	if err != nil {
+		log.Errorf("Failed to get heartbeat locations: %s", err)
+
+		return
+	}
+
+	now := time.Now().Round(0)
+
+	for _, location := range locations {
+		switch {
+		// no device has reported yet or the sensor is paused
+		case location.LastHeartbeat.IsZero(), location.Paused:
+
+		case !location.OffSince.IsZero():
+			if location.LastHeartbeat.After(location.OffSince) {
+				receiver.powerOn(location, location.OffSince, location.LastHeartbeat)
+			}
+
+		case now.Sub(maxTime(location.LastHeartbeat, receiver.started)) > receiver.Threshold():
+			receiver.powerOff(location, location.LastHeartbeat)
+		}
+	}
+}
+
+func (receiver *Receiver) powerOff(location database.HeartbeatLocation, start time.Time) {
+	log.WithFields(log.Fields{
+		"location": location.Name, "start": start.UTC(),
+	}).Info("Power outage detected by missing heartbeats")
+
+	if err := receiver.storage.SetLocationOffSince(location.ID, start); err != nil {
+		log.Errorf("Failed to store location state: %s", err)
+
+		return
+	}
+
+	// phase sensors have no subscribers of their own
+	if location.Phase == "" {
+		receiver.listener.LocationPowerOff(location.Name, start)
+	}
+
+	if location.ParentID != 0 {
+		receiver.parentPowerOff(location, start)
+	}
+}
+
+// parentPowerOff reports the phase loss, the parent location is off since all its parts and phases are.
+func (receiver *Receiver) parentPowerOff(location database.HeartbeatLocation, start time.Time) {
+	children, err := receiver.children(location.ParentID)
+	if err != nil {
+		log.Errorf("Failed to get location parts: %s", err)
+
+		return
+	}
+
+	parentStart := start
+
+	for _, child := range children {
+		if child.OffSince.IsZero() {
+			if location.Phase != "" {
+				receiver.listener.PhasePowerOff(location.ParentName, location.Phase, start)
+			}
+
+			return
+		}
+
+		parentStart = maxTime(parentStart, child.OffSince)
+	}
+
+	log.WithFields(log.Fields{
+		"location": location.ParentName, "start": parentStart.UTC(),
+	}).Info("Power outage detected in all location parts")
+
+	if err = receiver.storage.SetLocationOffSince(location.ParentID, parentStart); err != nil {
+		log.Errorf("Failed to store location state: %s", err)
+
+		return
+	}
+
+	receiver.listener.LocationPowerOff(location.ParentName, parentStart)
+}
+
+func (receiver *Receiver) powerOn(location database.HeartbeatLocation, start, end time.Time) {
+	log.WithFields(log.Fields{
+		"location": location.Name, "start": start.UTC(), "end": end.UTC(), "duration": end.Sub(start).Round(time.Second),
+	}).Info("Power restored according to heartbeats")
+
+	if err := receiver.storage.SetLocationOffSince(location.ID, time.Time{}); err != nil {
+		log.Errorf("Failed to store location state: %s", err)
+
+		return
+	}
+
+	if location.Phase == "" {
+		receiver.locationPowerOn(location.ID, location.Name, start, end)
+	}
+
+	if location.ParentID != 0 {
+		receiver.parentPowerOn(location, start, end)
+	}
+}
+
+// parentPowerOn reports the phase return, the first part or phase back ends the parent outage.
+func (receiver *Receiver) parentPowerOn(location database.HeartbeatLocation, start, end time.Time) {
+	children, err := receiver.children(location.ParentID)
+	if err != nil {
+		log.Errorf("Failed to get location parts: %s", err)
+
+		return
+	}
+
+	// the parent state may have been changed by other parts since the location was read
+	for _, child := range children {
+		if child.ID == location.ID {
+			location = child
+		}
+	}
+
+	if location.ParentOffSince.IsZero() {
+		if location.Phase != "" {
+			receiver.listener.PhasePowerOn(location.ParentName, location.Phase, start, end)
+		}
+
+		return
+	}
+
+	if err = receiver.storage.SetLocationOffSince(location.ParentID, time.Time{}); err != nil {
+		log.Errorf("Failed to store location state: %s", err)
+
+		return
+	}
+
+	receiver.locationPowerOn(location.ParentID, location.ParentName, location.ParentOffSince, end)
+}
+
+func (receiver *Receiver) locationPowerOn(locationID int64, name string, start, end time.Time) {
+	// the event history belongs to the main location
+	if locationID == database.MainLocationID {
+		if err := receiver.storage.RecordPowerOff(start, end); err != nil {
+			log.Errorf("Failed to store outage events: %s", err)
+		}
+	}
+
+	receiver.listener.LocationPowerOn(name, start, end)
+}
+
+// children returns followed sensors of the location parts and phases, sensors without heartbeats or paused are
+// ignored.
+func (receiver *Receiver) children(parentID int64) (children []database.HeartbeatLocation, err error) {
+	locations, err := receiver.storage.GetHeartbeatLocations()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, location := range locations {
+		if location.ParentID == parentID && !location.LastHeartbeat.IsZero() && !location.Paused {
+			children = append(children, location)
+		}
+	}
+
+	return children, nil
+}
+
+func (receiver *Receiver) reportState(err error) {
+	if receiver.config.Reporter != nil {
+		receiver.config.Reporter.SetSubsystemState(subsystemName, err)
+	}
+}
+
+// readTelemetry parses the optional heartbeat body, unreported values are unknown.
+func readTelemetry(body io.Reader) (battery, signal int, voltage float64, err error) {
+	battery, signal, voltage = database.UnknownBattery, database.UnknownSignal, unknownVoltage
+
+	data, err := io.ReadAll(io.LimitReader(body, maxTelemetrySize+1))
+	if err != nil || len(data) > maxTelemetrySize {
+		return battery, signal, voltage, errInvalidTelemetry
+	}
+
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return battery, signal, voltage, nil
+	}
+
+	var values telemetry
+
+	if err = json.Unmarshal(data, &values); err != nil {
+		return battery, signal, voltage, errInvalidTelemetry
+	}
+
+	if values.Battery != nil {
+		if *values.Battery < 0 || *values.Battery > maxBattery {
+			return battery, signal, voltage, errInvalidTelemetry
+		}
+
+		battery = *values.Battery
+	}
+
+	if values.Signal != nil {
+		if *values.Signal < minSignal || *values.Signal >= 0 {
+			return battery, signal, voltage, errInvalidTelemetry
+		}
+
+		signal = *values.Signal
+	}
+
+	if values.Voltage != nil {
+		if *values.Voltage <= 0 || *values.Voltage > maxVoltage {
+			return battery, signal, voltage, errInvalidTelemetry
+		}
+
+		voltage = *values.Voltage
+	}
+
+	return battery, signal, voltage, nil
+}
+
+func rejectHeartbeat(w http.ResponseWriter, r *http.Request, location database.HeartbeatLocation, err error) {
+	log.WithFields(log.Fields{"location": location.Name, "remoteAddr": r.RemoteAddr}).Warnf("Heartbeat rejected: %s",
+		err)
+
+	status := http.StatusConflict
+	if errors.Is(err, errInvalidTimestamp) || errors.Is(err, errFutureTimestamp) ||
+		errors.Is(err, errInvalidTelemetry) {
+		status = http.StatusBadRequest
+	}
+
+	http.Error(w, err.Error(), status)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,613 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heartbeat_test
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"electrobot/apitoken"
+	"electrobot/database"
+	"electrobot/heartbeat"
+	"electrobot/httpserver"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	testToken  = "location-token"
+	testSecret = "webhook-secret"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type testStorage struct {
+	sync.Mutex
+
+	heartbeats []time.Time
+	lastSent   time.Time
+	batteries  []int
+	paused     bool
+}
+
+type testListener struct {
+	batteryLow chan int
+	voltage    chan string
+	power      chan string
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestHeartbeatSignature(t *testing.T) {
+	storage := &testStorage{}
+	url := startReceiver(t, storage, testListener{})
+	timestamp := time.Now().Unix()
+	signed := http.Header{
+		httpserver.TimestampHeader: {strconv.FormatInt(timestamp, 10)},
+		httpserver.SignatureHeader: {httpserver.Sign(testSecret, timestamp, nil)},
+	}
+
+	if status := post(t, url, nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("Wrong unsigned heartbeat status: %d", status)
+	}
+
+	if count := storage.count(); count != 0 {
+		t.Errorf("Unsigned heartbeat recorded")
+	}
+
+	if status := post(t, url, signed, nil); status != http.StatusNoContent {
+		t.Errorf("Wrong signed heartbeat status: %d", status)
+	}
+
+	if count := storage.count(); count != 1 {
+		t.Errorf("Wrong recorded heartbeats count: %d", count)
+	}
+
+	if status := post(t, url, signed, nil); status != http.StatusUnauthorized {
+		t.Errorf("Wrong replayed heartbeat status: %d", status)
+	}
+}
+
+func TestHeartbeatTimestamps(t *testing.T) {
+	storage := &testStorage{}
+	url := startReceiver(t, storage, testListener{})
+	now := time.Now()
+
+	testData := []struct {
+		name    string
+		sentAt  time.Time
+		status  int
+		delayed bool
+	}{
+		{name: "stale", sentAt: now.Add(-10 * time.Minute), status: http.StatusUnauthorized},
+		{name: "delayed", sentAt: now.Add(-2 * time.Minute), status: http.StatusNoContent, delayed: true},
+		{name: "replayed", sentAt: now.Add(-2 * time.Minute), status: http.StatusUnauthorized},
+		{name: "out of order", sentAt: now.Add(-3 * time.Minute), status: http.StatusConflict},
+		{name: "future", sentAt: now.Add(10 * time.Minute), status: http.StatusUnauthorized},
+		{name: "within skew", sentAt: now.Add(-10 * time.Second), status: http.StatusNoContent},
+		{name: "without timestamp", status: http.StatusUnauthorized},
+	}
+
+	for _, item := range testData {
+		header := bearer(item.sentAt)
+
+		count := storage.count()
+
+		if status := post(t, url, header, nil); status != item.status {
+			t.Errorf("Wrong %s heartbeat status: %d", item.name, status)
+		}
+
+		if item.status != http.StatusNoContent {
+			if storage.count() != count {
+				t.Errorf("Rejected %s heartbeat recorded", item.name)
+			}
+
+			continue
+		}
+
+		at := storage.last()
+
+		if item.delayed && !at.Equal(time.Unix(item.sentAt.Unix(), 0)) {
+			t.Errorf("Wrong %s heartbeat time: %v", item.name, at)
+		}
+
+		if !item.delayed && now.Sub(at) > time.Second {
+			t.Errorf("Wrong %s heartbeat time: %v", item.name, at)
+		}
+	}
+}
+
+func TestHeartbeatTelemetry(t *testing.T) {
+	storage := &testStorage{}
+	listener := testListener{batteryLow: make(chan int, 1)}
+	url := startReceiver(t, storage, listener)
+
+	testData := []struct {
+		body    string
+		status  int
+		battery int
+		alert   bool
+	}{
+		{body: `{"battery": 87, "signal": -71}`, status: http.StatusNoContent, battery: 87},
+		{body: `{"signal": -80}`, status: http.StatusNoContent, battery: 87},
+		{body: `{"battery": 150}`, status: http.StatusBadRequest, battery: 87},
+		{body: `{"signal": 5}`, status: http.StatusBadRequest, battery: 87},
+		{body: `battery`, status: http.StatusBadRequest, battery: 87},
+		{body: `{"battery": 15}`, status: http.StatusNoContent, battery: 15, alert: true},
+		{body: `{"battery": 10}`, status: http.StatusNoContent, battery: 10},
+		{body: `{"battery": 80}`, status: http.StatusNoContent, battery: 80},
+		{body: `{"battery": 20}`, status: http.StatusNoContent, battery: 20, alert: true},
+	}
+
+	for i, item := range testData {
+		header := bearer(time.Now().Add(time.Duration(i-len(testData)) * time.Second))
+
+		if status := post(t, url, header, []byte(item.body)); status != item.status {
+			t.Errorf("Wrong %s heartbeat status: %d", item.body, status)
+		}
+
+		if battery := storage.battery(); battery != item.battery {
+			t.Errorf("Wrong battery after %s heartbeat: %d", item.body, battery)
+		}
+
+		select {
+		case battery := <-listener.batteryLow:
+			if !item.alert || battery != item.battery {
+				t.Errorf("Unexpected low battery alert after %s heartbeat: %d", item.body, battery)
+			}
+
+		case <-time.After(100 * time.Millisecond):
+			if item.alert {
+				t.Errorf("Low battery alert after %s heartbeat not sent", item.body)
+			}
+		}
+	}
+}
+
+func TestVoltageAlerts(t *testing.T) {
+	storage := &testStorage{}
+	listener := testListener{voltage: make(chan string, 1)}
+	url := startReceiver(t, storage, listener)
+
+	testData := []struct {
+		body   string
+		status int
+		alert  string
+	}{
+		{body: `{"voltage": 229.5}`, status: http.StatusNoContent},
+		{body: `{"voltage": -5}`, status: http.StatusBadRequest},
+		{body: `{"voltage": 251}`, status: http.StatusNoContent},
+		{body: `{"voltage": 254}`, status: http.StatusNoContent, alert: "high 254"},
+		{body: `{"voltage": 256}`, status: http.StatusNoContent},
+		{body: `{"voltage": 250}`, status: http.StatusNoContent},
+		{body: `{"voltage": 254}`, status: http.StatusNoContent},
+		{body: `{"voltage": 247}`, status: http.StatusNoContent, alert: "normal 247"},
+		{body: `{"battery": 80}`, status: http.StatusNoContent},
+		{body: `{"voltage": 190}`, status: http.StatusNoContent, alert: "low 190"},
+		{body: `{"voltage": 210}`, status: http.StatusNoContent},
+		{body: `{"voltage": 260}`, status: http.StatusNoContent, alert: "high 260"},
+	}
+
+	for i, item := range testData {
+		header := bearer(time.Now().Add(time.Duration(i-len(testData)) * time.Second))
+
+		if status := post(t, url, header, []byte(item.body)); status != item.status {
+			t.Errorf("Wrong %s heartbeat status: %d", item.body, status)
+		}
+
+		select {
+		case alert := <-listener.voltage:
+			if alert != item.alert {
+				t.Errorf("Unexpected voltage alert after %s heartbeat: %s", item.body, alert)
+			}
+
+		case <-time.After(100 * time.Millisecond):
+			if item.alert != "" {
+				t.Errorf("Voltage alert after %s heartbeat not sent", item.body)
+			}
+		}
+	}
+}
+
+func TestPhases(t *testing.T) {
+	db, ids, checkEvents := startParts(t, []string{"phaseA", "phaseB", "phaseC"}, []string{"A", "B", "C"})
+	future := time.Now().Add(time.Hour)
+
+	// the location is off when the last phase is
+	checkEvents("off home A", "off home B", "off home")
+
+	// the first phase back ends the location outage
+	if err := db.RecordLocationHeartbeat(ids["phaseB"], future, time.Time{}); err != nil {
+		t.Fatalf("Can't record heartbeat: %s", err)
+	}
+
+	checkEvents("on home")
+
+	if err := db.RecordLocationHeartbeat(ids["phaseA"], future, time.Time{}); err != nil {
+		t.Fatalf("Can't record heartbeat: %s", err)
+	}
+
+	checkEvents("on home A")
+
+	outages, err := db.GetOutages(10)
+	if err != nil {
+		t.Fatalf("Can't get outages: %s", err)
+	}
+
+	if len(outages) != 1 {
+		t.Errorf("Wrong outages count: %d", len(outages))
+	}
+}
+
+func TestLocationParts(t *testing.T) {
+	db, ids, checkEvents := startParts(t, []string{"entrance1", "entrance2"}, []string{"", ""})
+	future := time.Now().Add(time.Hour)
+
+	// subscribers of the building learn about its outage only when all entrances are off
+	checkEvents("off entrance1", "off entrance2", "off home")
+
+	if err := db.RecordLocationHeartbeat(ids["entrance1"], future, time.Time{}); err != nil {
+		t.Fatalf("Can't record heartbeat: %s", err)
+	}
+
+	checkEvents("on entrance1", "on home")
+
+	if err := db.RecordLocationHeartbeat(ids["entrance2"], future, time.Time{}); err != nil {
+		t.Fatalf("Can't record heartbeat: %s", err)
+	}
+
+	checkEvents("on entrance2")
+}
+
+func TestServiceHeartbeats(t *testing.T) {
+	storage := &testStorage{}
+	receiver, url := startReceiverConfig(t, heartbeat.Config{
+		CheckInterval: time.Hour, Services: []string{"nas", "backup"},
+	}, storage, testListener{})
+	serviceURL := strings.TrimSuffix(url, testToken) + "service/"
+	restored := time.Now()
+
+	if status := post(t, serviceURL+"nas", bearer(time.Now().Add(-time.Second)), nil); status != http.StatusNoContent {
+		t.Errorf("Wrong service heartbeat status: %d", status)
+	}
+
+	if status := post(t, serviceURL+"camera", bearer(time.Now()), nil); status != http.StatusNotFound {
+		t.Errorf("Wrong unknown service heartbeat status: %d", status)
+	}
+
+	if storage.count() != 0 {
+		t.Errorf("Service heartbeat recorded as location heartbeat")
+	}
+
+	alive, missing := receiver.Services(restored)
+	if strings.Join(alive, " ") != "nas" || strings.Join(missing, " ") != "backup" {
+		t.Errorf("Wrong services: alive %v, missing %v", alive, missing)
+	}
+
+	if alive, missing = receiver.Services(time.Now().Add(time.Minute)); len(alive) != 0 || len(missing) != 2 {
+		t.Errorf("Wrong services after later restoration: alive %v, missing %v", alive, missing)
+	}
+}
+
+func TestPausedSensor(t *testing.T) {
+	storage := &testStorage{paused: true}
+	url := startReceiver(t, storage, testListener{})
+
+	if status := post(t, url, bearer(time.Now()), []byte(`{"battery": 5}`)); status != http.StatusNoContent {
+		t.Errorf("Wrong paused sensor heartbeat status: %d", status)
+	}
+
+	if storage.count() != 0 || storage.battery() != database.UnknownBattery {
+		t.Errorf("Paused sensor heartbeat recorded")
+	}
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (storage *testStorage) GetHeartbeatLocation(hash string) (location database.HeartbeatLocation, err error) {
+	if hash != apitoken.Hash(testToken) {
+		return location, sql.ErrNoRows
+	}
+
+	storage.Lock()
+	defer storage.Unlock()
+
+	location.ID, location.Name, location.LastSent = database.MainLocationID, "home", storage.lastSent
+	location.Battery, location.Signal, location.Paused = database.UnknownBattery, database.UnknownSignal, storage.paused
+
+	if len(storage.batteries) != 0 {
+		location.Battery = storage.batteries[len(storage.batteries)-1]
+	}
+
+	return location, nil
+}
+
+func (storage *testStorage) GetHeartbeatLocations() (locations []database.HeartbeatLocation, err error) {
+	return nil, nil
+}
+
+func (storage *testStorage) RecordLocationHeartbeat(locationID int64, at, sentAt time.Time) error {
+	storage.Lock()
+	defer storage.Unlock()
+
+	storage.heartbeats = append(storage.heartbeats, at)
+
+	if !sentAt.IsZero() {
+		storage.lastSent = sentAt
+	}
+
+	return nil
+}
+
+func (storage *testStorage) RecordSensorTelemetry(locationID int64, battery, signal int) error {
+	storage.Lock()
+	defer storage.Unlock()
+
+	if battery != database.UnknownBattery {
+		storage.batteries = append(storage.batteries, battery)
+	}
+
+	return nil
+}
+
+func (storage *testStorage) SetLocationOffSince(locationID int64, offSince time.Time) error {
+	return nil
+}
+
+func (storage *testStorage) RecordPowerOff(start, end time.Time) error {
+	return nil
+}
+
+func (listener testListener) LocationPowerOff(name string, start time.Time) {
+	if listener.power != nil {
+		listener.power <- "off " + name
+	}
+}
+
+func (listener testListener) LocationPowerOn(name string, start, end time.Time) {
+	if listener.power != nil {
+		listener.power <- "on " + name
+	}
+}
+
+func (listener testListener) PhasePowerOff(name, phase string, start time.Time) {
+	if listener.power != nil {
+		listener.power <- "off " + name + " " + phase
+	}
+}
+
+func (listener testListener) PhasePowerOn(name, phase string, start, end time.Time) {
+	if listener.power != nil {
+		listener.power <- "on " + name + " " + phase
+	}
+}
+
+func (listener testListener) SensorBatteryLow(name string, battery int) {
+	if listener.batteryLow != nil {
+		listener.batteryLow <- battery
+	}
+}
+
+func (listener testListener) VoltageAbnormal(name string, voltage float64, high bool) {
+	if listener.voltage != nil {
+		state := "low"
+		if high {
+			state = "high"
+		}
+
+		listener.voltage <- fmt.Sprintf("%s %g", state, voltage)
+	}
+}
+
+func (listener testListener) VoltageNormal(name string, voltage float64) {
+	if listener.voltage != nil {
+		listener.voltage <- fmt.Sprintf("normal %g", voltage)
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (storage *testStorage) count() int {
+	storage.Lock()
+	defer storage.Unlock()
+
+	return len(storage.heartbeats)
+}
+
+func (storage *testStorage) battery() int {
+	storage.Lock()
+	defer storage.Unlock()
+
+	if len(storage.batteries) == 0 {
+		return database.UnknownBattery
+	}
+
+	return storage.batteries[len(storage.batteries)-1]
+}
+
+func (storage *testStorage) last() time.Time {
+	storage.Lock()
+	defer storage.Unlock()
+
+	return storage.heartbeats[len(storage.heartbeats)-1]
+}
+
+// bearer returns headers of a heartbeat authorized with the bearer secret and sent at the time, zero time omits the
+// timestamp.
+func bearer(sentAt time.Time) http.Header {
+	header := http.Header{"Authorization": {"Bearer " + testSecret}}
+
+	if !sentAt.IsZero() {
+		header.Set(httpserver.TimestampHeader, strconv.FormatInt(sentAt.Unix(), 10))
+	}
+
+	return header
+}
+
+func startReceiver(t *testing.T, storage heartbeat.Storage, listener heartbeat.Listener) (url string) {
+	t.Helper()
+
+	_, url = startReceiverConfig(t, heartbeat.Config{CheckInterval: time.Hour}, storage, listener)
+
+	return url
+}
+
+// startReceiverConfig starts the receiver with the config, url is the heartbeat URL of the test token.
+func startReceiverConfig(
+	t *testing.T, config heartbeat.Config, storage heartbeat.Storage, listener heartbeat.Listener,
+) (receiver *heartbeat.Receiver, url string) {
+	t.Helper()
+
+	receiver, err := heartbeat.New(config, storage, listener)
+	if err != nil {
+		t.Fatalf("Can't create heartbeat receiver: %s", err)
+	}
+
+	t.Cleanup(receiver.Close)
+
+	listen := freeAddress(t)
+
+	server := httpserver.New(httpserver.Config{
+		Features: map[string]httpserver.FeatureConfig{
+			heartbeat.FeatureName: {Enabled: true, Listen: listen, WebhookSecret: testSecret},
+		},
+	})
+
+	if err = server.Register(heartbeat.FeatureName, receiver.Routes()); err != nil {
+		t.Fatalf("Can't register heartbeat routes: %s", err)
+	}
+
+	if err = server.Start(); err != nil {
+		t.Fatalf("Can't start HTTP server: %s", err)
+	}
+
+	t.Cleanup(server.Close)
+
+	return receiver, "http://" + listen + "/api/v1/heartbeat/" + testToken
+}
+
+// startParts starts the receiver following sensors of the main location parts, phases are the part phases or empty.
+// The sensors have been silent for an hour, checkEvents waits for the listener power events.
+func startParts(t *testing.T, parts, phases []string) (
+	db *database.Database, ids map[string]int64, checkEvents func(events ...string),
+) {
+	t.Helper()
+
+	db, err := database.New(database.Config{WorkingDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Can't create database: %s", err)
+	}
+
+	t.Cleanup(db.Close)
+
+	ids = make(map[string]int64)
+
+	for i, name := range parts {
+		if ids[name], err = db.AddLocation(name); err != nil {
+			t.Fatalf("Can't add location: %s", err)
+		}
+
+		if err = db.SetHeartbeatToken(name, apitoken.Hash(name)); err != nil {
+			t.Fatalf("Can't set heartbeat token: %s", err)
+		}
+
+		if err = db.SetLocationParent(name, "home", phases[i]); err != nil {
+			t.Fatalf("Can't set location parent: %s", err)
+		}
+
+		if err = db.RecordLocationHeartbeat(ids[name], time.Now().Add(-time.Hour), time.Time{}); err != nil {
+			t.Fatalf("Can't record heartbeat: %s", err)
+		}
+	}
+
+	listener := testListener{power: make(chan string, 10)}
+
+	receiver, err := heartbeat.New(heartbeat.Config{
+		Threshold: 100 * time.Millisecond, CheckInterval: 20 * time.Millisecond,
+	}, db, listener)
+	if err != nil {
+		t.Fatalf("Can't create heartbeat receiver: %s", err)
+	}
+
+	t.Cleanup(receiver.Close)
+
+	return db, ids, func(events ...string) {
+		t.Helper()
+
+		for _, event := range events {
+			select {
+			case received := <-listener.power:
+				if received != event {
+					t.Errorf("Wrong power event: %q instead of %q", received, event)
+				}
+
+			case <-time.After(time.Second):
+				t.Fatalf("Power event %q not received", event)
+			}
+		}
+	}
+}
+
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Can't get free port: %s", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func post(t *testing.T, url string, header http.Header, body []byte) int {
+	t.Helper()
+
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Can't create request: %s", err)
+	}
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Can't send request: %s", err)
+	}
+	defer response.Body.Close()
+
+	return response.StatusCode
+}
@@ -0,0 +1,405 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hostmonitor detects power outages on the monitored premises by probing hosts there (router, NAS) over
+// the network, so the bot may run elsewhere, e.g. in the cloud. Power is present while a quorum of hosts responds.
+package hostmonitor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultInterval   = 30 * time.Second
+	defaultTimeout    = 3 * time.Second
+	defaultFailures   = 3
+	offSinceKey       = "host_monitor_off_since"
+	subsystemName     = "host monitor"
+	pingCommand       = "ping"
+	notificationsSize = 16
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with host monitor configuration.
+type Config struct {
+	// Location is the name of the monitored location.
+	Location string
+	// Hosts are probed with TCP connect if given as host:port and with ICMP echo (the system ping command) otherwise.
+	Hosts []string
+	// Quorum is the number of responding hosts meaning power is present, the majority of hosts by default.
+	Quorum int
+	// Interval is the period of host checks.
+	Interval time.Duration
+	// Timeout limits every probe.
+	Timeout time.Duration
+	// Failures is the number of consecutive checks without quorum treated as a power outage, a single failed check
+	// is often a network glitch.
+	Failures int
+	// RecordOutages stores outages in the event history, set for the main location only.
+	RecordOutages bool
+	// Reporter receives host monitor state, optional.
+	Reporter StateReporter
+}
+
+// Storage provides outage persistence.
+type Storage interface {
+	GetSetting(key string) (value string, err error)
+	SetSetting(key, value string) error
+	RecordPowerOff(start, end time.Time) error
+}
+
+// Listener is notified about power state transitions of the location.
+type Listener interface {
+	// RestoreOutage is called on startup if the location was off when the monitor stopped, no transition happened
+	// and users are not notified.
+	RestoreOutage(name string, since time.Time)
+	LocationPowerOff(name string, start time.Time)
+	LocationPowerOn(name string, start, end time.Time)
+}
+
+// StateReporter receives subsystem state changes.
+type StateReporter interface {
+	SetSubsystemState(name string, err error)
+}
+
+// Monitor periodically probes hosts of the monitored location.
+type Monitor struct {
+	config   Config
+	storage  Storage
+	listener Listener
+	targets  []target
+	// notifications are delivered in order by a separate goroutine, the listener fans out to all subscribers while
+	// probes must keep their interval
+	notifications chan func()
+	cancelFunc    context.CancelFunc
+	// failedSince is the time of the first check without quorum in a row, zero if the last check succeeded.
+	failedSince time.Time
+	failures    int
+	// offSince is the outage start, zero while power is present.
+	offSince time.Time
+}
+
+type target struct {
+	host string
+	// port is empty for ICMP probes.
+	port string
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates and starts host monitor.
+func New(config Config, storage Storage, listener Listener) (monitor *Monitor, err error) {
+	if len(config.Hosts) == 0 {
+		return nil, errors.New("no hosts to monitor")
+	}
+
+	if config.Quorum <= 0 {
+		config.Quorum = len(config.Hosts)/2 + 1 //nolint:gomnd
+	}
+
+	if config.Quorum > len(config.Hosts) {
+		return nil, fmt.Errorf("quorum %d exceeds the number of hosts %d", config.Quorum, len(config.Hosts))
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = defaultInterval
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	if config.Failures <= 0 {
+		config.Failures = defaultFailures
+	}
+
+	monitor = &Monitor{
+		config: config, storage: storage, listener: listener, notifications: make(chan func(), notificationsSize),
+	}
+
+	for _, host := range config.Hosts {
+		target, err := parseTarget(host)
+		if err != nil {
+			return nil, err
+		}
+
+		if target.port == "" {
+			if _, err = exec.LookPath(pingCommand); err != nil {
+				return nil, fmt.Errorf("ICMP probe of %s needs the %s command: %w", host, pingCommand, err)
+			}
+		}
+
+		monitor.targets = append(monitor.targets, target)
+	}
+
+	monitor.restoreState()
+
+	ctx, cancelFunction := context.WithCancel(context.Background())
+	monitor.cancelFunc = cancelFunction
+
+	go monitor.run(ctx)
+	go monitor.deliverNotifications(ctx)
+
+	return monitor, nil
+}
+
+// CheckHosts probes the hosts once and returns an error if the quorum is not reached, e.g. due to a wrong address
+// or a firewall. It doesn't affect the power state.
+func (monitor *Monitor) CheckHosts() error {
+	responding := monitor.probeAll(context.Background())
+	if responding < monitor.config.Quorum {
+		return fmt.Errorf("%d of %d hosts respond, quorum is %d", responding, len(monitor.targets),
+			monitor.config.Quorum)
+	}
+
+	return nil
+}
+
+// Close stops host monitor.
+func (monitor *Monitor) Close() {
+	monitor.cancelFunc()
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func parseTarget(host string) (target, error) {
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		// bare IPv6 addresses have colons too
+		if net.ParseIP(host) != nil || host != "" && !strings.Contains(host, ":") {
+			return target{host: host}, nil
+		}
+
+		return target{}, fmt.Errorf("invalid host %q, expected host or host:port", host)
+	}
+
+	if number, err := strconv.Atoi(port); err != nil || number <= 0 || number > 65535 {
+		return target{}, fmt.Errorf("invalid port in host %q", host)
+	}
+
+	return target{host: name, port: port}, nil
+}
+
+// restoreState continues the outage started before the restart, otherwise it would be reported twice.
+func (monitor *Monitor) restoreState() {
+	value, err := monitor.storage.GetSetting(offSinceKey)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("Failed to get host monitor state: %s", err)
+		}
+
+		return
+	}
+
+	if offSince, err := time.Parse(time.RFC3339, value); err == nil {
+		log.WithField("since", offSince.UTC()).Info("Host monitor continues outage")
+
+		monitor.offSince = offSince
+	}
+}
+
+func (monitor *Monitor) run(ctx context.Context) {
+	log.WithFields(log.Fields{
+		"location": monitor.config.Location, "hosts": monitor.config.Hosts, "quorum": monitor.config.Quorum,
+	}).Info("Host monitor started")
+
+	if offSince := monitor.offSince; !offSince.IsZero() {
+		monitor.notify(ctx, func() { monitor.listener.RestoreOutage(monitor.config.Location, offSince) })
+	}
+
+	monitor.check(ctx)
+
+	ticker := time.NewTicker(monitor.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			monitor.check(ctx)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (monitor *Monitor) deliverNotifications(ctx context.Context) {
+	for {
+		select {
+		case notification := <-monitor.notifications:
+			notification()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// notify queues the listener notification, it blocks only if the listener is behind by several transitions.
+func (monitor *Monitor) notify(ctx context.Context, notification func()) {
+	select {
+	case monitor.notifications <- notification:
+
+	case <-ctx.Done():
+	}
+}
+
+func (monitor *Monitor) check(ctx context.Context) {
+	now := time.Now().Round(0)
+	responding := monitor.probeAll(ctx)
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	log.WithFields(log.Fields{"responding": responding, "quorum": monitor.config.Quorum}).Debug("Hosts probed")
+
+	if responding >= monitor.config.Quorum {
+		monitor.failures, monitor.failedSince = 0, time.Time{}
+
+		if !monitor.offSince.IsZero() {
+			monitor.powerOn(ctx, monitor.offSince, now)
+		}
+
+		return
+	}
+
+	if monitor.failures == 0 {
+		monitor.failedSince = now
+	}
+
+	monitor.failures++
+
+	if monitor.offSince.IsZero() && monitor.failures >= monitor.config.Failures {
+		monitor.powerOff(ctx, monitor.failedSince)
+	}
+}
+
+// probeAll probes all hosts concurrently and returns the number of responding ones.
+func (monitor *Monitor) probeAll(ctx context.Context) (responding int) {
+	var (
+		mutex     sync.Mutex
+		waitGroup sync.WaitGroup
+	)
+
+	for _, target := range monitor.targets {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			if err := monitor.probe(ctx, target); err != nil {
+				log.WithField("host", target.host).Debugf("Host is not responding: %s", err)
+
+				return
+			}
+
+			mutex.Lock()
+			responding++
+			mutex.Unlock()
+		}()
+	}
+
+	waitGroup.Wait()
+
+	return responding
+}
+
+func (monitor *Monitor) probe(ctx context.Context, target target) error {
+	ctx, cancel := context.WithTimeout(ctx, monitor.config.Timeout)
+	defer cancel()
+
+	if target.port != "" {
+		dialer := net.Dialer{}
+
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(target.host, target.port))
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+
+	// ping waits for the reply in whole seconds, the context kills it on the configured timeout
+	wait := max(1, int(monitor.config.Timeout.Seconds()))
+
+	command := exec.CommandContext(ctx, pingCommand, "-n", "-c", "1", "-W", strconv.Itoa(wait), target.host) //nolint:gosec
+
+	return command.Run()
+}
+
+func (monitor *Monitor) powerOff(ctx context.Context, start time.Time) {
+	log.WithFields(log.Fields{
+		"location": monitor.config.Location, "start": start.UTC(),
+	}).Info("Power outage detected by host probes")
+
+	monitor.offSince = start
+	monitor.saveState(start.UTC().Format(time.RFC3339))
+
+	monitor.notify(ctx, func() { monitor.listener.LocationPowerOff(monitor.config.Location, start) })
+}
+
+func (monitor *Monitor) powerOn(ctx context.Context, start, end time.Time) {
+	log.WithFields(log.Fields{
+		"location": monitor.config.Location, "start": start.UTC(), "end": end.UTC(),
+		"duration": end.Sub(start).Round(time.Second),
+	}).Info("Power restored according to host probes")
+
+	monitor.offSince = time.Time{}
+
+	if monitor.config.RecordOutages {
+		if err := monitor.storage.RecordPowerOff(start, end); err != nil {
+			log.Errorf("Failed to store outage events: %s", err)
+		}
+	}
+
+	monitor.saveState("")
+
+	monitor.notify(ctx, func() { monitor.listener.LocationPowerOn(monitor.config.Location, start, end) })
+}
+
+// saveState stores the outage start, empty value means power is present.
+func (monitor *Monitor) saveState(offSince string) {
+	err := monitor.storage.SetSetting(offSinceKey, offSince)
+	if err != nil {
+		log.Errorf("Failed to store host monitor state: %s", err)
+	}
+
+	if monitor.config.Reporter != nil {
+		monitor.config.Reporter.SetSubsystemState(subsystemName, err)
+	}
+}
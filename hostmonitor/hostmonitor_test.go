@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostmonitor_test
+
+import (
+	"database/sql"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"electrobot/hostmonitor"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	testLocation = "home"
+	waitTimeout  = 5 * time.Second
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type testStorage struct {
+	sync.Mutex
+
+	settings map[string]string
+	outages  int
+}
+
+type testListener struct {
+	events chan string
+	starts chan time.Time
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestRestoreOutage(t *testing.T) {
+	server := newTestServer(t)
+
+	offSince := time.Now().Add(-time.Hour).Truncate(time.Second)
+	storage := &testStorage{settings: map[string]string{"host_monitor_off_since": offSince.Format(time.RFC3339)}}
+	listener := &testListener{events: make(chan string, 4), starts: make(chan time.Time, 4)}
+
+	monitor, err := hostmonitor.New(hostmonitor.Config{
+		Location: testLocation, Hosts: []string{server.Addr().String()}, Interval: time.Hour, RecordOutages: true,
+	}, storage, listener)
+	if err != nil {
+		t.Fatalf("Can't create host monitor: %s", err)
+	}
+	defer monitor.Close()
+
+	// the restored outage ends on the first check as the host responds
+	for _, expected := range []string{"restore", "on"} {
+		select {
+		case event := <-listener.events:
+			if event != expected {
+				t.Fatalf("Wrong event: %s, expected %s", event, expected)
+			}
+
+			if start := <-listener.starts; !start.Equal(offSince) {
+				t.Errorf("Wrong outage start: %s", start)
+			}
+
+		case <-time.After(waitTimeout):
+			t.Fatalf("Can't get %s event", expected)
+		}
+	}
+
+	storage.Lock()
+	defer storage.Unlock()
+
+	if storage.outages != 1 {
+		t.Errorf("Wrong outages count: %d", storage.outages)
+	}
+
+	if value := storage.settings["host_monitor_off_since"]; value != "" {
+		t.Errorf("Wrong stored state: %s", value)
+	}
+}
+
+func TestCheckHosts(t *testing.T) {
+	server := newTestServer(t)
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Can't listen: %s", err)
+	}
+
+	closed.Close()
+
+	testData := []struct {
+		quorum int
+		failed bool
+	}{
+		{quorum: 1},
+		{quorum: 2, failed: true},
+	}
+
+	for _, item := range testData {
+		monitor, err := hostmonitor.New(hostmonitor.Config{
+			Location: testLocation, Hosts: []string{server.Addr().String(), closed.Addr().String()},
+			Quorum: item.quorum, Interval: time.Hour,
+		}, &testStorage{settings: map[string]string{}}, &testListener{
+			events: make(chan string, 4), starts: make(chan time.Time, 4),
+		})
+		if err != nil {
+			t.Fatalf("Can't create host monitor: %s", err)
+		}
+
+		if err = monitor.CheckHosts(); (err != nil) != item.failed {
+			t.Errorf("Wrong check result with quorum %d: %v", item.quorum, err)
+		}
+
+		monitor.Close()
+	}
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (storage *testStorage) GetSetting(key string) (value string, err error) {
+	storage.Lock()
+	defer storage.Unlock()
+
+	value, ok := storage.settings[key]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+
+	return value, nil
+}
+
+func (storage *testStorage) SetSetting(key, value string) error {
+	storage.Lock()
+	defer storage.Unlock()
+
+	storage.settings[key] = value
+
+	return nil
+}
+
+func (storage *testStorage) RecordPowerOff(start, end time.Time) error {
+	storage.Lock()
+	defer storage.Unlock()
+
+	storage.outages++
+
+	return nil
+}
+
+func (listener *testListener) RestoreOutage(name string, since time.Time) {
+	listener.events <- "restore"
+	listener.starts <- since
+}
+
+func (listener *testListener) LocationPowerOff(name string, start time.Time) {
+	listener.events <- "off"
+	listener.starts <- start
+}
+
+func (listener *testListener) LocationPowerOn(name string, start, end time.Time) {
+	listener.events <- "on"
+	listener.starts <- start
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// newTestServer starts a TCP server accepting connections like a responding host.
+func newTestServer(t *testing.T) net.Listener {
+	t.Helper()
+
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Can't listen: %s", err)
+	}
+
+	t.Cleanup(func() { server.Close() })
+
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+
+			conn.Close()
+		}
+	}()
+
+	return server
+}
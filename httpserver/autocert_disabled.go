@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build minimal || noautocert
+
+package httpserver
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// newCertManager returns no manager in builds without Let's Encrypt support, Start fails if it is configured.
+func newCertManager(AutocertConfig) certManager {
+	return nil
+}
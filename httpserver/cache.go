@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// maxCachedResponses bounds memory used by a feature cache, responses are not cached when it is full of unexpired
+// ones.
+const maxCachedResponses = 256
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// responseCache keeps successful GET responses of feature routes for a configured time.
+type responseCache struct {
+	sync.Mutex
+
+	ttl       time.Duration
+	responses map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// responseRecorder captures a response to be cached while it is written to the client.
+type responseRecorder struct {
+	http.ResponseWriter
+
+	status int
+	body   bytes.Buffer
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// WriteHeader records the response status.
+func (recorder *responseRecorder) WriteHeader(status int) {
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the response body.
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+
+	recorder.body.Write(data)
+
+	return recorder.ResponseWriter.Write(data)
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, responses: make(map[string]cachedResponse)}
+}
+
+// handler serves GET and HEAD requests from the cache. Misses are handled one at a time, so a burst of requests for
+// the same URL reaches the storage once.
+func (cache *responseCache) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		key := cacheKey(r)
+
+		cache.Lock()
+		defer cache.Unlock()
+
+		if response, ok := cache.responses[key]; ok && time.Now().Before(response.expires) {
+			for name, values := range response.header {
+				w.Header()[name] = values
+			}
+
+			// cached ETag and Last-Modified headers answer conditional requests
+			modified, _ := http.ParseTime(response.header.Get("Last-Modified"))
+
+			http.ServeContent(w, r, "", modified, bytes.NewReader(response.body))
+
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(recorder, r)
+
+		// bodies of HEAD responses are not written
+		if recorder.status == http.StatusOK && r.Method == http.MethodGet {
+			cache.store(key, cachedResponse{
+				header: w.Header().Clone(), body: recorder.body.Bytes(), expires: time.Now().Add(cache.ttl),
+			})
+		}
+	})
+}
+
+// store adds the response dropping expired ones, it is not cached if the cache is still full.
+func (cache *responseCache) store(key string, response cachedResponse) {
+	if len(cache.responses) >= maxCachedResponses {
+		now := time.Now()
+
+		for key, cached := range cache.responses {
+			if !now.Before(cached.expires) {
+				delete(cache.responses, key)
+			}
+		}
+	}
+
+	if len(cache.responses) < maxCachedResponses {
+		cache.responses[key] = response
+	}
+}
+
+// cacheKey returns the request path with sorted query parameters except the token, responses do not depend on it
+// once the request is authorized.
+func cacheKey(r *http.Request) string {
+	query := r.URL.Query()
+
+	query.Del(tokenQueryParam)
+
+	if len(query) == 0 {
+		return r.URL.Path
+	}
+
+	return r.URL.Path + "?" + query.Encode()
+}
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestResponseCache(t *testing.T) {
+	calls := 0
+
+	handler := newResponseCache(time.Hour).handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"calls": %d}`, calls)
+	}))
+
+	testData := []struct {
+		target string
+		body   string
+		calls  int
+	}{
+		{target: "/status", body: `{"calls": 1}`, calls: 1},
+		{target: "/status", body: `{"calls": 1}`, calls: 1},
+		{target: "/status?token=secret", body: `{"calls": 1}`, calls: 1},
+		{target: "/status?b=1&a=2", body: `{"calls": 2}`, calls: 2},
+		{target: "/status?a=2&b=1", body: `{"calls": 2}`, calls: 2},
+		{target: "/status?fail=1", calls: 3},
+		{target: "/status?fail=1", calls: 4},
+	}
+
+	for _, item := range testData {
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, item.target, nil))
+
+		if recorder.Body.String() != item.body {
+			t.Errorf("Wrong %s response: %s", item.target, recorder.Body)
+		}
+
+		if calls != item.calls {
+			t.Errorf("Wrong handler calls after %s: %d", item.target, calls)
+		}
+
+		if item.body != "" && recorder.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Wrong %s content type: %s", item.target, recorder.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestResponseCacheExpiration(t *testing.T) {
+	calls := 0
+
+	handler := newResponseCache(time.Millisecond).handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	time.Sleep(2 * time.Millisecond)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	if calls != 2 {
+		t.Errorf("Expired response is served: %d calls", calls)
+	}
+}
+
+func TestResponseCacheConditional(t *testing.T) {
+	handler := newResponseCache(time.Hour).handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"1"`)
+		fmt.Fprint(w, "on")
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	request := httptest.NewRequest(http.MethodGet, "/status", nil)
+	request.Header.Set("If-None-Match", `"1"`)
+
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotModified {
+		t.Errorf("Wrong status of cached unchanged response: %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "/status", nil))
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("ETag") != `"1"` {
+		t.Errorf("Wrong cached HEAD response: %d, ETag %q", recorder.Code, recorder.Header().Get("ETag"))
+	}
+}
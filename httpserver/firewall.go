@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultMaxBodySize    = 64 * 1024
+	defaultMaxURLLength   = 2048
+	defaultMaxHeaderBytes = 16 * 1024
+	rateLimitWindow       = time.Minute
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// FirewallConfig request filtering configuration applied to all listeners.
+type FirewallConfig struct {
+	// AllowIPs lists IPs or CIDRs allowed to connect, empty means any.
+	AllowIPs []string
+	// DenyIPs lists IPs or CIDRs which are always rejected, takes precedence over AllowIPs.
+	DenyIPs []string
+	// MaxBodySize limits request body size in bytes, 0 means default.
+	MaxBodySize int64
+	// MaxURLLength limits request URI length, 0 means default.
+	MaxURLLength int
+	// RateLimit limits requests per minute from a single IP, 0 disables the limit.
+	RateLimit int
+}
+
+type firewall struct {
+	config  FirewallConfig
+	allow   []netip.Prefix
+	deny    []netip.Prefix
+	limiter *rateLimiter
+}
+
+// rateLimiter counts requests per IP in fixed windows.
+type rateLimiter struct {
+	sync.Mutex
+
+	limit       int
+	windowStart time.Time
+	requests    map[netip.Addr]int
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func newFirewall(config FirewallConfig) (fw *firewall, err error) {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaultMaxBodySize
+	}
+
+	if config.MaxURLLength <= 0 {
+		config.MaxURLLength = defaultMaxURLLength
+	}
+
+	fw = &firewall{config: config, limiter: newRateLimiter(config.RateLimit)}
+
+	if fw.allow, err = parsePrefixes(config.AllowIPs); err != nil {
+		return nil, fmt.Errorf("invalid HTTP allow list: %w", err)
+	}
+
+	if fw.deny, err = parsePrefixes(config.DenyIPs); err != nil {
+		return nil, fmt.Errorf("invalid HTTP deny list: %w", err)
+	}
+
+	return fw, nil
+}
+
+// parsePrefixes parses list of IPs and CIDRs, a single IP is treated as a full-length prefix. IPv4-mapped IPv6
+// addresses and prefixes are converted to IPv4 ones.
+func parsePrefixes(items []string) (prefixes []netip.Prefix, err error) {
+	for _, item := range items {
+		if !strings.Contains(item, "/") {
+			addr, err := netip.ParseAddr(item)
+			if err != nil {
+				return nil, err
+			}
+
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, err
+		}
+
+		// client addresses are unmapped, so IPv4-mapped prefixes have to be unmapped too to match them
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96) //nolint:gomnd
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteAddr returns request client IP, X-Forwarded-For is ignored as it can be spoofed.
+func remoteAddr(r *http.Request) (addr netip.Addr, err error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return addr, err
+	}
+
+	if addr, err = netip.ParseAddr(host); err != nil {
+		return addr, err
+	}
+
+	return addr.Unmap(), nil
+}
+
+// handler rejects requests from disallowed IPs, over the rate limit or violating basic request rules.
+func (fw *firewall) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, err := remoteAddr(r)
+		if err != nil {
+			log.WithField("remoteAddr", r.RemoteAddr).Errorf("Failed to parse HTTP client address: %s", err)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+			return
+		}
+
+		if status := fw.check(addr, r); status != http.StatusOK {
+			log.WithFields(log.Fields{
+				"remoteAddr": addr, "method": r.Method, "path": r.URL.Path, "status": status,
+			}).Warn("HTTP request rejected")
+
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", strconv.Itoa(int(rateLimitWindow.Seconds())))
+			}
+
+			http.Error(w, http.StatusText(status), status)
+
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, fw.config.MaxBodySize)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// check returns HTTP status the request should be rejected with, http.StatusOK if it is accepted.
+func (fw *firewall) check(addr netip.Addr, r *http.Request) int {
+	switch {
+	case containsAddr(fw.deny, addr), len(fw.allow) != 0 && !containsAddr(fw.allow, addr):
+		return http.StatusForbidden
+
+	case !fw.limiter.allow(addr):
+		return http.StatusTooManyRequests
+
+	case r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodPost:
+		return http.StatusMethodNotAllowed
+
+	case len(r.RequestURI) > fw.config.MaxURLLength:
+		return http.StatusRequestURITooLong
+
+	case strings.Contains(r.URL.Path, ".."):
+		return http.StatusBadRequest
+
+	case r.ContentLength > fw.config.MaxBodySize:
+		return http.StatusRequestEntityTooLarge
+
+	default:
+		return http.StatusOK
+	}
+}
+
+func newRateLimiter(limit int) *rateLimiter {
+	return &rateLimiter{limit: limit, requests: make(map[netip.Addr]int)}
+}
+
+// allow counts requests per IP in fixed windows, counters are dropped when the window ends.
+func (limiter *rateLimiter) allow(addr netip.Addr) bool {
+	if limiter.limit <= 0 {
+		return true
+	}
+
+	limiter.Lock()
+	defer limiter.Unlock()
+
+	if now := time.Now(); now.Sub(limiter.windowStart) >= rateLimitWindow {
+		limiter.windowStart = now
+		limiter.requests = make(map[netip.Addr]int)
+	}
+
+	limiter.requests[addr]++
+
+	return limiter.requests[addr] <= limiter.limit
+}
+
+// rateLimit restricts requests per IP to feature routes in addition to the server firewall limit.
+func rateLimit(limiter *rateLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if addr, err := remoteAddr(r); err == nil && !limiter.allow(addr) {
+			log.WithFields(log.Fields{"remoteAddr": addr, "path": r.URL.Path}).Warn("HTTP feature rate limit exceeded")
+			w.Header().Set("Retry-After", strconv.Itoa(int(rateLimitWindow.Seconds())))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ipFilter restricts feature routes to the listed networks in addition to the server firewall.
+func ipFilter(allow []netip.Prefix, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if addr, err := remoteAddr(r); err != nil || !containsAddr(allow, addr) {
+			log.WithFields(log.Fields{"remoteAddr": r.RemoteAddr, "path": r.URL.Path}).Warn("HTTP feature access denied")
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
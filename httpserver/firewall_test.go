@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestParsePrefixes(t *testing.T) {
+	testData := []struct {
+		item   string
+		prefix string
+		failed bool
+	}{
+		{item: "192.168.1.10", prefix: "192.168.1.10/32"},
+		{item: "192.168.1.10/24", prefix: "192.168.1.0/24"},
+		{item: "::ffff:192.168.1.10", prefix: "192.168.1.10/32"},
+		{item: "::ffff:192.168.1.0/120", prefix: "192.168.1.0/24"},
+		{item: "2001:db8::1", prefix: "2001:db8::1/128"},
+		{item: "2001:db8::/32", prefix: "2001:db8::/32"},
+		{item: "192.168.1.256", failed: true},
+		{item: "192.168.1.0/33", failed: true},
+		{item: "localhost", failed: true},
+		{item: "", failed: true},
+	}
+
+	for _, item := range testData {
+		prefixes, err := parsePrefixes([]string{item.item})
+
+		if item.failed {
+			if err == nil {
+				t.Errorf("Prefix %q is parsed: %v", item.item, prefixes)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Can't parse prefix %q: %s", item.item, err)
+
+			continue
+		}
+
+		if prefixes[0].String() != item.prefix {
+			t.Errorf("Wrong prefix %s parsed from %q", prefixes[0], item.item)
+		}
+	}
+}
+
+func TestFirewallAccess(t *testing.T) {
+	testData := []struct {
+		name          string
+		allow         []string
+		deny          []string
+		remoteAddr    string
+		forwardedFor  string
+		status        int
+		filteredAllow []string
+	}{
+		{name: "no lists", remoteAddr: "203.0.113.5:1000", status: http.StatusOK},
+		{name: "allowed", allow: []string{"192.168.1.0/24"}, remoteAddr: "192.168.1.5:1000", status: http.StatusOK},
+		{
+			name: "not allowed", allow: []string{"192.168.1.0/24"}, remoteAddr: "192.168.2.5:1000",
+			status: http.StatusForbidden,
+		},
+		{
+			name: "mapped address allowed", allow: []string{"192.168.1.0/24"}, remoteAddr: "[::ffff:192.168.1.5]:1000",
+			status: http.StatusOK,
+		},
+		{name: "denied", deny: []string{"203.0.113.5"}, remoteAddr: "203.0.113.5:1000", status: http.StatusForbidden},
+		{
+			name: "deny over allow", allow: []string{"192.168.1.0/24"}, deny: []string{"192.168.1.5"},
+			remoteAddr: "192.168.1.5:1000", status: http.StatusForbidden,
+		},
+		{
+			name: "mapped address denied", deny: []string{"::ffff:203.0.113.0/120"}, remoteAddr: "203.0.113.5:1000",
+			status: http.StatusForbidden,
+		},
+		{
+			name: "IPv6 allowed", allow: []string{"2001:db8::/32"}, remoteAddr: "[2001:db8::5]:1000",
+			status: http.StatusOK,
+		},
+		{
+			name: "forwarded for allowed address", allow: []string{"192.168.1.0/24"}, remoteAddr: "203.0.113.5:1000",
+			forwardedFor: "192.168.1.5", status: http.StatusForbidden,
+		},
+		{
+			name: "forwarded for denied address", deny: []string{"203.0.113.5"}, remoteAddr: "192.168.1.5:1000",
+			forwardedFor: "203.0.113.5", status: http.StatusOK,
+		},
+		{name: "invalid remote address", remoteAddr: "192.168.1.5", status: http.StatusForbidden},
+	}
+
+	for _, item := range testData {
+		fw, err := newFirewall(FirewallConfig{AllowIPs: item.allow, DenyIPs: item.deny})
+		if err != nil {
+			t.Fatalf("Can't create firewall: %s", err)
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.RemoteAddr = item.remoteAddr
+
+		if item.forwardedFor != "" {
+			request.Header.Set("X-Forwarded-For", item.forwardedFor)
+		}
+
+		if status := serve(fw.handler(okHandler()), request); status != item.status {
+			t.Errorf("Wrong %s status: %d", item.name, status)
+		}
+	}
+}
+
+func TestFirewallRequests(t *testing.T) {
+	testData := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{name: "get", method: http.MethodGet, target: "/status", status: http.StatusOK},
+		{name: "head", method: http.MethodHead, target: "/status", status: http.StatusOK},
+		{name: "post", method: http.MethodPost, target: "/heartbeat", body: "{}", status: http.StatusOK},
+		{name: "put", method: http.MethodPut, target: "/status", status: http.StatusMethodNotAllowed},
+		{name: "delete", method: http.MethodDelete, target: "/status", status: http.StatusMethodNotAllowed},
+		{
+			name: "long URL", method: http.MethodGet, target: "/" + strings.Repeat("a", 100),
+			status: http.StatusRequestURITooLong,
+		},
+		{name: "path traversal", method: http.MethodGet, target: "/static/../config", status: http.StatusBadRequest},
+		{
+			name: "large body", method: http.MethodPost, target: "/heartbeat", body: strings.Repeat("a", 101),
+			status: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	fw, err := newFirewall(FirewallConfig{MaxBodySize: 100, MaxURLLength: 100})
+	if err != nil {
+		t.Fatalf("Can't create firewall: %s", err)
+	}
+
+	for _, item := range testData {
+		request := httptest.NewRequest(item.method, item.target, strings.NewReader(item.body))
+
+		if status := serve(fw.handler(okHandler()), request); status != item.status {
+			t.Errorf("Wrong %s status: %d", item.name, status)
+		}
+	}
+}
+
+func TestFirewallRateLimit(t *testing.T) {
+	fw, err := newFirewall(FirewallConfig{RateLimit: 3})
+	if err != nil {
+		t.Fatalf("Can't create firewall: %s", err)
+	}
+
+	handler := fw.handler(okHandler())
+
+	for i := 0; i < 3; i++ {
+		if status := serve(handler, newRequest("192.168.1.5:1000", "")); status != http.StatusOK {
+			t.Fatalf("Wrong status of request %d: %d", i, status)
+		}
+	}
+
+	if status := serve(handler, newRequest("192.168.1.5:2000", "")); status != http.StatusTooManyRequests {
+		t.Errorf("Wrong status over the rate limit: %d", status)
+	}
+
+	// the limit is per client IP, not per forwarded address
+	if status := serve(handler, newRequest("192.168.1.5:3000", "192.168.1.6")); status != http.StatusTooManyRequests {
+		t.Errorf("Wrong status of forwarded request over the rate limit: %d", status)
+	}
+
+	if status := serve(handler, newRequest("192.168.1.6:1000", "")); status != http.StatusOK {
+		t.Errorf("Wrong status of another client: %d", status)
+	}
+}
+
+func TestFeatureRateLimit(t *testing.T) {
+	handler := rateLimit(newRateLimiter(2), okHandler())
+
+	for i := 0; i < 2; i++ {
+		if status := serve(handler, newRequest("192.168.1.5:1000", "")); status != http.StatusOK {
+			t.Fatalf("Wrong status of request %d: %d", i, status)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, newRequest("192.168.1.5:1000", ""))
+
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") != "60" {
+		t.Errorf("Wrong response over the rate limit: %d, retry after %q", recorder.Code,
+			recorder.Header().Get("Retry-After"))
+	}
+
+	if status := serve(handler, newRequest("192.168.1.6:1000", "")); status != http.StatusOK {
+		t.Errorf("Wrong status of another client: %d", status)
+	}
+}
+
+func TestIPFilter(t *testing.T) {
+	allow, err := parsePrefixes([]string{"127.0.0.1", "10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("Can't parse prefixes: %s", err)
+	}
+
+	testData := []struct {
+		remoteAddr   string
+		forwardedFor string
+		status       int
+	}{
+		{remoteAddr: "127.0.0.1:1000", status: http.StatusOK},
+		{remoteAddr: "10.1.2.3:1000", status: http.StatusOK},
+		{remoteAddr: "192.168.1.5:1000", status: http.StatusForbidden},
+		{remoteAddr: "192.168.1.5:1000", forwardedFor: "127.0.0.1", status: http.StatusForbidden},
+	}
+
+	handler := ipFilter(allow, okHandler())
+
+	for _, item := range testData {
+		if status := serve(handler, newRequest(item.remoteAddr, item.forwardedFor)); status != item.status {
+			t.Errorf("Wrong status of %s forwarded for %q: %d", item.remoteAddr, item.forwardedFor, status)
+		}
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func newRequest(remoteAddr, forwardedFor string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.RemoteAddr = remoteAddr
+
+	if forwardedFor != "" {
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+
+	return request
+}
+
+func serve(handler http.Handler, request *http.Request) int {
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	return recorder.Code
+}
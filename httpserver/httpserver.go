@@ -0,0 +1,488 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"electrobot/apitoken"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 5 * time.Second
+	bearerPrefix      = "Bearer "
+	tokenQueryParam   = "token"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// TLSConfig TLS configuration, TLS is enabled when both files are set or autocert is configured.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables client certificate verification against this CA bundle.
+	ClientCAFile string
+	// Autocert obtains certificates from Let's Encrypt instead of CertFile and KeyFile.
+	Autocert AutocertConfig
+}
+
+// AutocertConfig Let's Encrypt configuration, enabled when domains are set.
+type AutocertConfig struct {
+	Domains []string
+	Email   string
+	// CacheDir stores account key and certificates between restarts.
+	CacheDir string
+	// ChallengeListen is the address (usually ":80") serving HTTP-01 challenges and redirecting to HTTPS,
+	// empty means only TLS-ALPN-01 challenges on the TLS listeners are used.
+	ChallengeListen string
+}
+
+// FeatureConfig per-feature configuration.
+type FeatureConfig struct {
+	Enabled bool
+	// Listen overrides the server listen address for this feature.
+	Listen string
+	// Auth requires token authentication for the feature routes.
+	Auth bool
+	// AuthToken overrides the server auth token for this feature.
+	AuthToken string
+	// ClientCert requires a client certificate signed by TLS.ClientCAFile.
+	ClientCert bool
+	// AllowIPs restricts the feature to these IPs or CIDRs, empty means no extra restriction.
+	AllowIPs []string
+	// WebhookSecret verifies webhook routes of the feature, overrides the secret managed by admin commands.
+	WebhookSecret string
+	// RateLimit limits requests per minute from a single IP to the feature routes, 0 means only the firewall limit
+	// applies.
+	RateLimit int
+	// CacheTTL serves successful GET responses of the feature routes from memory for this time, 0 disables caching.
+	// Responses are shared by all authorized clients, so it suits features which return the same data to everyone.
+	CacheTTL time.Duration
+}
+
+// Config structure with HTTP server configuration.
+type Config struct {
+	Listen string
+	TLS    TLSConfig
+	// AuthToken is a static token with admin scope.
+	AuthToken string
+	// Tokens provides scoped API tokens, optional.
+	Tokens TokenStore
+	// WebhookSecrets provides webhook secrets by feature name, optional.
+	WebhookSecrets WebhookSecretStore
+	Firewall       FirewallConfig
+	Features       map[string]FeatureConfig
+}
+
+// TokenStore looks up API token scope by token hash.
+type TokenStore interface {
+	GetAPITokenScope(hash string) (scope string, err error)
+}
+
+// Route is a single HTTP route provided by a feature.
+type Route struct {
+	Pattern string
+	Handler http.Handler
+	// Scope is the token scope required by auth-enabled features, empty means read.
+	Scope apitoken.Scope
+	// Webhook routes accept inbound events and are verified with the feature webhook secret.
+	Webhook bool
+}
+
+// certManager obtains TLS certificates from Let's Encrypt.
+type certManager interface {
+	TLSConfig() *tls.Config
+	HTTPHandler(fallback http.Handler) http.Handler
+}
+
+// Server serves all HTTP features on one or more listeners.
+type Server struct {
+	sync.Mutex
+
+	config   Config
+	muxes    map[string]*http.ServeMux
+	servers  []*http.Server
+	autocert certManager
+	replays  replayCache
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates HTTP server, features should be registered before Start.
+func New(config Config) *Server {
+	server := &Server{config: config, muxes: make(map[string]*http.ServeMux)}
+
+	if len(config.TLS.Autocert.Domains) != 0 {
+		server.autocert = newCertManager(config.TLS.Autocert)
+	}
+
+	return server
+}
+
+// Enabled returns true if the feature is enabled in the configuration.
+func (server *Server) Enabled(feature string) bool {
+	return server.config.Features[feature].Enabled
+}
+
+// Register adds feature routes, routes of disabled features are ignored.
+func (server *Server) Register(feature string, routes []Route) error {
+	featureConfig, ok := server.config.Features[feature]
+	if !ok || !featureConfig.Enabled {
+		log.WithField("feature", feature).Debug("HTTP feature is disabled")
+
+		return nil
+	}
+
+	listen := featureConfig.Listen
+	if listen == "" {
+		listen = server.config.Listen
+	}
+
+	if listen == "" {
+		return fmt.Errorf("no listen address for HTTP feature %s", feature)
+	}
+
+	token := featureConfig.AuthToken
+	if token == "" {
+		token = server.config.AuthToken
+	}
+
+	if featureConfig.Auth && token == "" && server.config.Tokens == nil {
+		return fmt.Errorf("HTTP feature %s requires auth but no token is configured", feature)
+	}
+
+	if featureConfig.ClientCert && (!server.tlsEnabled() || server.config.TLS.ClientCAFile == "") {
+		return fmt.Errorf("HTTP feature %s requires client certificate but TLS client CA is not configured", feature)
+	}
+
+	for _, route := range routes {
+		if route.Webhook && featureConfig.WebhookSecret == "" && server.config.WebhookSecrets == nil {
+			return fmt.Errorf("HTTP feature %s has webhooks but no webhook secret is configured", feature)
+		}
+	}
+
+	allowIPs, err := parsePrefixes(featureConfig.AllowIPs)
+	if err != nil {
+		return fmt.Errorf("invalid allow list of HTTP feature %s: %w", feature, err)
+	}
+
+	server.Lock()
+	defer server.Unlock()
+
+	mux, ok := server.muxes[listen]
+	if !ok {
+		mux = http.NewServeMux()
+		server.muxes[listen] = mux
+	}
+
+	var (
+		limiter *rateLimiter
+		cache   *responseCache
+	)
+
+	if featureConfig.RateLimit > 0 {
+		limiter = newRateLimiter(featureConfig.RateLimit)
+	}
+
+	if featureConfig.CacheTTL > 0 {
+		cache = newResponseCache(featureConfig.CacheTTL)
+	}
+
+	for _, route := range routes {
+		handler := route.Handler
+
+		if cache != nil && !route.Webhook {
+			handler = cache.handler(handler)
+		}
+
+		if featureConfig.Auth {
+			handler = server.tokenAuth(token, route.Scope, handler)
+		}
+
+		if route.Webhook {
+			handler = server.webhookAuth(feature, featureConfig.WebhookSecret, handler)
+		}
+
+		if featureConfig.ClientCert {
+			handler = clientCertAuth(handler)
+		}
+
+		if len(allowIPs) != 0 {
+			handler = ipFilter(allowIPs, handler)
+		}
+
+		if limiter != nil {
+			handler = rateLimit(limiter, handler)
+		}
+
+		mux.Handle(route.Pattern, handler)
+	}
+
+	log.WithFields(log.Fields{
+		"feature": feature, "listen": listen, "auth": featureConfig.Auth, "clientCert": featureConfig.ClientCert,
+	}).Info("HTTP feature registered")
+
+	return nil
+}
+
+// Start starts listeners for all registered features.
+func (server *Server) Start() (err error) {
+	server.Lock()
+	defer server.Unlock()
+
+	defer func() {
+		if err != nil {
+			server.closeServers()
+		}
+	}()
+
+	// serving plain HTTP instead of the configured TLS would expose tokens
+	if len(server.config.TLS.Autocert.Domains) != 0 && server.autocert == nil {
+		return errors.New("automatic Let's Encrypt certificates are not included in this build")
+	}
+
+	tlsConfig, err := server.newTLSConfig()
+	if err != nil {
+		return err
+	}
+
+	fw, err := newFirewall(server.config.Firewall)
+	if err != nil {
+		return err
+	}
+
+	for listen, mux := range server.muxes {
+		listener, err := net.Listen("tcp", listen)
+		if err != nil {
+			return err
+		}
+
+		httpServer := &http.Server{
+			Addr: listen, Handler: fw.handler(mux), TLSConfig: tlsConfig, ReadHeaderTimeout: readHeaderTimeout,
+			MaxHeaderBytes: defaultMaxHeaderBytes,
+		}
+		server.servers = append(server.servers, httpServer)
+
+		go server.serve(httpServer, listener)
+	}
+
+	if server.autocert != nil && server.config.TLS.Autocert.ChallengeListen != "" {
+		if err = server.startChallengeServer(server.config.TLS.Autocert.ChallengeListen); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close stops all listeners.
+func (server *Server) Close() {
+	server.Lock()
+	defer server.Unlock()
+
+	server.closeServers()
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (server *Server) serve(httpServer *http.Server, listener net.Listener) {
+	log.WithFields(log.Fields{"listen": httpServer.Addr, "tls": server.tlsEnabled()}).Info("Starting HTTP server")
+
+	var err error
+
+	switch {
+	case server.autocert != nil:
+		// certificates are provided by the TLS config
+		err = httpServer.ServeTLS(listener, "", "")
+
+	case server.tlsEnabled():
+		err = httpServer.ServeTLS(listener, server.config.TLS.CertFile, server.config.TLS.KeyFile)
+
+	default:
+		err = httpServer.Serve(listener)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.WithField("listen", httpServer.Addr).Errorf("HTTP server failed: %s", err)
+	}
+}
+
+// startChallengeServer serves ACME HTTP-01 challenges, other requests are redirected to HTTPS.
+func (server *Server) startChallengeServer(listen string) error {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Addr: listen, Handler: server.autocert.HTTPHandler(nil), ReadHeaderTimeout: readHeaderTimeout,
+		MaxHeaderBytes: defaultMaxHeaderBytes,
+	}
+	server.servers = append(server.servers, httpServer)
+
+	log.WithField("listen", listen).Info("Starting ACME challenge server")
+
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithField("listen", listen).Errorf("ACME challenge server failed: %s", err)
+		}
+	}()
+
+	return nil
+}
+
+func (server *Server) tlsEnabled() bool {
+	return server.autocert != nil || (server.config.TLS.CertFile != "" && server.config.TLS.KeyFile != "")
+}
+
+// newTLSConfig returns autocert TLS config if enabled, verifying client certificates if client CA is configured.
+func (server *Server) newTLSConfig() (tlsConfig *tls.Config, err error) {
+	if server.autocert != nil {
+		tlsConfig = server.autocert.TLSConfig()
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+
+	if !server.tlsEnabled() || server.config.TLS.ClientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	data, err := os.ReadFile(server.config.TLS.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", server.config.TLS.ClientCAFile)
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	// certificates are verified if given, features requiring them reject requests without one
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+
+	return tlsConfig, nil
+}
+
+func (server *Server) closeServers() {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelFunc()
+
+	for _, httpServer := range server.servers {
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.WithField("listen", httpServer.Addr).Errorf("Failed to stop HTTP server: %s", err)
+		}
+	}
+
+	server.servers = nil
+}
+
+// tokenAuth accepts the token as "Authorization: Bearer <token>" header or "token" query parameter.
+// The static token has admin scope, stored API tokens have their own scope.
+func (server *Server) tokenAuth(token string, required apitoken.Scope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := r.URL.Query().Get(tokenQueryParam)
+
+		if header := r.Header.Get("Authorization"); strings.HasPrefix(header, bearerPrefix) {
+			provided = strings.TrimPrefix(header, bearerPrefix)
+		}
+
+		scope, ok := server.tokenScope(token, provided)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+			return
+		}
+
+		if !scope.Allows(required) {
+			log.WithFields(log.Fields{"path": r.URL.Path, "scope": scope}).Warn("API token scope is not sufficient")
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (server *Server) tokenScope(token, provided string) (scope apitoken.Scope, ok bool) {
+	if provided == "" {
+		return "", false
+	}
+
+	if token != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1 {
+		return apitoken.ScopeAdmin, true
+	}
+
+	if server.config.Tokens == nil {
+		return "", false
+	}
+
+	name, err := server.config.Tokens.GetAPITokenScope(apitoken.Hash(provided))
+	if err != nil {
+		return "", false
+	}
+
+	if scope, err = apitoken.ParseScope(name); err != nil {
+		log.Errorf("Invalid stored API token scope: %s", err)
+
+		return "", false
+	}
+
+	return scope, true
+}
+
+// clientCertAuth rejects requests without a verified client certificate.
+func clientCertAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	// SignatureHeader carries hex HMAC-SHA256 of the timestamp and the webhook request body prefixed with "sha256=".
+	SignatureHeader = "X-Electrobot-Signature"
+	// TimestampHeader carries the Unix time the webhook request was signed at.
+	TimestampHeader = "X-Electrobot-Timestamp"
+	signaturePrefix = "sha256="
+	// replayWindow bounds the signature timestamp skew, signatures seen within the window are rejected as replays.
+	replayWindow = 5 * time.Minute
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// replayCache remembers signatures of accepted webhook requests until they leave the replay window.
+type replayCache struct {
+	sync.Mutex
+
+	seen map[string]time.Time
+}
+
+// WebhookSecretStore looks up admin-managed webhook secrets by source name.
+type WebhookSecretStore interface {
+	GetWebhookSecret(source string) (secret string, err error)
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Sign returns the signature header value of the body sent with the timestamp header value.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// webhookAuth verifies webhook requests of the source by the timestamped body signature, signed requests older than
+// the replay window or seen before are rejected. Senders which can't sign requests may pass the secret as a bearer
+// token instead, they must send the timestamp header too and are checked for replays like signed ones. The configured
+// secret takes precedence over the one managed by admin commands.
+func (server *Server) webhookAuth(source, configured string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := configured
+
+		if secret == "" && server.config.WebhookSecrets != nil {
+			secret, _ = server.config.WebhookSecrets.GetWebhookSecret(source)
+		}
+
+		if secret == "" {
+			log.WithField("source", source).Warn("Webhook secret is not set, request rejected")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !server.verifyWebhook(r, secret, body, time.Now()) {
+			log.WithFields(log.Fields{"source": source, "remoteAddr": r.RemoteAddr}).Warn("Webhook verification failed")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (server *Server) verifyWebhook(r *http.Request, secret string, body []byte, now time.Time) bool {
+	timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	if skew := now.Sub(time.Unix(timestamp, 0)); skew > replayWindow || skew < -replayWindow {
+		return false
+	}
+
+	expected := Sign(secret, timestamp, body)
+
+	if signature := r.Header.Get(SignatureHeader); signature != "" {
+		if !hmac.Equal([]byte(signature), []byte(expected)) {
+			return false
+		}
+	} else {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, bearerPrefix) ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, bearerPrefix)), []byte(secret)) != 1 {
+			return false
+		}
+	}
+
+	// bearer requests are remembered by the signature they would have, so a replay is detected either way
+	return server.replays.add(expected, now)
+}
+
+// add remembers the signature, returns false if it has been seen within the replay window.
+func (cache *replayCache) add(signature string, now time.Time) bool {
+	cache.Lock()
+	defer cache.Unlock()
+
+	if cache.seen == nil {
+		cache.seen = make(map[string]time.Time)
+	}
+
+	for seen, at := range cache.seen {
+		// timestamps are checked against the window in both directions, so a signature may be replayed until
+		// twice the window after it has been accepted
+		if now.Sub(at) > 2*replayWindow {
+			delete(cache.seen, seen)
+		}
+	}
+
+	if _, ok := cache.seen[signature]; ok {
+		return false
+	}
+
+	cache.seen[signature] = now
+
+	return true
+}
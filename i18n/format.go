@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// pluralSeparator separates plural forms in source texts and translations.
+const pluralSeparator = "|"
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// locale describes language specific formatting.
+type locale struct {
+	dateTimeFormat     string
+	dateFormat         string
+	thousandsSeparator string
+	// pluralForm returns the index of the plural form for count.
+	pluralForm func(count int) int
+}
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//nolint:gochecknoglobals
+var locales = map[string]locale{
+	English: {
+		dateTimeFormat:     "2006-01-02 15:04:05",
+		dateFormat:         "2006-01-02",
+		thousandsSeparator: ",",
+		pluralForm:         englishPluralForm,
+	},
+	Ukrainian: {
+		dateTimeFormat:     "02.01.2006 15:04:05",
+		dateFormat:         "02.01.2006",
+		thousandsSeparator: " ",
+		pluralForm:         ukrainianPluralForm,
+	},
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// N translates English source text with plural forms separated by "|" ("%d outage|%d outages"), picks the form
+// matching count by the language plural rules and formats it with args. Without args count itself is formatted.
+func N(language string, count int, text string, args ...interface{}) string {
+	if translation, ok := catalogs[language][text]; ok {
+		text = translation
+	} else {
+		language = English
+	}
+
+	forms := strings.Split(text, pluralSeparator)
+	text = forms[min(getLocale(language).pluralForm(count), len(forms)-1)]
+
+	if len(args) == 0 {
+		args = []interface{}{count}
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+// Number formats integer with the language thousands separator.
+func Number(language string, number int) string {
+	digits := strconv.Itoa(number)
+
+	sign := ""
+	if number < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var result strings.Builder
+
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			result.WriteString(getLocale(language).thousandsSeparator)
+		}
+
+		result.WriteRune(digit)
+	}
+
+	return sign + result.String()
+}
+
+// DateTime formats time in the language date and time format.
+func DateTime(language string, t time.Time) string {
+	return t.Format(getLocale(language).dateTimeFormat)
+}
+
+// Date formats date in the language date format.
+func Date(language string, t time.Time) string {
+	return t.Format(getLocale(language).dateFormat)
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func getLocale(language string) locale {
+	if found, ok := locales[language]; ok {
+		return found
+	}
+
+	return locales[English]
+}
+
+// englishPluralForm returns 0 for one and 1 for other.
+func englishPluralForm(count int) int {
+	if count == 1 {
+		return 0
+	}
+
+	return 1
+}
+
+// ukrainianPluralForm returns 0 for one (1, 21, 31), 1 for few (2-4, 22-24) and 2 for many (0, 5-20, 25-30).
+func ukrainianPluralForm(count int) int {
+	if count < 0 {
+		count = -count
+	}
+
+	switch {
+	case count%10 == 1 && count%100 != 11:
+		return 0
+
+	case count%10 >= 2 && count%10 <= 4 && (count%100 < 12 || count%100 > 14):
+		return 1
+
+	default:
+		return 2
+	}
+}
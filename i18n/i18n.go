@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Supported languages.
+const (
+	English   = "en"
+	Ukrainian = "uk"
+)
+
+// DefaultLanguage is used when user language is unknown.
+const DefaultLanguage = Ukrainian
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Language describes supported language.
+type Language struct {
+	Code string
+	Name string
+}
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//nolint:gochecknoglobals
+var languages = []Language{
+	{Code: Ukrainian, Name: "Українська"},
+	{Code: English, Name: "English"},
+}
+
+// catalogs maps English source text to its translation, English itself needs no catalog.
+//
+//nolint:gochecknoglobals
+var catalogs = map[string]map[string]string{
+	Ukrainian: ukrainian,
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// T translates English source text to the language and formats it with args.
+// Untranslated texts and unknown languages fall back to English.
+func T(language, text string, args ...interface{}) string {
+	if translation, ok := catalogs[language][text]; ok {
+		text = translation
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+// Languages returns supported languages.
+func Languages() []Language {
+	return languages
+}
+
+// IsSupported returns true if the language is supported.
+func IsSupported(language string) bool {
+	for _, supported := range languages {
+		if supported.Code == language {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match returns supported language for Telegram language code like "uk" or "en-US", empty string if not supported.
+func Match(languageCode string) string {
+	code, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+
+	if IsSupported(code) {
+		return code
+	}
+
+	return ""
+}
@@ -0,0 +1,492 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//nolint:gochecknoglobals,lll
+var ukrainian = map[string]string{
+	// commands
+	"Type /start to get started":                                                        "Надішліть /start, щоб почати",
+	"Type /stop to stop receiving notifications":                                        "Надішліть /stop, щоб більше не отримувати сповіщень",
+	"Type /lastshutdown to get the last shutdown time":                                  "Надішліть /lastshutdown, щоб дізнатися час останнього відключення",
+	"Type /history [N] to get the last N outages":                                       "Надішліть /history [N], щоб переглянути останні N відключень",
+	"Type /stats to get outage statistics":                                              "Надішліть /stats, щоб переглянути статистику відключень",
+	"Type /remindme <task> to be reminded about it when power returns":                  "Надішліть /remindme <завдання>, щоб отримати нагадування, коли повернеться світло",
+	"Type /language to change the language":                                             "Надішліть /language, щоб змінити мову",
+	"Type /health to get the bot subsystems state":                                      "Надішліть /health, щоб переглянути стан підсистем бота",
+	"Type /timezone <zone> to change the timezone":                                      "Надішліть /timezone <пояс>, щоб змінити часовий пояс",
+	"Type /plaintext on|off to get messages without emoji and buttons":                  "Надішліть /plaintext on|off, щоб отримувати повідомлення без емодзі та кнопок",
+	"Last shutdown time is %s":                                                          "Останнє відключення: %s",
+	"You're already registered":                                                         "Ви вже зареєстровані",
+	"Failed to register you. Please try again later":                                    "Не вдалося вас зареєструвати. Спробуйте пізніше",
+	"You've been successfully registered":                                               "Вас успішно зареєстровано",
+	"Failed to unregister you. Please try again later":                                  "Не вдалося скасувати реєстрацію. Спробуйте пізніше",
+	"You've been successfully unregistered":                                             "Реєстрацію успішно скасовано",
+	"Health information is not available":                                               "Інформація про стан недоступна",
+	"Subsystems state:":                                                                 "Стан підсистем:",
+	"Choose your language:":                                                             "Оберіть мову:",
+	"Language has been changed":                                                         "Мову змінено",
+	"Please register with /start first":                                                 "Спочатку зареєструйтеся за допомогою /start",
+	"Failed to change language. Please try again later":                                 "Не вдалося змінити мову. Спробуйте пізніше",
+	"Unknown language":                                                                  "Невідома мова",
+	"This bot already has an owner":                                                     "У цього бота вже є власник",
+	"Invalid claim code":                                                                "Невірний код",
+	"Ownership can be claimed in a private chat with the bot only":                      "Стати власником можна лише в особистому чаті з ботом",
+	"Too many invalid claim attempts, try again in %s":                                  "Забагато невдалих спроб, спробуйте через %s",
+	"Failed to claim ownership. Please try again later":                                 "Не вдалося отримати права власника. Спробуйте пізніше",
+	"You are now the owner of this bot":                                                 "Тепер ви власник цього бота",
+	"Setup is available to the bot owner in a private chat with the bot only":           "Налаштування доступне лише власнику бота в особистому чаті з ботом",
+	"Let's set up the bot. Choose the region to import the planned outage schedule of:": "Налаштуймо бота. Оберіть регіон, графік планових відключень якого слід завантажувати:",
+	"Choose your outage schedule group in %s:":                                          "Оберіть свою чергу відключень (%s):",
+	"Planned schedule of group %s in %s will be imported":                               "Буде завантажуватися плановий графік черги %s (%s)",
+	"Admin settings:":                                                                   "Налаштування адміністратора:",
+	"Low-bandwidth mode: %s":                                                            "Режим економії трафіку: %s",
+	"Yearly report to all users: %s":                                                    "Річний звіт усім користувачам: %s",
+	"Low-bandwidth mode":                                                                "Режим економії трафіку",
+	"Yearly report":                                                                     "Річний звіт",
+	"Turn on: %s":                                                                       "Увімкнути: %s",
+	"Turn off: %s":                                                                      "Вимкнути: %s",
+	"on":                                                                                "увімкнено",
+	"off":                                                                               "вимкнено",
+	"Skip":                                                                              "Пропустити",
+	"Done":                                                                              "Готово",
+	"Kyiv":                                                                              "Київ",
+	"Dnipro":                                                                            "Дніпро",
+	"Failed to change settings. Please try again later":                                 "Не вдалося змінити налаштування. Спробуйте пізніше",
+	"Setup is complete. Use /setup to change these settings later":                      "Налаштування завершено. Змінити їх можна командою /setup",
+	"Usage: /history [N], where N is a positive number of outages":                      "Використання: /history [N], де N — кількість відключень (додатне число)",
+	"Failed to get outage history. Please try again later":                              "Не вдалося отримати історію відключень. Спробуйте пізніше",
+	"No outages recorded yet":                                                           "Відключень ще не зафіксовано",
+	"Last %d outage:|Last %d outages:":                                                  "Останні %d відключення:|Останні %d відключення:|Останні %d відключень:",
+	"Outage statistics:":                                                                "Статистика відключень:",
+	"Failed to get outage statistics. Please try again later":                           "Не вдалося отримати статистику відключень. Спробуйте пізніше",
+	"Today":      "Сьогодні",
+	"This week":  "Цього тижня",
+	"This month": "Цього місяця",
+	"No outages": "Відключень не було",
+	"Outages: %d\nTotal: %s\nLongest: %s\nAverage: %s":                                   "Відключень: %d\nЗагалом: %s\nНайдовше: %s\nВ середньому: %s",
+	"Please specify what to remind you about":                                            "Вкажіть, про що вам нагадати",
+	"Reminder is too long, please keep it under %s":                                      "Нагадування задовге, будь ласка, вкладіться в %s",
+	"Failed to add reminder. Please try again later":                                     "Не вдалося додати нагадування. Спробуйте пізніше",
+	"You can't have more than %d reminder|You can't have more than %d reminders":         "Не можна мати більше ніж %d нагадування|Не можна мати більше ніж %d нагадування|Не можна мати більше ніж %d нагадувань",
+	"Reminder #%d added, I'll remind you when power returns":                             "Нагадування #%d додано, я нагадаю, коли повернеться світло",
+	"Failed to get reminders. Please try again later":                                    "Не вдалося отримати нагадування. Спробуйте пізніше",
+	"You have no reminders":                                                              "У вас немає нагадувань",
+	"Your reminders:":                                                                    "Ваші нагадування:",
+	"Usage: /remindme cancel <id>":                                                       "Використання: /remindme cancel <id>",
+	"Reminder #%d not found":                                                             "Нагадування #%d не знайдено",
+	"Reminder #%d cancelled":                                                             "Нагадування #%d скасовано",
+	"Your timezone is %s, local time is %s\nUsage: /timezone <zone>, e.g. /timezone %s":  "Ваш часовий пояс: %s, місцевий час: %s\nВикористання: /timezone <пояс>, наприклад /timezone %s",
+	"Unknown timezone %q, use a name like %s":                                            "Невідомий часовий пояс %q, вкажіть назву на кшталт %s",
+	"Failed to change timezone. Please try again later":                                  "Не вдалося змінити часовий пояс. Спробуйте пізніше",
+	"Timezone has been set to %s, local time is %s":                                      "Часовий пояс змінено на %s, місцевий час: %s",
+	"Usage: /plaintext on|off, plain text mode sends messages without emoji and buttons": "Використання: /plaintext on|off, у текстовому режимі повідомлення надходять без емодзі та кнопок",
+	"Failed to change plain text mode. Please try again later":                           "Не вдалося змінити текстовий режим. Спробуйте пізніше",
+	"Plain text mode is on, use commands from /help instead of buttons":                  "Текстовий режим увімкнено, замість кнопок використовуйте команди з /help",
+	"Plain text mode is off":                                                             "Текстовий режим вимкнено",
+	"Type /locations to choose monitored locations":                                      "Надішліть /locations, щоб обрати локації для сповіщень",
+	"Failed to get locations. Please try again later":                                    "Не вдалося отримати локації. Спробуйте пізніше",
+	"Monitored locations:":                                                               "Локації під наглядом:",
+	"%s (subscribed)":                                                                    "%s (ви підписані)",
+	"Use /subscribe <location> and /unsubscribe <location> to choose locations":          "Використовуйте /subscribe <локація> та /unsubscribe <локація>, щоб обрати локації",
+	"Unknown location %q, see /locations":                                                "Невідома локація %q, див. /locations",
+	"Failed to change subscription. Please try again later":                              "Не вдалося змінити підписку. Спробуйте пізніше",
+	"You're subscribed to %s":                                                            "Ви підписалися на %s",
+	"You're unsubscribed from %s":                                                        "Ви відписалися від %s",
+	"Sorry, this command is available to admins only":                                    "Вибачте, ця команда доступна лише адміністраторам",
+	"Admin commands are available in a private chat with the bot only":                   "Команди адміністратора доступні лише в особистому чаті з ботом",
+	"Admin commands:":                                                                    "Команди адміністратора:",
+	"/users - list registered users":                                                     "/users - список зареєстрованих користувачів",
+	"/broadcast [#tag] <text> - send an announcement to all or tagged users":             "/broadcast [#тег] <текст> - надіслати оголошення всім користувачам або користувачам з тегом",
+	"/dbstats - show database statistics":                                                "/dbstats - статистика бази даних",
+	"/token - manage API tokens":                                                         "/token - керування API-токенами",
+	"/webhook - manage inbound webhook secrets":                                          "/webhook - керування секретами вхідних вебхуків",
+	"/sensors - manage heartbeat sensors":                                                "/sensors - керування heartbeat-датчиками",
+	"🪫 Sensor %s battery is low: %d%%. Charge or replace it, otherwise its silence will be reported as a power outage": "🪫 Низький заряд батареї датчика %s: %d%%. Зарядіть або замініть її, інакше його мовчання буде сприйнято як відключення світла",
+	"Failed to get sensors. Please try again later":                                                                    "Не вдалося отримати датчики. Спробуйте пізніше",
+	"There are no sensors, register one with /sensors add <name>":                                                      "Датчиків немає, додайте датчик командою /sensors add <назва>",
+	"Usage:\n/sensors - list sensors\n/sensors add <name> - register a sensor and get its token\n/sensors rename <name> <new name> - rename sensor\n/sensors token <name> - replace the sensor token\n/sensors phase <name> <location> <A|B|C> - the sensor monitors a phase of the location\n/sensors phase <name> none - the sensor monitors its own location\n/sensors pause <name> - ignore the sensor heartbeats\n/sensors resume <name> - follow the sensor heartbeats again\n/sensors delete <name> - delete the sensor, its location is kept": "Використання:\n/sensors - список датчиків\n/sensors add <назва> - додати датчик і отримати його токен\n/sensors rename <назва> <нова назва> - перейменувати датчик\n/sensors token <назва> - замінити токен датчика\n/sensors phase <назва> <локація> <A|B|C> - датчик стежить за фазою локації\n/sensors phase <назва> none - датчик стежить за власною локацією\n/sensors pause <назва> - ігнорувати heartbeat датчика\n/sensors resume <назва> - знову стежити за heartbeat датчика\n/sensors delete <назва> - видалити датчик, локація залишається",
+	"Unknown sensor %q, see /sensors": "Невідомий датчик %q, див. /sensors",
+	"Sensor %q deleted, its token stopped working. The location is kept, remove it with /location remove": "Датчик %q видалено, його токен більше не діє. Локація залишається, видаліть її командою /location remove",
+	"Sensor %q already exists, replace its token with /sensors token %s":                                  "Датчик %q вже існує, замініть його токен командою /sensors token %s",
+	"Failed to add sensor %q. Please try again later":                                                     "Не вдалося додати датчик %q. Спробуйте пізніше",
+	"Failed to rename sensor %q, the new name is taken":                                                   "Не вдалося перейменувати датчик %q, нова назва вже зайнята",
+	"Sensor %q renamed to %q":                                                                             "Датчик %q перейменовано на %q",
+	"Sensor %q paused, its heartbeats and silence don't change the power state":                           "Датчик %q призупинено, його heartbeat і мовчання не змінюють стан світла",
+	"Sensor %q resumed, the power state follows its next heartbeat":                                       "Датчик %q відновлено, стан світла залежатиме від його наступного heartbeat",
+	"paused":                                 "призупинено",
+	"Sensors (%d):":                          "Датчики (%d):",
+	"no heartbeats yet":                      "heartbeat ще не надходив",
+	"last heartbeat %s":                      "останній heartbeat %s",
+	"no power since %s":                      "без світла з %s",
+	"battery %s":                             "батарея %s",
+	"signal %d dBm":                          "сигнал %d dBm",
+	"/location - manage monitored locations": "/location - керування локаціями",
+	"Location name is too long, please keep it under %s":       "Назва локації задовга, будь ласка, вкладіться в %s",
+	"Failed to add location %q, the name may be taken already": "Не вдалося додати локацію %q, можливо, назва вже зайнята",
+	"Location %q added": "Локацію %q додано",
+	"Failed to remove location %q, it doesn't exist or is the main location": "Не вдалося видалити локацію %q, її не існує або це основна локація",
+	"Location %q removed": "Локацію %q видалено",
+	"Failed to rename location %q, it doesn't exist or the new name is taken": "Не вдалося перейменувати локацію %q, її не існує або нова назва зайнята",
+	"Location %q renamed to %q": "Локацію %q перейменовано на %q",
+	"Usage:\n/location add <name> - add location\n/location remove <name> - remove location\n/location rename <name> <new name> - rename location\n/location token <name> - generate a new heartbeat token of the location\n/location skew <name> <duration> - set the heartbeat clock skew of the location, 0 for default\n/location parent <name> <building> - make the location a part of the building, none to detach it": "Використання:\n/location add <назва> - додати локацію\n/location remove <назва> - видалити локацію\n/location rename <назва> <нова назва> - перейменувати локацію\n/location token <назва> - створити новий heartbeat-токен локації\n/location skew <назва> <тривалість> - задати допустиме розходження годинника heartbeat-пристрою локації, 0 - типове\n/location parent <назва> <будинок> - зробити локацію частиною будинку, none - відокремити її",
+	"Wrong clock skew %q, use a duration up to %s, e.g. 30s": "Неправильне розходження годинника %q, вкажіть тривалість до %s, наприклад 30s",
+	"Heartbeat clock skew of %q reset to default":            "Розходження годинника heartbeat-пристрою %q скинуто до типового",
+	"Heartbeat clock skew of %q set to %s":                   "Розходження годинника heartbeat-пристрою %q: %s",
+	"Failed to set heartbeat token. Please try again later":  "Не вдалося задати heartbeat-токен. Спробуйте пізніше",
+	"Heartbeat token of %q set, the previous one stopped working. A device on the premises should send POST %s every minute. Delete this message after saving the token": "Heartbeat-токен %q задано, попередній більше не діє. Пристрій на об'єкті має надсилати POST %s щохвилини. Видаліть це повідомлення після збереження токена",
+	"/backup [force] - send a database backup": "/backup [force] - надіслати резервну копію бази даних",
+	"/setup - change the bot setup":            "/setup - змінити налаштування бота",
+	"The bot is on a backup uplink, the backup is not sent to save traffic. Use /backup force to send it anyway": "Бот працює через резервний канал зв'язку, резервну копію не надіслано для економії трафіку. Використайте /backup force, щоб надіслати її все одно",
+	"Backup started":    "Резервне копіювання розпочато",
+	"Backup failed: %s": "Не вдалося створити резервну копію: %s",
+	"Failed to get users. Please try again later":               "Не вдалося отримати список користувачів. Спробуйте пізніше",
+	"There are no registered users":                             "Зареєстрованих користувачів немає",
+	"Registered users (%d):":                                    "Зареєстровані користувачі (%d):",
+	"Usage: /broadcast [#tag] <text>":                           "Використання: /broadcast [#тег] <текст>",
+	"Broadcast finished: %d delivered, %d queued, %d failed":    "Розсилку завершено: доставлено %d, у черзі %d, не доставлено %d",
+	"Broadcast started":                                         "Розсилку розпочато",
+	"Failed to get database statistics. Please try again later": "Не вдалося отримати статистику бази даних. Спробуйте пізніше",
+	"Database statistics:\nSchema version: %d\nUsers: %s\nEvents: %s\nReminders: %s\nAPI tokens: %s\nSize: %s KiB": "Статистика бази даних:\nВерсія схеми: %d\nКористувачі: %s\nПодії: %s\nНагадування: %s\nAPI-токени: %s\nРозмір: %s КіБ",
+	"Failed to get API tokens. Please try again later":                                                             "Не вдалося отримати API-токени. Спробуйте пізніше",
+	"There are no API tokens":                                      "API-токенів немає",
+	"API tokens:":                                                  "API-токени:",
+	"Unknown token scope %q, use read or admin":                    "Невідома область доступу %q, використовуйте read або admin",
+	"Token name is too long, please keep it under %s":              "Назва токена задовга, будь ласка, вкладіться в %s",
+	"Failed to create API token. Please try again later":           "Не вдалося створити API-токен. Спробуйте пізніше",
+	"Failed to create API token %q, the name may be taken already": "Не вдалося створити API-токен %q, можливо, ця назва вже зайнята",
+	"API token %q (%s) created:\n%s\nIt is shown only once, delete this message after saving it": "API-токен %q (%s) створено:\n%s\nВін показується лише один раз, видаліть це повідомлення після збереження",
+	"API token %q not found": "API-токен %q не знайдено",
+	"API token %q revoked":   "API-токен %q відкликано",
+	"Usage:\n/token list - show API tokens\n/token add <name> [read|admin] - create API token\n/token revoke <name> - revoke API token":                                                  "Використання:\n/token list - показати API-токени\n/token add <назва> [read|admin] - створити API-токен\n/token revoke <назва> - відкликати API-токен",
+	"Usage:\n/webhook list - show webhook sources with secrets\n/webhook set <source> - generate a new secret of the source\n/webhook remove <source> - remove the secret of the source": "Використання:\n/webhook list - показати джерела вебхуків із секретами\n/webhook set <джерело> - згенерувати новий секрет джерела\n/webhook remove <джерело> - видалити секрет джерела",
+	"Failed to get webhook secrets. Please try again later": "Не вдалося отримати секрети вебхуків. Спробуйте пізніше",
+	"There are no webhook secrets":                          "Секретів вебхуків немає",
+	"Webhook secrets:":                                      "Секрети вебхуків:",
+	"Source name is too long, please keep it under %s":      "Назва джерела задовга, використовуйте не більше %s",
+	"Failed to set webhook secret. Please try again later":  "Не вдалося задати секрет вебхука. Спробуйте пізніше",
+	"Webhook secret of %q set:\n%s\nSign request bodies with HMAC-SHA256 in the %s header or pass the secret as a bearer token. Delete this message after saving the secret": "Секрет вебхука %q задано:\n%s\nПідписуйте тіло запиту HMAC-SHA256 у заголовку %s або передавайте секрет як bearer-токен. Видаліть це повідомлення після збереження секрету",
+	"Webhook secret of %q not found": "Секрет вебхука %q не знайдено",
+	"Webhook secret of %q removed":   "Секрет вебхука %q видалено",
+	"Usage:\n/remindme <task> when power returns\n/remindme before <minutes> <task> - remind before each planned outage\n/remindme list - show your reminders\n/remindme cancel <id> - cancel a reminder": "Використання:\n/remindme <завдання> коли повернеться світло\n/remindme before <хвилини> <завдання> - нагадувати перед кожним плановим відключенням\n/remindme list - показати ваші нагадування\n/remindme cancel <id> - скасувати нагадування",
+	"Type /schedule to get the outage schedule":                             "Надішліть /schedule, щоб переглянути графік відключень",
+	"/schedule set|except|history - edit the outage schedule":               "/schedule set|except|history - редагування графіка відключень",
+	"Failed to get outage schedule. Please try again later":                 "Не вдалося отримати графік відключень. Спробуйте пізніше",
+	"Outage schedule is not set":                                            "Графік відключень не задано",
+	"Outage schedule:":                                                      "Графік відключень:",
+	"Planned by the grid operator:":                                         "Планові відключення від оператора мережі:",
+	"Group %s:":                                                             "Черга %s:",
+	"Usage: /schedule history [N], where N is a positive number of changes": "Використання: /schedule history [N], де N — кількість змін (додатне число)",
+	"Group name is too long, please keep it under %s":                       "Назва черги задовга, будь ласка, вкладіться в %s",
+	"Unknown weekday %q, use mon-sun or 1-7":                                "Невідомий день тижня %q, використовуйте mon-sun або 1-7",
+	"Invalid outage windows: %s":                                            "Некоректні інтервали відключень: %s",
+	"Failed to change outage schedule. Please try again later":              "Не вдалося змінити графік відключень. Спробуйте пізніше",
+	"Schedule version %d: group %s has no outages on %s":                    "Версія графіка %d: у черги %s немає відключень у %s",
+	"Schedule version %d: group %s on %s: %s":                               "Версія графіка %d: черга %s, %s: %s",
+	"Failed to get schedule changes. Please try again later":                "Не вдалося отримати зміни графіка. Спробуйте пізніше",
+	"Outage schedule has never been changed":                                "Графік відключень ще не змінювався",
+	"Schedule changes:":                                                     "Зміни графіка:",
+	"no outages":                                                            "без відключень",
+	"Usage:\n/schedule - show outage schedule\n/schedule set <group> <weekday> <HH:MM-HH:MM,...|off> - set outage windows\n/schedule except <YYYY-MM-DD> <group|*> <HH:MM-HH:MM,...|off|normal> [note] - override a date\n/schedule history [N] - show last schedule changes": "Використання:\n/schedule - показати графік відключень\n/schedule set <черга> <день> <ГГ:ХХ-ГГ:ХХ,...|off> - задати інтервали відключень\n/schedule except <РРРР-ММ-ДД> <черга|*> <ГГ:ХХ-ГГ:ХХ,...|off|normal> [примітка] - змінити графік на дату\n/schedule history [N] - показати останні зміни графіка",
+	"Exceptions:":                     "Винятки:",
+	"all groups":                      "усі черги",
+	"Invalid date %q, use YYYY-MM-DD": "Некоректна дата %q, використовуйте РРРР-ММ-ДД",
+	"Note is too long, please keep it under %s":       "Примітка задовга, будь ласка, вкладіться в %s",
+	"Weekly schedule restored for group %s on %s":     "Для черги %s на %s відновлено тижневий графік",
+	"Schedule exception for group %s on %s: %s":       "Виняток у графіку для черги %s на %s: %s",
+	"Failed to recognize the schedule: %s":            "Не вдалося розпізнати графік: %s",
+	"The recognized schedule matches the current one": "Розпізнаний графік збігається з поточним",
+	"Apply":                        "Застосувати",
+	"Cancel":                       "Скасувати",
+	"Recognized schedule changes:": "Розпізнані зміни графіка:",
+	"Days not listed are left unchanged. Apply the changes?": "Дні, яких немає в списку, залишаться без змін. Застосувати зміни?",
+	"There is no schedule import to confirm":                 "Немає імпорту графіка для підтвердження",
+	"Schedule import cancelled":                              "Імпорт графіка скасовано",
+	"Schedule import applied, %s changed":                    "Імпорт графіка застосовано, змінено: %s",
+	"Type /menu to open the menu":                            "Надішліть /menu, щоб відкрити меню",
+	"Status":                                                 "Стан",
+	"History":                                                "Історія",
+	"Stats":                                                  "Статистика",
+	"Schedule":                                               "Графік",
+	"Settings":                                               "Налаштування",
+	"Language":                                               "Мова",
+	"Back":                                                   "Назад",
+	"Choose an option:":                                      "Оберіть дію:",
+	"Settings:\nUse /timezone <zone> to change the timezone": "Налаштування:\nЩоб змінити часовий пояс, надішліть /timezone <пояс>",
+	"Type /status to get the current power state":            "Надішліть /status, щоб дізнатися поточний стан світла",
+	"🤖 Bot uptime: %s":                                       "🤖 Бот працює: %s",
+	"⚪ Power state is not detected yet":                      "⚪ Стан світла ще не визначено",
+	"🟢 Power is on":                                          "🟢 Світло є",
+	"📡 Uplink: %s":                                           "📡 Канал зв'язку: %s",
+	"📡 Uplink: %s (backup)":                                  "📡 Канал зв'язку: %s (резервний)",
+	"⚠️ Uplink failed over from %s to backup %s, low-bandwidth mode is on": "⚠️ Канал зв'язку перемкнувся з %s на резервний %s, увімкнено режим економії трафіку",
+	"✅ Uplink is back on %s":   "✅ Канал зв'язку знову %s",
+	"🩺 Self-test summary:\n%s": "🩺 Результати самоперевірки:\n%s",
+	"DEGRADED %s: %s":          "ЗБІЙ %s: %s",
+	"OK %s":                    "ОК %s",
+	"🔴 Power is off":           "🔴 Світла немає",
+	"⏱ For %s, since %s":       "⏱ Вже %s, з %s",
+	"🔄 Last check: %s":         "🔄 Остання перевірка: %s",
+	"Type /report [year] to get the yearly outage report":    "Надішліть /report [рік], щоб отримати річний звіт про відключення",
+	"Usage: /report [year], where year is between %d and %d": "Використання: /report [рік], де рік від %d до %d",
+	"Failed to build the report. Please try again later":     "Не вдалося сформувати звіт. Спробуйте пізніше",
+	"No outages recorded in %d":                              "У %d році відключень не зафіксовано",
+	"📅 Outage report for %d":                                 "📅 Звіт про відключення за %d рік",
+	"Without power: %s, outages: %d":                         "Без світла: %s, відключень: %d",
+	"(%s vs %d)":                                             "(%s порівняно з %d)",
+	"🏆 Records:":                                             "🏆 Рекорди:",
+	"Type /chart [week|month] to get the outage chart":       "Надішліть /chart [week|month], щоб отримати графік відключень",
+	"Hours without power in the last 7 days":                 "Години без світла за останні 7 днів",
+	"Hours without power in the last 30 days":                "Години без світла за останні 30 днів",
+	"Usage: /chart [week|month]":                             "Використання: /chart [week|month]",
+	"Failed to build the chart. Please try again later":      "Не вдалося побудувати графік. Спробуйте пізніше",
+	"Total: %s": "Загалом: %s",
+	"Details":   "Докладніше",
+	"Outage not found, it may have been archived":           "Відключення не знайдено, можливо, його вже архівовано",
+	"Outage details:\nStarted: %s\nEnded: %s\nDuration: %s": "Подробиці відключення:\nПочаток: %s\nКінець: %s\nТривалість: %s",
+	"Detected by: bot heartbeat, accurate to %s":            "Виявлено: за сигналом роботи бота, з точністю до %s",
+	"Detected by: bot heartbeat":                            "Виявлено: за сигналом роботи бота",
+	"Matches scheduled outages:":                            "Збігається з плановими відключеннями:",
+	"Group %s: %s":                                          "Черга %s: %s",
+	"No scheduled outage at that time":                      "У цей час планових відключень не було",
+	"%s in the previous %s, %s in total":                    "%s за попередні %s, загалом %s",
+	"Power was on for %s before this outage":                "Перед цим відключенням світло було %s",
+	"Longest outage: %s, started %s":                        "Найдовше відключення: %s, почалося %s",
+	"Worst month: %s (%s)":                                  "Найгірший місяць: %s (%s)",
+	"Best month: %s (%s)":                                   "Найкращий місяць: %s (%s)",
+	"Jan":                                                   "Січ",
+	"Feb":                                                   "Лют",
+	"Mar":                                                   "Бер",
+	"Apr":                                                   "Кві",
+	"May":                                                   "Тра",
+	"Jun":                                                   "Чер",
+	"Jul":                                                   "Лип",
+	"Aug":                                                   "Сер",
+	"Sep":                                                   "Вер",
+	"Oct":                                                   "Жов",
+	"Nov":                                                   "Лис",
+	"Dec":                                                   "Гру",
+
+	"Type /group <group> to set your outage schedule group":                                                                       "Надішліть /group <черга>, щоб задати вашу чергу відключень",
+	"Type /elevator <minutes>|off to be warned not to take the elevator before planned outages":                                   "Надішліть /elevator <хвилини>|off, щоб отримувати попередження не користуватися ліфтом перед плановими відключеннями",
+	"Your outage schedule group is not set\nUsage: /group <group>|off":                                                            "Вашу чергу відключень не задано\nВикористання: /group <черга>|off",
+	"Your outage schedule group is %s\nUsage: /group <group>|off":                                                                 "Ваша черга відключень: %s\nВикористання: /group <черга>|off",
+	"Usage: /group <group>|off, group names are up to %s without spaces":                                                          "Використання: /group <черга>|off, назва черги — до %s без пробілів",
+	"Failed to change your outage schedule group. Please try again later":                                                         "Не вдалося змінити вашу чергу відключень. Спробуйте пізніше",
+	"Your outage schedule group is removed":                                                                                       "Вашу чергу відключень видалено",
+	"You follow the bot outage schedule group %s":                                                                                 "Ви стежите за чергою відключень бота: %s",
+	"Your outage schedule group is %s":                                                                                            "Ваша черга відключень: %s",
+	"Usage: /elevator <minutes>|off, warns not to take the elevator the given number of minutes before planned outages, up to %d": "Використання: /elevator <хвилини>|off, попереджає не користуватися ліфтом за вказану кількість хвилин до планових відключень, не більше %d",
+	"Failed to change elevator warnings. Please try again later":                                                                  "Не вдалося змінити попередження про ліфт. Спробуйте пізніше",
+	"Elevator warnings are off":                                                                                                   "Попередження про ліфт вимкнено",
+	"Elevator warnings are on, lead time: %s. Set your outage schedule group with /group to get them":                             "Попередження про ліфт увімкнено, завчасно: %s. Задайте вашу чергу відключень за допомогою /group, щоб отримувати їх",
+	"Elevator warnings before planned outages of group %s are on, lead time: %s":                                                  "Попередження про ліфт перед плановими відключеннями черги %s увімкнено, завчасно: %s",
+	"Type /utilities to warn about water and heating depending on electricity":                                                    "Надішліть /utilities, щоб попереджати про воду й опалення, що залежать від електрики",
+	"Failed to change utilities. Please try again later":                                                                          "Не вдалося змінити налаштування комунальних послуг. Спробуйте пізніше",
+	"Usage:\n/utilities - show utilities depending on electricity\n/utilities water on|off - water pumps depend on electricity\n/utilities heating on|off - heating depends on electricity\n/utilities note <text>|off - note added to power off notifications": "Використання:\n/utilities - показати комунальні послуги, що залежать від електрики\n/utilities water on|off - насоси води залежать від електрики\n/utilities heating on|off - опалення залежить від електрики\n/utilities note <текст>|off - примітка до сповіщень про відключення",
+	"Only group administrators can change utilities of the group": "Змінювати комунальні послуги групи можуть лише адміністратори групи",
+	"Power off notifications of this chat warn about:":            "Сповіщення про відключення в цьому чаті попереджають про:",
+	"- water":    "- воду",
+	"- heating":  "- опалення",
+	"- note: %s": "- примітку: %s",
+	"Power off notifications of this chat don't warn about utilities, see /utilities help": "Сповіщення про відключення в цьому чаті не попереджають про комунальні послуги, див. /utilities help",
+
+	// notifications
+	"Bot started at %s\nLast alive time: %s":                                                "Бот запущено о %s\nВостаннє був на зв'язку: %s",
+	"⚠️ Looks like a wide outage: power went off at %d of %d monitored locations within %s": "⚠️ Схоже на масштабне відключення: світло зникло на %d з %d локацій протягом %s",
+	"⚠️ Unusual outage: %s":                                                                 "⚠️ Незвичне відключення: %s",
+	"⚠️ Outage anomaly: %s\nPower was off from %s to %s":                                    "⚠️ Аномалія відключення: %s\nСвітла не було з %s до %s",
+	"unusually long outage":                                                                 "незвично довге відключення",
+	"outage outside the planned schedule":                                                   "відключення поза графіком",
+	"power is flapping":                                                                     "світло постійно зникає і з'являється",
+	"Type /forecast to get likely outage windows estimated from the outage history":         "Введіть /forecast, щоб отримати ймовірні відключення за історією відключень",
+	"Not enough outage history for a forecast yet":                                          "Поки що недостатньо історії відключень для прогнозу",
+	"Likely outages based on the last %d days:":                                             "Ймовірні відключення за останні %d днів:",
+	"Tomorrow":            "Завтра",
+	"no outages expected": "відключень не очікується",
+	"/update [force] - install the latest release and restart": "/update [force] - встановити останній випуск і перезапуститися",
+	"Self-update is not configured":                            "Самооновлення не налаштоване",
+	"The bot is on a backup uplink, the update is not downloaded to save traffic. Use /update force to download it anyway": "Бот працює через резервний канал зв'язку, оновлення не завантажується для економії трафіку. Використайте /update force, щоб завантажити його все одно",
+	"Update failed: %s": "Не вдалося оновитися: %s",
+	"Update downloaded and verified, restarting to install it": "Оновлення завантажене й перевірене, перезапускаюся, щоб встановити його",
+	"Downloading the update":                                   "Завантажую оновлення",
+	"/restart - restart the bot":                               "/restart - перезапустити бота",
+	"/logs [N] - get the last N log lines":                     "/logs [N] - отримати останні N рядків журналу",
+	"Restart is not available":                                 "Перезапуск недоступний",
+	"Restarting, I'll be back in a minute":                     "Перезапускаюся, повернуся за хвилину",
+	"Logs are not available":                                   "Журнал недоступний",
+	"Usage: /logs [N], where N is from 1 to %d":                "Використання: /logs [N], де N від 1 до %d",
+	"There are no log lines":                                   "У журналі немає рядків",
+	"/config get|set|reset - change config values at runtime":  "/config get|set|reset - змінити налаштування під час роботи",
+	"Usage:\n/config get [name] - show config values changeable at runtime\n/config set <name> <value> - change the value, e.g. /config set wideOutageWindow 2m\n/config reset <name> - return to the value of the config file": "Використання:\n/config get [назва] - показати налаштування, які можна змінити під час роботи\n/config set <назва> <значення> - змінити значення, наприклад /config set wideOutageWindow 2m\n/config reset <назва> - повернути значення з файлу конфігурації",
+	"Config values:": "Налаштування:",
+	"Unknown config value %q, see /config get":            "Невідоме налаштування %q, дивіться /config get",
+	" (config file: %s)":                                  " (файл конфігурації: %s)",
+	", from %s to %s":                                     ", від %s до %s",
+	"%s must be a duration from %s to %s, e.g. 90s or 5m": "%s має бути тривалістю від %s до %s, наприклад 90s або 5m",
+	"Can't set %s: %s":                                    "Не вдалося змінити %s: %s",
+	"%s is %s until restart, failed to store it":          "%s дорівнює %s до перезапуску, зберегти не вдалося",
+	"%s is %s now":                                        "%s тепер дорівнює %s",
+	"Failed to reset %s. Please try again later":          "Не вдалося скинути %s. Спробуйте пізніше",
+	"/flags - roll out experimental features":             "/flags - керувати поширенням експериментальних функцій",
+	"This feature is not available in this chat yet":      "Ця функція ще недоступна в цьому чаті",
+	"Unknown feature %q, see /flags":                      "Невідома функція %q, дивіться /flags",
+	"Usage:\n/flags list - show experimental features and their rollout\n/flags set <feature> <percent|on|off> - enable the feature for a percent of chats\n/flags add <feature> <chat ID> - enable the feature for the chat\n/flags remove <feature> <chat ID> - remove the chat added to the feature": "Використання:\n/flags list - показати експериментальні функції та їх поширення\n/flags set <функція> <відсоток|on|off> - увімкнути функцію для відсотка чатів\n/flags add <функція> <ID чату> - увімкнути функцію для чату\n/flags remove <функція> <ID чату> - прибрати доданий до функції чат",
+	"Experimental features:": "Експериментальні функції:",
+	"%s: %d%% of chats":      "%s: %d%% чатів",
+	" and chats %s":          " і чати %s",
+	"Rollout must be on, off or a percent from 0 to %d":                                 "Поширення має бути on, off або відсотком від 0 до %d",
+	"Failed to set feature flag. Please try again later":                                "Не вдалося змінити функцію. Спробуйте пізніше",
+	"%s is enabled for %d%% of chats":                                                   "%s увімкнено для %d%% чатів",
+	"Invalid chat ID %q":                                                                "Неправильний ID чату %q",
+	"%s is enabled for chat %d":                                                         "%s увімкнено для чату %d",
+	"Chat %d is removed from %s":                                                        "Чат %d прибрано з %s",
+	"🟢 Power is back after %s":                                                          "🟢 Світло повернулося через %s",
+	"%s wording: %d sent, details opened %d times (%.0f%%)":                             "Формулювання %s: надіслано %d, подробиці відкрито %d разів (%.0f%%)",
+	"This step is over, use /menu to change settings":                                   "Цей крок уже пройдено, змініть налаштування через /menu",
+	"You're all set! Type /help to see what I can do":                                   "Все готово! Надішліть /help, щоб дізнатися, що я вмію",
+	"Let's set you up in three short steps. Choose your language:":                      "Налаштуймо все за три короткі кроки. Оберіть мову:",
+	"Choose your outage schedule group to get warnings before planned outages:":         "Оберіть вашу групу графіка відключень, щоб отримувати попередження перед плановими відключеннями:",
+	"Should power off notifications warn you about utilities depending on electricity?": "Чи попереджати вас у сповіщеннях про відключення про комунальні послуги, що залежать від електрики?",
+	"Water: %s":   "Вода: %s",
+	"Heating: %s": "Опалення: %s",
+	"Water":       "Вода",
+	"Heating":     "Опалення",
+	"Please use the buttons above or send /cancel":               "Скористайтеся кнопками вище або надішліть /cancel",
+	"There is nothing to cancel":                                 "Немає чого скасовувати",
+	"Canceled":                                                   "Скасовано",
+	"Feedback is available in a private chat with the bot only":  "Відгук можна надіслати лише в приватному чаті з ботом",
+	"Failed to send feedback. Please try again later":            "Не вдалося надіслати відгук. Спробуйте пізніше",
+	"Send your feedback or complaint in one message, or /cancel": "Надішліть ваш відгук або скаргу одним повідомленням або /cancel",
+	"Feedback is too long, please keep it under %s":              "Відгук задовгий, вкладіться в %s",
+	"💬 Feedback from %d %s:\n%s":                                 "💬 Відгук від %d %s:\n%s",
+	"Thank you, your feedback has been sent to the admins":       "Дякуємо, ваш відгук надіслано адміністраторам",
+	"Type /feedback to send feedback or a complaint to admins":   "Надішліть /feedback, щоб надіслати відгук або скаргу адміністраторам",
+	"/user - manage notes and tags of users":                     "/user - керувати примітками й тегами користувачів",
+	"Usage:\n/user find <text> - find users by name, note or tag" +
+		"\n/user <ID> note <text>|off - set the note of the user" +
+		"\n/user <ID> tag <tag> - tag the user, /broadcast #tag <text> sends to tagged users" +
+		"\n/user <ID> untag <tag> - remove the tag of the user": "Використання:\n/user find <текст> - знайти користувачів за іменем, приміткою чи тегом" +
+		"\n/user <ID> note <текст>|off - задати примітку користувача" +
+		"\n/user <ID> tag <тег> - додати тег користувачу, /broadcast #тег <текст> надсилає користувачам з тегом" +
+		"\n/user <ID> untag <тег> - прибрати тег користувача",
+	"Tags are up to %s without spaces":                   "Тег має бути не довшим за %s і без пробілів",
+	"Failed to change user %d, check the ID with /users": "Не вдалося змінити користувача %d, перевірте ID через /users",
+	"No users found":         "Користувачів не знайдено",
+	"Found users (%d):":      "Знайдені користувачі (%d):",
+	"No users are tagged %s": "Немає користувачів з тегом %s",
+	"Type /silent to choose minor notifications sent without sound": "Надішліть /silent, щоб обрати другорядні сповіщення, які надходитимуть без звуку",
+	"Usage: /silent <event> on|off, events: %s":                     "Використання: /silent <подія> on|off, події: %s",
+	"Failed to get settings. Please try again later":                "Не вдалося отримати налаштування. Спробуйте пізніше",
+	"Minor notifications:":                                          "Другорядні сповіщення:",
+	"with sound":                                                    "зі звуком",
+	"silent":                                                        "без звуку",
+	"power has been stable long enough to turn appliances on":       "світло є достатньо довго, щоб увімкнути прилади",
+	"warnings not to take the elevator before planned outages":      "попередження не користуватися ліфтом перед плановими відключеннями",
+	"the yearly outage report":                                      "річний звіт про відключення",
+	"Power off and on notifications always come with sound. Use /silent <event> on|off to change": "Сповіщення про зникнення й появу світла завжди надходять зі звуком. Щоб змінити, надішліть /silent <подія> on|off",
+	"updates that power is still off during long outages":                                         "нагадування, що світла досі немає, під час довгих відключень",
+	"🔴 Still no power, %s and counting":                                                           "🔴 Світла досі немає, вже %s",
+	"Is power back at your place too?":                                                            "Світло з'явилося й у вас?",
+	"Yes":                                                                                         "Так",
+	"No, still off":                                                                               "Ні, досі немає",
+	"Thank you, this outage is over already":                                                      "Дякуємо, це відключення вже завершилося",
+	"Thank you for the answer":                                                                    "Дякуємо за відповідь",
+	"%d user|%d users":                                                                            "%d користувач|%d користувачі|%d користувачів",
+	"⚠️ %s report that power is still off at their place, it may be back only in part of the area": "⚠️ %s повідомляють, що в них світла досі немає, можливо, його повернули лише частині району",
+	"⚠️ Some users report that power is still off at their place":                                  "⚠️ Деякі користувачі повідомляють, що в них світла досі немає",
+	"Type /sla [N] to see how outages followed the schedule in the last N weeks":                   "Надішліть /sla [N], щоб побачити, як відключення відповідали графіку за останні N тижнів",
+	"Usage: /sla [N], where N is the number of weeks up to %d":                                     "Використання: /sla [N], де N — кількість тижнів, не більше %d",
+	"The schedule group of the bot is not set, the owner can choose it in /setup":                  "Черга відключень бота не задана, власник може обрати її в /setup",
+	"Schedule adherence of group %s, on time is within %s:":                                        "Дотримання графіка черги %s, вчасно — з відхиленням до %s:",
+	"Week of %s:":                    "Тиждень з %s:",
+	"No planned or recorded outages": "Ні планових, ні зафіксованих відключень",
+	"Planned: %d\nStarted on time: %s\nEnded on time: %s\nSkipped: %d\nUnplanned: %d":           "Заплановано: %d\nПочалися вчасно: %s\nЗавершилися вчасно: %s\nНе відбулися: %d\nПоза графіком: %d",
+	"/annotate <ID> <cause> - annotate an outage with its cause":                                "/annotate <ID> <причина> - вказати причину відключення",
+	"Usage: /annotate <ID> <cause>|off, e.g. /annotate 12 emergency repairs":                    "Використання: /annotate <ID> <причина>|off, наприклад, /annotate 12 аварійний ремонт",
+	"Cause is too long, please keep it under %s":                                                "Причина задовга, будь ласка, вкладіться в %s",
+	"Failed to change the outage. Please try again later":                                       "Не вдалося змінити відключення. Спробуйте пізніше",
+	"Cause of outage %d removed":                                                                "Причину відключення %d видалено",
+	"Cause of outage %d set":                                                                    "Причину відключення %d задано",
+	"Cause: %s":                                                                                 "Причина: %s",
+	"Type /remindme before <minutes> <task> to be reminded about it before each planned outage": "Надішліть /remindme before <хвилини> <завдання>, щоб отримувати нагадування перед кожним плановим відключенням",
+	"Send how many minutes before planned outages to remind you and what about, like \"60 charge power banks\", or /cancel":             "Надішліть, за скільки хвилин до планових відключень і про що нагадувати, наприклад \"60 зарядити павербанки\", або /cancel",
+	"Usage: /remindme before <minutes> <task>, reminds about the task the given number of minutes before each planned outage, up to %d": "Використання: /remindme before <хвилини> <завдання>, нагадує про завдання за вказану кількість хвилин перед кожним плановим відключенням, до %d",
+	"Reminder #%d added, I'll remind you %s before planned outages. Set your outage schedule group with /group to get it":               "Нагадування #%d додано, завчасно: %s перед плановими відключеннями. Задайте вашу чергу відключень за допомогою /group, щоб отримувати його",
+	"Reminder #%d added, I'll remind you %s before each planned outage of group %s":                                                     "Нагадування #%d додано, завчасно: %s перед кожним плановим відключенням черги %s",
+	"%s before planned outages": "завчасно: %s перед плановими відключеннями",
+	"⏰ Power of group %s is planned to go off at %s. Don't forget: %s":         "⏰ Світло черги %s планово вимкнуть о %s. Не забудьте: %s",
+	"Type /checklist to change the checklist sent before long planned outages": "Надішліть /checklist, щоб змінити список справ перед довгими плановими відключеннями",
+	"Failed to change the checklist. Please try again later":                   "Не вдалося змінити список справ. Спробуйте пізніше",
+	"Checklist is too long, please keep it under %s":                           "Список справ задовгий, будь ласка, вкладіться в %s",
+	"Usage:\n/checklist - show the checklist sent before long planned outages\n/checklist on|off - turn the checklist on or off\n/checklist set <item>; <item> - set your own items\n/checklist reset - use the default items": "Використання:\n/checklist - показати список справ перед довгими плановими відключеннями\n/checklist on|off - увімкнути чи вимкнути список справ\n/checklist set <пункт>; <пункт> - задати власні пункти\n/checklist reset - повернути типові пункти",
+	"Only group administrators can change the checklist of the group":                                                  "Лише адміністратори групи можуть змінювати список справ групи",
+	"Checklists before long planned outages are off, see /checklist help":                                              "Список справ перед довгими плановими відключеннями вимкнено, див. /checklist help",
+	"Before planned outages of %s or longer this chat gets the checklist:":                                             "Перед плановими відключеннями тривалістю %s і довше цей чат отримує список справ:",
+	"charge phones, power banks and laptops":                                                                           "зарядити телефони, павербанки й ноутбуки",
+	"fill water bottles":                                                                                               "набрати воду",
+	"prepare flashlights":                                                                                              "підготувати ліхтарики",
+	"🔋 Power of group %s is planned to go off at %s for %s. Get ready:":                                                "🔋 Світло черги %s планово вимкнуть о %s на %s. Підготуйтеся:",
+	"⚡️ Voltage is too high: %d V. Consider unplugging sensitive devices":                                              "⚡️ Зависока напруга: %d В. Варто вимкнути з розеток чутливі прилади",
+	"⚡️ Voltage is too low: %d V. Consider unplugging sensitive devices":                                               "⚡️ Занизька напруга: %d В. Варто вимкнути з розеток чутливі прилади",
+	"✅ Voltage is back to normal: %d V":                                                                                "✅ Напруга знову в нормі: %d В",
+	"🟡 Phase %s went off at %s, power is partially off":                                                                "🟡 Фаза %s зникла о %s, світло є лише частково",
+	"Phase %s is back at %s\nIt was off for %s":                                                                        "Фаза %s повернулася о %s\nЇї не було %s",
+	"Unknown phase %q, use %s":                                                                                         "Невідома фаза %q, використовуйте %s",
+	"Failed to set phase of sensor %q. The location must exist without a sensor of its own and the phase must be free": "Не вдалося встановити фазу датчика %q. Локація має існувати без власного датчика, а фаза має бути вільною",
+	"Sensor %q monitors its own location":                                                                              "Датчик %q стежить за власною локацією",
+	"Sensor %q monitors phase %s of %s":                                                                                "Датчик %q стежить за фазою %s локації %s",
+	"phase %s of %s":                                                                                                   "фаза %s локації %s",
+	"✅ Restore report: all services are back after the outage: %s":                                                     "✅ Звіт після відновлення: усі сервіси повернулися після відключення: %s",
+	"⚠️ Restore report: services that didn't survive the outage: %s\nNo heartbeats since power was restored at %s":     "⚠️ Звіт після відновлення: сервіси, що не пережили відключення: %s\nВід них немає heartbeat відколи світло повернулося о %s",
+	"Failed to make %q a part of %q. The part needs a sensor, the building must exist without a sensor of its own":     "Не вдалося зробити %q частиною %q. Частина потребує датчика, а будинок має існувати без власного датчика",
+	"Location %q is not a part of another one":                                                                         "Локація %q більше не є частиною іншої",
+	"Location %q is a part of %q, its outages are sent to its subscribers only":                                        "Локація %q є частиною %q, про її відключення дізнаються лише її підписники",
+	"part of %s":                   "частина %s",
+	"Channel reach:":               "Охоплення каналів:",
+	"%s: not sampled yet":          "%s: ще не виміряно",
+	"%s: %s at %s":                 "%s: %s станом на %s",
+	"%s subscriber|%s subscribers": "%s підписник|%s підписники|%s підписників",
+	"Estimated reach: up to %s people (users: %s, channel subscribers: %s)": "Оціночне охоплення: до %s людей (користувачі: %s, підписники каналів: %s)",
+	"Power went off at %s":                   "Світло зникло о %s",
+	"Power is back at %s\nIt was off for %s": "Світло повернулося о %s\nЙого не було %s",
+	"Don't forget:":                          "Не забудьте:",
+	"Power has been stable for %s, it should be safe to turn appliances back on": "Живлення стабільне вже %s, можна вмикати побутові прилади",
+
+	"🛗 Power of group %s is planned to go off at %s. Please don't take the elevator from now on, it may stop between floors": "🛗 Світло черги %s планово вимкнуть о %s. Будь ласка, не користуйтеся ліфтом відтепер, він може зупинитися між поверхами",
+	"🚱 Water pumps depend on electricity, expect no water during the outage":                                                 "🚱 Насоси води залежать від електрики, під час відключення води не буде",
+	"🌡 Heating depends on electricity, expect no heating during the outage":                                                  "🌡 Опалення залежить від електрики, під час відключення опалення не буде",
+
+	// weekdays
+	"Mon": "Пн",
+	"Tue": "Вт",
+	"Wed": "Ср",
+	"Thu": "Чт",
+	"Fri": "Пт",
+	"Sat": "Сб",
+	"Sun": "Нд",
+
+	// durations
+	"%d second|%d seconds":       "%d секунда|%d секунди|%d секунд",
+	"%d minute|%d minutes":       "%d хвилина|%d хвилини|%d хвилин",
+	"%d character|%d characters": "%d символ|%d символи|%d символів",
+	"%d day|%d days":             "%d день|%d дні|%d днів",
+	"%d outage|%d outages":       "%d відключення|%d відключення|%d відключень",
+	"%d hour|%d hours":           "%d година|%d години|%d годин",
+}
@@ -0,0 +1,405 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package installer deploys the bot as a systemd service: it installs the binary, creates the service user and
+// the data directory, stores the bot token as a systemd credential, writes the unit and enables the service.
+package installer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultName     = "electrobot"
+	defaultBinary   = "/usr/local/bin/electrobot"
+	defaultDataDir  = "/var/electrobot"
+	defaultUnitDir  = "/etc/systemd/system"
+	defaultWatchdog = time.Minute
+	dataDirMode     = 0o750
+	configMode      = 0o640
+	secretMode      = 0o600
+	binaryMode      = 0o755
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//nolint:gochecknoglobals
+var unitTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description=Electrobot power outage notification bot
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=notify
+# installs the release staged by /update, "+" runs it as root to replace the binary
+ExecStartPre=+{{.Binary}} self-update -c {{.ConfigFile}} -staged
+ExecStart={{.Binary}} -c {{.ConfigFile}}
+User={{.User}}
+Group={{.User}}
+WorkingDirectory={{.DataDir}}
+Environment=ELECTROBOT_WORKING_DIR={{.DataDir}}
+{{- if .Credential}}
+LoadCredential={{.TokenCredential}}:{{.Credential}}
+{{- end}}
+WatchdogSec={{.WatchdogSeconds}}
+Restart=always
+RestartSec=10
+# config errors need a fix, restarting won't help
+RestartPreventExitStatus={{.ConfigExitCode}}
+ProtectSystem=strict
+ProtectHome=true
+PrivateTmp=true
+ReadWritePaths={{.DataDir}}
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with installation parameters, empty values are replaced with defaults.
+type Config struct {
+	// Name is the systemd service name.
+	Name string
+	// Source is the binary to install, it is copied to Binary unless they are the same file.
+	Source string
+	Binary string
+	// ConfigFile is created from ExampleConfig if it doesn't exist.
+	ConfigFile    string
+	ExampleConfig []byte
+	DataDir       string
+	UnitDir       string
+	// User runs the service, it is created as a system user if it doesn't exist.
+	User string
+	// Token is stored as the TokenCredential systemd credential next to the config file, empty keeps the stored one.
+	Token           string
+	TokenCredential string
+	Watchdog        time.Duration
+	// ConfigExitCode is the bot exit code on config errors, the service is not restarted with it.
+	ConfigExitCode int
+	// Start starts the service after enabling it.
+	Start bool
+	// Output receives progress messages.
+	Output io.Writer
+}
+
+type unitData struct {
+	Config
+	Credential      string
+	WatchdogSeconds int
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// CredentialFile returns the path the bot token credential is stored at.
+func (config Config) CredentialFile() string {
+	return filepath.Join(filepath.Dir(config.withDefaults().ConfigFile), config.TokenCredential)
+}
+
+// Unit returns systemd unit of the service, withCredential adds the bot token credential.
+func Unit(config Config, withCredential bool) string {
+	config = config.withDefaults()
+
+	data := unitData{
+		Config: config, WatchdogSeconds: int(config.Watchdog.Seconds()),
+	}
+
+	if withCredential {
+		data.Credential = config.CredentialFile()
+	}
+
+	var buffer bytes.Buffer
+
+	// the template and its data are static, execution can't fail
+	_ = unitTemplate.Execute(&buffer, data)
+
+	return buffer.String()
+}
+
+// Install deploys the service, it must be run as root. Every step may be repeated, existing config and data are kept.
+func Install(config Config) (err error) {
+	config = config.withDefaults()
+
+	if os.Geteuid() != 0 {
+		return errors.New("installation requires root privileges")
+	}
+
+	serviceUser, err := config.ensureUser()
+	if err != nil {
+		return err
+	}
+
+	if err = config.installBinary(); err != nil {
+		return err
+	}
+
+	if err = config.createDataDir(serviceUser); err != nil {
+		return err
+	}
+
+	if err = config.writeConfig(serviceUser); err != nil {
+		return err
+	}
+
+	withCredential, err := config.storeToken()
+	if err != nil {
+		return err
+	}
+
+	unitFile := filepath.Join(config.UnitDir, config.Name+".service")
+
+	if err = os.WriteFile(unitFile, []byte(Unit(config, withCredential)), configMode); err != nil {
+		return fmt.Errorf("failed to write unit: %w", err)
+	}
+
+	config.printf("Unit written to %s", unitFile)
+
+	if !withCredential {
+		config.printf("Bot token is not stored, set telegram.token in %s", config.ConfigFile)
+	}
+
+	if err = systemctl("daemon-reload"); err != nil {
+		return err
+	}
+
+	enable := []string{"enable", config.Name}
+	if config.Start {
+		enable = []string{"enable", "--now", config.Name}
+	}
+
+	if err = systemctl(enable...); err != nil {
+		return err
+	}
+
+	config.printf("Service %s enabled, check it with: systemctl status %s", config.Name, config.Name)
+
+	return nil
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (config Config) withDefaults() Config {
+	if config.Name == "" {
+		config.Name = defaultName
+	}
+
+	if config.Binary == "" {
+		config.Binary = defaultBinary
+	}
+
+	if config.DataDir == "" {
+		config.DataDir = defaultDataDir
+	}
+
+	if config.UnitDir == "" {
+		config.UnitDir = defaultUnitDir
+	}
+
+	if config.User == "" {
+		config.User = defaultName
+	}
+
+	if config.Watchdog <= 0 {
+		config.Watchdog = defaultWatchdog
+	}
+
+	if config.Output == nil {
+		config.Output = io.Discard
+	}
+
+	return config
+}
+
+func (config Config) printf(format string, args ...interface{}) {
+	fmt.Fprintf(config.Output, format+"\n", args...)
+}
+
+// ensureUser creates the service system user without a login shell and home if it doesn't exist.
+func (config Config) ensureUser() (*user.User, error) {
+	serviceUser, err := user.Lookup(config.User)
+	if err == nil {
+		return serviceUser, nil
+	}
+
+	var unknownErr user.UnknownUserError
+	if !errors.As(err, &unknownErr) {
+		return nil, fmt.Errorf("failed to look up user %s: %w", config.User, err)
+	}
+
+	if err = run("useradd", "--system", "--user-group", "--no-create-home", "--shell", "/usr/sbin/nologin",
+		config.User); err != nil {
+		return nil, err
+	}
+
+	config.printf("System user %s created", config.User)
+
+	return user.Lookup(config.User)
+}
+
+func (config Config) installBinary() error {
+	source, err := filepath.EvalSymlinks(config.Source)
+	if err != nil {
+		return fmt.Errorf("failed to find binary: %w", err)
+	}
+
+	if target, err := filepath.EvalSymlinks(config.Binary); err == nil && target == source {
+		return nil
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("failed to read binary: %w", err)
+	}
+
+	// the running service keeps the replaced binary open, write a new file instead of truncating it
+	temp := config.Binary + ".new"
+
+	if err = os.WriteFile(temp, data, binaryMode); err != nil {
+		return fmt.Errorf("failed to install binary: %w", err)
+	}
+
+	if err = os.Rename(temp, config.Binary); err != nil {
+		return fmt.Errorf("failed to install binary: %w", err)
+	}
+
+	config.printf("Binary installed to %s", config.Binary)
+
+	return nil
+}
+
+// createDataDir creates the working directory accessible by the service user only.
+func (config Config) createDataDir(serviceUser *user.User) error {
+	if err := os.MkdirAll(config.DataDir, dataDirMode); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	if err := chown(config.DataDir, serviceUser); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(config.DataDir, dataDirMode); err != nil {
+		return fmt.Errorf("failed to set data directory permissions: %w", err)
+	}
+
+	config.printf("Data directory %s is ready", config.DataDir)
+
+	return nil
+}
+
+// writeConfig writes the example config readable by the service group, an existing config is kept.
+func (config Config) writeConfig(serviceUser *user.User) error {
+	if _, err := os.Stat(config.ConfigFile); err == nil {
+		config.printf("Config %s exists, keeping it", config.ConfigFile)
+
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.ConfigFile), binaryMode); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(config.ConfigFile, config.ExampleConfig, configMode); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	gid, err := strconv.Atoi(serviceUser.Gid)
+	if err != nil {
+		return fmt.Errorf("invalid group of user %s: %w", serviceUser.Username, err)
+	}
+
+	if err = os.Chown(config.ConfigFile, 0, gid); err != nil {
+		return fmt.Errorf("failed to set config owner: %w", err)
+	}
+
+	config.printf("Example config written to %s", config.ConfigFile)
+
+	return nil
+}
+
+// storeToken writes the bot token credential readable by root only, systemd passes it to the service.
+func (config Config) storeToken() (stored bool, err error) {
+	credentialFile := config.CredentialFile()
+
+	if config.Token == "" {
+		_, err = os.Stat(credentialFile)
+
+		return err == nil, nil
+	}
+
+	if err = os.WriteFile(credentialFile, []byte(config.Token+"\n"), secretMode); err != nil {
+		return false, fmt.Errorf("failed to store bot token: %w", err)
+	}
+
+	config.printf("Bot token stored to %s", credentialFile)
+
+	return true, nil
+}
+
+func chown(path string, owner *user.User) error {
+	uid, err := strconv.Atoi(owner.Uid)
+	if err != nil {
+		return fmt.Errorf("invalid user %s: %w", owner.Username, err)
+	}
+
+	gid, err := strconv.Atoi(owner.Gid)
+	if err != nil {
+		return fmt.Errorf("invalid group of user %s: %w", owner.Username, err)
+	}
+
+	if err = os.Chown(path, uid, gid); err != nil {
+		return fmt.Errorf("failed to set owner of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func systemctl(args ...string) error {
+	return run("systemctl", args...)
+}
+
+func run(name string, args ...string) error {
+	log.Debugf("Running %s %v", name, args)
+
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", name, err, bytes.TrimSpace(output))
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtest
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"electrobot/database"
+	"electrobot/telegrambot"
+	"electrobot/telegramtest"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultUsers   = 1000
+	defaultEvents  = 2
+	defaultLatency = 50 * time.Millisecond
+	// firstUserID keeps simulated users apart from real chat IDs in logs.
+	firstUserID = 1_000_000
+	// deliveryGrace is added to the expected fan-out time before the test gives up waiting.
+	deliveryGrace = time.Minute
+	// expectedRate is a conservative estimate of the bot fan-out rate used for the wait timeout only.
+	expectedRate = 10
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with load test configuration.
+type Config struct {
+	// Users is the number of simulated subscribers.
+	Users int
+	// Events is the number of power state changes sent in a burst, power off and on alternate.
+	Events int
+	// Latency is the fake Bot API response time.
+	Latency time.Duration
+	// FailEvery makes every n-th sendMessage fail with flood control, 0 disables failures.
+	FailEvery int
+}
+
+// Result structure with load test results, latency is measured from the state change to the message delivery.
+type Result struct {
+	Users     int
+	Events    int
+	Expected  int
+	Delivered int
+	Duration  time.Duration
+	P50       time.Duration
+	P95       time.Duration
+	P99       time.Duration
+	Max       time.Duration
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Run simulates subscribers and a burst of power state changes against the real bot sender pipeline and storage
+// with the fake Telegram server. The workload is deterministic: user IDs, events and injected failures are the same
+// for the same configuration.
+func Run(config Config) (result Result, err error) {
+	if config.Users <= 0 {
+		config.Users = defaultUsers
+	}
+
+	if config.Events <= 0 {
+		config.Events = defaultEvents
+	}
+
+	if config.Latency < 0 {
+		config.Latency = defaultLatency
+	}
+
+	workingDir, err := os.MkdirTemp("", "electrobot-loadtest-")
+	if err != nil {
+		return result, err
+	}
+	defer os.RemoveAll(workingDir)
+
+	db, err := database.New(database.Config{WorkingDir: workingDir})
+	if err != nil {
+		return result, err
+	}
+	defer db.Close()
+
+	log.WithField("users", config.Users).Info("Registering simulated users")
+
+	for i := 0; i < config.Users; i++ {
+		if err = db.StoreUserInfo(botApi.Message{Chat: &botApi.Chat{ID: int64(firstUserID + i)}}); err != nil {
+			return result, fmt.Errorf("failed to register user: %w", err)
+		}
+	}
+
+	server := telegramtest.NewServer()
+	defer server.Close()
+
+	server.SetLatency(config.Latency)
+	server.FailEvery("sendMessage", config.FailEvery, http.StatusTooManyRequests, "Too Many Requests", 1)
+
+	botConfig := server.BotConfig()
+	// production retry settings, the fake server config only sends once
+	botConfig.SendAttempts = 0
+	botConfig.DisableYearlyReport = true
+
+	bot, err := telegrambot.New(botConfig, db)
+	if err != nil {
+		return result, err
+	}
+	defer bot.Close()
+
+	result = Result{Users: config.Users, Events: config.Events, Expected: config.Users * config.Events}
+
+	log.WithFields(log.Fields{"events": config.Events, "messages": result.Expected}).Info("Sending state changes")
+
+	eventTimes := make([]time.Time, 0, config.Events)
+	// fixed outage times keep message texts the same between runs
+	outageStart := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC) //nolint:gomnd
+	start := time.Now()
+
+	for i := 0; i < config.Events; i++ {
+		eventTimes = append(eventTimes, time.Now())
+
+		// events alternate starting with power off, like the power monitor reports them
+		if i%2 == 0 {
+			bot.PowerOff(outageStart)
+		} else {
+			bot.PowerOn(outageStart, outageStart.Add(time.Hour))
+		}
+	}
+
+	timeout := time.Duration(result.Expected/expectedRate)*time.Second + deliveryGrace
+
+	messages, err := server.WaitForMessages(result.Expected, timeout)
+	result.Delivered = len(messages)
+
+	if err != nil {
+		return result, err
+	}
+
+	result.Duration = time.Since(start)
+	result.calculateLatency(messages, eventTimes)
+
+	return result, nil
+}
+
+// Throughput returns delivered messages per second.
+func (result Result) Throughput() float64 {
+	if result.Duration <= 0 {
+		return 0
+	}
+
+	return float64(result.Delivered) / result.Duration.Seconds()
+}
+
+// String returns human readable report.
+func (result Result) String() string {
+	lines := []string{
+		fmt.Sprintf("Users:       %d", result.Users),
+		fmt.Sprintf("Events:      %d", result.Events),
+		fmt.Sprintf("Messages:    %d of %d delivered", result.Delivered, result.Expected),
+		fmt.Sprintf("Duration:    %s", result.Duration.Round(time.Millisecond)),
+		fmt.Sprintf("Throughput:  %.1f messages/s", result.Throughput()),
+		fmt.Sprintf("Latency p50: %s", result.P50.Round(time.Millisecond)),
+		fmt.Sprintf("Latency p95: %s", result.P95.Round(time.Millisecond)),
+		fmt.Sprintf("Latency p99: %s", result.P99.Round(time.Millisecond)),
+		fmt.Sprintf("Latency max: %s", result.Max.Round(time.Millisecond)),
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// calculateLatency matches messages to events by their order within each chat, every event sends one message
+// to every user and the queue keeps per-chat order.
+func (result *Result) calculateLatency(messages []telegramtest.Message, eventTimes []time.Time) {
+	chatEvents := make(map[int64]int)
+	latencies := make([]time.Duration, 0, len(messages))
+
+	for _, message := range messages {
+		event := chatEvents[message.ChatID]
+		chatEvents[message.ChatID]++
+
+		if event < len(eventTimes) {
+			latencies = append(latencies, message.SentAt.Sub(eventTimes[event]))
+		}
+	}
+
+	if len(latencies) == 0 {
+		return
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	percentile := func(p int) time.Duration {
+		return latencies[(len(latencies)-1)*p/100] //nolint:gomnd
+	}
+
+	result.P50, result.P95, result.P99, result.Max = percentile(50), percentile(95), percentile(99), //nolint:gomnd
+		latencies[len(latencies)-1]
+}
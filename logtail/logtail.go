@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logtail keeps the latest log lines in memory, so admins may read them without shell access.
+package logtail
+
+import (
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Buffer is a logrus hook keeping the latest formatted log lines.
+type Buffer struct {
+	sync.Mutex
+
+	lines []string
+	next  int
+	full  bool
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates buffer of size lines.
+func New(size int) *Buffer {
+	return &Buffer{lines: make([]string, size)}
+}
+
+// Levels returns levels the hook fires for, entries below the logger level are never fired.
+func (buffer *Buffer) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire stores the entry formatted by the logger formatter.
+func (buffer *Buffer) Fire(entry *log.Entry) error {
+	formatted, err := entry.Logger.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	buffer.Lock()
+	defer buffer.Unlock()
+
+	buffer.lines[buffer.next] = strings.TrimRight(string(formatted), "\n")
+	buffer.next = (buffer.next + 1) % len(buffer.lines)
+	buffer.full = buffer.full || buffer.next == 0
+
+	return nil
+}
+
+// Tail returns up to count latest lines, oldest first.
+func (buffer *Buffer) Tail(count int) []string {
+	buffer.Lock()
+	defer buffer.Unlock()
+
+	stored := buffer.next
+	if buffer.full {
+		stored = len(buffer.lines)
+	}
+
+	count = min(count, stored)
+	lines := make([]string, 0, count)
+
+	for i := buffer.next - count; i < buffer.next; i++ {
+		lines = append(lines, buffer.lines[(i+len(buffer.lines))%len(buffer.lines)])
+	}
+
+	return lines
+}
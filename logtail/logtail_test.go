@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logtail
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestTail(t *testing.T) {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	logger.SetFormatter(&log.TextFormatter{DisableTimestamp: true, DisableColors: true})
+
+	buffer := New(3)
+	logger.AddHook(buffer)
+
+	if lines := buffer.Tail(10); len(lines) != 0 {
+		t.Errorf("Wrong lines of empty buffer: %v", lines)
+	}
+
+	logger.Debug("not logged")
+
+	for i := 1; i <= 4; i++ {
+		logger.Infof("line %d", i)
+	}
+
+	testData := []struct {
+		count int
+		lines []string
+	}{
+		{count: 1, lines: []string{`level=info msg="line 4"`}},
+		{count: 10, lines: []string{`level=info msg="line 2"`, `level=info msg="line 3"`, `level=info msg="line 4"`}},
+	}
+
+	for _, item := range testData {
+		if lines := buffer.Tail(item.count); !reflect.DeepEqual(lines, item.lines) {
+			t.Errorf("Wrong last %d lines: %s", item.count, fmt.Sprintf("%q", lines))
+		}
+	}
+}
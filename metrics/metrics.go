@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exposes the power state and outages in the OpenMetrics text format.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"electrobot/database"
+	"electrobot/httpserver"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// FeatureName is the HTTP server feature name of metrics.
+const FeatureName = "metrics"
+
+const contentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Storage provides outages kept in the database.
+type Storage interface {
+	GetOutages(limit int) ([]database.Outage, error)
+	GetOutageStats(from, to time.Time) (database.OutageStats, error)
+}
+
+// StatusProvider provides the current power state.
+type StatusProvider interface {
+	// PowerState returns whether power is on, the time it is on or off since and the time of the last power check,
+	// zero if not checked yet.
+	PowerState() (on bool, since, lastCheck time.Time)
+}
+
+// Metrics serves metrics of the power state and outages.
+type Metrics struct {
+	storage Storage
+	status  StatusProvider
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates metrics.
+func New(storage Storage, status StatusProvider) *Metrics {
+	return &Metrics{storage: storage, status: status}
+}
+
+// Routes returns the metrics route.
+func (metrics *Metrics) Routes() []httpserver.Route {
+	return []httpserver.Route{{Pattern: "/metrics", Handler: http.HandlerFunc(metrics.handle)}}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (metrics *Metrics) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	body, err := metrics.write(time.Now())
+	if err != nil {
+		log.Errorf("Failed to collect metrics: %s", err)
+
+		http.Error(w, "internal error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+
+	if _, err = w.Write(body); err != nil {
+		log.Debugf("Failed to write metrics: %s", err)
+	}
+}
+
+// write renders metrics. The power state is a state set timestamped by the last power check, so a state timeline
+// shows when it was detected. Outage counters cover outages kept in the database, archiving resets them, and the
+// outage counter has the latest outage as the exemplar with its duration and end time.
+func (metrics *Metrics) write(now time.Time) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	if on, since, lastCheck := metrics.status.PowerState(); !lastCheck.IsZero() {
+		fmt.Fprintf(&buffer, "# TYPE electrobot_power stateset\n")
+		fmt.Fprintf(&buffer, "# HELP electrobot_power Power state, absent until the startup check finishes.\n")
+		fmt.Fprintf(&buffer, "electrobot_power{electrobot_power=\"on\"} %d %s\n", boolValue(on), timestamp(lastCheck))
+		fmt.Fprintf(&buffer, "electrobot_power{electrobot_power=\"off\"} %d %s\n", boolValue(!on),
+			timestamp(lastCheck))
+		fmt.Fprintf(&buffer, "# TYPE electrobot_power_since_seconds gauge\n")
+		fmt.Fprintf(&buffer, "# UNIT electrobot_power_since_seconds seconds\n")
+		fmt.Fprintf(&buffer, "# HELP electrobot_power_since_seconds Time of the last power state change.\n")
+		fmt.Fprintf(&buffer, "electrobot_power_since_seconds %s\n", timestamp(since))
+	}
+
+	stats, err := metrics.storage.GetOutageStats(time.Time{}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := metrics.storage.GetOutages(1)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(&buffer, "# TYPE electrobot_outages counter\n")
+	fmt.Fprintf(&buffer, "# HELP electrobot_outages Outages kept in the database.\n")
+	fmt.Fprintf(&buffer, "electrobot_outages_total %d", stats.Count)
+
+	if len(latest) != 0 {
+		fmt.Fprintf(&buffer, " # {start=\"%s\"} %d %s", latest[0].Start.UTC().Format(time.RFC3339),
+			int64(latest[0].Duration().Seconds()), timestamp(latest[0].End))
+	}
+
+	fmt.Fprintf(&buffer, "\n# TYPE electrobot_outage_seconds counter\n")
+	fmt.Fprintf(&buffer, "# UNIT electrobot_outage_seconds seconds\n")
+	fmt.Fprintf(&buffer, "# HELP electrobot_outage_seconds Total duration of outages kept in the database.\n")
+	fmt.Fprintf(&buffer, "electrobot_outage_seconds_total %d\n", int64(stats.Total.Seconds()))
+
+	if len(latest) != 0 {
+		fmt.Fprintf(&buffer, "# TYPE electrobot_last_outage info\n")
+		fmt.Fprintf(&buffer, "# HELP electrobot_last_outage The latest outage.\n")
+		fmt.Fprintf(&buffer, "electrobot_last_outage_info{start=\"%s\",end=\"%s\"} 1\n",
+			latest[0].Start.UTC().Format(time.RFC3339), latest[0].End.UTC().Format(time.RFC3339))
+	}
+
+	buffer.WriteString("# EOF\n")
+
+	return buffer.Bytes(), nil
+}
+
+func boolValue(value bool) int {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+// timestamp formats the time as OpenMetrics timestamp in seconds.
+func timestamp(t time.Time) string {
+	return fmt.Sprintf("%.3f", float64(t.UnixMilli())/1000) //nolint:gomnd
+}
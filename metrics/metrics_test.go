@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"electrobot/database"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type testStorage struct {
+	outages []database.Outage
+}
+
+type testStatus struct {
+	on               bool
+	since, lastCheck time.Time
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	metrics := New(&testStorage{outages: []database.Outage{{ID: 2, Start: start, End: end}}},
+		&testStatus{on: true, since: end, lastCheck: end.Add(time.Minute)})
+
+	body, err := metrics.write(end.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Can't write metrics: %s", err)
+	}
+
+	expected := `# TYPE electrobot_power stateset
+# HELP electrobot_power Power state, absent until the startup check finishes.
+electrobot_power{electrobot_power="on"} 1 1709319660.000
+electrobot_power{electrobot_power="off"} 0 1709319660.000
+# TYPE electrobot_power_since_seconds gauge
+# UNIT electrobot_power_since_seconds seconds
+# HELP electrobot_power_since_seconds Time of the last power state change.
+electrobot_power_since_seconds 1709319600.000
+# TYPE electrobot_outages counter
+# HELP electrobot_outages Outages kept in the database.
+electrobot_outages_total 1 # {start="2024-03-01T18:00:00Z"} 3600 1709319600.000
+# TYPE electrobot_outage_seconds counter
+# UNIT electrobot_outage_seconds seconds
+# HELP electrobot_outage_seconds Total duration of outages kept in the database.
+electrobot_outage_seconds_total 3600
+# TYPE electrobot_last_outage info
+# HELP electrobot_last_outage The latest outage.
+electrobot_last_outage_info{start="2024-03-01T18:00:00Z",end="2024-03-01T19:00:00Z"} 1
+# EOF
+`
+
+	if string(body) != expected {
+		t.Errorf("Wrong metrics:\n%s", body)
+	}
+
+	// power state is unknown before the startup check
+	metrics = New(&testStorage{}, &testStatus{})
+
+	if body, err = metrics.write(end); err != nil {
+		t.Fatalf("Can't write metrics: %s", err)
+	}
+
+	expected = `# TYPE electrobot_outages counter
+# HELP electrobot_outages Outages kept in the database.
+electrobot_outages_total 0
+# TYPE electrobot_outage_seconds counter
+# UNIT electrobot_outage_seconds seconds
+# HELP electrobot_outage_seconds Total duration of outages kept in the database.
+electrobot_outage_seconds_total 0
+# EOF
+`
+
+	if string(body) != expected {
+		t.Errorf("Wrong metrics without power state:\n%s", body)
+	}
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (storage *testStorage) GetOutages(limit int) ([]database.Outage, error) {
+	return storage.outages[:min(limit, len(storage.outages))], nil
+}
+
+func (storage *testStorage) GetOutageStats(from, to time.Time) (stats database.OutageStats, err error) {
+	for _, outage := range storage.outages {
+		stats.Count++
+		stats.Total += outage.Duration()
+	}
+
+	return stats, nil
+}
+
+func (status *testStatus) PowerState() (on bool, since, lastCheck time.Time) {
+	return status.on, status.since, status.lastCheck
+}
@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opendata
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"electrobot/archive"
+	"electrobot/httpserver"
+	"electrobot/report"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// FeatureName is the HTTP server feature name of open data publishing.
+const FeatureName = "opendata"
+
+const (
+	defaultDays     = 365
+	defaultInterval = time.Hour
+	dateFormat      = "2006-01-02"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with open data publisher configuration.
+type Config struct {
+	// Region is a free-form name of the area covered by the dataset.
+	Region string
+	// Timezone defines day boundaries, UTC is used if empty or invalid.
+	Timezone string
+	// Days is the number of complete days in the dataset.
+	Days     int
+	Interval time.Duration
+}
+
+// Day structure with aggregated outages of a day, outages crossing midnight are split between days.
+type Day struct {
+	Date                string `json:"date"`
+	Outages             int    `json:"outages"`
+	MinutesWithoutPower int    `json:"minutes_without_power"`
+}
+
+// Dataset structure with published outage data, it contains only daily aggregates and no user information.
+type Dataset struct {
+	Region      string    `json:"region"`
+	Timezone    string    `json:"timezone"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Days        []Day     `json:"days"`
+}
+
+// Publisher periodically regenerates the dataset and serves it over HTTP.
+type Publisher struct {
+	sync.Mutex
+
+	config     Config
+	location   *time.Location
+	storage    archive.OutageStorage
+	dataset    *Dataset
+	cancelFunc context.CancelFunc
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates and starts open data publisher.
+func New(config Config, storage archive.OutageStorage) (publisher *Publisher, err error) {
+	if config.Days <= 0 {
+		config.Days = defaultDays
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = defaultInterval
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		log.Warnf("Invalid open data timezone %q, using UTC: %s", config.Timezone, err)
+
+		location = time.UTC
+	}
+
+	publisher = &Publisher{config: config, location: location, storage: storage}
+
+	ctx, cancelFunction := context.WithCancel(context.Background())
+	publisher.cancelFunc = cancelFunction
+
+	go publisher.run(ctx)
+
+	return publisher, nil
+}
+
+// Close stops open data publisher.
+func (publisher *Publisher) Close() {
+	publisher.cancelFunc()
+}
+
+// Routes returns JSON and CSV dataset routes.
+func (publisher *Publisher) Routes() []httpserver.Route {
+	return []httpserver.Route{
+		{Pattern: "/opendata/outages.json", Handler: publisher.handler(writeJSON)},
+		{Pattern: "/opendata/outages.csv", Handler: publisher.handler(writeCSV)},
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (publisher *Publisher) run(ctx context.Context) {
+	ticker := time.NewTicker(publisher.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		publisher.generate()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// generate rebuilds the dataset of complete days, the previous dataset is kept if it fails.
+func (publisher *Publisher) generate() {
+	now := time.Now().In(publisher.location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, publisher.location)
+	from := to.AddDate(0, 0, -publisher.config.Days)
+
+	dailyOutages, err := report.Daily(publisher.storage, from, publisher.config.Days)
+	if err != nil {
+		log.Errorf("Failed to generate open data: %s", err)
+
+		return
+	}
+
+	days := make([]Day, 0, len(dailyOutages))
+
+	for _, day := range dailyOutages {
+		days = append(days, Day{
+			Date: day.Date.Format(dateFormat), Outages: day.Count,
+			MinutesWithoutPower: int(day.Total.Round(time.Minute).Minutes()),
+		})
+	}
+
+	publisher.Lock()
+	defer publisher.Unlock()
+
+	publisher.dataset = &Dataset{
+		Region: publisher.config.Region, Timezone: publisher.location.String(), GeneratedAt: now.UTC(), Days: days,
+	}
+
+	log.WithField("days", len(days)).Debug("Open data generated")
+}
+
+func (publisher *Publisher) handler(write func(w http.ResponseWriter, dataset *Dataset) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		publisher.Lock()
+		dataset := publisher.dataset
+		publisher.Unlock()
+
+		if dataset == nil {
+			http.Error(w, "dataset is not generated yet", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		// the dataset is public and meant to be used from other sites
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Last-Modified", dataset.GeneratedAt.Format(http.TimeFormat))
+
+		if err := write(w, dataset); err != nil {
+			log.Errorf("Failed to write open data: %s", err)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, dataset *Dataset) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(dataset)
+}
+
+// writeCSV writes one row per day, the region is repeated in every row so files of several regions can be merged.
+func writeCSV(w http.ResponseWriter, dataset *Dataset) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="outages.csv"`)
+
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write([]string{"region", "date", "outages", "minutes_without_power"}); err != nil {
+		return err
+	}
+
+	for _, day := range dataset.Days {
+		if err := csvWriter.Write([]string{
+			dataset.Region, day.Date, strconv.Itoa(day.Outages), strconv.Itoa(day.MinutesWithoutPower),
+		}); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
@@ -0,0 +1,301 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package powermonitor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"electrobot/chaos"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultAliveInterval   = 5 * time.Second
+	defaultOutageThreshold = time.Minute
+	subsystemName          = "power monitor"
+	notificationsSize      = 16
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with power monitor configuration.
+type Config struct {
+	// AliveInterval is the period of heartbeat event updates.
+	AliveInterval time.Duration
+	// OutageThreshold is the minimal heartbeat gap treated as a power outage.
+	OutageThreshold time.Duration
+	// Passive disables outage detection, power state comes from another source and heartbeat gaps only mean
+	// the bot itself was not running.
+	Passive bool
+	// Reporter receives power monitor state, optional.
+	Reporter StateReporter
+}
+
+// Storage provides event persistence.
+type Storage interface {
+	RecordHeartbeat() error
+	GetLastHeartbeat() (dateTime time.Time, err error)
+	RecordPowerOff(start, end time.Time) error
+	RecordStartup(reason string) error
+}
+
+// Listener is notified about power state transitions.
+type Listener interface {
+	// Started is called on startup when no outage is detected.
+	Started(lastAlive time.Time)
+	PowerOff(start time.Time)
+	PowerOn(start, end time.Time)
+	// Heartbeat is called after every power check.
+	Heartbeat(at time.Time)
+}
+
+// StateReporter receives subsystem state changes.
+type StateReporter interface {
+	SetSubsystemState(name string, err error)
+}
+
+// Monitor detects power outages by gaps between heartbeat events.
+type Monitor struct {
+	config        Config
+	storage       Storage
+	listener      Listener
+	lastHeartbeat time.Time
+	// notifications are delivered in order by a separate goroutine, listeners may fan out to thousands of users
+	// while heartbeats must keep going
+	notifications chan func()
+	cancelFunc    context.CancelFunc
+
+	writeMutex     sync.Mutex
+	lastWriteTime  time.Time
+	lastWriteError error
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates and starts power monitor.
+func New(config Config, storage Storage, listener Listener) (monitor *Monitor, err error) {
+	if config.AliveInterval <= 0 {
+		config.AliveInterval = defaultAliveInterval
+	}
+
+	if config.OutageThreshold <= 0 {
+		config.OutageThreshold = defaultOutageThreshold
+	}
+
+	if config.OutageThreshold <= config.AliveInterval {
+		log.WithFields(log.Fields{
+			"outageThreshold": config.OutageThreshold, "aliveInterval": config.AliveInterval,
+		}).Warn("Outage threshold is not greater than alive interval, every heartbeat may look like an outage")
+	}
+
+	monitor = &Monitor{
+		config: config, storage: storage, listener: listener, notifications: make(chan func(), notificationsSize),
+	}
+
+	ctx, cancelFunction := context.WithCancel(context.Background())
+	monitor.cancelFunc = cancelFunction
+
+	go monitor.deliverNotifications(ctx)
+
+	// the startup check is done before returning, so the first heartbeat is written when the self-test runs
+	monitor.detectStartupOutage(ctx)
+
+	go monitor.run(ctx)
+
+	return monitor, nil
+}
+
+// Close stops power monitor.
+func (monitor *Monitor) Close() {
+	monitor.cancelFunc()
+}
+
+// LastHeartbeatWrite returns time of the last successful heartbeat event write, zero if there was none.
+func (monitor *Monitor) LastHeartbeatWrite() time.Time {
+	monitor.writeMutex.Lock()
+	defer monitor.writeMutex.Unlock()
+
+	return monitor.lastWriteTime
+}
+
+// CheckHeartbeat returns an error if the heartbeat event hasn't been written for longer than the outage threshold.
+func (monitor *Monitor) CheckHeartbeat() error {
+	monitor.writeMutex.Lock()
+	defer monitor.writeMutex.Unlock()
+
+	if monitor.lastWriteTime.IsZero() {
+		return errors.New("no heartbeat written yet")
+	}
+
+	if age := time.Since(monitor.lastWriteTime); age > monitor.config.OutageThreshold {
+		if monitor.lastWriteError != nil {
+			return fmt.Errorf("last heartbeat written %s ago: %w", age.Round(time.Second), monitor.lastWriteError)
+		}
+
+		return fmt.Errorf("last heartbeat written %s ago", age.Round(time.Second))
+	}
+
+	return nil
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (monitor *Monitor) run(ctx context.Context) {
+	ticker := time.NewTicker(monitor.config.AliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			monitor.checkGap(ctx)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (monitor *Monitor) deliverNotifications(ctx context.Context) {
+	for {
+		select {
+		case notification := <-monitor.notifications:
+			notification()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// notify queues the listener notification, it blocks only if the listener is behind by several outages.
+func (monitor *Monitor) notify(ctx context.Context, notification func()) {
+	select {
+	case monitor.notifications <- notification:
+
+	case <-ctx.Done():
+	}
+}
+
+func (monitor *Monitor) detectStartupOutage(ctx context.Context) {
+	// strip monotonic reading, gaps must be measured by the wall clock to include host suspend
+	now := time.Now().Round(0)
+
+	reason := "restart"
+
+	lastAlive, err := monitor.storage.GetLastHeartbeat()
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("Failed to get last alive time: %s", err)
+		} else {
+			reason = "first start"
+		}
+
+		lastAlive = now
+	}
+
+	log.WithField("lastAlive", lastAlive.UTC()).Info("Power monitor started")
+
+	gap := now.Sub(lastAlive)
+	outage := gap > monitor.config.OutageThreshold && !monitor.config.Passive
+
+	if outage {
+		reason = "power restored after " + gap.Round(time.Second).String()
+	}
+
+	if err := monitor.storage.RecordStartup(reason); err != nil {
+		log.Errorf("Failed to store startup event: %s", err)
+	}
+
+	if outage {
+		monitor.recordOutage(ctx, lastAlive, now)
+	} else {
+		monitor.notify(ctx, func() { monitor.listener.Started(lastAlive) })
+	}
+
+	monitor.updateHeartbeat(now)
+}
+
+func (monitor *Monitor) checkGap(ctx context.Context) {
+	now := time.Now().Round(0)
+
+	if gap := now.Sub(monitor.lastHeartbeat); gap > monitor.config.OutageThreshold {
+		log.WithField("gap", gap).Warn("Heartbeat gap detected while running")
+
+		if !monitor.config.Passive {
+			monitor.recordOutage(ctx, monitor.lastHeartbeat, now)
+		}
+	}
+
+	monitor.updateHeartbeat(now)
+}
+
+func (monitor *Monitor) recordOutage(ctx context.Context, start, end time.Time) {
+	duration := end.Sub(start).Round(time.Second)
+
+	log.WithFields(log.Fields{"start": start.UTC(), "end": end.UTC(), "duration": duration}).Info("Power outage detected")
+
+	if err := monitor.storage.RecordPowerOff(start, end); err != nil {
+		log.Errorf("Failed to store outage events: %s", err)
+	}
+
+	monitor.notify(ctx, func() {
+		monitor.listener.PowerOff(start)
+		monitor.listener.PowerOn(start, end)
+	})
+}
+
+func (monitor *Monitor) updateHeartbeat(now time.Time) {
+	log.Debug("Bot is alive")
+
+	monitor.lastHeartbeat = now
+
+	err := monitor.storage.RecordHeartbeat()
+	if err != nil {
+		log.Errorf("Failed to store event due to DB error: %s", err)
+	}
+
+	chaos.CrashPoint("heartbeat")
+
+	monitor.writeMutex.Lock()
+
+	if monitor.lastWriteError = err; err == nil {
+		monitor.lastWriteTime = time.Now()
+	}
+
+	monitor.writeMutex.Unlock()
+
+	if monitor.config.Reporter != nil {
+		monitor.config.Reporter.SetSubsystemState(subsystemName, err)
+	}
+
+	monitor.listener.Heartbeat(now)
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package powermonitor_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"electrobot/powermonitor"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type testStorage struct {
+	sync.Mutex
+
+	lastHeartbeat time.Time
+	outages       int
+}
+
+// slowListener blocks like a fan-out to many users.
+type slowListener struct {
+	delay time.Duration
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestSlowListenerDoesNotCauseOutages(t *testing.T) {
+	storage := &testStorage{lastHeartbeat: time.Now().Add(-time.Hour)}
+
+	monitor, err := powermonitor.New(powermonitor.Config{
+		AliveInterval: 10 * time.Millisecond, OutageThreshold: 50 * time.Millisecond,
+	}, storage, slowListener{delay: 300 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Can't create power monitor: %s", err)
+	}
+	defer monitor.Close()
+
+	time.Sleep(500 * time.Millisecond)
+
+	if outages := storage.outageCount(); outages != 1 {
+		t.Errorf("Wrong outages count: %d", outages)
+	}
+
+	if err = monitor.CheckHeartbeat(); err != nil {
+		t.Errorf("Heartbeat is not written during notification: %s", err)
+	}
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (storage *testStorage) RecordHeartbeat() error {
+	storage.Lock()
+	defer storage.Unlock()
+
+	storage.lastHeartbeat = time.Now()
+
+	return nil
+}
+
+func (storage *testStorage) GetLastHeartbeat() (dateTime time.Time, err error) {
+	storage.Lock()
+	defer storage.Unlock()
+
+	return storage.lastHeartbeat, nil
+}
+
+func (storage *testStorage) RecordPowerOff(start, end time.Time) error {
+	storage.Lock()
+	defer storage.Unlock()
+
+	storage.outages++
+
+	return nil
+}
+
+func (storage *testStorage) RecordStartup(reason string) error {
+	return nil
+}
+
+func (listener slowListener) Started(lastAlive time.Time) {}
+
+func (listener slowListener) PowerOff(start time.Time) {
+	time.Sleep(listener.delay)
+}
+
+func (listener slowListener) PowerOn(start, end time.Time) {
+	time.Sleep(listener.delay)
+}
+
+func (listener slowListener) Heartbeat(at time.Time) {}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (storage *testStorage) outageCount() int {
+	storage.Lock()
+	defer storage.Unlock()
+
+	return storage.outages
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"electrobot/httpserver"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// FeatureName is the HTTP server feature name of probes.
+const FeatureName = "probes"
+
+const (
+	statusOK     = "ok"
+	statusFailed = "failed"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Check is a single probe check.
+type Check struct {
+	Name string
+	Run  func() error
+	// Details returns extra information reported regardless of the check result, optional.
+	Details func() string
+}
+
+type probeResponse struct {
+	Status  string            `json:"status"`
+	Checks  map[string]string `json:"checks"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Routes returns /livez and /readyz routes and /healthz route running both liveness and readiness checks.
+func Routes(liveness, readiness []Check) []httpserver.Route {
+	return []httpserver.Route{
+		{Pattern: "/livez", Handler: handler(liveness)},
+		{Pattern: "/readyz", Handler: handler(readiness)},
+		{Pattern: "/healthz", Handler: handler(append(append([]Check{}, liveness...), readiness...))},
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func handler(checks []Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		response := probeResponse{Status: statusOK, Checks: make(map[string]string)}
+
+		for _, check := range checks {
+			if check.Details != nil {
+				if response.Details == nil {
+					response.Details = make(map[string]string)
+				}
+
+				response.Details[check.Name] = check.Details()
+			}
+
+			if err := check.Run(); err != nil {
+				response.Status = statusFailed
+				response.Checks[check.Name] = err.Error()
+
+				continue
+			}
+
+			response.Checks[check.Name] = statusOK
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if response.Status != statusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Errorf("Failed to write probe response: %s", err)
+		}
+	}
+}
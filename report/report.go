@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"time"
+
+	"electrobot/archive"
+	"electrobot/database"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const monthsInYear = 12
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Month structure with outages of a calendar month, outages crossing month boundaries are split between months.
+type Month struct {
+	Count int
+	Total time.Duration
+}
+
+// Day structure with outages of a day, outages crossing midnight are split between days.
+type Day struct {
+	Date  time.Time
+	Count int
+	Total time.Duration
+}
+
+// Year structure with yearly outage report and the previous year for comparison.
+type Year struct {
+	Year     int
+	Months   [monthsInYear]Month
+	Previous [monthsInYear]Month
+	// Longest is the longest outage overlapping the year, zero if there were no outages.
+	Longest database.Outage
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Yearly builds report of the year in the location from database and archived outages.
+func Yearly(storage archive.OutageStorage, year int, location *time.Location) (report Year, err error) {
+	from := time.Date(year-1, time.January, 1, 0, 0, 0, 0, location)
+	yearStart := from.AddDate(1, 0, 0)
+	to := yearStart.AddDate(1, 0, 0)
+
+	outages, err := archive.Outages(storage, from, to)
+	if err != nil {
+		return report, err
+	}
+
+	report.Year = year
+
+	for _, outage := range outages {
+		if outage.End.After(yearStart) && outage.Duration() > report.Longest.Duration() {
+			report.Longest = outage
+		}
+
+		start, end := maxTime(outage.Start, from), minTime(outage.End, to)
+
+		for month := monthStart(start.In(location)); month.Before(end); month = month.AddDate(0, 1, 0) {
+			months := &report.Months
+			if month.Before(yearStart) {
+				months = &report.Previous
+			}
+
+			stats := &months[month.Month()-1]
+
+			if !outage.Start.Before(month) {
+				stats.Count++
+			}
+
+			stats.Total += minTime(end, month.AddDate(0, 1, 0)).Sub(maxTime(start, month))
+		}
+	}
+
+	return report, nil
+}
+
+// Daily returns outages of count days starting from the midnight of from in its location.
+func Daily(storage archive.OutageStorage, from time.Time, count int) (days []Day, err error) {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	to := from.AddDate(0, 0, count)
+
+	outages, err := archive.Outages(storage, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	days = make([]Day, 0, count)
+
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, Day{Date: day})
+	}
+
+	for _, outage := range outages {
+		for i := range days {
+			nextDay := days[i].Date.AddDate(0, 0, 1)
+			if !outage.End.After(days[i].Date) || !outage.Start.Before(nextDay) {
+				continue
+			}
+
+			if !outage.Start.Before(days[i].Date) {
+				days[i].Count++
+			}
+
+			days[i].Total += minTime(outage.End, nextDay).Sub(maxTime(outage.Start, days[i].Date))
+		}
+	}
+
+	return days, nil
+}
+
+// Count returns number of outages started within the year.
+func (report Year) Count() (count int) {
+	for _, month := range report.Months {
+		count += month.Count
+	}
+
+	return count
+}
+
+// Total returns time without power within the year.
+func (report Year) Total() time.Duration {
+	return total(report.Months)
+}
+
+// PreviousTotal returns time without power within the previous year.
+func (report Year) PreviousTotal() time.Duration {
+	return total(report.Previous)
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func total(months [monthsInYear]Month) (total time.Duration) {
+	for _, month := range months {
+		total += month.Total
+	}
+
+	return total
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"electrobot/database"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultCheckInterval  = 24 * time.Hour
+	defaultVacuumInterval = 7 * 24 * time.Hour
+	lastVacuumSettingKey  = "last_vacuum"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with event retention configuration.
+type Config struct {
+	// MaxAge is the age after which events are removed.
+	MaxAge time.Duration
+	// Keep lists event types which are never removed, e.g. outage events moved away by the archiver.
+	Keep          []database.EventType
+	CheckInterval time.Duration
+	// VacuumInterval is the minimum time between database vacuums.
+	VacuumInterval time.Duration
+}
+
+// Storage removes old events and compacts the database.
+type Storage interface {
+	PruneEvents(before time.Time, keep []database.EventType) (count int64, err error)
+	Vacuum() error
+	GetSetting(key string) (value string, err error)
+	SetSetting(key, value string) error
+}
+
+// Pruner periodically removes old events and vacuums the database.
+type Pruner struct {
+	config     Config
+	storage    Storage
+	cancelFunc context.CancelFunc
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates and starts pruner.
+func New(config Config, storage Storage) (pruner *Pruner, err error) {
+	if config.MaxAge <= 0 {
+		return nil, fmt.Errorf("invalid event max age %s", config.MaxAge)
+	}
+
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaultCheckInterval
+	}
+
+	if config.VacuumInterval <= 0 {
+		config.VacuumInterval = defaultVacuumInterval
+	}
+
+	pruner = &Pruner{config: config, storage: storage}
+
+	ctx, cancelFunction := context.WithCancel(context.Background())
+	pruner.cancelFunc = cancelFunction
+
+	go pruner.run(ctx)
+
+	return pruner, nil
+}
+
+// Close stops pruner.
+func (pruner *Pruner) Close() {
+	pruner.cancelFunc()
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (pruner *Pruner) run(ctx context.Context) {
+	ticker := time.NewTicker(pruner.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		pruner.prune()
+		pruner.vacuum()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (pruner *Pruner) prune() {
+	count, err := pruner.storage.PruneEvents(time.Now().Add(-pruner.config.MaxAge), pruner.config.Keep)
+	if err != nil {
+		log.Errorf("Failed to prune events: %s", err)
+
+		return
+	}
+
+	if count > 0 {
+		log.WithField("events", count).Info("Old events pruned")
+	}
+}
+
+// vacuum runs VACUUM if the last one was longer than the vacuum interval ago, the time is kept in settings
+// so frequent restarts don't postpone it forever.
+func (pruner *Pruner) vacuum() {
+	value, err := pruner.storage.GetSetting(lastVacuumSettingKey)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Errorf("Failed to get last vacuum time: %s", err)
+
+		return
+	}
+
+	if lastVacuum, err := time.Parse(time.RFC3339, value); err == nil &&
+		time.Since(lastVacuum) < pruner.config.VacuumInterval {
+		return
+	}
+
+	start := time.Now()
+
+	if err = pruner.storage.Vacuum(); err != nil {
+		log.Errorf("Failed to vacuum database: %s", err)
+
+		return
+	}
+
+	log.WithField("duration", time.Since(start).Round(time.Millisecond)).Info("Database vacuumed")
+
+	if err = pruner.storage.SetSetting(lastVacuumSettingKey, start.UTC().Format(time.RFC3339)); err != nil {
+		log.Errorf("Failed to store last vacuum time: %s", err)
+	}
+}
@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	day           = 24 * time.Hour
+	windowsSep    = ","
+	timeSep       = "-"
+	clockSep      = ":"
+	midnightClock = "24:00"
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+//nolint:gochecknoglobals
+var weekdayNames = map[string]time.Weekday{
+	"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+}
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Window is a planned outage window within a day, Start and End are offsets from the local midnight.
+type Window struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// ParseWindows parses windows like "08:00-12:00,20:00-24:00", windows are sorted and must not overlap.
+// Empty string and "off" mean no windows.
+func ParseWindows(text string) (windows []Window, err error) {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.EqualFold(text, "off") {
+		return nil, nil
+	}
+
+	for _, item := range strings.Split(text, windowsSep) {
+		startStr, endStr, found := strings.Cut(strings.TrimSpace(item), timeSep)
+		if !found {
+			return nil, fmt.Errorf("invalid window %q, expected HH:MM-HH:MM", item)
+		}
+
+		var window Window
+
+		if window.Start, err = parseClock(startStr); err != nil {
+			return nil, err
+		}
+
+		if window.End, err = parseClock(endStr); err != nil {
+			return nil, err
+		}
+
+		if window.End <= window.Start {
+			return nil, fmt.Errorf("window %q ends before it starts, split windows crossing midnight into "+
+				"HH:MM-24:00 and 00:00-HH:MM of the next day", item)
+		}
+
+		windows = append(windows, window)
+	}
+
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start < windows[j].Start })
+
+	for i := 1; i < len(windows); i++ {
+		if windows[i].Start < windows[i-1].End {
+			return nil, fmt.Errorf("windows %s and %s overlap", windows[i-1], windows[i])
+		}
+	}
+
+	return windows, nil
+}
+
+// FormatWindows formats windows in the form accepted by ParseWindows.
+func FormatWindows(windows []Window) string {
+	items := make([]string, 0, len(windows))
+
+	for _, window := range windows {
+		items = append(items, window.String())
+	}
+
+	return strings.Join(items, windowsSep)
+}
+
+// ParseWeekday parses weekday as a short English name (mon, tue, ...) or as a number 1-7 starting from Monday.
+func ParseWeekday(text string) (weekday time.Weekday, err error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+
+	if number, err := strconv.Atoi(text); err == nil && number >= 1 && number <= 7 {
+		return time.Weekday(number % 7), nil //nolint:gomnd // Sunday is 0
+	}
+
+	if len(text) >= 3 { //nolint:gomnd
+		if weekday, ok := weekdayNames[text[:3]]; ok {
+			return weekday, nil
+		}
+	}
+
+	return weekday, fmt.Errorf("invalid weekday %q", text)
+}
+
+// Weekdays returns weekdays starting from Monday.
+func Weekdays() []time.Weekday {
+	return []time.Weekday{
+		time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+	}
+}
+
+func (window Window) String() string {
+	return formatClock(window.Start) + timeSep + formatClock(window.End)
+}
+
+// At returns window start and end times on the date of the day time. Offsets are wall clock times, so windows keep
+// their clock times on days of DST changes.
+func (window Window) At(day time.Time) (start, end time.Time) {
+	return wallClock(day, window.Start), wallClock(day, window.End)
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func parseClock(text string) (offset time.Duration, err error) {
+	text = strings.TrimSpace(text)
+	if text == midnightClock {
+		return day, nil
+	}
+
+	hoursStr, minutesStr, found := strings.Cut(text, clockSep)
+	if !found {
+		minutesStr = "0"
+	}
+
+	hours, err := strconv.Atoi(hoursStr)
+	if err != nil || hours < 0 || hours > 23 {
+		return 0, fmt.Errorf("invalid time %q", text)
+	}
+
+	minutes, err := strconv.Atoi(minutesStr)
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("invalid time %q", text)
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+// wallClock returns the time of the day at the offset from midnight by the wall clock, 24:00 is the next midnight.
+func wallClock(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, int(offset/time.Minute), 0, 0, day.Location())
+}
+
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60) //nolint:gomnd
+}
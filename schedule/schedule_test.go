@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule_test
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // the test zone must not depend on the host
+
+	"electrobot/schedule"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestParseWindows(t *testing.T) {
+	testData := []struct {
+		text      string
+		formatted string
+		failed    bool
+	}{
+		{text: "", formatted: ""},
+		{text: "off", formatted: ""},
+		{text: "OFF", formatted: ""},
+		{text: "08:00-12:00", formatted: "08:00-12:00"},
+		{text: " 20:00-24:00 , 08:00-12:00 ", formatted: "08:00-12:00,20:00-24:00"},
+		{text: "8-12", formatted: "08:00-12:00"},
+		{text: "00:00-04:30,04:30-09:00", formatted: "00:00-04:30,04:30-09:00"},
+		{text: "00:00-24:00", formatted: "00:00-24:00"},
+		{text: "08:00-12:00,11:00-14:00", failed: true},
+		{text: "12:00-08:00", failed: true},
+		{text: "22:00-02:00", failed: true},
+		{text: "08:00-08:00", failed: true},
+		{text: "24:00-24:00", failed: true},
+		{text: "08:00", failed: true},
+		{text: "25:00-26:00", failed: true},
+		{text: "08:60-09:00", failed: true},
+		{text: "-1:00-02:00", failed: true},
+		{text: "08:00-12:00,", failed: true},
+		{text: "morning", failed: true},
+	}
+
+	for _, item := range testData {
+		windows, err := schedule.ParseWindows(item.text)
+
+		if item.failed {
+			if err == nil {
+				t.Errorf("Windows %q are parsed: %v", item.text, windows)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Can't parse windows %q: %s", item.text, err)
+
+			continue
+		}
+
+		if formatted := schedule.FormatWindows(windows); formatted != item.formatted {
+			t.Errorf("Wrong windows %q parsed from %q", formatted, item.text)
+		}
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	testData := []struct {
+		text    string
+		weekday time.Weekday
+		failed  bool
+	}{
+		{text: "mon", weekday: time.Monday},
+		{text: "Tuesday", weekday: time.Tuesday},
+		{text: " SUN ", weekday: time.Sunday},
+		{text: "1", weekday: time.Monday},
+		{text: "7", weekday: time.Sunday},
+		{text: "0", failed: true},
+		{text: "8", failed: true},
+		{text: "mo", failed: true},
+		{text: "", failed: true},
+	}
+
+	for _, item := range testData {
+		weekday, err := schedule.ParseWeekday(item.text)
+
+		switch {
+		case item.failed && err == nil:
+			t.Errorf("Weekday %q is parsed: %s", item.text, weekday)
+
+		case !item.failed && err != nil:
+			t.Errorf("Can't parse weekday %q: %s", item.text, err)
+
+		case !item.failed && weekday != item.weekday:
+			t.Errorf("Wrong weekday %s parsed from %q", weekday, item.text)
+		}
+	}
+}
+
+func TestWindowAt(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Fatalf("Can't load location: %s", err)
+	}
+
+	testData := []struct {
+		name   string
+		day    time.Time
+		window string
+		start  string
+		end    string
+		length time.Duration
+	}{
+		{
+			name: "regular day", day: time.Date(2024, 3, 1, 15, 0, 0, 0, location), window: "08:00-12:00",
+			start: "2024-03-01 08:00 EET", end: "2024-03-01 12:00 EET", length: 4 * time.Hour,
+		},
+		{
+			name: "ends at midnight", day: time.Date(2024, 3, 1, 0, 0, 0, 0, location), window: "20:00-24:00",
+			start: "2024-03-01 20:00 EET", end: "2024-03-02 00:00 EET", length: 4 * time.Hour,
+		},
+		{
+			name: "spring forward", day: time.Date(2024, 3, 31, 12, 0, 0, 0, location), window: "08:00-12:00",
+			start: "2024-03-31 08:00 EEST", end: "2024-03-31 12:00 EEST", length: 4 * time.Hour,
+		},
+		{
+			name: "spring forward across the change", day: time.Date(2024, 3, 31, 1, 0, 0, 0, location),
+			window: "00:00-06:00", start: "2024-03-31 00:00 EET", end: "2024-03-31 06:00 EEST", length: 5 * time.Hour,
+		},
+		{
+			name: "fall back", day: time.Date(2024, 10, 27, 23, 0, 0, 0, location), window: "18:00-24:00",
+			start: "2024-10-27 18:00 EET", end: "2024-10-28 00:00 EET", length: 6 * time.Hour,
+		},
+		{
+			name: "fall back across the change", day: time.Date(2024, 10, 27, 12, 0, 0, 0, location),
+			window: "00:00-06:00", start: "2024-10-27 00:00 EEST", end: "2024-10-27 06:00 EET", length: 7 * time.Hour,
+		},
+	}
+
+	const layout = "2006-01-02 15:04 MST"
+
+	for _, item := range testData {
+		t.Run(item.name, func(t *testing.T) {
+			windows, err := schedule.ParseWindows(item.window)
+			if err != nil {
+				t.Fatalf("Can't parse windows: %s", err)
+			}
+
+			start, end := windows[0].At(item.day)
+
+			if formatted := start.Format(layout); formatted != item.start {
+				t.Errorf("Wrong window start: %s", formatted)
+			}
+
+			if formatted := end.Format(layout); formatted != item.end {
+				t.Errorf("Wrong window end: %s", formatted)
+			}
+
+			if length := end.Sub(start); length != item.length {
+				t.Errorf("Wrong window length: %s", length)
+			}
+		})
+	}
+}
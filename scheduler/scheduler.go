@@ -0,0 +1,292 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+
+	"electrobot/database"
+	"electrobot/schedule"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Source is the planned schedule source name.
+const Source = "yasno"
+
+const (
+	// DefaultURL is the Yasno page API which also publishes DTEK planned outages of Kyiv and Dnipro.
+	DefaultURL           = "https://api.yasno.com.ua/api/v1/pages/home/schedule-turn-off-electricity"
+	defaultRegion        = "kiev"
+	defaultCheckInterval = 30 * time.Minute
+	requestTimeout       = 30 * time.Second
+	definiteOutage       = "DEFINITE_OUTAGE"
+	subsystemName        = "schedule import"
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// Regions lists supported Yasno region keys.
+//
+//nolint:gochecknoglobals
+var Regions = []string{"kiev", "dnipro"}
+
+// Groups lists outage queues published by Yasno.
+//
+//nolint:gochecknoglobals
+var Groups = []string{"1.1", "1.2", "2.1", "2.2", "3.1", "3.2", "4.1", "4.2", "5.1", "5.2", "6.1", "6.2"}
+
+// titleDate extracts the date from daily schedule titles like "Понеділок, 14.10.2024 на 00:00".
+//
+//nolint:gochecknoglobals
+var titleDate = regexp.MustCompile(`\d{2}\.\d{2}\.\d{4}`)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with schedule import configuration.
+type Config struct {
+	URL string
+	// Region is the Yasno region key, "kiev" or "dnipro".
+	Region string
+	// Group is the outage queue like "1.1".
+	Group         string
+	CheckInterval time.Duration
+	// Location defines dates of the daily schedules.
+	Location *time.Location
+	// Reporter receives fetch state, optional.
+	Reporter StateReporter
+}
+
+// Storage stores fetched schedules.
+type Storage interface {
+	SetPlannedSchedule(source, group, date, windows string) error
+}
+
+// StateReporter receives subsystem state changes.
+type StateReporter interface {
+	SetSubsystemState(name string, err error)
+}
+
+// Scheduler periodically imports the planned outage schedule of the group.
+type Scheduler struct {
+	config     Config
+	storage    Storage
+	client     *http.Client
+	cancelFunc context.CancelFunc
+}
+
+type yasnoWindow struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Type  string  `json:"type"`
+}
+
+type yasnoDay struct {
+	Title  string                   `json:"title"`
+	Groups map[string][]yasnoWindow `json:"groups"`
+}
+
+type yasnoResponse struct {
+	Components []struct {
+		DailySchedule map[string]map[string]yasnoDay `json:"dailySchedule"`
+	} `json:"components"`
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates and starts scheduler.
+func New(config Config, storage Storage) (scheduler *Scheduler, err error) {
+	if config.Group == "" {
+		return nil, errors.New("schedule group is not set")
+	}
+
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+
+	if config.Region == "" {
+		config.Region = defaultRegion
+	}
+
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaultCheckInterval
+	}
+
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+
+	scheduler = &Scheduler{config: config, storage: storage, client: &http.Client{Timeout: requestTimeout}}
+
+	ctx, cancelFunction := context.WithCancel(context.Background())
+	scheduler.cancelFunc = cancelFunction
+
+	go scheduler.run(ctx)
+
+	return scheduler, nil
+}
+
+// CheckSource fetches the schedule once to verify the source is reachable and its format is supported.
+func (scheduler *Scheduler) CheckSource() error {
+	_, err := scheduler.fetch(context.Background())
+
+	return err
+}
+
+// Close stops scheduler.
+func (scheduler *Scheduler) Close() {
+	scheduler.cancelFunc()
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (scheduler *Scheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		err := scheduler.importSchedule(ctx)
+		if err != nil {
+			log.Errorf("Failed to import planned schedule: %s", err)
+		}
+
+		if scheduler.config.Reporter != nil {
+			scheduler.config.Reporter.SetSubsystemState(subsystemName, err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (scheduler *Scheduler) importSchedule(ctx context.Context) error {
+	days, err := scheduler.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	for date, windows := range days {
+		if err = scheduler.storage.SetPlannedSchedule(Source, scheduler.config.Group, date,
+			schedule.FormatWindows(windows)); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{"group": scheduler.config.Group, "days": len(days)}).Debug("Planned schedule imported")
+
+	return nil
+}
+
+// fetch returns definite outage windows of the group by date.
+func (scheduler *Scheduler) fetch(ctx context.Context) (days map[string][]schedule.Window, err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, scheduler.config.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := scheduler.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", response.Status)
+	}
+
+	var body yasnoResponse
+
+	if err = json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode schedule: %w", err)
+	}
+
+	now := time.Now().In(scheduler.config.Location)
+	fallbackDates := map[string]time.Time{"today": now, "tomorrow": now.AddDate(0, 0, 1)}
+	days = make(map[string][]schedule.Window)
+
+	for _, component := range body.Components {
+		for key, day := range component.DailySchedule[scheduler.config.Region] {
+			date, ok := parseTitleDate(day.Title, scheduler.config.Location)
+			if !ok {
+				if date, ok = fallbackDates[key]; !ok {
+					continue
+				}
+			}
+
+			days[date.Format(database.DateFormat)] = convertWindows(day.Groups[scheduler.config.Group])
+		}
+	}
+
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no daily schedule of region %s", scheduler.config.Region)
+	}
+
+	return days, nil
+}
+
+func parseTitleDate(title string, location *time.Location) (date time.Time, ok bool) {
+	date, err := time.ParseInLocation("02.01.2006", titleDate.FindString(title), location)
+
+	return date, err == nil
+}
+
+// convertWindows converts hour offsets to schedule windows and joins adjacent ones.
+func convertWindows(yasnoWindows []yasnoWindow) (windows []schedule.Window) {
+	sort.Slice(yasnoWindows, func(i, j int) bool { return yasnoWindows[i].Start < yasnoWindows[j].Start })
+
+	for _, yasnoWindow := range yasnoWindows {
+		if yasnoWindow.Type != definiteOutage || yasnoWindow.End <= yasnoWindow.Start {
+			continue
+		}
+
+		window := schedule.Window{
+			Start: time.Duration(yasnoWindow.Start * float64(time.Hour)),
+			End:   time.Duration(yasnoWindow.End * float64(time.Hour)),
+		}
+
+		if last := len(windows) - 1; last >= 0 && window.Start <= windows[last].End {
+			windows[last].End = max(windows[last].End, window.End)
+
+			continue
+		}
+
+		windows = append(windows, window)
+	}
+
+	return windows
+}
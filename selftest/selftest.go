@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selftest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Check describes a single startup check.
+type Check struct {
+	Name string
+	// Essential checks abort startup on failure even in degrade mode.
+	Essential bool
+	Run       func() error
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name      string
+	Essential bool
+	Duration  time.Duration
+	Err       error
+}
+
+// Report is the outcome of all checks.
+type Report []Result
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Run executes checks one by one and logs their results.
+func Run(checks []Check) (report Report) {
+	for _, check := range checks {
+		start := time.Now()
+		err := check.Run()
+
+		result := Result{Name: check.Name, Essential: check.Essential, Duration: time.Since(start), Err: err}
+		report = append(report, result)
+
+		logEntry := log.WithFields(log.Fields{"check": check.Name, "duration": result.Duration})
+
+		if err != nil {
+			logEntry.Errorf("Self-test check failed: %s", err)
+		} else {
+			logEntry.Info("Self-test check passed")
+		}
+	}
+
+	return report
+}
+
+// Failed returns an error if any check failed, failFast makes non-essential check failures count as well.
+func (report Report) Failed(failFast bool) error {
+	var failed []string
+
+	for _, result := range report {
+		if result.Err != nil && (result.Essential || failFast) {
+			failed = append(failed, result.Name)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("self-test failed: %s", strings.Join(failed, ", "))
+}
+
+// Summary returns human readable report summary.
+func (report Report) Summary() string {
+	lines := make([]string, 0, len(report))
+
+	for _, result := range report {
+		if result.Err != nil {
+			lines = append(lines, fmt.Sprintf("FAIL %s: %s", result.Name, result.Err))
+		} else {
+			lines = append(lines, "OK   "+result.Name)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
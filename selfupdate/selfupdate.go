@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package selfupdate replaces the bot binary with a release downloaded from the configured URL. Releases are signed
+// with Ed25519, the signature is downloaded from the release URL with the ".sig" suffix, raw or base64 encoded. The
+// running service can't write its binary, so it stages the verified release in its working directory and the
+// privileged ExecStartPre of the unit installs it before the next start.
+package selfupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	downloadTimeout = 10 * time.Minute
+	maxBinarySize   = 256 << 20
+	maxSignatureLen = 1 << 10
+	signatureSuffix = ".sig"
+	stagedName      = "electrobot.update"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with release source configuration.
+type Config struct {
+	// URL of the release binary.
+	URL string
+	// PublicKey is the base64 encoded Ed25519 key releases are signed with.
+	PublicKey string
+	// Dir is the directory of the staged release.
+	Dir string
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// ParsePublicKey decodes base64 encoded Ed25519 public key.
+func ParsePublicKey(value string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// Stage downloads and verifies the release and keeps it with its signature in the staging directory.
+func Stage(ctx context.Context, config Config) error {
+	binary, signature, err := download(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if err = writeFile(filepath.Join(config.Dir, stagedName+signatureSuffix), signature, 0o600); err != nil {
+		return err
+	}
+
+	return writeFile(filepath.Join(config.Dir, stagedName), binary, 0o600)
+}
+
+// InstallStaged verifies the staged release again and replaces the binary with it, false is returned if there is no
+// staged release. The staged release is removed in any case, a bad one would fail every start.
+func InstallStaged(config Config, binaryPath string) (installed bool, err error) {
+	staged := filepath.Join(config.Dir, stagedName)
+
+	binary, err := os.ReadFile(staged)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	defer os.Remove(staged)
+	defer os.Remove(staged + signatureSuffix)
+
+	if err != nil {
+		return false, err
+	}
+
+	signature, err := os.ReadFile(staged + signatureSuffix)
+	if err != nil {
+		return false, err
+	}
+
+	if err = verify(config.PublicKey, binary, signature); err != nil {
+		return false, err
+	}
+
+	return true, replaceBinary(binaryPath, binary)
+}
+
+// Install downloads and verifies the release and replaces the binary with it.
+func Install(ctx context.Context, config Config, binaryPath string) error {
+	binary, _, err := download(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	return replaceBinary(binaryPath, binary)
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// download returns the verified release binary and its signature.
+func download(ctx context.Context, config Config) (binary, signature []byte, err error) {
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	if binary, err = get(ctx, config.URL, maxBinarySize); err != nil {
+		return nil, nil, fmt.Errorf("failed to download release: %w", err)
+	}
+
+	if signature, err = get(ctx, config.URL+signatureSuffix, maxSignatureLen); err != nil {
+		return nil, nil, fmt.Errorf("failed to download signature: %w", err)
+	}
+
+	if err = verify(config.PublicKey, binary, signature); err != nil {
+		return nil, nil, err
+	}
+
+	return binary, signature, nil
+}
+
+func get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", response.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response exceeds %d bytes", limit)
+	}
+
+	return data, nil
+}
+
+// verify checks the raw or base64 encoded signature of the binary.
+func verify(publicKey string, binary, signature []byte) error {
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	if len(signature) != ed25519.SignatureSize {
+		if signature, err = base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature))); err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+	}
+
+	if !ed25519.Verify(key, binary, signature) {
+		return errors.New("release signature verification failed")
+	}
+
+	return nil
+}
+
+// replaceBinary writes the new binary next to the old one and renames it over, so the old binary stays intact if
+// writing fails.
+func replaceBinary(binaryPath string, binary []byte) error {
+	tmpPath := binaryPath + ".new"
+
+	if err := writeFile(tmpPath, binary, 0o755); err != nil { //nolint:gosec // the binary must be executable
+		os.Remove(tmpPath)
+
+		return err
+	}
+
+	return os.Rename(tmpPath, binaryPath)
+}
+
+func writeFile(fileName string, data []byte, perm os.FileMode) (err error) {
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if _, err = file.Write(data); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selfupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestStagedUpdate(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Can't generate key: %s", err)
+	}
+
+	release := []byte("new release")
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, release))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/electrobot":
+			_, _ = w.Write(release)
+		case "/electrobot.sig":
+			_, _ = w.Write([]byte(signature + "\n"))
+		case "/forged":
+			_, _ = w.Write([]byte("forged release"))
+		case "/forged.sig":
+			_, _ = w.Write([]byte(signature))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "bin")
+
+	if err = os.WriteFile(binary, []byte("old release"), 0o600); err != nil {
+		t.Fatalf("Can't write binary: %s", err)
+	}
+
+	config := Config{URL: server.URL + "/electrobot", PublicKey: base64.StdEncoding.EncodeToString(publicKey), Dir: dir}
+
+	if installed, err := InstallStaged(config, binary); err != nil || installed {
+		t.Errorf("Wrong result without staged release: %v, %v", installed, err)
+	}
+
+	forged := config
+	forged.URL = server.URL + "/forged"
+
+	if err = Stage(context.Background(), forged); err == nil {
+		t.Error("Forged release is staged")
+	}
+
+	if err = Stage(context.Background(), config); err != nil {
+		t.Fatalf("Can't stage release: %s", err)
+	}
+
+	if installed, err := InstallStaged(config, binary); err != nil || !installed {
+		t.Fatalf("Can't install staged release: %v, %v", installed, err)
+	}
+
+	if data, err := os.ReadFile(binary); err != nil || string(data) != string(release) {
+		t.Errorf("Wrong installed binary: %q, %v", data, err)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, stagedName)); !os.IsNotExist(err) {
+		t.Errorf("Staged release is not removed: %v", err)
+	}
+}
@@ -0,0 +1,347 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/backup"
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	// maxDocumentSize is the Bot API limit of uploaded documents.
+	maxDocumentSize = 50 << 20
+	// restartDelay lets the reply to /restart be sent before the bot stops.
+	restartDelay    = 3 * time.Second
+	defaultLogLines = 20
+	maxLogLines     = 200
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Restarter restarts the bot process.
+type Restarter interface {
+	Restart()
+}
+
+// LogSource provides the latest log lines.
+type LogSource interface {
+	Tail(count int) []string
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// SelfTestCompleted sends the startup self-test summary to admins.
+func (bot *ElectroBot) SelfTestCompleted(summary string) {
+	bot.notifyAdmins(func(lang string, _ *time.Location) string {
+		return i18n.T(lang, "🩺 Self-test summary:\n%s", summary)
+	})
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// isAdmin returns true for configured admins and the bot owner. Admins are users, not chats, otherwise every member
+// of a group with an admin would be one.
+func (bot *ElectroBot) isAdmin(userID int64) bool {
+	return userID != 0 && (slices.Contains(bot.admins, userID) || userID == bot.OwnerChatID())
+}
+
+// notifyAdmins sends text to the owner and configured admins.
+func (bot *ElectroBot) notifyAdmins(text func(lang string, location *time.Location) string) {
+	admins := slices.Clone(bot.admins)
+
+	if owner := bot.OwnerChatID(); owner != 0 && !slices.Contains(admins, owner) {
+		admins = append(admins, owner)
+	}
+
+	bot.notifyUsers(admins, text, 0, nil)
+}
+
+// senderID returns the ID of the user who sent the message, 0 for messages without sender (e.g. channel posts).
+func senderID(user *botApi.User) int64 {
+	if user == nil {
+		return 0
+	}
+
+	return user.ID
+}
+
+// handleAdminCommand checks admin permissions, logs and dispatches admin commands. Admin commands are refused in
+// groups as their replies (tokens, user list, backups) must not be seen by other members.
+func (bot *ElectroBot) handleAdminCommand(message *botApi.Message, command, arguments, lang string) string {
+	chatID, userID := message.Chat.ID, senderID(message.From)
+
+	if !bot.isAdmin(userID) {
+		log.WithFields(log.Fields{
+			"chatID": chatID, "userID": userID, "command": command,
+		}).Warn("Unauthorized admin command attempt")
+
+		return i18n.T(lang, "Sorry, this command is available to admins only")
+	}
+
+	if !message.Chat.IsPrivate() {
+		return i18n.T(lang, "Admin commands are available in a private chat with the bot only")
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "command": command, "arguments": arguments}).Info("Admin command")
+
+	switch command {
+	case "users":
+		return bot.handleUsersCommand(chatID, lang)
+	case "broadcast":
+		return bot.handleBroadcastCommand(chatID, arguments, lang)
+	case "dbstats":
+		return bot.handleDBStatsCommand(chatID, lang)
+	case "token":
+		return bot.handleTokenCommand(chatID, arguments, lang)
+	case "webhook":
+		return bot.handleWebhookCommand(chatID, arguments, lang)
+	case "backup":
+		return bot.handleBackupCommand(chatID, arguments, lang)
+	case "location":
+		return bot.handleLocationCommand(chatID, arguments, lang)
+	case "sensors":
+		return bot.handleSensorsCommand(chatID, arguments, lang)
+	case "schedule":
+		return bot.handleScheduleEditCommand(chatID, arguments, lang)
+	case "update":
+		return bot.handleUpdateCommand(chatID, arguments, lang)
+	case "restart":
+		return bot.handleRestartCommand(chatID, lang)
+	case "logs":
+		return bot.handleLogsCommand(chatID, arguments, lang)
+	case "user":
+		return bot.handleUserCommand(chatID, arguments, lang)
+	case "config":
+		return bot.handleConfigCommand(chatID, arguments, lang)
+	case "flags":
+		return bot.handleFlagsCommand(chatID, arguments, lang)
+	case "annotate":
+		return bot.handleAnnotateCommand(arguments, lang, bot.userLocation(chatID))
+	default:
+		return bot.handleHelpCommand(userID, lang)
+	}
+}
+
+// handleUsersCommand lists registered users, a list longer than the message limit is sent in several messages and
+// the last part is returned.
+func (bot *ElectroBot) handleUsersCommand(chatID int64, lang string) string {
+	users, err := bot.db.GetUsers()
+	if err != nil {
+		log.Errorf("Failed to get users: %s", err)
+
+		return i18n.T(lang, "Failed to get users. Please try again later")
+	}
+
+	if len(users) == 0 {
+		return i18n.T(lang, "There are no registered users")
+	}
+
+	text := i18n.T(lang, "Registered users (%d):", len(users))
+
+	for _, user := range users {
+		text += "\n" + userLine(user)
+	}
+
+	parts := splitText(text)
+
+	for _, part := range parts[:len(parts)-1] {
+		bot.reply(botApi.NewMessage(chatID, part))
+	}
+
+	return parts[len(parts)-1]
+}
+
+// handleBroadcastCommand starts the broadcast in background and reports delivery results to the admin when done.
+func (bot *ElectroBot) handleBroadcastCommand(chatID int64, arguments, lang string) string {
+	text := strings.TrimSpace(arguments)
+	if text == "" {
+		return i18n.T(lang, "Usage: /broadcast [#tag] <text>")
+	}
+
+	var (
+		users []int64
+		err   error
+	)
+
+	// the segment tag is optional, all users get the broadcast without it
+	if tag, rest, _ := strings.Cut(text, " "); strings.HasPrefix(tag, "#") && strings.TrimSpace(rest) != "" {
+		if users, err = bot.db.GetTaggedUsers(strings.TrimPrefix(tag, "#")); err != nil {
+			log.Errorf("Failed to get tagged users: %s", err)
+
+			return i18n.T(lang, "Failed to get users. Please try again later")
+		}
+
+		if len(users) == 0 {
+			return i18n.T(lang, "No users are tagged %s", tag)
+		}
+
+		text = strings.TrimSpace(rest)
+	} else if users, err = bot.db.GetAllUsers(); err != nil {
+		log.Errorf("Failed to get all users: %s", err)
+
+		return i18n.T(lang, "Failed to get users. Please try again later")
+	}
+
+	go func() {
+		delivered, queued, failed := bot.notifyUsers(users, func(string, *time.Location) string { return text }, 0, nil)
+
+		log.WithFields(log.Fields{"delivered": delivered, "queued": queued, "failed": failed}).Info("Broadcast finished")
+
+		if _, err := bot.send(botApi.NewMessage(chatID, i18n.T(lang,
+			"Broadcast finished: %d delivered, %d queued, %d failed", delivered, queued, failed))); err != nil {
+			log.Errorf("Failed to send broadcast report: %s", err)
+		}
+	}()
+
+	return i18n.T(lang, "Broadcast started")
+}
+
+// handleBackupCommand makes a database snapshot in background and sends it to the admin as a document. The upload
+// is refused in low-bandwidth mode unless forced.
+func (bot *ElectroBot) handleBackupCommand(chatID int64, arguments, lang string) string {
+	if bot.LowBandwidth() && strings.TrimSpace(arguments) != "force" {
+		return i18n.T(lang, "The bot is on a backup uplink, the backup is not sent to save traffic. "+
+			"Use /backup force to send it anyway")
+	}
+
+	go func() {
+		if err := bot.sendBackup(chatID); err != nil {
+			log.Errorf("Failed to send backup: %s", err)
+
+			if _, err := bot.send(botApi.NewMessage(chatID, i18n.T(lang, "Backup failed: %s", err))); err != nil {
+				log.Errorf("Failed to send backup report: %s", err)
+			}
+		}
+	}()
+
+	return i18n.T(lang, "Backup started")
+}
+
+func (bot *ElectroBot) sendBackup(chatID int64) error {
+	dir, err := os.MkdirTemp("", "electrobot-backup")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(dir)
+
+	fileName, err := backup.Create(bot.db, dir)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return err
+	}
+
+	if info.Size() > maxDocumentSize {
+		return fmt.Errorf("backup size %d KiB exceeds the Telegram limit, configure scheduled backups instead",
+			info.Size()/1024) //nolint:gomnd
+	}
+
+	if _, err = bot.send(botApi.NewDocument(chatID, botApi.FilePath(fileName))); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "size": info.Size()}).Info("Backup sent")
+
+	return nil
+}
+
+func (bot *ElectroBot) handleRestartCommand(chatID int64, lang string) string {
+	if bot.restarter == nil {
+		return i18n.T(lang, "Restart is not available")
+	}
+
+	log.WithField("chatID", chatID).Warn("Restart requested")
+
+	time.AfterFunc(restartDelay, bot.restarter.Restart)
+
+	return i18n.T(lang, "Restarting, I'll be back in a minute")
+}
+
+// handleLogsCommand returns the latest log lines, logs longer than the message limit are sent in several messages.
+func (bot *ElectroBot) handleLogsCommand(chatID int64, arguments, lang string) string {
+	if bot.logs == nil {
+		return i18n.T(lang, "Logs are not available")
+	}
+
+	count := defaultLogLines
+
+	if arguments = strings.TrimSpace(arguments); arguments != "" {
+		value, err := strconv.Atoi(arguments)
+		if err != nil || value <= 0 || value > maxLogLines {
+			return i18n.T(lang, "Usage: /logs [N], where N is from 1 to %d", maxLogLines)
+		}
+
+		count = value
+	}
+
+	lines := bot.logs.Tail(count)
+	if len(lines) == 0 {
+		return i18n.T(lang, "There are no log lines")
+	}
+
+	parts := splitText(strings.Join(lines, "\n"))
+
+	for _, part := range parts[:len(parts)-1] {
+		bot.reply(botApi.NewMessage(chatID, part))
+	}
+
+	return parts[len(parts)-1]
+}
+
+// handleDBStatsCommand reports database statistics followed by the channel reach if channels are configured.
+func (bot *ElectroBot) handleDBStatsCommand(chatID int64, lang string) string {
+	stats, err := bot.db.GetStats()
+	if err != nil {
+		log.Errorf("Failed to get database stats: %s", err)
+
+		return i18n.T(lang, "Failed to get database statistics. Please try again later")
+	}
+
+	text := i18n.T(lang, "Database statistics:\nSchema version: %d\nUsers: %s\nEvents: %s\nReminders: %s"+
+		"\nAPI tokens: %s\nSize: %s KiB", stats.SchemaVersion, i18n.Number(lang, stats.Users),
+		i18n.Number(lang, stats.Events), i18n.Number(lang, stats.Reminders), i18n.Number(lang, stats.APITokens),
+		i18n.Number(lang, int(stats.Size/1024))) //nolint:gomnd
+
+	if reach := bot.channelReachText(stats.Users, lang, bot.userLocation(chatID)); reach != "" {
+		text += "\n\n" + reach
+	}
+
+	return text
+}
@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	// anomalyHistorySize is the number of previous outages the outage duration is compared to.
+	anomalyHistorySize = 50
+	// anomalyMinHistory is the minimal number of previous outages to tell the outage is unusually long.
+	anomalyMinHistory = 10
+	// anomalyDeviations is the number of standard deviations above the mean duration of an unusually long outage.
+	anomalyDeviations = 3
+	// anomalyScheduleTolerance is how far from planned windows an outage may start and still be expected.
+	anomalyScheduleTolerance = time.Hour
+	anomalyFlapWindow        = time.Hour
+	anomalyFlapCount         = 3
+)
+
+const (
+	anomalyLong outageAnomaly = iota
+	anomalyUnscheduled
+	anomalyFlapping
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// outageAnomaly is an unusual outage pattern reported to users and admins.
+type outageAnomaly int
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// outageAnomalies compares the main location outage with the outage history and the planned schedule.
+func (bot *ElectroBot) outageAnomalies(start, end time.Time) []outageAnomaly {
+	history, err := bot.db.GetOutages(anomalyHistorySize + 1)
+	if err != nil {
+		log.Errorf("Failed to get outages: %s", err)
+	}
+
+	planned, scheduleKnown := bot.plannedOutages(start)
+
+	anomalies := detectAnomalies(start, end, history, planned, scheduleKnown)
+	if len(anomalies) != 0 {
+		log.WithFields(log.Fields{
+			"start": start.UTC(), "end": end.UTC(), "anomalies": anomalies,
+		}).Warn("Outage anomaly detected")
+	}
+
+	return anomalies
+}
+
+// plannedOutages returns planned outages of the default schedule group around the outage start, false is returned
+// if the group has no schedule for the day.
+func (bot *ElectroBot) plannedOutages(start time.Time) (planned []scheduledOutage, known bool) {
+	group := bot.defaultScheduleGroup()
+	if group == "" {
+		return nil, false
+	}
+
+	groups, err := bot.scheduledWindows(start.In(bot.defaultLocation))
+	if err != nil {
+		log.Errorf("Failed to get scheduled windows: %s", err)
+
+		return nil, false
+	}
+
+	if _, ok := groups[group]; !ok {
+		return nil, false
+	}
+
+	outages, err := bot.scheduledOutages(start.Add(-upcomingOutageLookahead), start.Add(anomalyScheduleTolerance))
+	if err != nil {
+		log.Errorf("Failed to get planned outages: %s", err)
+
+		return nil, false
+	}
+
+	for _, outage := range outages {
+		if outage.Group == group {
+			planned = append(planned, outage)
+		}
+	}
+
+	return planned, true
+}
+
+// sendAnomalyAlert tells admins about the unusual outage.
+func (bot *ElectroBot) sendAnomalyAlert(start, end time.Time, anomalies []outageAnomaly) {
+	bot.notifyAdmins(func(lang string, location *time.Location) string {
+		return i18n.T(lang, "⚠️ Outage anomaly: %s\nPower was off from %s to %s", anomaliesText(lang, anomalies),
+			i18n.DateTime(lang, start.In(location)), i18n.DateTime(lang, end.In(location)))
+	})
+}
+
+// detectAnomalies returns anomalies of the outage, history may contain the outage itself and later outages.
+func detectAnomalies(
+	start, end time.Time, history []database.Outage, planned []scheduledOutage, scheduleKnown bool,
+) (anomalies []outageAnomaly) {
+	durations := make([]float64, 0, len(history))
+	flaps := 1
+
+	for _, outage := range history {
+		if outage.End.After(start) {
+			continue
+		}
+
+		durations = append(durations, outage.End.Sub(outage.Start).Seconds())
+
+		if outage.End.After(end.Add(-anomalyFlapWindow)) {
+			flaps++
+		}
+	}
+
+	if isUnusuallyLong(end.Sub(start).Seconds(), durations) {
+		anomalies = append(anomalies, anomalyLong)
+	}
+
+	if scheduleKnown && !isPlanned(start, planned) {
+		anomalies = append(anomalies, anomalyUnscheduled)
+	}
+
+	if flaps >= anomalyFlapCount {
+		anomalies = append(anomalies, anomalyFlapping)
+	}
+
+	return anomalies
+}
+
+// isUnusuallyLong tells whether the duration is far above the previous ones: more than anomalyDeviations standard
+// deviations above the mean and twice longer than it, the latter avoids alerts on a history of equal outages.
+func isUnusuallyLong(duration float64, durations []float64) bool {
+	if len(durations) < anomalyMinHistory {
+		return false
+	}
+
+	var sum, squares float64
+
+	for _, value := range durations {
+		sum += value
+	}
+
+	mean := sum / float64(len(durations))
+
+	for _, value := range durations {
+		squares += (value - mean) * (value - mean)
+	}
+
+	deviation := math.Sqrt(squares / float64(len(durations)))
+
+	return duration > mean+anomalyDeviations*deviation && duration > 2*mean
+}
+
+func isPlanned(start time.Time, planned []scheduledOutage) bool {
+	for _, outage := range planned {
+		if !start.Before(outage.Start.Add(-anomalyScheduleTolerance)) &&
+			start.Before(outage.End.Add(anomalyScheduleTolerance)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func anomaliesText(lang string, anomalies []outageAnomaly) string {
+	texts := make([]string, 0, len(anomalies))
+
+	for _, anomaly := range anomalies {
+		switch anomaly {
+		case anomalyLong:
+			texts = append(texts, i18n.T(lang, "unusually long outage"))
+
+		case anomalyUnscheduled:
+			texts = append(texts, i18n.T(lang, "outage outside the planned schedule"))
+
+		case anomalyFlapping:
+			texts = append(texts, i18n.T(lang, "power is flapping"))
+		}
+	}
+
+	return strings.Join(texts, ", ")
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"electrobot/database"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestDetectAnomalies(t *testing.T) {
+	base := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+
+	// ten previous outages lasting an hour, a day apart
+	var history []database.Outage
+
+	for i := 1; i <= anomalyMinHistory; i++ {
+		start := base.AddDate(0, 0, -i)
+		history = append(history, database.Outage{ID: int64(i), Start: start, End: start.Add(time.Hour)})
+	}
+
+	planned := []scheduledOutage{{Group: "1", Start: base, End: base.Add(4 * time.Hour)}}
+
+	flapping := []database.Outage{
+		{Start: base.Add(-40 * time.Minute), End: base.Add(-30 * time.Minute)},
+		{Start: base.Add(-20 * time.Minute), End: base.Add(-10 * time.Minute)},
+	}
+
+	testData := []struct {
+		name          string
+		start         time.Time
+		end           time.Time
+		history       []database.Outage
+		scheduleKnown bool
+		anomalies     []outageAnomaly
+	}{
+		{name: "usual", start: base, end: base.Add(time.Hour), history: history, scheduleKnown: true},
+		{
+			name: "long", start: base, end: base.Add(5 * time.Hour), history: history, scheduleKnown: true,
+			anomalies: []outageAnomaly{anomalyLong},
+		},
+		{name: "short history", start: base, end: base.Add(5 * time.Hour), history: history[1:]},
+		{
+			name: "current outage in history", start: base, end: base.Add(time.Hour),
+			history: append([]database.Outage{{Start: base, End: base.Add(time.Hour)}}, history...),
+		},
+		{name: "near planned", start: base.Add(-30 * time.Minute), end: base, scheduleKnown: true},
+		{
+			name: "unscheduled", start: base.Add(-3 * time.Hour), end: base.Add(-2 * time.Hour), scheduleKnown: true,
+			anomalies: []outageAnomaly{anomalyUnscheduled},
+		},
+		{name: "unknown schedule", start: base.Add(-3 * time.Hour), end: base.Add(-2 * time.Hour)},
+		{
+			name: "flapping", start: base.Add(-5 * time.Minute), end: base, history: flapping,
+			anomalies: []outageAnomaly{anomalyFlapping},
+		},
+		{name: "two outages", start: base.Add(-5 * time.Minute), end: base, history: flapping[1:]},
+	}
+
+	for _, item := range testData {
+		anomalies := detectAnomalies(item.start, item.end, item.history, planned, item.scheduleKnown)
+
+		if !reflect.DeepEqual(anomalies, item.anomalies) {
+			t.Errorf("Wrong anomalies of %q: %v", item.name, anomalies)
+		}
+	}
+}
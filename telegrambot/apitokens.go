@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"fmt"
+	"strings"
+
+	"electrobot/apitoken"
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const maxTokenNameLength = 32
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleTokenCommand manages API tokens of machine HTTP endpoints.
+func (bot *ElectroBot) handleTokenCommand(chatID int64, arguments, lang string) string {
+	fields := strings.Fields(arguments)
+	if len(fields) == 0 {
+		fields = []string{"list"}
+	}
+
+	switch {
+	case fields[0] == "list" && len(fields) == 1:
+		return bot.listAPITokens(lang)
+
+	case fields[0] == "add" && (len(fields) == 2 || len(fields) == 3):
+		scope := string(apitoken.ScopeRead)
+		if len(fields) == 3 {
+			scope = fields[2]
+		}
+
+		return bot.addAPIToken(chatID, fields[1], scope, lang)
+
+	case fields[0] == "revoke" && len(fields) == 2:
+		return bot.revokeAPIToken(chatID, fields[1], lang)
+
+	default:
+		return i18n.T(lang, "Usage:\n/token list - show API tokens"+
+			"\n/token add <name> [read|admin] - create API token"+
+			"\n/token revoke <name> - revoke API token")
+	}
+}
+
+func (bot *ElectroBot) listAPITokens(lang string) string {
+	tokens, err := bot.db.GetAPITokens()
+	if err != nil {
+		log.Errorf("Failed to get API tokens: %s", err)
+
+		return i18n.T(lang, "Failed to get API tokens. Please try again later")
+	}
+
+	if len(tokens) == 0 {
+		return i18n.T(lang, "There are no API tokens")
+	}
+
+	text := i18n.T(lang, "API tokens:")
+
+	for _, token := range tokens {
+		text += fmt.Sprintf("\n%s (%s), %s", token.Name, token.Scope,
+			i18n.DateTime(lang, token.CreatedAt.In(bot.defaultLocation)))
+	}
+
+	return text
+}
+
+func (bot *ElectroBot) addAPIToken(chatID int64, name, scopeName, lang string) string {
+	scope, err := apitoken.ParseScope(scopeName)
+	if err != nil {
+		return i18n.T(lang, "Unknown token scope %q, use read or admin", scopeName)
+	}
+
+	if len(name) > maxTokenNameLength {
+		return i18n.T(lang, "Token name is too long, please keep it under %s",
+			i18n.N(lang, maxTokenNameLength, "%d character|%d characters"))
+	}
+
+	token, hash, err := apitoken.Generate()
+	if err != nil {
+		log.Errorf("Failed to generate API token: %s", err)
+
+		return i18n.T(lang, "Failed to create API token. Please try again later")
+	}
+
+	if err = bot.db.AddAPIToken(name, hash, string(scope)); err != nil {
+		log.Errorf("Failed to store API token: %s", err)
+
+		return i18n.T(lang, "Failed to create API token %q, the name may be taken already", name)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "name": name, "scope": scope}).Info("API token created")
+
+	return i18n.T(lang, "API token %q (%s) created:\n%s\nIt is shown only once, delete this message after saving it",
+		name, scope, token)
+}
+
+func (bot *ElectroBot) revokeAPIToken(chatID int64, name, lang string) string {
+	if err := bot.db.RemoveAPIToken(name); err != nil {
+		log.Errorf("Failed to remove API token: %s", err)
+
+		return i18n.T(lang, "API token %q not found", name)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "name": name}).Info("API token revoked")
+
+	return i18n.T(lang, "API token %q revoked", name)
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+	"time"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// notificationBatch collects power state changes of several locations for the chat until the batch window ends.
+type notificationBatch struct {
+	texts    []string
+	keyboard *botApi.InlineKeyboardMarkup
+	extras   notificationExtras
+	timer    *time.Timer
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// batchNotification adds the notification to the batch of the chat subscribed to several locations and returns
+// true, the batch is sent when the window started by its first notification ends. False is returned if the
+// notification should be sent right away.
+func (bot *ElectroBot) batchNotification(chatID int64, text string, keyboard *botApi.InlineKeyboardMarkup,
+	extras notificationExtras,
+) bool {
+	window := bot.notificationBatchWindow.Load()
+	if window <= 0 {
+		return false
+	}
+
+	subscriptions, err := bot.db.GetSubscriptions(chatID)
+	if err != nil {
+		log.WithField("chatID", chatID).Errorf("Failed to get subscriptions: %s", err)
+
+		return false
+	}
+
+	if len(subscriptions) < 2 {
+		return false
+	}
+
+	bot.batchMutex.Lock()
+	defer bot.batchMutex.Unlock()
+
+	batch, ok := bot.batches[chatID]
+	if !ok {
+		batch = &notificationBatch{timer: time.AfterFunc(window, func() { bot.flushBatch(chatID) })}
+		bot.batches[chatID] = batch
+	}
+
+	batch.texts = append(batch.texts, text)
+	batch.keyboard = keyboard
+	batch.extras |= extras
+
+	return true
+}
+
+// flushBatch sends the batch of the chat as one message, the keyboard is kept only under a single notification.
+func (bot *ElectroBot) flushBatch(chatID int64) {
+	bot.batchMutex.Lock()
+
+	batch, ok := bot.batches[chatID]
+	delete(bot.batches, chatID)
+
+	bot.batchMutex.Unlock()
+
+	if !ok {
+		return
+	}
+
+	batch.timer.Stop()
+
+	keyboard := batch.keyboard
+	if len(batch.texts) > 1 {
+		keyboard = nil
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "count": len(batch.texts)}).Debug("Sending notification batch")
+
+	if _, err := bot.deliver(chatID, strings.Join(batch.texts, "\n\n"), keyboard, batch.extras); err != nil {
+		log.Errorf("Failed to send notification batch to user %d: %s", chatID, err)
+
+		if isChatUnreachable(err) {
+			bot.unregisterUnreachableUser(chatID, err)
+		}
+	}
+}
+
+// flushBatches sends all batches without waiting for their windows to end.
+func (bot *ElectroBot) flushBatches() {
+	bot.batchMutex.Lock()
+
+	chats := make([]int64, 0, len(bot.batches))
+
+	for chatID := range bot.batches {
+		chats = append(chats, chatID)
+	}
+
+	bot.batchMutex.Unlock()
+
+	for _, chatID := range chats {
+		bot.flushBatch(chatID)
+	}
+}
@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultChannelFlapWindow = 10 * time.Minute
+	// channelSampleInterval is the period of channel subscriber count sampling, counts change slowly.
+	channelSampleInterval = 6 * time.Hour
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// channel is a Telegram channel power announcements are published to, identified by ID or @username.
+type channel struct {
+	id       int64
+	username string
+	// the last post is edited instead of posting again while power flaps
+	lastPostID   int
+	lastPostText string
+	lastPostTime time.Time
+	// subscribers is the member count sampled at sampledAt, the Bot API doesn't report post views.
+	subscribers int
+	sampledAt   time.Time
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func parseChannels(channels []string) (parsed []channel, err error) {
+	for _, value := range channels {
+		value = strings.TrimSpace(value)
+
+		if strings.HasPrefix(value, "@") && len(value) > 1 {
+			parsed = append(parsed, channel{username: value})
+
+			continue
+		}
+
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid channel %q, expected @username or chat ID", value)
+		}
+
+		parsed = append(parsed, channel{id: id})
+	}
+
+	return parsed, nil
+}
+
+// publishToChannels sends the announcement in the default language and timezone to every configured channel,
+// the bot must be a channel admin allowed to post messages. Announcements within the flap window after the previous
+// one are appended to the last post by an edit, which doesn't notify channel subscribers, so power flaps don't
+// flood the channel.
+func (bot *ElectroBot) publishToChannels(text func(lang string, location *time.Location) string) {
+	bot.channelMutex.Lock()
+	defer bot.channelMutex.Unlock()
+
+	announcement := text(bot.defaultLanguage, bot.defaultLocation)
+	now := time.Now()
+
+	for i := range bot.channels {
+		channel := &bot.channels[i]
+
+		if combined := channel.lastPostText + "\n\n" + announcement; channel.lastPostID != 0 &&
+			now.Sub(channel.lastPostTime) < bot.channelFlapWindow.Load() && utf16Length(combined) <= maxMessageLength {
+			err := bot.editChannelPost(channel, combined)
+			if err == nil {
+				channel.lastPostText, channel.lastPostTime = combined, now
+
+				continue
+			}
+
+			// a failed edit falls back to a new post
+			log.WithField("channel", channel.name()).Warnf("Failed to edit channel post: %s", err)
+		}
+
+		message := botApi.NewMessage(channel.id, announcement)
+
+		if channel.username != "" {
+			message = botApi.NewMessageToChannel(channel.username, announcement)
+		}
+
+		posted, err := bot.send(message)
+		if err != nil {
+			log.WithField("channel", channel.name()).Errorf("Failed to publish to channel: %s", err)
+
+			continue
+		}
+
+		channel.lastPostID, channel.lastPostText, channel.lastPostTime = posted.MessageID, announcement, now
+	}
+}
+
+// runChannelSampling periodically samples channel subscriber counts for reach estimates.
+func (bot *ElectroBot) runChannelSampling(ctx context.Context) {
+	ticker := time.NewTicker(channelSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		bot.sampleChannels()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (bot *ElectroBot) sampleChannels() {
+	bot.channelMutex.Lock()
+	defer bot.channelMutex.Unlock()
+
+	for i := range bot.channels {
+		channel := &bot.channels[i]
+
+		count, err := bot.botApi.GetChatMembersCount(botApi.ChatMemberCountConfig{
+			ChatConfig: botApi.ChatConfig{ChatID: channel.id, SuperGroupUsername: channel.username},
+		})
+		if err != nil {
+			log.WithField("channel", channel.name()).Warnf("Failed to get channel subscribers: %s", err)
+
+			continue
+		}
+
+		channel.subscribers, channel.sampledAt = count, time.Now()
+	}
+}
+
+// channelReachText estimates how many people the announcements reach, registered users and channel subscribers may
+// overlap. Empty text is returned without channels.
+func (bot *ElectroBot) channelReachText(users int, lang string, location *time.Location) string {
+	bot.channelMutex.Lock()
+	defer bot.channelMutex.Unlock()
+
+	if len(bot.channels) == 0 {
+		return ""
+	}
+
+	lines := []string{i18n.T(lang, "Channel reach:")}
+	subscribers := 0
+
+	for _, channel := range bot.channels {
+		if channel.sampledAt.IsZero() {
+			lines = append(lines, i18n.T(lang, "%s: not sampled yet", channel.name()))
+
+			continue
+		}
+
+		subscribers += channel.subscribers
+		lines = append(lines, i18n.T(lang, "%s: %s at %s", channel.name(), i18n.N(lang, channel.subscribers,
+			"%s subscriber|%s subscribers", i18n.Number(lang, channel.subscribers)),
+			i18n.DateTime(lang, channel.sampledAt.In(location))))
+	}
+
+	lines = append(lines, i18n.T(lang, "Estimated reach: up to %s people (users: %s, channel subscribers: %s)",
+		i18n.Number(lang, users+subscribers), i18n.Number(lang, users), i18n.Number(lang, subscribers)))
+
+	return strings.Join(lines, "\n")
+}
+
+func (bot *ElectroBot) editChannelPost(channel *channel, text string) error {
+	edit := botApi.NewEditMessageText(channel.id, channel.lastPostID, text)
+	edit.ChannelUsername = channel.username
+
+	_, err := bot.send(edit)
+
+	return err
+}
+
+func (channel *channel) name() string {
+	if channel.username != "" {
+		return channel.username
+	}
+
+	return strconv.FormatInt(channel.id, 10)
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"electrobot/telegrambot"
+	"electrobot/telegramtest"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const channelID = -100500
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestChannelFlaps(t *testing.T) {
+	testData := []struct {
+		name       string
+		flapWindow time.Duration
+		edited     bool
+	}{
+		{name: "flap", flapWindow: time.Hour, edited: true},
+		{name: "stable", flapWindow: time.Nanosecond},
+	}
+
+	for _, item := range testData {
+		t.Run(item.name, func(t *testing.T) {
+			server, bot, _ := newTestBot(t, telegrambot.Config{
+				Channels: []string{"-100500"}, ChannelFlapWindow: item.flapWindow,
+			})
+
+			start := time.Now()
+
+			bot.PowerOff(start)
+			off := checkReply(t, server, channelID, "Power went off at")
+
+			bot.PowerOn(start, start.Add(time.Minute))
+
+			if !item.edited {
+				if on := checkReply(t, server, channelID, "Power is back at"); on.Method != "sendMessage" {
+					t.Errorf("Wrong method of stable power announcement: %s", on.Method)
+				}
+
+				return
+			}
+
+			on := checkReply(t, server, channelID, off.Text+"\n\nPower is back at")
+			if on.Method != "editMessageText" || on.ID != off.ID {
+				t.Errorf("Flap is not appended to the last post: %s %d", on.Method, on.ID)
+			}
+
+			bot.PowerOff(start.Add(2 * time.Minute))
+
+			if offAgain := checkReply(t, server, channelID, on.Text); offAgain.ID != off.ID ||
+				strings.Count(offAgain.Text, "Power went off at") != 2 {
+				t.Errorf("Wrong flap post: %q", offAgain.Text)
+			}
+		})
+	}
+}
+
+func TestChannelReach(t *testing.T) {
+	server := telegramtest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.SetMemberCount("-100500", 1200)
+	server.SetMemberCount("@outages", 34)
+
+	startServerBot(t, server, telegrambot.Config{
+		Admins: []int64{adminID}, Channels: []string{"-100500", "@outages"},
+	}, newTestDatabase(t))
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.SendMessage(adminID, "/dbstats")
+	message := checkReply(t, server, adminID, "Database statistics:")
+
+	for _, line := range []string{
+		"\n\nChannel reach:\n-100500: 1,200 subscribers at ", "\n@outages: 34 subscribers at ",
+		"\nEstimated reach: up to 1,235 people (users: 1, channel subscribers: 1,234)",
+	} {
+		if !strings.Contains(message.Text, line) {
+			t.Errorf("Channel reach %q not found in %q", line, message.Text)
+		}
+	}
+}
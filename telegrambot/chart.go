@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+	"time"
+
+	"electrobot/chart"
+	"electrobot/i18n"
+	"electrobot/report"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	chartWeekDays  = 7
+	chartMonthDays = 30
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleChartCommand returns a photo with daily outage hours, text is returned instead if the chart can't be made.
+// Daily totals are sent as text in low-bandwidth mode and in builds without charts.
+func (bot *ElectroBot) handleChartCommand(chatID int64, arguments, lang string, location *time.Location,
+) (photo *botApi.PhotoConfig, text string) {
+	count := chartWeekDays
+	caption := i18n.T(lang, "Hours without power in the last 7 days")
+
+	switch strings.TrimSpace(arguments) {
+	case "", "week":
+	case "month":
+		count = chartMonthDays
+		caption = i18n.T(lang, "Hours without power in the last 30 days")
+	default:
+		return nil, i18n.T(lang, "Usage: /chart [week|month]")
+	}
+
+	days, err := report.Daily(bot.db, time.Now().In(location).AddDate(0, 0, 1-count), count)
+	if err != nil {
+		log.Errorf("Failed to get daily outages: %s", err)
+
+		return nil, i18n.T(lang, "Failed to build the chart. Please try again later")
+	}
+
+	bars := make([]chart.Bar, 0, len(days))
+
+	var total time.Duration
+
+	for _, day := range days {
+		bars = append(bars, chart.Bar{Label: day.Date.Day(), Value: day.Total})
+		total += day.Total
+	}
+
+	totalText := i18n.T(lang, "Total: %s", formatDuration(total, lang))
+
+	if !chart.Enabled || bot.LowBandwidth() {
+		lines := []string{caption}
+
+		for _, day := range days {
+			if day.Total > 0 {
+				lines = append(lines, i18n.Date(lang, day.Date)+": "+formatDuration(day.Total, lang))
+			}
+		}
+
+		return nil, strings.Join(append(lines, totalText), "\n")
+	}
+
+	image, err := chart.Render(bars)
+	if err != nil {
+		log.Errorf("Failed to render chart: %s", err)
+
+		return nil, i18n.T(lang, "Failed to build the chart. Please try again later")
+	}
+
+	message := botApi.NewPhoto(chatID, botApi.FileBytes{Name: "chart.png", Bytes: image})
+	message.Caption = caption + "\n" + totalText
+
+	return &message, ""
+}
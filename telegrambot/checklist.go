@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	// checklistMinOutage is the shortest planned outage the checklist is sent before.
+	checklistMinOutage = 4 * time.Hour
+	// checklistLead leaves time to charge devices before the outage.
+	checklistLead       = 2 * time.Hour
+	maxChecklistLength  = 500
+	checklistItemPrefix = "- "
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// defaultChecklist is sent to chats without their own checklist.
+//
+//nolint:gochecknoglobals
+var defaultChecklist = []string{
+	"charge phones, power banks and laptops",
+	"fill water bottles",
+	"prepare flashlights",
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleChecklistCommand shows or changes the checklist sent to the chat before long planned outages, in groups only
+// administrators may change it.
+func (bot *ElectroBot) handleChecklistCommand(message *botApi.Message, arguments, lang string) string {
+	chatID := message.Chat.ID
+
+	if !bot.db.UserExists(chatID) {
+		return i18n.T(lang, "Please register with /start first")
+	}
+
+	checklist, err := bot.db.GetUserChecklist(chatID)
+	if err != nil {
+		log.Errorf("Failed to get checklist: %s", err)
+
+		return i18n.T(lang, "Failed to change the checklist. Please try again later")
+	}
+
+	subcommand, value, _ := strings.Cut(strings.TrimSpace(arguments), " ")
+	value = strings.TrimSpace(value)
+
+	switch {
+	case subcommand == "":
+		return checklistSettingsText(checklist, lang)
+
+	case subcommand == settingOn || subcommand == settingOff:
+		checklist.Enabled = subcommand == settingOn
+
+	case subcommand == "set" && value != "":
+		if len([]rune(value)) > maxChecklistLength {
+			return i18n.T(lang, "Checklist is too long, please keep it under %s",
+				i18n.N(lang, maxChecklistLength, "%d character|%d characters"))
+		}
+
+		checklist.Items, checklist.Enabled = parseChecklist(value), true
+
+	case subcommand == "reset":
+		checklist.Items = ""
+
+	default:
+		return i18n.T(lang, "Usage:\n/checklist - show the checklist sent before long planned outages"+
+			"\n/checklist on|off - turn the checklist on or off"+
+			"\n/checklist set <item>; <item> - set your own items"+
+			"\n/checklist reset - use the default items")
+	}
+
+	if !bot.canConfigureChat(message.From, message.Chat) {
+		return i18n.T(lang, "Only group administrators can change the checklist of the group")
+	}
+
+	if err = bot.db.SetUserChecklist(chatID, checklist.Items, checklist.Enabled); err != nil {
+		log.Errorf("Failed to store checklist: %s", err)
+
+		return i18n.T(lang, "Failed to change the checklist. Please try again later")
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "enabled": checklist.Enabled}).Info("Chat checklist changed")
+
+	return checklistSettingsText(checklist, lang)
+}
+
+// parseChecklist returns items separated by semicolons or new lines, one per line.
+func parseChecklist(text string) string {
+	var items []string
+
+	for _, item := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == ';' }) {
+		// items may be pasted as a list
+		if item = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(item), "-")); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func checklistSettingsText(checklist database.Checklist, lang string) string {
+	if !checklist.Enabled {
+		return i18n.T(lang, "Checklists before long planned outages are off, see /checklist help")
+	}
+
+	return i18n.T(lang, "Before planned outages of %s or longer this chat gets the checklist:",
+		formatDuration(checklistMinOutage, lang)) + checklistItemsText(checklist, lang)
+}
+
+// checklistItemsText returns items of the checklist as a list, the default items are translated.
+func checklistItemsText(checklist database.Checklist, lang string) string {
+	var items []string
+
+	if checklist.Items != "" {
+		items = strings.Split(checklist.Items, "\n")
+	} else {
+		for _, item := range defaultChecklist {
+			items = append(items, i18n.T(lang, item))
+		}
+	}
+
+	return "\n" + checklistItemPrefix + strings.Join(items, "\n"+checklistItemPrefix)
+}
+
+// sendChecklists sends checklists due after from up to to before long outages.
+func (bot *ElectroBot) sendChecklists(outages []scheduledOutage, from, to time.Time) {
+	checklists, err := bot.db.GetChecklists()
+	if err != nil {
+		log.Errorf("Failed to get checklists: %s", err)
+
+		return
+	}
+
+	defaultGroup := bot.defaultScheduleGroup()
+
+	for _, checklist := range checklists {
+		group := checklist.Group
+		if group == "" {
+			group = defaultGroup
+		}
+
+		for _, outage := range dueChecklistOutages(outages, group, from, to) {
+			log.WithFields(log.Fields{
+				"chatID": checklist.ChatID, "group": outage.Group, "start": outage.Start,
+			}).Info("Sending checklist")
+
+			bot.notifyUsers([]int64{checklist.ChatID}, func(lang string, location *time.Location) string {
+				return i18n.T(lang, "🔋 Power of group %s is planned to go off at %s for %s. Get ready:", outage.Group,
+					outage.Start.In(location).Format(clockFormat), formatDuration(outage.End.Sub(outage.Start), lang)) +
+					checklistItemsText(checklist, lang)
+			}, 0, nil)
+		}
+	}
+}
+
+// dueChecklistOutages returns long outages of the group the checklist is due before after from up to to.
+func dueChecklistOutages(outages []scheduledOutage, group string, from, to time.Time) (due []scheduledOutage) {
+	for _, outage := range outages {
+		if outage.Group != group || outage.End.Sub(outage.Start) < checklistMinOutage {
+			continue
+		}
+
+		if start := outage.Start.Add(-checklistLead); start.After(from) && !start.After(to) {
+			due = append(due, outage)
+		}
+	}
+
+	return due
+}
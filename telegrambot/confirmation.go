@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	restoreCallbackPrefix = "restore:"
+	restoreAnswerYes      = "yes"
+	restoreAnswerNo       = "no"
+	// minRestoreDisputes is the number of users reporting no power needed to dispute the restoration, they also
+	// have to outnumber users confirming it.
+	minRestoreDisputes = 2
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// restoreConfirmation collects answers of the sample asked to confirm the restoration of the location.
+type restoreConfirmation struct {
+	end time.Time
+	// powerOn is the answer of each chat, chats may change their answers.
+	powerOn  map[int64]bool
+	disputed bool
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// askRestoreConfirmation asks subscribers of the location in the restoreconfirm rollout whether power is back at
+// their place too.
+func (bot *ElectroBot) askRestoreConfirmation(locationID int64, end time.Time) {
+	users, err := bot.db.GetSubscribers(locationID)
+	if err != nil {
+		log.WithField("location", locationID).Errorf("Failed to get location subscribers: %s", err)
+
+		return
+	}
+
+	var sample []int64
+
+	for _, user := range users {
+		if bot.featureEnabled(featureRestoreConfirm, user) {
+			sample = append(sample, user)
+		}
+	}
+
+	bot.confirmationMutex.Lock()
+	bot.restoreConfirmations[locationID] = &restoreConfirmation{end: end, powerOn: make(map[int64]bool)}
+	bot.confirmationMutex.Unlock()
+
+	if len(sample) == 0 {
+		return
+	}
+
+	log.WithFields(log.Fields{"location": locationID, "sample": len(sample)}).Info("Asking to confirm restoration")
+
+	data := restoreCallbackPrefix + strconv.FormatInt(locationID, 10) + ":" + strconv.FormatInt(end.Unix(), 10) + ":"
+
+	bot.notifyUsers(sample, bot.locationText(locationID, func(lang string, _ *time.Location) string {
+		return i18n.T(lang, "Is power back at your place too?")
+	}), 0, func(lang string) *botApi.InlineKeyboardMarkup {
+		keyboard := botApi.NewInlineKeyboardMarkup(botApi.NewInlineKeyboardRow(
+			botApi.NewInlineKeyboardButtonData(i18n.T(lang, "Yes"), data+restoreAnswerYes),
+			botApi.NewInlineKeyboardButtonData(i18n.T(lang, "No, still off"), data+restoreAnswerNo)))
+
+		return &keyboard
+	})
+}
+
+// endRestoreConfirmation forgets answers about the restoration of the location, power went off again.
+func (bot *ElectroBot) endRestoreConfirmation(locationID int64) {
+	bot.confirmationMutex.Lock()
+	defer bot.confirmationMutex.Unlock()
+
+	delete(bot.restoreConfirmations, locationID)
+}
+
+// handleRestoreCallback records the answer about the restoration, answers about earlier restorations are ignored.
+// Subscribers of the location are warned once enough users report that power is still off.
+func (bot *ElectroBot) handleRestoreCallback(query *botApi.CallbackQuery, data string) string {
+	chatID := query.Message.Chat.ID
+	lang := bot.userLanguage(chatID, query.From)
+
+	fields := strings.Split(data, ":")
+	if len(fields) != 3 {
+		log.WithField("data", data).Warn("Invalid restore confirmation")
+
+		return ""
+	}
+
+	locationID, _ := strconv.ParseInt(fields[0], 10, 64)
+	end, _ := strconv.ParseInt(fields[1], 10, 64)
+
+	bot.confirmationMutex.Lock()
+
+	confirmation, ok := bot.restoreConfirmations[locationID]
+	if !ok || confirmation.end.Unix() != end {
+		bot.confirmationMutex.Unlock()
+
+		return i18n.T(lang, "Thank you, this outage is over already")
+	}
+
+	confirmation.powerOn[chatID] = fields[2] == restoreAnswerYes
+
+	confirmed, disputed := 0, 0
+
+	for _, powerOn := range confirmation.powerOn {
+		if powerOn {
+			confirmed++
+		} else {
+			disputed++
+		}
+	}
+
+	dispute := !confirmation.disputed && disputed >= minRestoreDisputes && disputed > confirmed
+	confirmation.disputed = confirmation.disputed || dispute
+
+	bot.confirmationMutex.Unlock()
+
+	log.WithFields(log.Fields{
+		"location": locationID, "chatID": chatID, "answer": fields[2],
+	}).Info("Restoration confirmation received")
+
+	if dispute {
+		go bot.reportRestoreDispute(locationID, disputed)
+	}
+
+	return i18n.T(lang, "Thank you for the answer")
+}
+
+// reportRestoreDispute warns subscribers and admins that power may be back only in part of the location.
+func (bot *ElectroBot) reportRestoreDispute(locationID int64, disputed int) {
+	log.WithFields(log.Fields{"location": locationID, "disputed": disputed}).Warn("Restoration disputed by users")
+
+	bot.notifyLocation(locationID, func(lang string, _ *time.Location) string {
+		return i18n.T(lang, "⚠️ %s report that power is still off at their place, it may be back only in part "+
+			"of the area", i18n.N(lang, disputed, "%d user|%d users"))
+	}, 0, nil)
+}
+
+// restoreDisputeStatus returns the status line of the disputed main location restoration, empty if it is not
+// disputed.
+func (bot *ElectroBot) restoreDisputeStatus(lang string) string {
+	bot.confirmationMutex.Lock()
+	defer bot.confirmationMutex.Unlock()
+
+	confirmation, ok := bot.restoreConfirmations[database.MainLocationID]
+	if !ok || !confirmation.disputed {
+		return ""
+	}
+
+	return "\n" + i18n.T(lang, "⚠️ Some users report that power is still off at their place")
+}
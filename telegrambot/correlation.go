@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// pendingPowerOff is a power-off collected to find out whether other locations lose power at the same time.
+type pendingPowerOff struct {
+	LocationID int64
+	Start      time.Time
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// notifyPowerOff notifies location subscribers about the power-off, with the wide outage window configured the
+// notification is delayed to combine it with power-offs at other locations.
+func (bot *ElectroBot) notifyPowerOff(locationID int64, start time.Time) {
+	bot.endRestoreConfirmation(locationID)
+
+	if bot.wideOutageWindow.Load() <= 0 {
+		bot.notifyLocation(locationID, powerOffText(start), critical|batched|withUtilities, nil)
+
+		return
+	}
+
+	bot.powerOffMutex.Lock()
+	defer bot.powerOffMutex.Unlock()
+
+	bot.pendingPowerOffs = append(bot.pendingPowerOffs, pendingPowerOff{LocationID: locationID, Start: start})
+
+	// the window starts with the first power-off, the others don't prolong it
+	if bot.powerOffTimer == nil {
+		bot.powerOffTimer = time.AfterFunc(bot.wideOutageWindow.Load(), bot.flushPowerOffs)
+	}
+}
+
+// flushPowerOffs sends notifications about collected power-offs, power-offs at several locations are reported as
+// one wide outage.
+func (bot *ElectroBot) flushPowerOffs() {
+	bot.powerOffMutex.Lock()
+
+	pending := bot.pendingPowerOffs
+	bot.pendingPowerOffs = nil
+
+	if bot.powerOffTimer != nil {
+		bot.powerOffTimer.Stop()
+		bot.powerOffTimer = nil
+	}
+
+	bot.powerOffMutex.Unlock()
+
+	switch len(pending) {
+	case 0:
+		return
+
+	case 1:
+		bot.notifyLocation(pending[0].LocationID, powerOffText(pending[0].Start), critical|batched|withUtilities, nil)
+
+		return
+	}
+
+	bot.notifyWideOutage(pending)
+}
+
+// notifyWideOutage sends every subscriber one message listing the power-offs at the locations the user is
+// subscribed to.
+func (bot *ElectroBot) notifyWideOutage(pending []pendingPowerOff) {
+	locations, err := bot.db.GetLocations()
+	if err != nil {
+		log.Errorf("Failed to get locations: %s", err)
+	}
+
+	names := make(map[int64]string, len(locations))
+
+	for _, location := range locations {
+		names[location.ID] = location.Name
+	}
+
+	log.WithFields(log.Fields{"locations": len(pending), "monitored": len(locations)}).Warn("Wide outage detected")
+
+	userPowerOffs := make(map[int64][]pendingPowerOff)
+
+	for _, powerOff := range pending {
+		users, err := bot.db.GetSubscribers(powerOff.LocationID)
+		if err != nil {
+			log.WithField("location", powerOff.LocationID).Errorf("Failed to get location subscribers: %s", err)
+
+			continue
+		}
+
+		for _, user := range users {
+			userPowerOffs[user] = append(userPowerOffs[user], powerOff)
+		}
+	}
+
+	// users subscribed to the same locations get the same text
+	groupUsers := make(map[string][]int64)
+	groupPowerOffs := make(map[string][]pendingPowerOff)
+
+	for user, powerOffs := range userPowerOffs {
+		ids := make([]string, 0, len(powerOffs))
+
+		for _, powerOff := range powerOffs {
+			ids = append(ids, strconv.FormatInt(powerOff.LocationID, 10))
+		}
+
+		key := strings.Join(ids, ",")
+
+		groupUsers[key] = append(groupUsers[key], user)
+		groupPowerOffs[key] = powerOffs
+	}
+
+	for key, users := range groupUsers {
+		sort.Slice(users, func(i, j int) bool { return users[i] < users[j] })
+
+		powerOffs := groupPowerOffs[key]
+
+		bot.notifyUsers(users, func(lang string, location *time.Location) string {
+			lines := []string{i18n.T(lang, "⚠️ Looks like a wide outage: power went off at %d of %d monitored "+
+				"locations within %s", len(pending), len(locations), formatDuration(bot.wideOutageWindow.Load(), lang))}
+
+			for _, powerOff := range powerOffs {
+				lines = append(lines, names[powerOff.LocationID]+": "+powerOffText(powerOff.Start)(lang, location))
+			}
+
+			return strings.Join(lines, "\n")
+		}, critical|batched|withUtilities, nil)
+	}
+}
+
+func powerOffText(start time.Time) func(lang string, location *time.Location) string {
+	return func(lang string, location *time.Location) string {
+		return i18n.T(lang, "Power went off at %s", i18n.DateTime(lang, start.In(location)))
+	}
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"electrobot/telegrambot"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestWideOutage(t *testing.T) {
+	const window = 200 * time.Millisecond
+
+	server, bot, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}, WideOutageWindow: window})
+
+	for _, name := range []string{"garage", "shed"} {
+		server.SendMessage(adminID, "/location add "+name)
+		checkReply(t, server, adminID, `Location "`+name+`" added`)
+	}
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.SendMessage(userID, "/subscribe garage")
+	checkReply(t, server, userID, "You're subscribed to garage")
+
+	server.SendMessage(userID, "/subscribe shed")
+	checkReply(t, server, userID, "You're subscribed to shed")
+
+	start := time.Now()
+
+	bot.LocationPowerOff("garage", start)
+	bot.LocationPowerOff("shed", start.Add(time.Second))
+
+	message := checkReply(t, server, userID, "⚠️ Looks like a wide outage: power went off at 2 of 3 monitored locations")
+
+	if !strings.Contains(message.Text, "\ngarage: Power went off at") ||
+		!strings.Contains(message.Text, "\nshed: Power went off at") {
+		t.Errorf("Wrong wide outage message: %q", message.Text)
+	}
+
+	if message, err := server.NextMessage(userID, 2*window); err == nil {
+		t.Errorf("Unexpected message: %q", message.Text)
+	}
+
+	bot.LocationPowerOn("garage", start, start.Add(time.Minute))
+	checkReply(t, server, userID, "garage: Power is back at")
+
+	// a single power-off is reported as usual, the power-on doesn't wait for the window
+	bot.LocationPowerOff("garage", start.Add(2*time.Minute))
+	bot.LocationPowerOn("garage", start.Add(2*time.Minute), start.Add(3*time.Minute))
+
+	checkReply(t, server, userID, "garage: Power went off at")
+	checkReply(t, server, userID, "garage: Power is back at")
+}
+
+func TestNotificationBatch(t *testing.T) {
+	const window = 200 * time.Millisecond
+
+	server, bot, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}, NotificationBatchWindow: window})
+
+	server.SendMessage(adminID, "/location add garage")
+	checkReply(t, server, adminID, `Location "garage" added`)
+
+	for _, chatID := range []int64{userID, adminID} {
+		server.SendMessage(chatID, "/start")
+		checkReply(t, server, chatID, "You've been successfully registered")
+	}
+
+	server.SendMessage(userID, "/subscribe garage")
+	checkReply(t, server, userID, "You're subscribed to garage")
+
+	start := time.Now()
+
+	bot.LocationPowerOff("garage", start)
+	bot.PowerOff(start.Add(time.Second))
+
+	// the admin is subscribed to the main location only and is notified right away
+	checkReply(t, server, adminID, "home: Power went off at")
+
+	message := checkReply(t, server, userID, "garage: Power went off at")
+	if !strings.Contains(message.Text, "\n\nhome: Power went off at") {
+		t.Errorf("Wrong notification batch: %q", message.Text)
+	}
+
+	if message, err := server.NextMessage(userID, 2*window); err == nil {
+		t.Errorf("Unexpected message: %q", message.Text)
+	}
+}
@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+	"electrobot/schedule"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	detailsCallbackPrefix = "details:"
+	detailsHistoryDays    = 7
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// outageDetailsKeyboard returns the "Details" keyboard of the outage ended at end, nil if it isn't stored.
+func (bot *ElectroBot) outageDetailsKeyboard(end time.Time) func(lang string) *botApi.InlineKeyboardMarkup {
+	outages, err := bot.db.GetOutages(1)
+	if err != nil {
+		log.Errorf("Failed to get last outage: %s", err)
+
+		return nil
+	}
+
+	if len(outages) == 0 || !outages[0].End.Equal(end) {
+		return nil
+	}
+
+	data := detailsCallbackPrefix + strconv.FormatInt(outages[0].ID, 10)
+
+	return func(lang string) *botApi.InlineKeyboardMarkup {
+		keyboard := botApi.NewInlineKeyboardMarkup(botApi.NewInlineKeyboardRow(
+			botApi.NewInlineKeyboardButtonData(i18n.T(lang, "Details"), data)))
+
+		return &keyboard
+	}
+}
+
+// handleDetailsCallback sends outage details as a new message keeping the notification intact.
+func (bot *ElectroBot) handleDetailsCallback(query *botApi.CallbackQuery, data string) string {
+	chatID := query.Message.Chat.ID
+	lang := bot.userLanguage(chatID, query.From)
+
+	text := i18n.T(lang, "Outage not found, it may have been archived")
+
+	if id, err := strconv.ParseInt(data, 10, 64); err == nil {
+		text = bot.outageDetailsText(id, lang, bot.userLocation(chatID))
+	}
+
+	bot.recordVariantEngaged(chatID)
+
+	message := botApi.NewMessage(chatID, text)
+	message.ReplyToMessageID = query.Message.MessageID
+
+	bot.reply(message)
+
+	return ""
+}
+
+func (bot *ElectroBot) outageDetailsText(id int64, lang string, location *time.Location) string {
+	outage, err := bot.db.GetOutage(id)
+	if err != nil {
+		log.Errorf("Failed to get outage %d: %s", id, err)
+
+		return i18n.T(lang, "Outage not found, it may have been archived")
+	}
+
+	text := i18n.T(lang, "Outage details:\nStarted: %s\nEnded: %s\nDuration: %s",
+		i18n.DateTime(lang, outage.Start.In(location)), i18n.DateTime(lang, outage.End.In(location)),
+		formatDuration(outage.Duration(), lang))
+
+	if outage.Cause != "" {
+		text += "\n" + i18n.T(lang, "Cause: %s", outage.Cause)
+	}
+
+	// the outage starts at the last heartbeat before it, so the start is only known to the heartbeat interval
+	if bot.aliveInterval > 0 {
+		text += "\n" + i18n.T(lang, "Detected by: bot heartbeat, accurate to %s",
+			formatDuration(bot.aliveInterval, lang))
+	} else {
+		text += "\n" + i18n.T(lang, "Detected by: bot heartbeat")
+	}
+
+	text += "\n\n" + bot.outageScheduleText(outage, lang, location)
+
+	previous, err := bot.db.GetOutagesBetween(outage.Start.AddDate(0, 0, -detailsHistoryDays), outage.Start)
+	if err != nil {
+		log.Errorf("Failed to get previous outages: %s", err)
+
+		return text
+	}
+
+	var total time.Duration
+
+	for _, outage := range previous {
+		total += outage.Duration()
+	}
+
+	text += "\n\n" + i18n.T(lang, "%s in the previous %s, %s in total",
+		i18n.N(lang, len(previous), "%d outage|%d outages"), i18n.N(lang, detailsHistoryDays, "%d day|%d days"),
+		formatDuration(total, lang))
+
+	if len(previous) != 0 {
+		last := previous[len(previous)-1]
+
+		text += "\n" + i18n.T(lang, "Power was on for %s before this outage",
+			formatDuration(outage.Start.Sub(last.End), lang))
+	}
+
+	return text
+}
+
+// outageScheduleText tells whether the outage overlaps planned or scheduled outage windows on its start date.
+func (bot *ElectroBot) outageScheduleText(outage database.Outage, lang string, location *time.Location) string {
+	start := outage.Start.In(location)
+	groups, err := bot.scheduledWindows(start)
+	if err != nil {
+		log.Errorf("Failed to get outage schedule: %s", err)
+
+		return i18n.T(lang, "Failed to get outage schedule. Please try again later")
+	}
+
+	names := make([]string, 0, len(groups))
+
+	for group := range groups {
+		names = append(names, group)
+	}
+
+	sort.Strings(names)
+
+	text := ""
+
+	for _, group := range names {
+		windows, err := schedule.ParseWindows(groups[group])
+		if err != nil {
+			continue
+		}
+
+		for _, window := range windows {
+			if windowStart, windowEnd := window.At(start); outage.Start.Before(windowEnd) &&
+				outage.End.After(windowStart) {
+				if text == "" {
+					text = i18n.T(lang, "Matches scheduled outages:")
+				}
+
+				text += "\n" + i18n.T(lang, "Group %s: %s", group, window.String())
+			}
+		}
+	}
+
+	if text == "" {
+		return i18n.T(lang, "No scheduled outage at that time")
+	}
+
+	return text
+}
+
+// scheduledWindows returns outage windows by group on the day, planned schedules from the grid operator and
+// schedule exceptions override the weekly schedule.
+func (bot *ElectroBot) scheduledWindows(day time.Time) (groups map[string]string, err error) {
+	date := day.Format(database.DateFormat)
+	groups = make(map[string]string)
+
+	days, err := bot.db.GetSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scheduleDay := range days {
+		if scheduleDay.Weekday == day.Weekday() {
+			groups[scheduleDay.Group] = scheduleDay.Windows
+		}
+	}
+
+	exceptions, err := bot.db.GetScheduleExceptions(date)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, exception := range exceptions {
+		if exception.Date != date {
+			continue
+		}
+
+		if exception.Group != scheduleAllGroups {
+			groups[exception.Group] = exception.Windows
+
+			continue
+		}
+
+		for group := range groups {
+			groups[group] = exception.Windows
+		}
+	}
+
+	planned, err := bot.db.GetPlannedSchedules(date)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, plannedDay := range planned {
+		if plannedDay.Date == date {
+			groups[plannedDay.Group] = plannedDay.Windows
+		}
+	}
+
+	return groups, nil
+}
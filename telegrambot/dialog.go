@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// dialogTimeout ends dialogs left without an answer, later answers are handled as if there was no dialog.
+const dialogTimeout = time.Hour
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// dialogStep handles a text message sent at the step and returns the reply and the next step, the empty step ends
+// the dialog.
+type dialogStep func(message *botApi.Message, lang string) (reply, next string)
+
+// dialog is a multi-step conversation, steps answered with buttons have no text handlers.
+type dialog struct {
+	steps map[string]dialogStep
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// registerDialogs registers the dialogs of the bot.
+func (bot *ElectroBot) registerDialogs() {
+	bot.dialogs = map[string]dialog{
+		onboardingDialog: {},
+		feedbackDialog:   {steps: map[string]dialogStep{feedbackText: bot.handleFeedbackText}},
+		reminderDialog:   {steps: map[string]dialogStep{reminderRule: bot.handleReminderRule}},
+	}
+}
+
+// setDialogStep moves the dialog of the chat to the step, a new dialog ends the previous dialog of the chat.
+func (bot *ElectroBot) setDialogStep(chatID int64, name, step string) error {
+	return bot.db.SetDialog(chatID, name, step)
+}
+
+// activeDialog returns the dialog of the chat, expired dialogs are ended.
+func (bot *ElectroBot) activeDialog(chatID int64) (active database.Dialog, ok bool) {
+	active, err := bot.db.GetDialog(chatID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.WithField("chatID", chatID).Errorf("Failed to get dialog: %s", err)
+		}
+
+		return active, false
+	}
+
+	if time.Since(active.UpdatedAt) > dialogTimeout {
+		log.WithFields(log.Fields{"chatID": chatID, "dialog": active.Name}).Debug("Dialog expired")
+		bot.endDialog(chatID)
+
+		return active, false
+	}
+
+	return active, true
+}
+
+// endDialog ends the dialog of the chat.
+func (bot *ElectroBot) endDialog(chatID int64) {
+	if err := bot.db.RemoveDialog(chatID); err != nil {
+		log.WithField("chatID", chatID).Errorf("Failed to end dialog: %s", err)
+	}
+}
+
+// handleDialogMessage passes the text message to the step of the chat dialog, messages of chats without dialogs are
+// ignored.
+func (bot *ElectroBot) handleDialogMessage(message *botApi.Message) {
+	chatID := message.Chat.ID
+
+	active, ok := bot.activeDialog(chatID)
+	if !ok {
+		return
+	}
+
+	lang := bot.userLanguage(chatID, message.From)
+	reply := botApi.NewMessage(chatID, "")
+	reply.ReplyToMessageID = message.MessageID
+
+	step, ok := bot.dialogs[active.Name].steps[active.Step]
+	if !ok {
+		reply.Text = i18n.T(lang, "Please use the buttons above or send /cancel")
+		bot.reply(reply)
+
+		return
+	}
+
+	var next string
+
+	if reply.Text, next = step(message, lang); next == "" {
+		bot.endDialog(chatID)
+	} else if err := bot.setDialogStep(chatID, active.Name, next); err != nil {
+		log.WithField("chatID", chatID).Errorf("Failed to store dialog step: %s", err)
+	}
+
+	bot.reply(reply)
+}
+
+func (bot *ElectroBot) handleCancelCommand(chatID int64, lang string) string {
+	if _, ok := bot.activeDialog(chatID); !ok {
+		return i18n.T(lang, "There is nothing to cancel")
+	}
+
+	bot.endDialog(chatID)
+
+	return i18n.T(lang, "Canceled")
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"testing"
+
+	"electrobot/telegrambot"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestFeedbackDialog(t *testing.T) {
+	server, _, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(userID, "/feedback")
+	checkReply(t, server, userID, "Send your feedback or complaint in one message")
+
+	server.SendMessage(userID, "The schedule of group 1.2 is wrong")
+	checkReply(t, server, userID, "Thank you, your feedback has been sent to the admins")
+	checkReply(t, server, adminID, "💬 Feedback from 2")
+
+	server.SendMessage(userID, "/cancel")
+	checkReply(t, server, userID, "There is nothing to cancel")
+
+	server.SendMessage(userID, "/feedback")
+	checkReply(t, server, userID, "Send your feedback or complaint in one message")
+
+	server.SendMessage(userID, "/cancel")
+	checkReply(t, server, userID, "Canceled")
+
+	server.SendMessage(userID, "Never mind")
+
+	if message, err := server.NextMessage(adminID, waitTimeout/10); err == nil {
+		t.Errorf("Feedback is sent after cancel: %q", message.Text)
+	}
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const maxElevatorWarning = 120
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// outageWarning is a warning to the chat about the planned outage, Task is the task of a recurring reminder.
+type outageWarning struct {
+	ChatID int64
+	Outage scheduledOutage
+	Task   string
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleElevatorCommand turns on warnings not to take the elevator sent minutes before planned outages of the chat
+// group, separate from other notifications so building chats may opt in alone.
+func (bot *ElectroBot) handleElevatorCommand(chatID int64, arguments, lang string) string {
+	arguments = strings.ToLower(strings.TrimSpace(arguments))
+
+	minutes, err := strconv.Atoi(arguments)
+	if arguments == "off" {
+		minutes, err = 0, nil
+	}
+
+	if err != nil || minutes < 0 || minutes > maxElevatorWarning {
+		return i18n.T(lang, "Usage: /elevator <minutes>|off, warns not to take the elevator the given number of "+
+			"minutes before planned outages, up to %d", maxElevatorWarning)
+	}
+
+	if !bot.db.UserExists(chatID) {
+		return i18n.T(lang, "Please register with /start first")
+	}
+
+	if err = bot.db.SetUserElevatorWarning(chatID, minutes); err != nil {
+		log.Errorf("Failed to store elevator warning: %s", err)
+
+		return i18n.T(lang, "Failed to change elevator warnings. Please try again later")
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "minutes": minutes}).Info("Elevator warning changed")
+
+	if minutes == 0 {
+		return i18n.T(lang, "Elevator warnings are off")
+	}
+
+	lead := i18n.N(lang, minutes, "%d minute|%d minutes")
+
+	group := bot.chatScheduleGroup(chatID)
+	if group == "" {
+		return i18n.T(lang, "Elevator warnings are on, lead time: %s. Set your outage schedule group with /group "+
+			"to get them", lead)
+	}
+
+	return i18n.T(lang, "Elevator warnings before planned outages of group %s are on, lead time: %s", group, lead)
+}
+
+// sendElevatorWarnings sends warnings due after from up to to about the outages.
+func (bot *ElectroBot) sendElevatorWarnings(outages []scheduledOutage, from, to time.Time) {
+	warnings, err := bot.elevatorWarnings(outages, from, to)
+	if err != nil {
+		log.Errorf("Failed to get elevator warnings: %s", err)
+
+		return
+	}
+
+	for _, warning := range warnings {
+		outage := warning.Outage
+
+		log.WithFields(log.Fields{
+			"chatID": warning.ChatID, "group": outage.Group, "start": outage.Start,
+		}).Info("Sending elevator warning")
+
+		bot.notifyUsers([]int64{warning.ChatID}, func(lang string, location *time.Location) string {
+			return i18n.T(lang, "🛗 Power of group %s is planned to go off at %s. Please don't take the elevator "+
+				"from now on, it may stop between floors", outage.Group, outage.Start.In(location).Format(clockFormat))
+		}, minorElevator, nil)
+	}
+}
+
+// elevatorWarnings returns warnings due after from up to to about the outages of the chat groups, chats without
+// their own group follow the bot group.
+func (bot *ElectroBot) elevatorWarnings(outages []scheduledOutage, from, to time.Time,
+) (warnings []outageWarning, err error) {
+	chats, err := bot.db.GetElevatorWarnings()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultGroup := bot.defaultScheduleGroup()
+
+	for _, chat := range chats {
+		group := chat.Group
+		if group == "" {
+			group = defaultGroup
+		}
+
+		lead := time.Duration(chat.Minutes) * time.Minute
+
+		for _, outage := range outages {
+			if due := outage.Start.Add(-lead); outage.Group == group && due.After(from) && !due.After(to) {
+				warnings = append(warnings, outageWarning{ChatID: chat.ChatID, Outage: outage})
+			}
+		}
+	}
+
+	return warnings, nil
+}
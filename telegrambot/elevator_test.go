@@ -0,0 +1,331 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"electrobot/database"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// testElevatorStorage adds elevator warnings of chats to the schedule kept in memory.
+type testElevatorStorage struct {
+	*testScheduleStorage
+	warnings []database.ElevatorWarning
+}
+
+// testReminderStorage adds recurring reminders to the schedule kept in memory.
+type testReminderStorage struct {
+	*testScheduleStorage
+	reminders []database.RecurringReminder
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestScheduledOutages(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Fatalf("Can't load location: %s", err)
+	}
+
+	storage := &testScheduleStorage{
+		days: []database.ScheduleDay{
+			{Group: "1", Weekday: time.Saturday, Windows: "20:00-24:00"},
+			{Group: "1", Weekday: time.Sunday, Windows: "00:00-04:00,08:00-12:00"},
+			{Group: "2", Weekday: time.Sunday, Windows: "10:00-11:00"},
+		},
+	}
+
+	testData := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		outages string
+	}{
+		{
+			name: "weekend", from: time.Date(2024, 3, 9, 0, 0, 0, 0, location),
+			to:      time.Date(2024, 3, 11, 0, 0, 0, 0, location),
+			outages: "1 03-09 20:00-03-10 04:00; 1 03-10 08:00-03-10 12:00; 2 03-10 10:00-03-10 11:00",
+		},
+		{
+			name: "after midnight", from: time.Date(2024, 3, 10, 0, 0, 0, 0, location),
+			to:      time.Date(2024, 3, 10, 10, 0, 0, 0, location),
+			outages: "1 03-10 08:00-03-10 12:00",
+		},
+		{
+			name: "no outages", from: time.Date(2024, 3, 11, 0, 0, 0, 0, location),
+			to: time.Date(2024, 3, 16, 0, 0, 0, 0, location),
+		},
+	}
+
+	bot := &ElectroBot{db: storage, defaultLocation: location}
+
+	for _, item := range testData {
+		outages, err := bot.scheduledOutages(item.from, item.to)
+		if err != nil {
+			t.Fatalf("Can't get scheduled outages: %s", err)
+		}
+
+		if text := scheduledOutagesText(outages, location); text != item.outages {
+			t.Errorf("Wrong %s outages: %s", item.name, text)
+		}
+	}
+}
+
+func TestNextPlannedOutage(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Fatalf("Can't load location: %s", err)
+	}
+
+	storage := &testScheduleStorage{
+		days: []database.ScheduleDay{
+			{Group: "1", Weekday: time.Saturday, Windows: "20:00-24:00"},
+			{Group: "1", Weekday: time.Sunday, Windows: "00:00-04:00,08:00-12:00"},
+			{Group: "2", Weekday: time.Sunday, Windows: "10:00-11:00"},
+		},
+	}
+
+	testData := []struct {
+		name   string
+		group  string
+		now    time.Time
+		outage string
+	}{
+		{
+			name: "next", group: "1", now: time.Date(2024, 3, 9, 12, 0, 0, 0, location),
+			outage: "1 03-09 20:00-03-10 04:00",
+		},
+		{
+			name: "in progress", group: "1", now: time.Date(2024, 3, 10, 2, 0, 0, 0, location),
+			outage: "1 03-09 20:00-03-10 04:00",
+		},
+		{
+			name: "between", group: "1", now: time.Date(2024, 3, 10, 5, 0, 0, 0, location),
+			outage: "1 03-10 08:00-03-10 12:00",
+		},
+		{
+			name: "next week", group: "1", now: time.Date(2024, 3, 10, 13, 0, 0, 0, location),
+			outage: "1 03-16 20:00-03-17 04:00",
+		},
+		{
+			name: "other group", group: "2", now: time.Date(2024, 3, 10, 5, 0, 0, 0, location),
+			outage: "2 03-10 10:00-03-10 11:00",
+		},
+		{name: "no schedule", group: "3", now: time.Date(2024, 3, 10, 5, 0, 0, 0, location)},
+	}
+
+	for _, item := range testData {
+		bot := &ElectroBot{db: storage, defaultLocation: location, scheduleGroup: item.group}
+
+		var outages []scheduledOutage
+
+		if start, end, ok := bot.NextPlannedOutage(item.now); ok {
+			outages = append(outages, scheduledOutage{Group: item.group, Start: start, End: end})
+		}
+
+		if text := scheduledOutagesText(outages, location); text != item.outage {
+			t.Errorf("Wrong %s planned outage: %s", item.name, text)
+		}
+	}
+}
+
+func TestElevatorWarnings(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Fatalf("Can't load location: %s", err)
+	}
+
+	outages := []scheduledOutage{
+		{Group: "1", Start: time.Date(2024, 3, 10, 8, 0, 0, 0, location)},
+		{Group: "2", Start: time.Date(2024, 3, 10, 8, 30, 0, 0, location)},
+	}
+
+	storage := &testElevatorStorage{
+		testScheduleStorage: &testScheduleStorage{},
+		warnings: []database.ElevatorWarning{
+			{ChatID: 10, Minutes: 15},
+			{ChatID: 11, Group: "2", Minutes: 60},
+			{ChatID: 12, Group: "3", Minutes: 15},
+		},
+	}
+
+	testData := []struct {
+		from  time.Time
+		to    time.Time
+		chats []int64
+	}{
+		{from: time.Date(2024, 3, 10, 7, 29, 0, 0, location), to: time.Date(2024, 3, 10, 7, 30, 0, 0, location),
+			chats: []int64{11}},
+		{from: time.Date(2024, 3, 10, 7, 30, 0, 0, location), to: time.Date(2024, 3, 10, 7, 44, 0, 0, location)},
+		{from: time.Date(2024, 3, 10, 7, 44, 0, 0, location), to: time.Date(2024, 3, 10, 7, 46, 0, 0, location),
+			chats: []int64{10}},
+		{from: time.Date(2024, 3, 10, 7, 0, 0, 0, location), to: time.Date(2024, 3, 10, 8, 0, 0, 0, location),
+			chats: []int64{10, 11}},
+	}
+
+	bot := &ElectroBot{db: storage, defaultLocation: location, scheduleGroup: "1"}
+
+	for _, item := range testData {
+		warnings, err := bot.elevatorWarnings(outages, item.from, item.to)
+		if err != nil {
+			t.Fatalf("Can't get elevator warnings: %s", err)
+		}
+
+		var chats []int64
+
+		for _, warning := range warnings {
+			chats = append(chats, warning.ChatID)
+		}
+
+		if !reflect.DeepEqual(chats, item.chats) {
+			t.Errorf("Wrong warned chats from %s to %s: %v", item.from.Format(clockFormat),
+				item.to.Format(clockFormat), chats)
+		}
+	}
+}
+
+func TestDueChecklistOutages(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Fatalf("Can't load location: %s", err)
+	}
+
+	outages := []scheduledOutage{
+		{Group: "1", Start: time.Date(2024, 3, 10, 8, 0, 0, 0, location), End: time.Date(2024, 3, 10, 12, 0, 0, 0,
+			location)},
+		{Group: "1", Start: time.Date(2024, 3, 10, 16, 0, 0, 0, location), End: time.Date(2024, 3, 10, 18, 0, 0, 0,
+			location)},
+		{Group: "2", Start: time.Date(2024, 3, 10, 8, 0, 0, 0, location), End: time.Date(2024, 3, 10, 14, 0, 0, 0,
+			location)},
+	}
+
+	testData := []struct {
+		group   string
+		from    time.Time
+		to      time.Time
+		outages string
+	}{
+		{
+			group: "1", from: time.Date(2024, 3, 10, 5, 59, 0, 0, location),
+			to: time.Date(2024, 3, 10, 6, 0, 0, 0, location), outages: "1 03-10 08:00-03-10 12:00",
+		},
+		{group: "1", from: time.Date(2024, 3, 10, 6, 0, 0, 0, location), to: time.Date(2024, 3, 10, 18, 0, 0, 0, location)},
+		{
+			group: "2", from: time.Date(2024, 3, 10, 5, 0, 0, 0, location),
+			to: time.Date(2024, 3, 10, 7, 0, 0, 0, location), outages: "2 03-10 08:00-03-10 14:00",
+		},
+	}
+
+	for _, item := range testData {
+		due := dueChecklistOutages(outages, item.group, item.from, item.to)
+
+		if text := scheduledOutagesText(due, location); text != item.outages {
+			t.Errorf("Wrong checklist outages of group %s from %s to %s: %s", item.group,
+				item.from.Format(clockFormat), item.to.Format(clockFormat), text)
+		}
+	}
+}
+
+func TestDueRecurringReminders(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Fatalf("Can't load location: %s", err)
+	}
+
+	outages := []scheduledOutage{
+		{Group: "1", Start: time.Date(2024, 3, 10, 8, 0, 0, 0, location)},
+		{Group: "2", Start: time.Date(2024, 3, 10, 9, 0, 0, 0, location)},
+	}
+
+	storage := &testReminderStorage{
+		testScheduleStorage: &testScheduleStorage{},
+		reminders: []database.RecurringReminder{
+			{Reminder: database.Reminder{UserID: 10, Task: "charge", Minutes: 60}},
+			{Reminder: database.Reminder{UserID: 11, Task: "water", Minutes: 15}, Group: "2"},
+			{Reminder: database.Reminder{UserID: 12, Task: "lamp", Minutes: 15}, Group: "3"},
+		},
+	}
+
+	testData := []struct {
+		from  time.Time
+		to    time.Time
+		tasks []string
+	}{
+		{from: time.Date(2024, 3, 10, 6, 59, 0, 0, location), to: time.Date(2024, 3, 10, 7, 0, 0, 0, location),
+			tasks: []string{"charge"}},
+		{from: time.Date(2024, 3, 10, 7, 0, 0, 0, location), to: time.Date(2024, 3, 10, 8, 44, 0, 0, location)},
+		{from: time.Date(2024, 3, 10, 8, 44, 0, 0, location), to: time.Date(2024, 3, 10, 8, 45, 0, 0, location),
+			tasks: []string{"water"}},
+	}
+
+	bot := &ElectroBot{db: storage, defaultLocation: location, scheduleGroup: "1"}
+
+	for _, item := range testData {
+		due, err := bot.recurringReminders(outages, item.from, item.to)
+		if err != nil {
+			t.Fatalf("Can't get recurring reminders: %s", err)
+		}
+
+		var tasks []string
+
+		for _, reminder := range due {
+			tasks = append(tasks, reminder.Task)
+		}
+
+		if !reflect.DeepEqual(tasks, item.tasks) {
+			t.Errorf("Wrong reminders from %s to %s: %v", item.from.Format(clockFormat), item.to.Format(clockFormat),
+				tasks)
+		}
+	}
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (storage *testElevatorStorage) GetElevatorWarnings() ([]database.ElevatorWarning, error) {
+	return storage.warnings, nil
+}
+
+func (storage *testReminderStorage) GetRecurringReminders() ([]database.RecurringReminder, error) {
+	return storage.reminders, nil
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func scheduledOutagesText(outages []scheduledOutage, location *time.Location) string {
+	items := make([]string, 0, len(outages))
+
+	for _, outage := range outages {
+		items = append(items, fmt.Sprintf("%s %s-%s", outage.Group, outage.Start.In(location).Format("01-02 15:04"),
+			outage.End.In(location).Format("01-02 15:04")))
+	}
+
+	return strings.Join(items, "; ")
+}
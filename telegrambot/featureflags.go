@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Experimental features gated by feature flags.
+const (
+	featureForecast = "forecast"
+	// featureCompactWording sends power notifications in the compact wording to compare engagement with it.
+	featureCompactWording = "compactwording"
+	// featureRestoreConfirm asks chats to confirm power restorations, the percent is the sample size.
+	featureRestoreConfirm = "restoreconfirm"
+)
+
+const maxFeaturePercent = 100
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// featureDefaults are percents of chats features are enabled for until admins set their flags, new experimental
+// features start at 0.
+//
+//nolint:gochecknoglobals
+var featureDefaults = map[string]int{
+	featureForecast:       maxFeaturePercent,
+	featureCompactWording: 0,
+	featureRestoreConfirm: 0,
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// loadFeatureFlags caches feature flags, features keep their defaults if flags can't be loaded.
+func (bot *ElectroBot) loadFeatureFlags() {
+	flags, err := bot.db.GetFeatureFlags()
+	if err != nil {
+		log.Errorf("Failed to get feature flags: %s", err)
+
+		return
+	}
+
+	bot.flagMutex.Lock()
+	defer bot.flagMutex.Unlock()
+
+	bot.featureFlags = make(map[string]database.FeatureFlag, len(flags))
+
+	for _, flag := range flags {
+		bot.featureFlags[flag.Name] = flag
+	}
+}
+
+// featureEnabled returns true if the feature is rolled out to the chat. Chats are assigned to percents by a hash of
+// the feature and chat, so raising the percent keeps the feature for chats which already have it.
+func (bot *ElectroBot) featureEnabled(name string, chatID int64) bool {
+	flag := bot.featureFlag(name)
+
+	if slices.Contains(flag.Chats, chatID) {
+		return true
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name + ":" + strconv.FormatInt(chatID, 10)))
+
+	return int(hash.Sum32()%maxFeaturePercent) < flag.Percent
+}
+
+// featureFlag returns the flag of the feature, the default one if admins haven't set it.
+func (bot *ElectroBot) featureFlag(name string) database.FeatureFlag {
+	bot.flagMutex.Lock()
+	defer bot.flagMutex.Unlock()
+
+	if flag, ok := bot.featureFlags[name]; ok {
+		return flag
+	}
+
+	return database.FeatureFlag{Name: name, Percent: featureDefaults[name]}
+}
+
+// handleFlagsCommand shows and changes feature flags.
+func (bot *ElectroBot) handleFlagsCommand(chatID int64, arguments, lang string) string {
+	fields := strings.Fields(arguments)
+	if len(fields) == 0 {
+		fields = []string{"list"}
+	}
+
+	if len(fields) > 1 {
+		if _, ok := featureDefaults[fields[1]]; !ok {
+			return i18n.T(lang, "Unknown feature %q, see /flags", fields[1])
+		}
+	}
+
+	switch {
+	case fields[0] == "list" && len(fields) == 1:
+		return bot.listFeatureFlags(lang)
+
+	case fields[0] == "set" && len(fields) == 3:
+		return bot.setFeatureFlag(chatID, fields[1], fields[2], lang)
+
+	case (fields[0] == "add" || fields[0] == "remove") && len(fields) == 3:
+		return bot.setFeatureFlagChat(chatID, fields[1], fields[2], fields[0] == "add", lang)
+
+	default:
+		return i18n.T(lang, "Usage:\n/flags list - show experimental features and their rollout"+
+			"\n/flags set <feature> <percent|on|off> - enable the feature for a percent of chats"+
+			"\n/flags add <feature> <chat ID> - enable the feature for the chat"+
+			"\n/flags remove <feature> <chat ID> - remove the chat added to the feature")
+	}
+}
+
+func (bot *ElectroBot) listFeatureFlags(lang string) string {
+	names := make([]string, 0, len(featureDefaults))
+
+	for name := range featureDefaults {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	text := i18n.T(lang, "Experimental features:")
+
+	for _, name := range names {
+		flag := bot.featureFlag(name)
+		text += "\n" + i18n.T(lang, "%s: %d%% of chats", name, flag.Percent)
+
+		if len(flag.Chats) != 0 {
+			chats := make([]string, 0, len(flag.Chats))
+
+			for _, chat := range flag.Chats {
+				chats = append(chats, strconv.FormatInt(chat, 10))
+			}
+
+			text += i18n.T(lang, " and chats %s", strings.Join(chats, ", "))
+		}
+	}
+
+	return text + bot.variantStatsText(lang)
+}
+
+func (bot *ElectroBot) setFeatureFlag(chatID int64, name, value, lang string) string {
+	var percent int
+
+	switch value {
+	case settingOn:
+		percent = maxFeaturePercent
+	case settingOff:
+		percent = 0
+	default:
+		var err error
+
+		if percent, err = strconv.Atoi(strings.TrimSuffix(value, "%")); err != nil ||
+			percent < 0 || percent > maxFeaturePercent {
+			return i18n.T(lang, "Rollout must be on, off or a percent from 0 to %d", maxFeaturePercent)
+		}
+	}
+
+	if err := bot.db.SetFeatureFlag(name, percent); err != nil {
+		log.Errorf("Failed to set feature flag: %s", err)
+
+		return i18n.T(lang, "Failed to set feature flag. Please try again later")
+	}
+
+	bot.loadFeatureFlags()
+
+	log.WithFields(log.Fields{"chatID": chatID, "feature": name, "percent": percent}).Info("Feature flag set")
+
+	return i18n.T(lang, "%s is enabled for %d%% of chats", name, percent)
+}
+
+func (bot *ElectroBot) setFeatureFlagChat(chatID int64, name, value string, add bool, lang string) string {
+	flagChatID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return i18n.T(lang, "Invalid chat ID %q", value)
+	}
+
+	flag := bot.featureFlag(name)
+
+	// the flag row keeps the default percent if admins haven't set it
+	if err = bot.db.SetFeatureFlag(name, flag.Percent); err == nil {
+		if add {
+			err = bot.db.AddFeatureFlagChat(name, flagChatID)
+		} else {
+			err = bot.db.RemoveFeatureFlagChat(name, flagChatID)
+		}
+	}
+
+	if err != nil {
+		log.Errorf("Failed to set feature flag chat: %s", err)
+
+		return i18n.T(lang, "Failed to set feature flag. Please try again later")
+	}
+
+	bot.loadFeatureFlags()
+
+	log.WithFields(log.Fields{
+		"chatID": chatID, "feature": name, "flagChatID": flagChatID, "add": add,
+	}).Info("Feature flag chat set")
+
+	if add {
+		return i18n.T(lang, "%s is enabled for chat %d", name, flagChatID)
+	}
+
+	return i18n.T(lang, "Chat %d is removed from %s", flagChatID, name)
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+	"time"
+
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	feedbackDialog = "feedback"
+	// feedbackText is the step waiting for the feedback message.
+	feedbackText      = "text"
+	maxFeedbackLength = 1000
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleFeedbackCommand starts the feedback dialog, the next message of the chat is sent to admins.
+func (bot *ElectroBot) handleFeedbackCommand(message *botApi.Message, lang string) string {
+	if !message.Chat.IsPrivate() {
+		return i18n.T(lang, "Feedback is available in a private chat with the bot only")
+	}
+
+	if err := bot.setDialogStep(message.Chat.ID, feedbackDialog, feedbackText); err != nil {
+		log.WithField("chatID", message.Chat.ID).Errorf("Failed to start feedback dialog: %s", err)
+
+		return i18n.T(lang, "Failed to send feedback. Please try again later")
+	}
+
+	return i18n.T(lang, "Send your feedback or complaint in one message, or /cancel")
+}
+
+func (bot *ElectroBot) handleFeedbackText(message *botApi.Message, lang string) (reply, next string) {
+	text := strings.TrimSpace(message.Text)
+
+	if text == "" {
+		return i18n.T(lang, "Send your feedback or complaint in one message, or /cancel"), feedbackText
+	}
+
+	if len([]rune(text)) > maxFeedbackLength {
+		return i18n.T(lang, "Feedback is too long, please keep it under %s",
+			i18n.N(lang, maxFeedbackLength, "%d character|%d characters")), feedbackText
+	}
+
+	var name string
+
+	if message.From != nil {
+		name = strings.TrimSpace(message.From.FirstName + " " + message.From.LastName)
+
+		if message.From.UserName != "" {
+			name = strings.TrimSpace(name + " @" + message.From.UserName)
+		}
+	}
+
+	log.WithField("chatID", message.Chat.ID).Info("Feedback received")
+
+	bot.notifyAdmins(func(lang string, _ *time.Location) string {
+		return i18n.T(lang, "💬 Feedback from %d %s:\n%s", message.Chat.ID, name, text)
+	})
+
+	return i18n.T(lang, "Thank you, your feedback has been sent to the admins"), ""
+}
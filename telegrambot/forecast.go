@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	forecastDays = 28
+	// forecastMinHistory is the minimal age of the oldest outage within forecastDays to make a forecast.
+	forecastMinHistory = 7 * 24 * time.Hour
+	// forecastThreshold is the share of past days an hour has been off on to be forecast as off.
+	forecastThreshold = 0.5
+	hoursPerDay       = 24
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// hourStats counts hours of the week power has been off during, the hour is off if power has been off for at least
+// half of it.
+type hourStats struct {
+	off   [7][hoursPerDay]int
+	total [7][hoursPerDay]int
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleForecastCommand estimates outage windows of today and tomorrow from the outages of the last forecastDays
+// days, the same weekday and hour are compared.
+func (bot *ElectroBot) handleForecastCommand(chatID int64, lang string, location *time.Location) string {
+	if !bot.featureEnabled(featureForecast, chatID) {
+		return i18n.T(lang, "This feature is not available in this chat yet")
+	}
+
+	now := time.Now().In(location)
+	from := now.AddDate(0, 0, -forecastDays)
+
+	outages, err := bot.db.GetOutagesBetween(from, now)
+	if err != nil {
+		log.Errorf("Failed to get outages: %s", err)
+
+		return i18n.T(lang, "Failed to get outage statistics. Please try again later")
+	}
+
+	if len(outages) == 0 || outages[0].Start.After(now.Add(-forecastMinHistory)) {
+		return i18n.T(lang, "Not enough outage history for a forecast yet")
+	}
+
+	stats := countOffHours(outages, from, now)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	lines := []string{i18n.T(lang, "Likely outages based on the last %d days:", forecastDays)}
+
+	for i, name := range []string{"Today", "Tomorrow"} {
+		windows := stats.windows(today.AddDate(0, 0, i).Weekday())
+		if len(windows) == 0 {
+			windows = []string{i18n.T(lang, "no outages expected")}
+		}
+
+		lines = append(lines, i18n.T(lang, name)+": "+strings.Join(windows, ", "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// countOffHours counts whole hours within [from, to) power has been off during, hours are taken in the timezone of
+// from.
+func countOffHours(outages []database.Outage, from, to time.Time) (stats hourStats) {
+	location := from.Location()
+
+	hour := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), 0, 0, 0, location)
+	if hour.Before(from) {
+		hour = hour.Add(time.Hour)
+	}
+
+	for ; !hour.Add(time.Hour).After(to); hour = hour.Add(time.Hour) {
+		var off time.Duration
+
+		for _, outage := range outages {
+			start, end := outage.Start, outage.End
+
+			if start.Before(hour) {
+				start = hour
+			}
+
+			if end.After(hour.Add(time.Hour)) {
+				end = hour.Add(time.Hour)
+			}
+
+			if end.After(start) {
+				off += end.Sub(start)
+			}
+		}
+
+		local := hour.In(location)
+		stats.total[local.Weekday()][local.Hour()]++
+
+		if off >= time.Hour/2 {
+			stats.off[local.Weekday()][local.Hour()]++
+		}
+	}
+
+	return stats
+}
+
+// windows returns ranges of the weekday hours power is likely off during with their average probability.
+func (stats *hourStats) windows(weekday time.Weekday) (windows []string) {
+	for hour := 0; hour < hoursPerDay; {
+		if !stats.likelyOff(weekday, hour) {
+			hour++
+
+			continue
+		}
+
+		start, probability := hour, 0.0
+
+		for ; hour < hoursPerDay && stats.likelyOff(weekday, hour); hour++ {
+			probability += stats.probability(weekday, hour)
+		}
+
+		windows = append(windows, fmt.Sprintf("%02d:00-%02d:00 (%.0f%%)", start, hour,
+			probability/float64(hour-start)*100)) //nolint:gomnd
+	}
+
+	return windows
+}
+
+func (stats *hourStats) likelyOff(weekday time.Weekday, hour int) bool {
+	return stats.probability(weekday, hour) >= forecastThreshold
+}
+
+func (stats *hourStats) probability(weekday time.Weekday, hour int) float64 {
+	if stats.total[weekday][hour] == 0 {
+		return 0
+	}
+
+	return float64(stats.off[weekday][hour]) / float64(stats.total[weekday][hour])
+}
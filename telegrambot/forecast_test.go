@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"electrobot/database"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestForecastWindows(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Fatalf("Can't load location: %s", err)
+	}
+
+	to := time.Date(2024, 3, 29, 0, 0, 0, 0, location)
+	from := to.AddDate(0, 0, -forecastDays)
+
+	var outages []database.Outage
+
+	// evening outages during the last two weeks and a single morning one
+	for day := 1; day <= 14; day++ {
+		start := time.Date(2024, 3, 29-day, 18, 0, 0, 0, location)
+		outages = append(outages, database.Outage{Start: start, End: start.Add(2*time.Hour + 10*time.Minute)})
+	}
+
+	morning := time.Date(2024, 3, 1, 8, 0, 0, 0, location)
+	outages = append(outages, database.Outage{Start: morning, End: morning.Add(time.Hour)})
+
+	stats := countOffHours(outages, from, to)
+
+	if windows := stats.windows(time.Monday); !reflect.DeepEqual(windows, []string{"18:00-20:00 (50%)"}) {
+		t.Errorf("Wrong forecast windows: %v", windows)
+	}
+
+	if windows := stats.windows(morning.Weekday()); !reflect.DeepEqual(windows, []string{"18:00-20:00 (50%)"}) {
+		t.Errorf("Wrong forecast windows of the morning outage day: %v", windows)
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"errors"
+	"strings"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// isAddressedToBot returns false for commands addressed to other bots in group chats, e.g. /start@otherbot.
+func (bot *ElectroBot) isAddressedToBot(message *botApi.Message) bool {
+	_, username, mentioned := strings.Cut(message.CommandWithAt(), "@")
+
+	return !mentioned || strings.EqualFold(username, bot.botApi.Self.UserName)
+}
+
+// canConfigureChat returns true if the user may change settings shared by the whole chat: anyone in a private chat,
+// group administrators and bot admins in groups.
+func (bot *ElectroBot) canConfigureChat(user *botApi.User, chat *botApi.Chat) bool {
+	userID := senderID(user)
+
+	if chat.IsPrivate() || bot.isAdmin(userID) {
+		return true
+	}
+
+	member, err := bot.botApi.GetChatMember(botApi.GetChatMemberConfig{
+		ChatConfigWithUser: botApi.ChatConfigWithUser{ChatID: chat.ID, UserID: userID},
+	})
+	if err != nil {
+		log.WithFields(log.Fields{"chatID": chat.ID, "userID": userID}).Errorf("Failed to get chat member: %s", err)
+
+		return false
+	}
+
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+// migrateChat moves the group registration and its data to the supergroup the group was upgraded to,
+// Telegram refuses messages to the old group chat ID after the upgrade.
+func (bot *ElectroBot) migrateChat(oldChatID, newChatID int64) {
+	if err := bot.db.MigrateChat(oldChatID, newChatID); err != nil {
+		log.WithFields(log.Fields{"from": oldChatID, "to": newChatID}).Errorf("Failed to migrate chat: %s", err)
+
+		return
+	}
+
+	log.WithFields(log.Fields{"from": oldChatID, "to": newChatID}).Info("Group chat migrated to supergroup")
+}
+
+// migratedChatID returns the supergroup chat ID if sending failed because the group was upgraded, 0 otherwise.
+func migratedChatID(err error) int64 {
+	var apiErr *botApi.Error
+
+	if errors.As(err, &apiErr) {
+		return apiErr.MigrateToChatID
+	}
+
+	return 0
+}
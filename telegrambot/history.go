@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultHistoryLimit = 10
+	maxHistoryLimit     = 50
+	// annotateHistoryLimit is the number of latest outages listed with their IDs by /annotate.
+	annotateHistoryLimit = 5
+	maxOutageCauseLength = 100
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (bot *ElectroBot) handleHistoryCommand(arguments, lang string, location *time.Location) string {
+	limit := defaultHistoryLimit
+
+	if arguments = strings.TrimSpace(arguments); arguments != "" {
+		value, err := strconv.Atoi(arguments)
+		if err != nil || value <= 0 {
+			return i18n.T(lang, "Usage: /history [N], where N is a positive number of outages")
+		}
+
+		limit = min(value, maxHistoryLimit)
+	}
+
+	outages, err := bot.db.GetOutages(limit)
+	if err != nil {
+		log.Errorf("Failed to get outages: %s", err)
+
+		return i18n.T(lang, "Failed to get outage history. Please try again later")
+	}
+
+	if len(outages) == 0 {
+		return i18n.T(lang, "No outages recorded yet")
+	}
+
+	text := i18n.N(lang, len(outages), "Last %d outage:|Last %d outages:")
+
+	for _, outage := range outages {
+		text += "\n" + outageLine(outage, lang, location)
+	}
+
+	return text
+}
+
+// handleAnnotateCommand sets the cause of the outage, without arguments the latest outages are listed with their IDs.
+func (bot *ElectroBot) handleAnnotateCommand(arguments, lang string, location *time.Location) string {
+	id, cause, _ := strings.Cut(strings.TrimSpace(arguments), " ")
+	cause = strings.TrimSpace(cause)
+
+	outageID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || cause == "" {
+		return bot.annotateUsage(lang, location)
+	}
+
+	if len([]rune(cause)) > maxOutageCauseLength {
+		return i18n.T(lang, "Cause is too long, please keep it under %s",
+			i18n.N(lang, maxOutageCauseLength, "%d character|%d characters"))
+	}
+
+	if cause == settingOff {
+		cause = ""
+	}
+
+	if err = bot.db.SetOutageCause(outageID, cause); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return i18n.T(lang, "Outage not found, it may have been archived")
+		}
+
+		log.WithField("outage", outageID).Errorf("Failed to set outage cause: %s", err)
+
+		return i18n.T(lang, "Failed to change the outage. Please try again later")
+	}
+
+	log.WithFields(log.Fields{"outage": outageID, "cause": cause}).Info("Outage cause changed")
+
+	if cause == "" {
+		return i18n.T(lang, "Cause of outage %d removed", outageID)
+	}
+
+	return i18n.T(lang, "Cause of outage %d set", outageID)
+}
+
+// annotateUsage returns the /annotate usage with the latest outages and their IDs.
+func (bot *ElectroBot) annotateUsage(lang string, location *time.Location) string {
+	text := i18n.T(lang, "Usage: /annotate <ID> <cause>|off, e.g. /annotate 12 emergency repairs")
+
+	outages, err := bot.db.GetOutages(annotateHistoryLimit)
+	if err != nil {
+		log.Errorf("Failed to get outages: %s", err)
+
+		return text
+	}
+
+	if len(outages) != 0 {
+		text += "\n\n" + i18n.N(lang, len(outages), "Last %d outage:|Last %d outages:")
+	}
+
+	for _, outage := range outages {
+		text += fmt.Sprintf("\n%d: ", outage.ID) + outageLine(outage, lang, location)
+	}
+
+	return text
+}
+
+// outageLine returns the outage period, duration and cause if known.
+func outageLine(outage database.Outage, lang string, location *time.Location) string {
+	line := fmt.Sprintf("%s - %s (%s)", i18n.DateTime(lang, outage.Start.In(location)),
+		i18n.DateTime(lang, outage.End.In(location)), formatDuration(outage.Duration(), lang))
+
+	if outage.Cause != "" {
+		line += " - " + outage.Cause
+	}
+
+	return line
+}
+
+// formatDuration formats duration as "1 hour 5 minutes" rounded to minutes, shorter durations are shown in seconds.
+func formatDuration(duration time.Duration, lang string) string {
+	if duration < time.Minute {
+		return i18n.N(lang, int(duration.Round(time.Second).Seconds()), "%d second|%d seconds")
+	}
+
+	duration = duration.Round(time.Minute)
+
+	hours := int(duration.Hours())
+	minutes := int(duration.Minutes()) % 60
+
+	if hours == 0 {
+		return i18n.N(lang, minutes, "%d minute|%d minutes")
+	}
+
+	if minutes == 0 {
+		return i18n.N(lang, hours, "%d hour|%d hours")
+	}
+
+	return i18n.N(lang, hours, "%d hour|%d hours") + " " + i18n.N(lang, minutes, "%d minute|%d minutes")
+}
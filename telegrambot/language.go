@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const languageCallbackPrefix = "language:"
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// userLanguage returns stored user language, falls back to Telegram client language and then to the default one.
+func (bot *ElectroBot) userLanguage(chatID int64, from *botApi.User) string {
+	lang, err := bot.db.GetUserLanguage(chatID)
+	if err == nil && i18n.IsSupported(lang) {
+		return lang
+	}
+
+	if from != nil {
+		if lang = i18n.Match(from.LanguageCode); lang != "" {
+			return lang
+		}
+	}
+
+	return bot.defaultLanguage
+}
+
+func (bot *ElectroBot) handleLanguageCommand(lang string) (text string, keyboard botApi.InlineKeyboardMarkup) {
+	buttons := make([]botApi.InlineKeyboardButton, 0, len(i18n.Languages()))
+
+	for _, language := range i18n.Languages() {
+		buttons = append(buttons, botApi.NewInlineKeyboardButtonData(language.Name,
+			languageCallbackPrefix+language.Code))
+	}
+
+	return i18n.T(lang, "Choose your language:"), botApi.NewInlineKeyboardMarkup(buttons)
+}
+
+func (bot *ElectroBot) handleLanguageCallback(query *botApi.CallbackQuery, code string) string {
+	chatID := query.Message.Chat.ID
+	lang := bot.userLanguage(chatID, query.From)
+
+	if !i18n.IsSupported(code) {
+		return i18n.T(lang, "Unknown language")
+	}
+
+	if !bot.db.UserExists(chatID) {
+		return i18n.T(lang, "Please register with /start first")
+	}
+
+	if err := bot.db.SetUserLanguage(chatID, code); err != nil {
+		log.Errorf("Failed to store user language: %s", err)
+
+		return i18n.T(lang, "Failed to change language. Please try again later")
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "language": code}).Info("User language changed")
+
+	return i18n.T(code, "Language has been changed")
+}
@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"electrobot/apitoken"
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	maxLocationNameLength = 32
+	// maxHeartbeatClockSkew is the heartbeat timestamp window, older heartbeats are rejected anyway.
+	maxHeartbeatClockSkew = 5 * time.Minute
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleLocationsCommand lists monitored locations marking the chat subscriptions.
+func (bot *ElectroBot) handleLocationsCommand(chatID int64, lang string) string {
+	locations, err := bot.db.GetLocations()
+	if err != nil {
+		log.Errorf("Failed to get locations: %s", err)
+
+		return i18n.T(lang, "Failed to get locations. Please try again later")
+	}
+
+	subscriptions, err := bot.db.GetSubscriptions(chatID)
+	if err != nil {
+		log.Errorf("Failed to get subscriptions: %s", err)
+
+		return i18n.T(lang, "Failed to get locations. Please try again later")
+	}
+
+	subscribed := make(map[int64]bool)
+
+	for _, location := range subscriptions {
+		subscribed[location.ID] = true
+	}
+
+	lines := []string{i18n.T(lang, "Monitored locations:")}
+
+	for _, location := range locations {
+		if subscribed[location.ID] {
+			lines = append(lines, i18n.T(lang, "%s (subscribed)", location.Name))
+		} else {
+			lines = append(lines, location.Name)
+		}
+	}
+
+	lines = append(lines, i18n.T(lang, "Use /subscribe <location> and /unsubscribe <location> to choose locations"))
+
+	return strings.Join(lines, "\n")
+}
+
+func (bot *ElectroBot) handleSubscribeCommand(chatID int64, arguments, lang string, subscribe bool) string {
+	name := strings.TrimSpace(arguments)
+	if name == "" {
+		return bot.handleLocationsCommand(chatID, lang)
+	}
+
+	if !bot.db.UserExists(chatID) {
+		return i18n.T(lang, "Please register with /start first")
+	}
+
+	location, err := bot.db.GetLocation(name)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("Failed to get location: %s", err)
+		}
+
+		return i18n.T(lang, "Unknown location %q, see /locations", name)
+	}
+
+	if subscribe {
+		err = bot.db.Subscribe(chatID, location.ID)
+	} else {
+		err = bot.db.Unsubscribe(chatID, location.ID)
+	}
+
+	if err != nil {
+		log.Errorf("Failed to change subscription: %s", err)
+
+		return i18n.T(lang, "Failed to change subscription. Please try again later")
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "location": location.Name, "subscribed": subscribe}).Info(
+		"Subscription changed")
+
+	if subscribe {
+		return i18n.T(lang, "You're subscribed to %s", location.Name)
+	}
+
+	return i18n.T(lang, "You're unsubscribed from %s", location.Name)
+}
+
+// handleLocationCommand manages monitored locations, power state sources report locations by name.
+func (bot *ElectroBot) handleLocationCommand(chatID int64, arguments, lang string) string {
+	fields := strings.Fields(arguments)
+
+	switch {
+	case len(fields) == 2 && fields[0] == "add":
+		if len(fields[1]) > maxLocationNameLength {
+			return i18n.T(lang, "Location name is too long, please keep it under %s",
+				i18n.N(lang, maxLocationNameLength, "%d character|%d characters"))
+		}
+
+		if _, err := bot.db.AddLocation(fields[1]); err != nil {
+			log.Errorf("Failed to add location: %s", err)
+
+			return i18n.T(lang, "Failed to add location %q, the name may be taken already", fields[1])
+		}
+
+		log.WithFields(log.Fields{"chatID": chatID, "location": fields[1]}).Info("Location added")
+
+		return i18n.T(lang, "Location %q added", fields[1])
+
+	case len(fields) == 2 && fields[0] == "remove":
+		if err := bot.db.RemoveLocation(fields[1]); err != nil {
+			log.Errorf("Failed to remove location: %s", err)
+
+			return i18n.T(lang, "Failed to remove location %q, it doesn't exist or is the main location", fields[1])
+		}
+
+		log.WithFields(log.Fields{"chatID": chatID, "location": fields[1]}).Info("Location removed")
+
+		return i18n.T(lang, "Location %q removed", fields[1])
+
+	case len(fields) == 3 && fields[0] == "rename":
+		if len(fields[2]) > maxLocationNameLength {
+			return i18n.T(lang, "Location name is too long, please keep it under %s",
+				i18n.N(lang, maxLocationNameLength, "%d character|%d characters"))
+		}
+
+		if err := bot.db.RenameLocation(fields[1], fields[2]); err != nil {
+			log.Errorf("Failed to rename location: %s", err)
+
+			return i18n.T(lang, "Failed to rename location %q, it doesn't exist or the new name is taken", fields[1])
+		}
+
+		log.WithFields(log.Fields{"chatID": chatID, "location": fields[1], "name": fields[2]}).Info(
+			"Location renamed")
+
+		return i18n.T(lang, "Location %q renamed to %q", fields[1], fields[2])
+
+	case len(fields) == 2 && fields[0] == "token":
+		return bot.setHeartbeatToken(chatID, fields[1], lang)
+
+	case len(fields) == 3 && fields[0] == "skew":
+		return bot.setHeartbeatClockSkew(chatID, fields[1], fields[2], lang)
+
+	case len(fields) == 3 && fields[0] == "parent":
+		return bot.setLocationParent(chatID, fields[1], fields[2], lang)
+
+	default:
+		return i18n.T(lang, "Usage:\n/location add <name> - add location\n/location remove <name> - remove location"+
+			"\n/location rename <name> <new name> - rename location"+
+			"\n/location token <name> - generate a new heartbeat token of the location"+
+			"\n/location skew <name> <duration> - set the heartbeat clock skew of the location, 0 for default"+
+			"\n/location parent <name> <building> - make the location a part of the building, none to detach it")
+	}
+}
+
+// setLocationParent makes the location with a sensor a part of the building, e.g. an entrance or a floor. Outages of
+// the part are sent to its subscribers only, the building is off while all its parts are. Parent "none" detaches
+// the location.
+func (bot *ElectroBot) setLocationParent(chatID int64, name, parent, lang string) string {
+	if parent == "none" {
+		parent = ""
+	}
+
+	if err := bot.db.SetLocationParent(name, parent, ""); err != nil {
+		log.Errorf("Failed to set location parent: %s", err)
+
+		if parent == "" {
+			return i18n.T(lang, "Unknown sensor %q, see /sensors", name)
+		}
+
+		return i18n.T(lang, "Failed to make %q a part of %q. The part needs a sensor, the building must exist "+
+			"without a sensor of its own", name, parent)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "location": name, "parent": parent}).Info("Location parent set")
+
+	if parent == "" {
+		return i18n.T(lang, "Location %q is not a part of another one", name)
+	}
+
+	return i18n.T(lang, "Location %q is a part of %q, its outages are sent to its subscribers only", name, parent)
+}
+
+// setHeartbeatClockSkew sets how far the device clock may differ from the bot one, heartbeats delayed longer are
+// dated by the device timestamp.
+func (bot *ElectroBot) setHeartbeatClockSkew(chatID int64, name, value, lang string) string {
+	skew, err := time.ParseDuration(value)
+	if err != nil || skew < 0 || skew > maxHeartbeatClockSkew {
+		return i18n.T(lang, "Wrong clock skew %q, use a duration up to %s, e.g. 30s", value, maxHeartbeatClockSkew)
+	}
+
+	if err = bot.db.SetHeartbeatClockSkew(name, skew); err != nil {
+		log.Errorf("Failed to store heartbeat clock skew: %s", err)
+
+		return i18n.T(lang, "Unknown location %q, see /locations", name)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "location": name, "skew": skew}).Info("Heartbeat clock skew set")
+
+	if skew == 0 {
+		return i18n.T(lang, "Heartbeat clock skew of %q reset to default", name)
+	}
+
+	return i18n.T(lang, "Heartbeat clock skew of %q set to %s", name, skew)
+}
+
+// setHeartbeatToken replaces the token a device on the location premises reports heartbeats with.
+func (bot *ElectroBot) setHeartbeatToken(chatID int64, name, lang string) string {
+	token, hash, err := apitoken.Generate()
+	if err != nil {
+		log.Errorf("Failed to generate heartbeat token: %s", err)
+
+		return i18n.T(lang, "Failed to set heartbeat token. Please try again later")
+	}
+
+	if err = bot.db.SetHeartbeatToken(name, hash); err != nil {
+		log.Errorf("Failed to store heartbeat token: %s", err)
+
+		return i18n.T(lang, "Unknown location %q, see /locations", name)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "location": name}).Info("Heartbeat token set")
+
+	return i18n.T(lang, "Heartbeat token of %q set, the previous one stopped working. A device on the premises "+
+		"should send POST %s every minute. Delete this message after saving the token", name,
+		"/api/v1/heartbeat/"+token)
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"electrobot/telegrambot"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestLocationClockSkew(t *testing.T) {
+	server, _, db := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(adminID, "/location add garage")
+	checkReply(t, server, adminID, `Location "garage" added`)
+
+	server.SendMessage(adminID, "/location token garage")
+	checkReply(t, server, adminID, `Heartbeat token of "garage" set`)
+
+	testData := []struct {
+		command string
+		reply   string
+		skew    time.Duration
+	}{
+		{command: "/location skew garage 1m", reply: `Heartbeat clock skew of "garage" set to 1m0s`, skew: time.Minute},
+		{command: "/location skew garage 10m", reply: `Wrong clock skew "10m"`, skew: time.Minute},
+		{command: "/location skew garage soon", reply: `Wrong clock skew "soon"`, skew: time.Minute},
+		{command: "/location skew shed 1m", reply: `Unknown location "shed"`, skew: time.Minute},
+		{command: "/location skew garage 0", reply: `Heartbeat clock skew of "garage" reset to default`},
+	}
+
+	for _, item := range testData {
+		server.SendMessage(adminID, item.command)
+		checkReply(t, server, adminID, item.reply)
+
+		locations, err := db.GetHeartbeatLocations()
+		if err != nil {
+			t.Fatalf("Can't get heartbeat locations: %s", err)
+		}
+
+		if len(locations) != 1 || locations[0].ClockSkew != item.skew {
+			t.Errorf("Wrong clock skew after %q: %v", item.command, locations)
+		}
+	}
+}
+
+func TestLocationParent(t *testing.T) {
+	server, _, db := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	for _, name := range []string{"entrance1", "entrance2"} {
+		server.SendMessage(adminID, "/sensors add "+name)
+		checkReply(t, server, adminID, `Heartbeat token of "`+name+`" set`)
+	}
+
+	server.SendMessage(adminID, "/location add yard")
+	checkReply(t, server, adminID, `Location "yard" added`)
+
+	testData := []struct {
+		command string
+		reply   string
+		parents string
+	}{
+		{command: "/location parent entrance1 home", reply: `Location "entrance1" is a part of "home"`,
+			parents: "entrance1:home"},
+		{command: "/location parent yard home", reply: `Failed to make "yard" a part of "home"`,
+			parents: "entrance1:home"},
+		{command: "/location parent entrance2 entrance1", reply: `Failed to make "entrance2" a part of "entrance1"`,
+			parents: "entrance1:home"},
+		{command: "/location parent entrance2 home", reply: `Location "entrance2" is a part of "home"`,
+			parents: "entrance1:home entrance2:home"},
+		{command: "/location parent entrance1 none", reply: `Location "entrance1" is not a part of another one`,
+			parents: "entrance2:home"},
+	}
+
+	for _, item := range testData {
+		server.SendMessage(adminID, item.command)
+		checkReply(t, server, adminID, item.reply)
+
+		sensors, err := db.GetHeartbeatLocations()
+		if err != nil {
+			t.Fatalf("Can't get sensors: %s", err)
+		}
+
+		var parents []string
+
+		for _, sensor := range sensors {
+			if sensor.ParentID != 0 {
+				parents = append(parents, sensor.Name+":"+sensor.ParentName)
+			}
+		}
+
+		if strings.Join(parents, " ") != item.parents {
+			t.Errorf("Wrong parents after %q: %v", item.command, parents)
+		}
+	}
+
+	server.SendMessage(adminID, "/sensors")
+
+	if message := checkReply(t, server, adminID, "Sensors (2):"); !strings.Contains(message.Text,
+		"\nentrance2: part of home, no heartbeats yet") {
+		t.Errorf("Wrong sensor parent: %q", message.Text)
+	}
+}
+
+func TestRestoreOutage(t *testing.T) {
+	_, bot, db := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	main, err := db.GetMainLocation()
+	if err != nil {
+		t.Fatalf("Can't get main location: %s", err)
+	}
+
+	offSince := time.Now().Add(-time.Hour).Round(0)
+
+	// host monitor restores the outage before the power monitor reports the bot start
+	bot.RestoreOutage(main.Name, offSince)
+	bot.Started(time.Now().Add(-time.Minute))
+
+	if powerOn, since, _ := bot.PowerState(); powerOn || !since.Equal(offSince) {
+		t.Errorf("Wrong power state: on %v since %s", powerOn, since)
+	}
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const menuCallbackPrefix = "menu:"
+
+// Menu actions.
+const (
+	menuStatus   = "status"
+	menuHistory  = "history"
+	menuStats    = "stats"
+	menuSchedule = "schedule"
+	menuSettings = "settings"
+	menuLanguage = "language"
+	menuBack     = "back"
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func menuButton(text, action string) botApi.InlineKeyboardButton {
+	return botApi.NewInlineKeyboardButtonData(text, menuCallbackPrefix+action)
+}
+
+// menuKeyboard returns the main menu attached to command replies, so the bot can be used without typing commands.
+func menuKeyboard(lang string) botApi.InlineKeyboardMarkup {
+	return botApi.NewInlineKeyboardMarkup(
+		botApi.NewInlineKeyboardRow(
+			menuButton(i18n.T(lang, "Status"), menuStatus),
+			menuButton(i18n.T(lang, "History"), menuHistory),
+			menuButton(i18n.T(lang, "Stats"), menuStats)),
+		botApi.NewInlineKeyboardRow(
+			menuButton(i18n.T(lang, "Schedule"), menuSchedule),
+			menuButton(i18n.T(lang, "Settings"), menuSettings)))
+}
+
+func settingsKeyboard(lang string) botApi.InlineKeyboardMarkup {
+	return botApi.NewInlineKeyboardMarkup(
+		botApi.NewInlineKeyboardRow(
+			menuButton(i18n.T(lang, "Language"), menuLanguage),
+			menuButton(i18n.T(lang, "Back"), menuBack)))
+}
+
+// handleMenuCallback returns the reply to a menu button and the keyboard to keep under it.
+func (bot *ElectroBot) handleMenuCallback(query *botApi.CallbackQuery, action string,
+) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	chatID := query.Message.Chat.ID
+	lang := bot.userLanguage(chatID, query.From)
+	location := bot.userLocation(chatID)
+	menu := menuKeyboard(lang)
+
+	switch action {
+	case menuStatus:
+		return bot.handleStatusCommand(lang, location), &menu
+
+	case menuHistory:
+		return bot.handleHistoryCommand("", lang, location), &menu
+
+	case menuStats:
+		return bot.handleStatsCommand(lang, location), &menu
+
+	case menuSchedule:
+		return bot.handleScheduleCommand(lang, location), &menu
+
+	case menuSettings:
+		settings := settingsKeyboard(lang)
+
+		return i18n.T(lang, "Settings:\nUse /timezone <zone> to change the timezone"), &settings
+
+	case menuLanguage:
+		text, languages := bot.handleLanguageCommand(lang)
+
+		return text, &languages
+
+	case menuBack:
+		return i18n.T(lang, "Choose an option:"), &menu
+
+	default:
+		log.WithField("action", action).Warn("Unknown menu action")
+
+		return "", nil
+	}
+}
+
+// handleCallbackQuery dispatches inline keyboard callbacks by data prefix and edits the message with the result.
+func (bot *ElectroBot) handleCallbackQuery(query *botApi.CallbackQuery) {
+	if query.Message == nil {
+		return
+	}
+
+	var (
+		text     string
+		keyboard *botApi.InlineKeyboardMarkup
+	)
+
+	switch {
+	case strings.HasPrefix(query.Data, menuCallbackPrefix):
+		text, keyboard = bot.handleMenuCallback(query, strings.TrimPrefix(query.Data, menuCallbackPrefix))
+
+	case strings.HasPrefix(query.Data, setupCallbackPrefix):
+		text, keyboard = bot.handleSetupCallback(query, strings.TrimPrefix(query.Data, setupCallbackPrefix))
+
+	case strings.HasPrefix(query.Data, onboardingCallbackPrefix):
+		text, keyboard = bot.handleOnboardingCallback(query, strings.TrimPrefix(query.Data, onboardingCallbackPrefix))
+
+	case strings.HasPrefix(query.Data, languageCallbackPrefix):
+		text = bot.handleLanguageCallback(query, strings.TrimPrefix(query.Data, languageCallbackPrefix))
+
+	case strings.HasPrefix(query.Data, detailsCallbackPrefix):
+		text = bot.handleDetailsCallback(query, strings.TrimPrefix(query.Data, detailsCallbackPrefix))
+
+	case strings.HasPrefix(query.Data, restoreCallbackPrefix):
+		text = bot.handleRestoreCallback(query, strings.TrimPrefix(query.Data, restoreCallbackPrefix))
+
+	case strings.HasPrefix(query.Data, scheduleImportCallbackPrefix):
+		text = bot.handleScheduleImportCallback(query, strings.TrimPrefix(query.Data, scheduleImportCallbackPrefix))
+
+	default:
+		log.WithField("data", query.Data).Warn("Unknown callback query")
+	}
+
+	if _, err := bot.botApi.Request(botApi.NewCallback(query.ID, "")); err != nil {
+		log.Errorf("Failed to answer callback query: %s", err)
+	}
+
+	if text == "" {
+		return
+	}
+
+	edit := botApi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ReplyMarkup = keyboard
+
+	bot.reply(edit)
+}
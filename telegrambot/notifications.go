@@ -0,0 +1,431 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"electrobot/chaos"
+	"electrobot/database"
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	// withReminders appends pending reminders of the chat and clears them.
+	withReminders notificationExtras = 1 << iota
+	// withUtilities appends warnings about utilities of the chat building depending on electricity.
+	withUtilities
+	// minorAdvisory, minorElevator, minorReport and minorOngoing mark minor events the chat may get without
+	// sound, see minorEvents.
+	minorAdvisory
+	minorElevator
+	minorReport
+	minorOngoing
+	// critical marks power state changes, they are queued ahead of other messages.
+	critical
+	// batched marks location power state changes combined for chats subscribed to several locations, see
+	// batchNotification.
+	batched
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// notificationExtras are per-chat additions to notification texts and their delivery options.
+type notificationExtras int
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Started notifies users that the bot has been restarted without power outage.
+func (bot *ElectroBot) Started(lastAlive time.Time) {
+	bot.setLastShutdownTime(lastAlive)
+
+	powerOnTime := bot.lastPowerOnTime()
+
+	// host probes may have restored or detected an outage already, the bot restart does not end it
+	bot.stateMutex.Lock()
+	if !bot.powerOff {
+		bot.powerSince = powerOnTime
+	}
+	bot.stateMutex.Unlock()
+
+	text := func(lang string, location *time.Location) string {
+		return i18n.T(lang, "Bot started at %s\nLast alive time: %s",
+			i18n.DateTime(lang, bot.launchTime.In(location)), i18n.DateTime(lang, lastAlive.In(location)))
+	}
+
+	bot.publishToChannels(text)
+	bot.notifyLocation(database.MainLocationID, text, critical, nil)
+}
+
+// PowerOff notifies users that power went off.
+func (bot *ElectroBot) PowerOff(start time.Time) {
+	bot.setLastShutdownTime(start)
+	bot.setPowerState(false, start)
+	bot.cancelRestoreAdvisory()
+
+	bot.publishToChannels(powerOffText(start))
+	bot.notifyPowerOff(database.MainLocationID, start)
+
+	bot.scheduleOutageUpdate(start)
+}
+
+// PowerOn notifies users that power is back and delivers their reminders.
+func (bot *ElectroBot) PowerOn(start, end time.Time) {
+	bot.setPowerState(true, end)
+	bot.cancelOutageUpdate()
+	bot.flushPowerOffs()
+
+	anomalies := bot.outageAnomalies(start, end)
+
+	anomalyText := func(lang string) string {
+		if len(anomalies) == 0 {
+			return ""
+		}
+
+		return "\n\n" + i18n.T(lang, "⚠️ Unusual outage: %s", anomaliesText(lang, anomalies))
+	}
+
+	text := func(lang string, location *time.Location) string {
+		return i18n.T(lang, "Power is back at %s\nIt was off for %s",
+			i18n.DateTime(lang, end.In(location)), formatDuration(end.Sub(start), lang)) + anomalyText(lang)
+	}
+
+	compactText := func(lang string, _ *time.Location) string {
+		return i18n.T(lang, "🟢 Power is back after %s", formatDuration(end.Sub(start), lang)) + anomalyText(lang)
+	}
+
+	// channels are published first, the building learns about the change before the user fan-out finishes
+	bot.publishToChannels(text)
+	bot.notifyLocationVariants(database.MainLocationID, map[string]func(string, *time.Location) string{
+		variantControl: text, variantCompact: compactText,
+	}, critical|batched|withReminders, bot.outageDetailsKeyboard(end))
+
+	bot.scheduleRestoreAdvisory()
+	bot.scheduleServicesReport(end)
+	bot.askRestoreConfirmation(database.MainLocationID, end)
+
+	if len(anomalies) != 0 {
+		bot.sendAnomalyAlert(start, end, anomalies)
+	}
+}
+
+// LocationPowerOff notifies users subscribed to the location that power went off there, it is used by power state
+// sources other than the bot itself. Unknown locations are created.
+func (bot *ElectroBot) LocationPowerOff(name string, start time.Time) {
+	locationID, err := bot.db.EnsureLocation(name)
+	if err != nil {
+		log.WithField("location", name).Errorf("Failed to get location: %s", err)
+
+		return
+	}
+
+	if locationID == database.MainLocationID {
+		bot.PowerOff(start)
+
+		return
+	}
+
+	bot.notifyPowerOff(locationID, start)
+}
+
+// LocationPowerOn notifies users subscribed to the location that power is back there and delivers their reminders.
+// Unknown locations are created.
+func (bot *ElectroBot) LocationPowerOn(name string, start, end time.Time) {
+	locationID, err := bot.db.EnsureLocation(name)
+	if err != nil {
+		log.WithField("location", name).Errorf("Failed to get location: %s", err)
+
+		return
+	}
+
+	if locationID == database.MainLocationID {
+		bot.PowerOn(start, end)
+
+		return
+	}
+
+	bot.flushPowerOffs()
+	bot.notifyLocation(locationID, func(lang string, location *time.Location) string {
+		return i18n.T(lang, "Power is back at %s\nIt was off for %s",
+			i18n.DateTime(lang, end.In(location)), formatDuration(end.Sub(start), lang))
+	}, critical|batched|withReminders, nil)
+
+	bot.askRestoreConfirmation(locationID, end)
+}
+
+// PhasePowerOff notifies users subscribed to the location that one of its phases lost power while others still
+// have it.
+func (bot *ElectroBot) PhasePowerOff(name, phase string, start time.Time) {
+	locationID, err := bot.db.EnsureLocation(name)
+	if err != nil {
+		log.WithField("location", name).Errorf("Failed to get location: %s", err)
+
+		return
+	}
+
+	bot.notifyLocation(locationID, func(lang string, location *time.Location) string {
+		return i18n.T(lang, "🟡 Phase %s went off at %s, power is partially off",
+			phase, i18n.DateTime(lang, start.In(location)))
+	}, critical|batched, nil)
+}
+
+// PhasePowerOn notifies users subscribed to the location that its phase is back.
+func (bot *ElectroBot) PhasePowerOn(name, phase string, start, end time.Time) {
+	locationID, err := bot.db.EnsureLocation(name)
+	if err != nil {
+		log.WithField("location", name).Errorf("Failed to get location: %s", err)
+
+		return
+	}
+
+	bot.notifyLocation(locationID, func(lang string, location *time.Location) string {
+		return i18n.T(lang, "Phase %s is back at %s\nIt was off for %s",
+			phase, i18n.DateTime(lang, end.In(location)), formatDuration(end.Sub(start), lang))
+	}, critical|batched, nil)
+}
+
+// RestoreOutage continues the location outage that was in progress before the restart, users have been notified
+// about it already.
+func (bot *ElectroBot) RestoreOutage(name string, since time.Time) {
+	locationID, err := bot.db.EnsureLocation(name)
+	if err != nil {
+		log.WithField("location", name).Errorf("Failed to get location: %s", err)
+
+		return
+	}
+
+	// only the main location power state is kept in memory
+	if locationID == database.MainLocationID {
+		bot.setLastShutdownTime(since)
+		bot.setPowerState(false, since)
+		bot.scheduleOutageUpdate(since)
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (bot *ElectroBot) setLastShutdownTime(lastShutdownTime time.Time) {
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	bot.lastShutdownTime = lastShutdownTime
+}
+
+// notifyAllUsers sends text to every registered user regardless of subscriptions, see notifyUsers.
+func (bot *ElectroBot) notifyAllUsers(text func(lang string, location *time.Location) string,
+	extras notificationExtras, keyboard func(lang string) *botApi.InlineKeyboardMarkup,
+) (delivered, queued, failed int) {
+	users, err := bot.db.GetAllUsers()
+	if err != nil {
+		log.Errorf("Failed to get all users: %s", err)
+
+		return 0, 0, 0
+	}
+
+	return bot.notifyUsers(users, text, extras, keyboard)
+}
+
+// notifyLocation sends text to users subscribed to the location, see notifyUsers. The text is prefixed with
+// the location name when several locations are monitored.
+func (bot *ElectroBot) notifyLocation(locationID int64, text func(lang string, location *time.Location) string,
+	extras notificationExtras, keyboard func(lang string) *botApi.InlineKeyboardMarkup,
+) (delivered, queued, failed int) {
+	users, err := bot.db.GetSubscribers(locationID)
+	if err != nil {
+		log.WithField("location", locationID).Errorf("Failed to get location subscribers: %s", err)
+
+		return 0, 0, 0
+	}
+
+	return bot.notifyUsers(users, bot.locationText(locationID, text), extras, keyboard)
+}
+
+// locationText prefixes text with the location name when several locations are monitored.
+func (bot *ElectroBot) locationText(locationID int64, text func(lang string, location *time.Location) string,
+) func(lang string, location *time.Location) string {
+	locations, err := bot.db.GetLocations()
+	if err != nil {
+		log.Errorf("Failed to get locations: %s", err)
+	}
+
+	for _, monitored := range locations {
+		if len(locations) > 1 && monitored.ID == locationID {
+			prefix := monitored.Name + ": "
+
+			return func(lang string, location *time.Location) string {
+				return prefix + text(lang, location)
+			}
+		}
+	}
+
+	return text
+}
+
+// notifyUsers sends text rendered in each user's language and timezone to the users with the per-chat extras,
+// keyboard is optional. Messages which can't be sent now are queued for later delivery.
+func (bot *ElectroBot) notifyUsers(users []int64, text func(lang string, location *time.Location) string,
+	extras notificationExtras, keyboard func(lang string) *botApi.InlineKeyboardMarkup,
+) (delivered, queued, failed int) {
+	for _, user := range users {
+		log.WithFields(log.Fields{"user": user}).Debug("Notifying user")
+
+		lang := bot.userLanguage(user, nil)
+
+		var reminderIDs []int64
+
+		userText := text(lang, bot.userLocation(user))
+		if extras&withUtilities != 0 {
+			userText += bot.utilitiesText(user, lang)
+		}
+
+		if extras&withReminders != 0 {
+			var remindersText string
+
+			remindersText, reminderIDs = bot.pendingRemindersText(user, lang)
+			userText += remindersText
+		}
+
+		var userKeyboard *botApi.InlineKeyboardMarkup
+
+		if keyboard != nil {
+			userKeyboard = keyboard(lang)
+		}
+
+		if extras&batched != 0 && bot.batchNotification(user, userText, userKeyboard, extras) {
+			queued++
+
+			// the batch already contains reminders
+			if extras&withReminders != 0 {
+				bot.clearDeliveredReminders(user, reminderIDs)
+			}
+
+			continue
+		}
+
+		isQueued, err := bot.deliver(user, userText, userKeyboard, extras)
+
+		if newChatID := migratedChatID(err); newChatID != 0 {
+			bot.migrateChat(user, newChatID)
+
+			user = newChatID
+			isQueued, err = bot.deliver(user, userText, userKeyboard, extras)
+		}
+
+		if err != nil {
+			log.Errorf("Failed to send message to user %d: %s", user, err)
+
+			if isChatUnreachable(err) {
+				bot.unregisterUnreachableUser(user, err)
+			}
+
+			failed++
+
+			continue
+		}
+
+		chaos.CrashPoint("broadcast")
+
+		if isQueued {
+			queued++
+		} else {
+			delivered++
+		}
+
+		// queued message already contains reminders
+		if extras&withReminders != 0 {
+			bot.clearDeliveredReminders(user, reminderIDs)
+		}
+	}
+
+	return delivered, queued, failed
+}
+
+// isChatUnreachable returns true if Telegram refuses delivery permanently:
+// the user blocked the bot, deleted the account or never started a chat with it.
+func isChatUnreachable(err error) bool {
+	var apiErr *botApi.Error
+
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden
+}
+
+// unregisterUnreachableUser removes the user so further notifications are not attempted.
+func (bot *ElectroBot) unregisterUnreachableUser(userID int64, reason error) {
+	if err := bot.db.RemoveUserInfo(userID); err != nil {
+		log.Errorf("Failed to unregister unreachable user %d: %s", userID, err)
+
+		return
+	}
+
+	log.WithField("user", userID).Warnf("User unregistered as unreachable: %s", reason)
+
+	if err := bot.db.RecordUserUnregistered(userID, reason.Error()); err != nil {
+		log.Errorf("Failed to store user unregistered event: %s", err)
+	}
+}
+
+func (bot *ElectroBot) scheduleRestoreAdvisory() {
+	if bot.restoreAdvisoryDelay.Load() <= 0 {
+		return
+	}
+
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	if bot.restoreAdvisoryTimer != nil {
+		bot.restoreAdvisoryTimer.Stop()
+	}
+
+	bot.restoreAdvisoryTimer = time.AfterFunc(bot.restoreAdvisoryDelay.Load(), bot.sendRestoreAdvisory)
+}
+
+// cancelRestoreAdvisory stops the advisory of the previous restoration, power was not stable long enough.
+func (bot *ElectroBot) cancelRestoreAdvisory() {
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	if bot.restoreAdvisoryTimer != nil {
+		bot.restoreAdvisoryTimer.Stop()
+		bot.restoreAdvisoryTimer = nil
+	}
+}
+
+// sendRestoreAdvisory tells users that power has been stable long enough to turn appliances back on.
+func (bot *ElectroBot) sendRestoreAdvisory() {
+	// the timer may fire while the next outage is being reported
+	if powerOn, _, _ := bot.PowerState(); !powerOn {
+		return
+	}
+
+	bot.notifyLocation(database.MainLocationID, func(lang string, _ *time.Location) string {
+		return i18n.T(lang, "Power has been stable for %s, it should be safe to turn appliances back on",
+			formatDuration(bot.restoreAdvisoryDelay.Load(), lang))
+	}, minorAdvisory, nil)
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"electrobot/telegrambot"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestRestoreAdvisoryCanceled(t *testing.T) {
+	const delay = 300 * time.Millisecond
+
+	server, bot, db := newTestBot(t, telegrambot.Config{RestoreAdvisoryDelay: delay})
+
+	main, err := db.GetMainLocation()
+	if err != nil {
+		t.Fatalf("Can't get main location: %s", err)
+	}
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	start := time.Now()
+
+	bot.PowerOff(start)
+	checkReply(t, server, userID, "Power went off at")
+
+	bot.PowerOn(start, start.Add(time.Minute))
+	checkReply(t, server, userID, "Power is back at")
+
+	// power went off again before the advisory delay expired
+	bot.LocationPowerOff(main.Name, start.Add(2*time.Minute))
+	checkReply(t, server, userID, "Power went off at")
+
+	if message, err := server.NextMessage(userID, 2*delay); err == nil {
+		t.Errorf("Advisory is sent during outage: %q", message.Text)
+	}
+
+	bot.PowerOn(start.Add(2*time.Minute), start.Add(3*time.Minute))
+	checkReply(t, server, userID, "Power is back at")
+	checkReply(t, server, userID, "Power has been stable for")
+}
+
+func TestSilentNotifications(t *testing.T) {
+	const delay = 100 * time.Millisecond
+
+	server, bot, _ := newTestBot(t, telegrambot.Config{RestoreAdvisoryDelay: delay})
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.SendMessage(userID, "/silent report")
+	checkReply(t, server, userID, "Usage: /silent <event> on|off")
+
+	start := time.Now()
+
+	for _, silentAdvisory := range []bool{true, false} {
+		bot.PowerOff(start)
+
+		if message := checkReply(t, server, userID, "Power went off at"); message.Silent {
+			t.Error("Power off notification is silent")
+		}
+
+		bot.PowerOn(start, start.Add(time.Minute))
+
+		if message := checkReply(t, server, userID, "Power is back at"); message.Silent {
+			t.Error("Power on notification is silent")
+		}
+
+		if message := checkReply(t, server, userID, "Power has been stable for"); message.Silent != silentAdvisory {
+			t.Errorf("Wrong advisory silent: %v", message.Silent)
+		}
+
+		server.SendMessage(userID, "/silent advisory off")
+		checkReply(t, server, userID, "Minor notifications:\nadvisory - power has been stable long enough to "+
+			"turn appliances on: with sound\nelevator")
+
+		start = start.Add(time.Hour)
+	}
+}
+
+func TestOutageUpdates(t *testing.T) {
+	const interval = 200 * time.Millisecond
+
+	server, bot, _ := newTestBot(t, telegrambot.Config{OutageUpdateInterval: interval})
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	start := time.Now().Add(-4 * time.Hour)
+
+	bot.PowerOff(start)
+	checkReply(t, server, userID, "Power went off at")
+
+	for i := 0; i < 2; i++ {
+		if message := checkReply(t, server, userID, "🔴 Still no power, 4 hours and counting"); !message.Silent {
+			t.Error("Outage update is not silent")
+		}
+	}
+
+	bot.PowerOn(start, time.Now())
+	checkReply(t, server, userID, "Power is back at")
+
+	if message, err := server.NextMessage(userID, 2*interval); err == nil {
+		t.Errorf("Outage update is sent after power-on: %q", message.Text)
+	}
+}
+
+func TestRestoreConfirmation(t *testing.T) {
+	const otherID = 3
+
+	server, bot, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	chats := []int64{adminID, userID, otherID}
+
+	for _, chatID := range chats {
+		server.SendMessage(chatID, "/start")
+		checkReply(t, server, chatID, "You've been successfully registered")
+	}
+
+	server.SendMessage(adminID, "/flags set restoreconfirm 100")
+	checkReply(t, server, adminID, "restoreconfirm is enabled for 100% of chats")
+
+	start := time.Now().Add(-time.Hour)
+	end := time.Now()
+
+	bot.PowerOff(start)
+	bot.PowerOn(start, end)
+
+	questions := make(map[int64]int)
+
+	for _, chatID := range chats {
+		checkReply(t, server, chatID, "Power went off at")
+		checkReply(t, server, chatID, "Power is back at")
+		questions[chatID] = checkReply(t, server, chatID, "Is power back at your place too?").ID
+	}
+
+	data := fmt.Sprintf("restore:1:%d:", end.Unix())
+
+	server.PressButton(adminID, questions[adminID], data+"yes")
+	checkReply(t, server, adminID, "Thank you for the answer")
+
+	server.PressButton(userID, questions[userID], data+"no")
+	checkReply(t, server, userID, "Thank you for the answer")
+
+	server.PressButton(otherID, questions[otherID], data+"no")
+	checkReply(t, server, otherID, "Thank you for the answer")
+
+	for _, chatID := range chats {
+		checkReply(t, server, chatID, "⚠️ 2 users report that power is still off at their place")
+	}
+
+	bot.Heartbeat(time.Now())
+
+	server.SendMessage(userID, "/status")
+	checkReply(t, server, userID, "🟢 Power is on\n⚠️ Some users report that power is still off at their place")
+
+	bot.PowerOff(end.Add(time.Minute))
+	checkReply(t, server, userID, "Power went off at")
+
+	server.PressButton(userID, questions[userID], data+"no")
+	checkReply(t, server, userID, "Thank you, this outage is over already")
+}
+
+func TestOutageAnomaly(t *testing.T) {
+	server, bot, db := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	start := time.Now().Add(-6 * time.Hour).Round(time.Second)
+
+	for i := 10; i > 0; i-- {
+		dayStart := start.AddDate(0, 0, -i)
+
+		if err := db.RecordPowerOff(dayStart, dayStart.Add(time.Hour)); err != nil {
+			t.Fatalf("Can't record outage: %s", err)
+		}
+	}
+
+	end := start.Add(5 * time.Hour)
+
+	if err := db.RecordPowerOff(start, end); err != nil {
+		t.Fatalf("Can't record outage: %s", err)
+	}
+
+	bot.PowerOn(start, end)
+
+	message := checkReply(t, server, userID, "Power is back at")
+
+	if !strings.Contains(message.Text, "⚠️ Unusual outage: unusually long outage") {
+		t.Errorf("Wrong power on message: %q", message.Text)
+	}
+
+	checkReply(t, server, adminID, "⚠️ Outage anomaly: unusually long outage")
+}
+
+func TestNotificationVariants(t *testing.T) {
+	server, bot, db := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	for _, chatID := range []int64{userID, adminID} {
+		server.SendMessage(chatID, "/start")
+		checkReply(t, server, chatID, "You've been successfully registered")
+	}
+
+	server.SendMessage(adminID, "/flags add compactwording 2")
+	checkReply(t, server, adminID, "compactwording is enabled for chat 2")
+
+	start := time.Now().Add(-time.Hour).Round(time.Second)
+
+	if err := db.RecordPowerOff(start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("Can't record outage: %s", err)
+	}
+
+	bot.PowerOn(start, start.Add(time.Hour))
+
+	message := checkReply(t, server, userID, "🟢 Power is back after 1 hour")
+	checkReply(t, server, adminID, "Power is back at")
+
+	outages, err := db.GetOutages(1)
+	if err != nil || len(outages) != 1 {
+		t.Fatalf("Can't get outage: %v %v", outages, err)
+	}
+
+	server.PressButton(userID, message.ID, fmt.Sprintf("details:%d", outages[0].ID))
+	checkReply(t, server, userID, "Outage details:")
+
+	server.SendMessage(adminID, "/flags")
+
+	if message = checkReply(t, server, adminID, "Experimental features:"); !strings.Contains(message.Text,
+		"compact wording: 1 sent, details opened 1 times (100%)\ncontrol wording: 1 sent, details opened 0 times (0%)") {
+		t.Errorf("Wrong variant stats: %s", message.Text)
+	}
+}
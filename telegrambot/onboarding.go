@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"slices"
+	"strings"
+
+	"electrobot/i18n"
+	"electrobot/scheduler"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	onboardingCallbackPrefix = "onboarding:"
+	onboardingDialog         = "onboarding"
+)
+
+// Onboarding steps, each step is also the action of its buttons.
+const (
+	onboardingLanguage  = "language"
+	onboardingGroup     = "group"
+	onboardingUtilities = "utilities"
+)
+
+// Onboarding actions available on several steps.
+const (
+	onboardingSkip = "skip"
+	onboardingDone = "done"
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// startOnboarding starts the onboarding of the new private chat and returns its first step, no keyboard is returned
+// if it fails.
+func (bot *ElectroBot) startOnboarding(chatID int64, lang string) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	if err := bot.setDialogStep(chatID, onboardingDialog, onboardingLanguage); err != nil {
+		log.WithField("chatID", chatID).Errorf("Failed to start onboarding: %s", err)
+
+		return "", nil
+	}
+
+	return bot.onboardingLanguageStep(lang)
+}
+
+// handleOnboardingCallback applies the choice of the current onboarding step and returns the next step, buttons of
+// passed steps are refused.
+func (bot *ElectroBot) handleOnboardingCallback(query *botApi.CallbackQuery, data string,
+) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	chatID := query.Message.Chat.ID
+	lang := bot.userLanguage(chatID, query.From)
+	action, argument, _ := strings.Cut(data, ":")
+
+	active, ok := bot.activeDialog(chatID)
+	if !ok || active.Name != onboardingDialog ||
+		(action != active.Step && action != onboardingSkip && action != onboardingDone) {
+		return i18n.T(lang, "This step is over, use /menu to change settings"), nil
+	}
+
+	switch action {
+	case onboardingLanguage:
+		if !i18n.IsSupported(argument) {
+			break
+		}
+
+		if err := bot.db.SetUserLanguage(chatID, argument); err != nil {
+			log.Errorf("Failed to store user language: %s", err)
+
+			return i18n.T(lang, "Failed to change settings. Please try again later"), nil
+		}
+
+		return bot.nextOnboardingStep(chatID, onboardingGroup, argument)
+
+	case onboardingGroup:
+		if !slices.Contains(scheduler.Groups, argument) {
+			break
+		}
+
+		if err := bot.db.SetUserScheduleGroup(chatID, argument); err != nil {
+			log.Errorf("Failed to store schedule group: %s", err)
+
+			return i18n.T(lang, "Failed to change settings. Please try again later"), nil
+		}
+
+		return bot.nextOnboardingStep(chatID, onboardingUtilities, lang)
+
+	case onboardingUtilities:
+		return bot.toggleOnboardingUtility(chatID, argument, lang)
+
+	case onboardingSkip:
+		if active.Step == onboardingLanguage {
+			return bot.nextOnboardingStep(chatID, onboardingGroup, lang)
+		}
+
+		return bot.nextOnboardingStep(chatID, onboardingUtilities, lang)
+
+	case onboardingDone:
+		bot.endDialog(chatID)
+
+		menu := menuKeyboard(lang)
+
+		return i18n.T(lang, "You're all set! Type /help to see what I can do"), &menu
+	}
+
+	log.WithField("data", data).Warn("Unknown onboarding action")
+
+	return "", nil
+}
+
+// nextOnboardingStep stores the step and returns it.
+func (bot *ElectroBot) nextOnboardingStep(chatID int64, step, lang string,
+) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	if err := bot.setDialogStep(chatID, onboardingDialog, step); err != nil {
+		log.WithField("chatID", chatID).Errorf("Failed to store onboarding step: %s", err)
+
+		return i18n.T(lang, "Failed to change settings. Please try again later"), nil
+	}
+
+	if step == onboardingGroup {
+		return bot.onboardingGroupStep(lang)
+	}
+
+	return bot.onboardingUtilitiesStep(chatID, lang)
+}
+
+func (bot *ElectroBot) onboardingLanguageStep(lang string) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	buttons := make([]botApi.InlineKeyboardButton, 0, len(i18n.Languages()))
+
+	for _, language := range i18n.Languages() {
+		buttons = append(buttons, onboardingButton(language.Name, onboardingLanguage, language.Code))
+	}
+
+	languages := botApi.NewInlineKeyboardMarkup(buttons,
+		botApi.NewInlineKeyboardRow(onboardingButton(i18n.T(lang, "Skip"), onboardingSkip, "")))
+
+	return i18n.T(lang, "Let's set you up in three short steps. Choose your language:"), &languages
+}
+
+func (bot *ElectroBot) onboardingGroupStep(lang string) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	var rows [][]botApi.InlineKeyboardButton
+
+	for i, group := range scheduler.Groups {
+		if i%setupGroupsPerRow == 0 {
+			rows = append(rows, nil)
+		}
+
+		rows[len(rows)-1] = append(rows[len(rows)-1], onboardingButton(group, onboardingGroup, group))
+	}
+
+	rows = append(rows, botApi.NewInlineKeyboardRow(onboardingButton(i18n.T(lang, "Skip"), onboardingSkip, "")))
+	groups := botApi.NewInlineKeyboardMarkup(rows...)
+
+	return i18n.T(lang, "Choose your outage schedule group to get warnings before planned outages:"), &groups
+}
+
+func (bot *ElectroBot) onboardingUtilitiesStep(chatID int64, lang string,
+) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	utilities, err := bot.db.GetUserUtilities(chatID)
+	if err != nil {
+		log.Errorf("Failed to get utilities: %s", err)
+	}
+
+	text = i18n.T(lang, "Should power off notifications warn you about utilities depending on electricity?") +
+		"\n" + i18n.T(lang, "Water: %s", onOffText(utilities.Water, lang)) +
+		"\n" + i18n.T(lang, "Heating: %s", onOffText(utilities.Heating, lang))
+
+	settings := botApi.NewInlineKeyboardMarkup(
+		botApi.NewInlineKeyboardRow(onboardingToggleButton(i18n.T(lang, "Water"), utilityWater, utilities.Water, lang)),
+		botApi.NewInlineKeyboardRow(onboardingToggleButton(i18n.T(lang, "Heating"), utilityHeating,
+			utilities.Heating, lang)),
+		botApi.NewInlineKeyboardRow(onboardingButton(i18n.T(lang, "Done"), onboardingDone, "")))
+
+	return text, &settings
+}
+
+// toggleOnboardingUtility switches the utility, the argument is the utility and its new state.
+func (bot *ElectroBot) toggleOnboardingUtility(chatID int64, argument, lang string,
+) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	utility, value, _ := strings.Cut(argument, ":")
+
+	utilities, err := bot.db.GetUserUtilities(chatID)
+	if err == nil {
+		switch utility {
+		case utilityWater:
+			utilities.Water = value == settingOn
+		case utilityHeating:
+			utilities.Heating = value == settingOn
+		}
+
+		err = bot.db.SetUserUtilities(chatID, utilities)
+	}
+
+	if err != nil {
+		log.Errorf("Failed to store utilities: %s", err)
+
+		return i18n.T(lang, "Failed to change settings. Please try again later"), nil
+	}
+
+	return bot.onboardingUtilitiesStep(chatID, lang)
+}
+
+func onboardingButton(text, action, argument string) botApi.InlineKeyboardButton {
+	data := onboardingCallbackPrefix + action
+	if argument != "" {
+		data += ":" + argument
+	}
+
+	return botApi.NewInlineKeyboardButtonData(text, data)
+}
+
+// onboardingToggleButton returns the button switching the utility to the opposite state.
+func onboardingToggleButton(name, utility string, enabled bool, lang string) botApi.InlineKeyboardButton {
+	if enabled {
+		return onboardingButton(i18n.T(lang, "Turn off: %s", name), onboardingUtilities, utility+":"+settingOff)
+	}
+
+	return onboardingButton(i18n.T(lang, "Turn on: %s", name), onboardingUtilities, utility+":"+settingOn)
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"testing"
+
+	"electrobot/telegrambot"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestOnboarding(t *testing.T) {
+	server, _, db := newTestBot(t, telegrambot.Config{})
+
+	server.SendMessage(adminID, "/start")
+	message := checkReply(t, server, adminID, "You've been successfully registered\n\nLet's set you up")
+
+	pressButton(t, server, message.ID, "onboarding:group:1.1", "This step is over")
+	pressButton(t, server, message.ID, "onboarding:language:uk", "Оберіть вашу групу")
+	pressButton(t, server, message.ID, "onboarding:group:1.2", "Чи попереджати")
+
+	if group, err := db.GetUserScheduleGroup(adminID); err != nil || group != "1.2" {
+		t.Errorf("Wrong schedule group: %s %v", group, err)
+	}
+
+	pressButton(t, server, message.ID, "onboarding:utilities:water:on", "Чи попереджати")
+
+	if utilities, err := db.GetUserUtilities(adminID); err != nil || !utilities.Water || utilities.Heating {
+		t.Errorf("Wrong utilities: %v %v", utilities, err)
+	}
+
+	pressButton(t, server, message.ID, "onboarding:done", "Все готово")
+	pressButton(t, server, message.ID, "onboarding:utilities:heating:on", "Цей крок уже пройдено")
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered\n\nLet's set you up")
+}
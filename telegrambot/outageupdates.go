@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// scheduleOutageUpdate plans the next "still no power" update about the main location outage started at start.
+func (bot *ElectroBot) scheduleOutageUpdate(start time.Time) {
+	interval := bot.outageUpdateInterval.Load()
+	if interval <= 0 {
+		return
+	}
+
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	if bot.outageUpdateTimer != nil {
+		bot.outageUpdateTimer.Stop()
+	}
+
+	bot.outageUpdateTimer = time.AfterFunc(interval, func() { bot.sendOutageUpdate(start) })
+}
+
+// cancelOutageUpdate stops updates about the outage, power is back.
+func (bot *ElectroBot) cancelOutageUpdate() {
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	if bot.outageUpdateTimer != nil {
+		bot.outageUpdateTimer.Stop()
+		bot.outageUpdateTimer = nil
+	}
+}
+
+// sendOutageUpdate tells subscribers that power is still off and plans the next update.
+func (bot *ElectroBot) sendOutageUpdate(start time.Time) {
+	// the timer may fire while the power-on is being reported
+	if powerOn, since, _ := bot.PowerState(); powerOn || !since.Equal(start) {
+		return
+	}
+
+	log.WithField("start", start).Info("Sending outage update")
+
+	duration := time.Since(start)
+
+	bot.notifyLocation(database.MainLocationID, func(lang string, _ *time.Location) string {
+		return i18n.T(lang, "🔴 Still no power, %s and counting", formatDuration(duration, lang))
+	}, minorOngoing, nil)
+
+	bot.scheduleOutageUpdate(start)
+}
@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	ownerSettingKey = "owner_chat_id"
+	claimCodeSize   = 8
+	// every failed claim doubles the time until the next attempt is accepted, up to maxClaimBackoff
+	minClaimBackoff = time.Second
+	maxClaimBackoff = time.Hour
+)
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// OwnerChatID returns the user ID of the bot owner, which is also the ID of the private chat with the owner, 0 if
+// the bot is not claimed yet.
+func (bot *ElectroBot) OwnerChatID() int64 {
+	return StoredOwnerChatID(bot.db)
+}
+
+// StoredOwnerChatID returns the owner claimed with /claim, 0 if the bot is not claimed yet.
+func StoredOwnerChatID(storage SettingsStorage) int64 {
+	value, err := storage.GetSetting(ownerSettingKey)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("Failed to get owner: %s", err)
+		}
+
+		return 0
+	}
+
+	ownerID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Errorf("Invalid owner chat ID %q: %s", value, err)
+
+		return 0
+	}
+
+	return ownerID
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// initOwnershipClaim prints a one-time claim code to the log if the bot has no owner yet.
+func (bot *ElectroBot) initOwnershipClaim() {
+	if bot.OwnerChatID() != 0 {
+		return
+	}
+
+	code := make([]byte, claimCodeSize)
+
+	if _, err := rand.Read(code); err != nil {
+		log.Errorf("Failed to generate claim code: %s", err)
+
+		return
+	}
+
+	bot.claimCode = hex.EncodeToString(code)
+
+	log.WithField("code", bot.claimCode).Warn(
+		"Bot has no owner, send /claim <code> in a private chat with the bot to claim ownership")
+}
+
+// handleClaimCommand makes the sender the bot owner, claims are accepted in private chats only so the owner is a user
+// and not a group. Failed claims are rate limited to keep the code from being guessed.
+func (bot *ElectroBot) handleClaimCommand(message *botApi.Message, code, lang string) (text string, claimed bool) {
+	if bot.OwnerChatID() != 0 {
+		return i18n.T(lang, "This bot already has an owner"), false
+	}
+
+	userID := senderID(message.From)
+
+	if !message.Chat.IsPrivate() || userID == 0 {
+		return i18n.T(lang, "Ownership can be claimed in a private chat with the bot only"), false
+	}
+
+	bot.claimMutex.Lock()
+	defer bot.claimMutex.Unlock()
+
+	if now := time.Now(); now.Before(bot.claimBlockedUntil) {
+		log.WithField("userID", userID).Warn("Ownership claim attempt rejected by rate limit")
+
+		return i18n.T(lang, "Too many invalid claim attempts, try again in %s",
+			formatDuration(max(bot.claimBlockedUntil.Sub(now), time.Second), lang)), false
+	}
+
+	code = strings.TrimSpace(code)
+
+	if bot.claimCode == "" || subtle.ConstantTimeCompare([]byte(code), []byte(bot.claimCode)) != 1 {
+		bot.claimFailures++
+		bot.claimBlockedUntil = time.Now().Add(claimBackoff(bot.claimFailures))
+
+		log.WithFields(log.Fields{
+			"userID": userID, "failures": bot.claimFailures,
+		}).Warn("Invalid ownership claim attempt")
+
+		return i18n.T(lang, "Invalid claim code"), false
+	}
+
+	if err := bot.db.SetSetting(ownerSettingKey, strconv.FormatInt(userID, 10)); err != nil {
+		log.Errorf("Failed to store owner: %s", err)
+
+		return i18n.T(lang, "Failed to claim ownership. Please try again later"), false
+	}
+
+	bot.claimCode = ""
+
+	log.WithField("userID", userID).Info("Bot ownership claimed")
+
+	return i18n.T(lang, "You are now the owner of this bot"), true
+}
+
+func claimBackoff(failures int) time.Duration {
+	backoff := minClaimBackoff
+
+	for i := 1; i < failures && backoff < maxClaimBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxClaimBackoff)
+}
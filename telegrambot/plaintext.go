@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+	"unicode"
+
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// plainTextSymbols replaces symbols carrying meaning with their text equivalents before emoji are stripped.
+//
+//nolint:gochecknoglobals
+var plainTextSymbols = strings.NewReplacer("▲", "+", "▼", "-")
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (bot *ElectroBot) handlePlainTextCommand(chatID int64, arguments, lang string) string {
+	arguments = strings.ToLower(strings.TrimSpace(arguments))
+	if arguments != "on" && arguments != "off" {
+		return i18n.T(lang, "Usage: /plaintext on|off, plain text mode sends messages without emoji and buttons")
+	}
+
+	enabled := arguments == "on"
+
+	if !bot.db.UserExists(chatID) {
+		return i18n.T(lang, "Please register with /start first")
+	}
+
+	if err := bot.db.SetUserPlainText(chatID, enabled); err != nil {
+		log.Errorf("Failed to store plain text preference: %s", err)
+
+		return i18n.T(lang, "Failed to change plain text mode. Please try again later")
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "enabled": enabled}).Info("User plain text mode changed")
+
+	if enabled {
+		return i18n.T(lang, "Plain text mode is on, use commands from /help instead of buttons")
+	}
+
+	return i18n.T(lang, "Plain text mode is off")
+}
+
+// render strips emoji, formatting and keyboards from messages to chats which prefer plain text.
+func (bot *ElectroBot) render(chattable botApi.Chattable) botApi.Chattable {
+	switch message := chattable.(type) {
+	case botApi.MessageConfig:
+		if bot.plainTextChat(message.ChatID) {
+			message.Text, message.ParseMode, message.Entities = plainText(message.Text), "", nil
+			message.ReplyMarkup = nil
+		}
+
+		return message
+
+	case botApi.EditMessageTextConfig:
+		if bot.plainTextChat(message.ChatID) {
+			message.Text, message.ParseMode, message.Entities = plainText(message.Text), "", nil
+			message.ReplyMarkup = nil
+		}
+
+		return message
+
+	case botApi.PhotoConfig:
+		if bot.plainTextChat(message.ChatID) {
+			message.Caption, message.ParseMode, message.CaptionEntities = plainText(message.Caption), "", nil
+			message.ReplyMarkup = nil
+		}
+
+		return message
+
+	case botApi.DocumentConfig:
+		if bot.plainTextChat(message.ChatID) {
+			message.Caption, message.ParseMode, message.CaptionEntities = plainText(message.Caption), "", nil
+			message.ReplyMarkup = nil
+		}
+
+		return message
+
+	default:
+		return chattable
+	}
+}
+
+// plainTextChat returns true if the chat prefers plain text, unknown chats get regular messages.
+func (bot *ElectroBot) plainTextChat(chatID int64) bool {
+	enabled, err := bot.db.GetUserPlainText(chatID)
+
+	return err == nil && enabled
+}
+
+// plainText removes emoji and pictographs and the spacing left after them, line breaks are kept.
+func plainText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		// symbols below U+2000 like "©" or "°" are regular text
+		if (unicode.Is(unicode.So, r) && r >= 0x2000) || unicode.Is(unicode.Variation_Selector, r) ||
+			r == '\u200d' || r == '\u20e3' || (r >= 0x1f3fb && r <= 0x1f3ff) {
+			return -1
+		}
+
+		return r
+	}, plainTextSymbols.Replace(text))
+
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"electrobot/chaos"
+	"electrobot/database"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	queueRetryInterval = 30 * time.Second
+	queueBatchSize     = 100
+	// maxQueuedMessageAge drops queued messages which are too old to be useful, e.g. outage notifications after a
+	// long Telegram outage.
+	maxQueuedMessageAge = 24 * time.Hour
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// deliver sends the message with optional inline keyboard, the extras choose its sound and queue priority. The message
+// is queued if messages to the chat with the same or higher priority are still queued, Telegram is known to be
+// unreachable or sending fails with a transient error.
+func (bot *ElectroBot) deliver(chatID int64, text string, keyboard *botApi.InlineKeyboardMarkup,
+	extras notificationExtras,
+) (queued bool, err error) {
+	silent, priority := bot.silentNotification(chatID, extras), messagePriority(extras)
+
+	pending, err := bot.db.HasPendingMessages(chatID, priority)
+	if err != nil {
+		log.Errorf("Failed to check pending messages: %s", err)
+	}
+
+	if !pending && bot.CheckTelegramReachable() == nil {
+		if _, err = bot.send(newMessage(chatID, text, keyboard, silent)); err == nil || !isTransientError(err) {
+			return false, err
+		}
+
+		log.WithField("chatID", chatID).Warnf("Failed to send message, queueing it: %s", err)
+	}
+
+	replyMarkup := ""
+
+	if keyboard != nil {
+		data, err := json.Marshal(keyboard)
+		if err != nil {
+			return false, err
+		}
+
+		replyMarkup = string(data)
+	}
+
+	if err = bot.db.AddPendingMessage(database.PendingMessage{
+		ChatID: chatID, Text: text, ReplyMarkup: replyMarkup, Silent: silent, Priority: priority,
+	}); err != nil {
+		return false, err
+	}
+
+	bot.wakeQueue()
+
+	return true, nil
+}
+
+// messagePriority returns the queue priority of the notification with the extras.
+func messagePriority(extras notificationExtras) database.MessagePriority {
+	switch {
+	case extras&critical != 0:
+		return database.PriorityCritical
+	case extras&minorReport != 0:
+		return database.PriorityDigest
+	default:
+		return database.PriorityNormal
+	}
+}
+
+func newMessage(chatID int64, text string, keyboard *botApi.InlineKeyboardMarkup, silent bool) botApi.MessageConfig {
+	message := botApi.NewMessage(chatID, text)
+	message.DisableNotification = silent
+
+	if keyboard != nil {
+		message.ReplyMarkup = *keyboard
+	}
+
+	return message
+}
+
+func (bot *ElectroBot) wakeQueue() {
+	select {
+	case bot.queueWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// runQueue delivers queued messages when woken up and periodically while messages are pending.
+func (bot *ElectroBot) runQueue(ctx context.Context) {
+	ticker := time.NewTicker(queueRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		bot.flushQueue(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-bot.queueWakeup:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// flushQueue sends queued messages in queue order and stops on the first transient error to preserve the order.
+// Expired messages are dropped without sending.
+func (bot *ElectroBot) flushQueue(ctx context.Context) {
+	messages, err := bot.db.GetPendingMessages(queueBatchSize)
+	if err != nil {
+		log.Errorf("Failed to get pending messages: %s", err)
+
+		return
+	}
+
+	if len(messages) == 0 {
+		return
+	}
+
+	log.WithField("count", len(messages)).Debug("Delivering queued messages")
+
+	// messages to unregistered chats are already removed from the database
+	unregistered := make(map[int64]bool)
+
+	for _, message := range messages {
+		if unregistered[message.ChatID] {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(message.CreatedAt) > maxQueuedMessageAge {
+			log.WithFields(log.Fields{
+				"chatID": message.ChatID, "createdAt": message.CreatedAt,
+			}).Warn("Dropping expired queued message")
+		} else if err = bot.sendQueued(message); err != nil {
+			// queued messages are moved to the supergroup and sent on the next flush
+			if newChatID := migratedChatID(err); newChatID != 0 {
+				bot.migrateChat(message.ChatID, newChatID)
+				bot.wakeQueue()
+
+				return
+			}
+
+			if isTransientError(err) {
+				log.Warnf("Failed to deliver queued message, will retry: %s", err)
+
+				return
+			}
+
+			log.WithField("chatID", message.ChatID).Errorf("Dropping undeliverable queued message: %s", err)
+
+			if isChatUnreachable(err) {
+				bot.unregisterUnreachableUser(message.ChatID, err)
+
+				unregistered[message.ChatID] = true
+			}
+		}
+
+		// a crash here delivers the message again after restart
+		chaos.CrashPoint("queue")
+
+		if err = bot.db.RemovePendingMessage(message.ID); err != nil {
+			log.Errorf("Failed to remove queued message: %s", err)
+
+			return
+		}
+	}
+
+	if len(messages) == queueBatchSize {
+		bot.wakeQueue()
+	}
+}
+
+func (bot *ElectroBot) sendQueued(message database.PendingMessage) error {
+	var keyboard *botApi.InlineKeyboardMarkup
+
+	if message.ReplyMarkup != "" {
+		keyboard = &botApi.InlineKeyboardMarkup{}
+
+		if err := json.Unmarshal([]byte(message.ReplyMarkup), keyboard); err != nil {
+			log.Errorf("Failed to parse queued message keyboard, sending without it: %s", err)
+
+			keyboard = nil
+		}
+	}
+
+	_, err := bot.send(newMessage(message.ChatID, message.Text, keyboard, message.Silent))
+
+	return err
+}
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"electrobot/database"
+	"electrobot/telegrambot"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// expiringStorage makes queued power off notifications look expired once expired is set.
+type expiringStorage struct {
+	*database.Database
+	expired atomic.Bool
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestQueueRetry(t *testing.T) {
+	server, bot, db := newTestBot(t, telegrambot.Config{})
+
+	for _, chatID := range []int64{userID, adminID} {
+		server.SendMessage(chatID, "/start")
+		checkReply(t, server, chatID, "You've been successfully registered")
+	}
+
+	server.FailEvery("sendMessage", 1, http.StatusBadGateway, "Bad Gateway", 0)
+
+	start := time.Now()
+
+	bot.PowerOff(start)
+
+	for _, chatID := range []int64{userID, adminID} {
+		checkPending(t, db, chatID, true)
+	}
+
+	server.FailEvery("sendMessage", 0, 0, "", 0)
+
+	// queued behind the power off notification even though Telegram is reachable now
+	bot.PowerOn(start, start.Add(time.Hour))
+
+	for _, chatID := range []int64{userID, adminID} {
+		checkReply(t, server, chatID, "Power went off at")
+		checkReply(t, server, chatID, "Power is back at")
+		checkPending(t, db, chatID, false)
+	}
+}
+
+func TestQueueUnreachableUser(t *testing.T) {
+	server, bot, db := newTestBot(t, telegrambot.Config{})
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.FailEvery("sendMessage", 1, http.StatusBadGateway, "Bad Gateway", 0)
+
+	start := time.Now()
+
+	bot.PowerOff(start)
+	checkPending(t, db, userID, true)
+
+	server.FailEvery("sendMessage", 0, 0, "", 0)
+	server.FailNext("sendMessage", http.StatusForbidden, "Forbidden: bot was blocked by the user", 0)
+
+	bot.PowerOn(start, start.Add(time.Hour))
+	checkPending(t, db, userID, false)
+
+	if db.UserExists(userID) {
+		t.Error("Unreachable user is still registered")
+	}
+
+	if messages := server.Messages(); len(messages) != 1 {
+		t.Errorf("Wrong messages sent to unreachable user: %v", messages)
+	}
+}
+
+func TestQueueExpiry(t *testing.T) {
+	storage := &expiringStorage{Database: newTestDatabase(t)}
+	server, bot := startTestBot(t, telegrambot.Config{}, storage)
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.FailEvery("sendMessage", 1, http.StatusBadGateway, "Bad Gateway", 0)
+
+	start := time.Now()
+
+	bot.PowerOff(start)
+	checkPending(t, storage, userID, true)
+
+	storage.expired.Store(true)
+	server.FailEvery("sendMessage", 0, 0, "", 0)
+
+	bot.PowerOn(start, start.Add(time.Hour))
+
+	checkReply(t, server, userID, "Power is back at")
+	checkPending(t, storage, userID, false)
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (storage *expiringStorage) GetPendingMessages(limit int) ([]database.PendingMessage, error) {
+	messages, err := storage.Database.GetPendingMessages(limit)
+
+	for i := range messages {
+		if storage.expired.Load() && strings.HasPrefix(messages[i].Text, "Power went off at") {
+			messages[i].CreatedAt = messages[i].CreatedAt.Add(-48 * time.Hour)
+		}
+	}
+
+	return messages, err
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// checkPending waits until the chat has or has no queued messages.
+func checkPending(t *testing.T, storage telegrambot.Storage, chatID int64, pending bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(waitTimeout)
+
+	for {
+		exists, err := storage.HasPendingMessages(chatID, database.PriorityDigest)
+		if err != nil {
+			t.Fatalf("Can't check pending messages: %s", err)
+		}
+
+		if exists == pending {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Wrong pending messages state of chat %d: %v", chatID, exists)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
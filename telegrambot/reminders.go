@@ -0,0 +1,324 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	maxReminderLength = 200
+	maxUserReminders  = 20
+	// maxReminderLead is in minutes, planned outages are looked up a day ahead.
+	maxReminderLead = 24 * 60
+	reminderDialog  = "reminder"
+	// reminderRule is the step waiting for the lead time and the task of a recurring reminder.
+	reminderRule = "rule"
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// reminderSuffixes are optional trailing phrases stripped from reminder tasks.
+//
+//nolint:gochecknoglobals
+var reminderSuffixes = []string{"when power returns", "коли повернеться світло", "коли з'явиться світло"}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (bot *ElectroBot) handleRemindMeCommand(message *botApi.Message, lang string) string {
+	userID := message.Chat.ID
+	arguments := strings.TrimSpace(message.CommandArguments())
+	subcommand, rest, _ := strings.Cut(arguments, " ")
+
+	switch subcommand {
+	case "":
+		return i18n.T(lang, "Usage:\n/remindme <task> when power returns"+
+			"\n/remindme before <minutes> <task> - remind before each planned outage"+
+			"\n/remindme list - show your reminders"+
+			"\n/remindme cancel <id> - cancel a reminder")
+	case "before":
+		return bot.handleRemindBeforeCommand(message, strings.TrimSpace(rest), lang)
+	case "list":
+		return bot.listReminders(userID, lang)
+	case "cancel":
+		return bot.cancelReminder(userID, strings.TrimSpace(rest), lang)
+	default:
+		return bot.addReminder(userID, arguments, lang)
+	}
+}
+
+// handleRemindBeforeCommand adds a recurring reminder, without arguments it is asked for in a dialog in private
+// chats.
+func (bot *ElectroBot) handleRemindBeforeCommand(message *botApi.Message, rule, lang string) string {
+	if rule != "" || !message.Chat.IsPrivate() {
+		reply, _ := bot.addRecurringReminder(message.Chat.ID, rule, lang)
+
+		return reply
+	}
+
+	if err := bot.setDialogStep(message.Chat.ID, reminderDialog, reminderRule); err != nil {
+		log.WithField("chatID", message.Chat.ID).Errorf("Failed to start reminder dialog: %s", err)
+
+		return i18n.T(lang, "Failed to add reminder. Please try again later")
+	}
+
+	return i18n.T(lang, "Send how many minutes before planned outages to remind you and what about, like "+
+		"\"60 charge power banks\", or /cancel")
+}
+
+func (bot *ElectroBot) handleReminderRule(message *botApi.Message, lang string) (reply, next string) {
+	reply, ok := bot.addRecurringReminder(message.Chat.ID, message.Text, lang)
+	if !ok {
+		return reply, reminderRule
+	}
+
+	return reply, ""
+}
+
+// addRecurringReminder adds the reminder from a rule like "60 charge power banks", false is returned if it is not
+// added.
+func (bot *ElectroBot) addRecurringReminder(userID int64, rule, lang string) (reply string, ok bool) {
+	minutesStr, task, _ := strings.Cut(strings.TrimSpace(rule), " ")
+	task = strings.TrimSpace(task)
+
+	minutes, err := strconv.Atoi(minutesStr)
+	if err != nil || minutes <= 0 || minutes > maxReminderLead || task == "" {
+		return i18n.T(lang, "Usage: /remindme before <minutes> <task>, reminds about the task the given number of "+
+			"minutes before each planned outage, up to %d", maxReminderLead), false
+	}
+
+	if !bot.db.UserExists(userID) {
+		return i18n.T(lang, "Please register with /start first"), false
+	}
+
+	if reply = bot.refuseReminder(userID, task, lang); reply != "" {
+		return reply, false
+	}
+
+	id, err := bot.db.AddRecurringReminder(userID, task, minutes)
+	if err != nil {
+		log.Errorf("Failed to add recurring reminder: %s", err)
+
+		return i18n.T(lang, "Failed to add reminder. Please try again later"), false
+	}
+
+	log.WithFields(log.Fields{"chatID": userID, "minutes": minutes}).Info("Recurring reminder added")
+
+	lead := i18n.N(lang, minutes, "%d minute|%d minutes")
+
+	group := bot.chatScheduleGroup(userID)
+	if group == "" {
+		return i18n.T(lang, "Reminder #%d added, I'll remind you %s before planned outages. Set your outage "+
+			"schedule group with /group to get it", id, lead), true
+	}
+
+	return i18n.T(lang, "Reminder #%d added, I'll remind you %s before each planned outage of group %s", id, lead,
+		group), true
+}
+
+func (bot *ElectroBot) addReminder(userID int64, arguments, lang string) string {
+	task := strings.TrimSpace(arguments)
+
+	for _, suffix := range reminderSuffixes {
+		// lower case may differ in byte length, so the tail of the suffix length is compared instead
+		if tail := len(task) - len(suffix); tail >= 0 && strings.EqualFold(task[tail:], suffix) {
+			task = strings.TrimSpace(task[:tail])
+
+			break
+		}
+	}
+
+	if task == "" {
+		return i18n.T(lang, "Please specify what to remind you about")
+	}
+
+	if reply := bot.refuseReminder(userID, task, lang); reply != "" {
+		return reply
+	}
+
+	id, err := bot.db.AddReminder(userID, task)
+	if err != nil {
+		log.Errorf("Failed to add reminder: %s", err)
+
+		return i18n.T(lang, "Failed to add reminder. Please try again later")
+	}
+
+	return i18n.T(lang, "Reminder #%d added, I'll remind you when power returns", id)
+}
+
+// refuseReminder returns the reason the task can't be added as a reminder of the user, empty if it can.
+func (bot *ElectroBot) refuseReminder(userID int64, task, lang string) string {
+	if len([]rune(task)) > maxReminderLength {
+		return i18n.T(lang, "Reminder is too long, please keep it under %s",
+			i18n.N(lang, maxReminderLength, "%d character|%d characters"))
+	}
+
+	reminders, err := bot.db.GetReminders(userID)
+	if err != nil {
+		log.Errorf("Failed to get reminders: %s", err)
+
+		return i18n.T(lang, "Failed to add reminder. Please try again later")
+	}
+
+	if len(reminders) >= maxUserReminders {
+		return i18n.N(lang, maxUserReminders, "You can't have more than %d reminder|You can't have more than %d reminders")
+	}
+
+	return ""
+}
+
+func (bot *ElectroBot) listReminders(userID int64, lang string) string {
+	reminders, err := bot.db.GetReminders(userID)
+	if err != nil {
+		log.Errorf("Failed to get reminders: %s", err)
+
+		return i18n.T(lang, "Failed to get reminders. Please try again later")
+	}
+
+	if len(reminders) == 0 {
+		return i18n.T(lang, "You have no reminders")
+	}
+
+	text := i18n.T(lang, "Your reminders:")
+
+	for _, reminder := range reminders {
+		text += fmt.Sprintf("\n#%d %s", reminder.ID, reminder.Task)
+
+		if reminder.Minutes != 0 {
+			text += " (" + i18n.T(lang, "%s before planned outages",
+				i18n.N(lang, reminder.Minutes, "%d minute|%d minutes")) + ")"
+		}
+	}
+
+	return text
+}
+
+func (bot *ElectroBot) cancelReminder(userID int64, idStr, lang string) string {
+	id, err := strconv.ParseInt(strings.TrimPrefix(idStr, "#"), 10, 64)
+	if err != nil {
+		return i18n.T(lang, "Usage: /remindme cancel <id>")
+	}
+
+	if err = bot.db.RemoveReminder(userID, id); err != nil {
+		log.Errorf("Failed to remove reminder: %s", err)
+
+		return i18n.T(lang, "Reminder #%d not found", id)
+	}
+
+	return i18n.T(lang, "Reminder #%d cancelled", id)
+}
+
+// pendingRemindersText returns reminders to append to the power restored notification and their IDs.
+func (bot *ElectroBot) pendingRemindersText(userID int64, lang string) (text string, ids []int64) {
+	reminders, err := bot.db.GetReminders(userID)
+	if err != nil {
+		log.Errorf("Failed to get reminders: %s", err)
+
+		return "", nil
+	}
+
+	for _, reminder := range reminders {
+		// recurring reminders are sent before planned outages and kept
+		if reminder.Minutes != 0 {
+			continue
+		}
+
+		text += "\n- " + reminder.Task
+		ids = append(ids, reminder.ID)
+	}
+
+	if len(ids) == 0 {
+		return "", nil
+	}
+
+	return "\n\n" + i18n.T(lang, "Don't forget:") + text, ids
+}
+
+// clearDeliveredReminders removes reminders included in the notification, reminders added meanwhile are kept for
+// the next one.
+func (bot *ElectroBot) clearDeliveredReminders(userID int64, ids []int64) {
+	if err := bot.db.RemoveReminders(userID, ids); err != nil {
+		log.Errorf("Failed to clear delivered reminders: %s", err)
+	}
+}
+
+// sendRecurringReminders sends recurring reminders due after from up to to before the outages.
+func (bot *ElectroBot) sendRecurringReminders(outages []scheduledOutage, from, to time.Time) {
+	reminders, err := bot.recurringReminders(outages, from, to)
+	if err != nil {
+		log.Errorf("Failed to get recurring reminders: %s", err)
+
+		return
+	}
+
+	for _, reminder := range reminders {
+		outage, task := reminder.Outage, reminder.Task
+
+		log.WithFields(log.Fields{
+			"chatID": reminder.ChatID, "group": outage.Group, "start": outage.Start,
+		}).Info("Sending recurring reminder")
+
+		bot.notifyUsers([]int64{reminder.ChatID}, func(lang string, location *time.Location) string {
+			return i18n.T(lang, "⏰ Power of group %s is planned to go off at %s. Don't forget: %s", outage.Group,
+				outage.Start.In(location).Format(clockFormat), task)
+		}, 0, nil)
+	}
+}
+
+// recurringReminders returns reminders due after from up to to before the outages of the chat groups, chats without
+// their own group follow the bot group.
+func (bot *ElectroBot) recurringReminders(outages []scheduledOutage, from, to time.Time,
+) (due []outageWarning, err error) {
+	reminders, err := bot.db.GetRecurringReminders()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultGroup := bot.defaultScheduleGroup()
+
+	for _, reminder := range reminders {
+		group := reminder.Group
+		if group == "" {
+			group = defaultGroup
+		}
+
+		lead := time.Duration(reminder.Minutes) * time.Minute
+
+		for _, outage := range outages {
+			if start := outage.Start.Add(-lead); outage.Group == group && start.After(from) && !start.After(to) {
+				due = append(due, outageWarning{ChatID: reminder.UserID, Outage: outage, Task: reminder.Task})
+			}
+		}
+	}
+
+	return due, nil
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"electrobot/database"
+	"electrobot/telegrambot"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// racingStorage adds a reminder right after reminders are read for the notification once race is set.
+type racingStorage struct {
+	*database.Database
+	race atomic.Bool
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestReminderSuffix(t *testing.T) {
+	server, _, db := newTestBot(t, telegrambot.Config{})
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	testData := []struct {
+		command string
+		task    string
+	}{
+		{command: "/remindme Turn on the boiler WHEN POWER RETURNS", task: "Turn on the boiler"},
+		{command: "/remindme Увімкнути бойлер КОЛИ ПОВЕРНЕТЬСЯ СВІТЛО", task: "Увімкнути бойлер"},
+		{command: "/remindme Charge the power bank", task: "Charge the power bank"},
+	}
+
+	for _, item := range testData {
+		server.SendMessage(userID, item.command)
+		checkReply(t, server, userID, "Reminder #")
+
+		reminders, err := db.GetReminders(userID)
+		if err != nil {
+			t.Fatalf("Can't get reminders: %s", err)
+		}
+
+		if task := reminders[len(reminders)-1].Task; task != item.task {
+			t.Errorf("Wrong task of %q: %q", item.command, task)
+		}
+	}
+}
+
+func TestRecurringReminders(t *testing.T) {
+	server, bot, db := newTestBot(t, telegrambot.Config{ScheduleGroup: "1.1"})
+
+	server.SendMessage(userID, "/remindme before 60 charge power banks")
+	checkReply(t, server, userID, "Please register with /start first")
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.SendMessage(userID, "/remindme before 60 charge power banks")
+	checkReply(t, server, userID, "Reminder #1 added, I'll remind you 60 minutes before each planned outage of group 1.1")
+
+	server.SendMessage(userID, "/remindme before")
+	checkReply(t, server, userID, "Send how many minutes before planned outages to remind you")
+
+	server.SendMessage(userID, "soon fill water")
+	checkReply(t, server, userID, "Usage: /remindme before <minutes> <task>")
+
+	server.SendMessage(userID, "30 fill water")
+	checkReply(t, server, userID, "Reminder #2 added, I'll remind you 30 minutes before each planned outage")
+
+	server.SendMessage(userID, "/remindme turn on the boiler")
+	checkReply(t, server, userID, "Reminder #3 added, I'll remind you when power returns")
+
+	server.SendMessage(userID, "/remindme list")
+	checkReply(t, server, userID, "Your reminders:\n#1 charge power banks (60 minutes before planned outages)\n"+
+		"#2 fill water (30 minutes before planned outages)\n#3 turn on the boiler")
+
+	start := time.Now()
+
+	bot.PowerOn(start, start.Add(time.Hour))
+
+	if message := checkReply(t, server, userID, "Power is back at"); !strings.HasSuffix(message.Text,
+		"Don't forget:\n- turn on the boiler") {
+		t.Errorf("Wrong reminders in notification: %q", message.Text)
+	}
+
+	reminders, err := db.GetReminders(userID)
+	if err != nil {
+		t.Fatalf("Can't get reminders: %s", err)
+	}
+
+	if len(reminders) != 2 || reminders[0].Minutes != 60 || reminders[1].Minutes != 30 {
+		t.Errorf("Recurring reminders are not kept: %v", reminders)
+	}
+}
+
+func TestRemindersAddedDuringNotification(t *testing.T) {
+	storage := &racingStorage{Database: newTestDatabase(t)}
+	server, bot := startTestBot(t, telegrambot.Config{}, storage)
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.SendMessage(userID, "/remindme turn on the boiler")
+	checkReply(t, server, userID, "Reminder #")
+
+	storage.race.Store(true)
+
+	start := time.Now()
+
+	bot.PowerOn(start, start.Add(time.Hour))
+	checkReply(t, server, userID, "Power is back at")
+
+	reminders, err := storage.GetReminders(userID)
+	if err != nil {
+		t.Fatalf("Can't get reminders: %s", err)
+	}
+
+	if len(reminders) != 1 || reminders[0].Task != "added meanwhile" {
+		t.Errorf("Wrong reminders after notification: %v", reminders)
+	}
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (storage *racingStorage) GetReminders(userID int64) ([]database.Reminder, error) {
+	reminders, err := storage.Database.GetReminders(userID)
+
+	if storage.race.CompareAndSwap(true, false) {
+		if _, err := storage.Database.AddReminder(userID, "added meanwhile"); err != nil {
+			return nil, err
+		}
+	}
+
+	return reminders, err
+}
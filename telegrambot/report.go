@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/i18n"
+	"electrobot/report"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	yearlyReportSettingKey    = "yearly_report_year"
+	yearlyReportCheckInterval = time.Hour
+	reportChartWidth          = 10
+	minReportYear             = 2000
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (bot *ElectroBot) handleReportCommand(arguments, lang string, location *time.Location) string {
+	year := time.Now().In(location).Year()
+
+	if arguments = strings.TrimSpace(arguments); arguments != "" {
+		value, err := strconv.Atoi(arguments)
+		if err != nil || value < minReportYear || value > year {
+			return i18n.T(lang, "Usage: /report [year], where year is between %d and %d", minReportYear, year)
+		}
+
+		year = value
+	}
+
+	yearReport, err := report.Yearly(bot.db, year, location)
+	if err != nil {
+		log.Errorf("Failed to build yearly report: %s", err)
+
+		return i18n.T(lang, "Failed to build the report. Please try again later")
+	}
+
+	return reportText(yearReport, lang, location)
+}
+
+// runYearlyReport sends the previous year report to all users once in January.
+func (bot *ElectroBot) runYearlyReport(ctx context.Context) {
+	ticker := time.NewTicker(yearlyReportCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		bot.sendYearlyReport()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (bot *ElectroBot) sendYearlyReport() {
+	now := time.Now().In(bot.defaultLocation)
+	if now.Month() != time.January {
+		return
+	}
+
+	if bot.setting(yearlyReportEnabledSettingKey) == settingOff {
+		return
+	}
+
+	year := now.Year() - 1
+
+	value, err := bot.db.GetSetting(yearlyReportSettingKey)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Errorf("Failed to get yearly report setting: %s", err)
+
+		return
+	}
+
+	if sent, _ := strconv.Atoi(value); sent >= year {
+		return
+	}
+
+	defaultReport, err := report.Yearly(bot.db, year, bot.defaultLocation)
+	if err != nil {
+		log.Errorf("Failed to build yearly report: %s", err)
+
+		return
+	}
+
+	// mark the report as sent first, a failure in the middle must not spam users who already got it
+	if err = bot.db.SetSetting(yearlyReportSettingKey, strconv.Itoa(year)); err != nil {
+		log.Errorf("Failed to store yearly report setting: %s", err)
+
+		return
+	}
+
+	if defaultReport.Count() == 0 {
+		return
+	}
+
+	log.WithField("year", year).Info("Sending yearly report")
+
+	reports := map[string]report.Year{bot.defaultLocation.String(): defaultReport}
+
+	bot.notifyAllUsers(func(lang string, location *time.Location) string {
+		yearReport, ok := reports[location.String()]
+		if !ok {
+			if yearReport, err = report.Yearly(bot.db, year, location); err != nil {
+				log.Errorf("Failed to build yearly report: %s", err)
+
+				yearReport = defaultReport
+			}
+
+			reports[location.String()] = yearReport
+		}
+
+		return reportText(yearReport, lang, location)
+	}, minorReport, nil)
+}
+
+func reportText(yearReport report.Year, lang string, location *time.Location) string {
+	if yearReport.Count() == 0 && yearReport.Total() == 0 {
+		return i18n.T(lang, "No outages recorded in %d", yearReport.Year)
+	}
+
+	// the previous year is compared only if there is data for it
+	compare := yearReport.PreviousTotal() > 0
+
+	text := i18n.T(lang, "📅 Outage report for %d", yearReport.Year) + "\n" +
+		i18n.T(lang, "Without power: %s, outages: %d", formatDuration(yearReport.Total(), lang), yearReport.Count())
+
+	if compare {
+		text += " " + i18n.T(lang, "(%s vs %d)",
+			formatChange(yearReport.Total()-yearReport.PreviousTotal(), lang), yearReport.Year-1)
+	}
+
+	var longestMonth time.Duration
+
+	for _, month := range yearReport.Months {
+		longestMonth = max(longestMonth, month.Total)
+	}
+
+	text += "\n"
+
+	for i, month := range yearReport.Months {
+		width := 0
+		if longestMonth > 0 {
+			width = int((month.Total*reportChartWidth + longestMonth/2) / longestMonth)
+		}
+
+		text += fmt.Sprintf("\n%s %s%s %s", monthName(time.Month(i+1), lang), strings.Repeat("█", width),
+			strings.Repeat("░", reportChartWidth-width), formatDuration(month.Total, lang))
+
+		if compare {
+			text += " " + formatChange(month.Total-yearReport.Previous[i].Total, lang)
+		}
+	}
+
+	return text + "\n\n" + reportRecordsText(yearReport, lang, location)
+}
+
+// reportRecordsText returns the longest outage and the worst and the best months among months already started.
+func reportRecordsText(yearReport report.Year, lang string, location *time.Location) string {
+	text := i18n.T(lang, "🏆 Records:")
+
+	if yearReport.Longest.Duration() > 0 {
+		text += "\n" + i18n.T(lang, "Longest outage: %s, started %s", formatDuration(yearReport.Longest.Duration(), lang),
+			i18n.DateTime(lang, yearReport.Longest.Start.In(location)))
+	}
+
+	now := time.Now().In(location)
+	worst, best := -1, -1
+
+	for i, month := range yearReport.Months {
+		if time.Date(yearReport.Year, time.Month(i+1), 1, 0, 0, 0, 0, location).After(now) {
+			break
+		}
+
+		if worst == -1 || month.Total > yearReport.Months[worst].Total {
+			worst = i
+		}
+
+		if best == -1 || month.Total < yearReport.Months[best].Total {
+			best = i
+		}
+	}
+
+	if worst != -1 && worst != best {
+		text += "\n" + i18n.T(lang, "Worst month: %s (%s)", monthName(time.Month(worst+1), lang),
+			formatDuration(yearReport.Months[worst].Total, lang)) +
+			"\n" + i18n.T(lang, "Best month: %s (%s)", monthName(time.Month(best+1), lang),
+			formatDuration(yearReport.Months[best].Total, lang))
+	}
+
+	return text
+}
+
+// formatChange formats duration difference with an arrow showing its direction.
+func formatChange(change time.Duration, lang string) string {
+	switch {
+	case change > 0:
+		return "▲" + formatDuration(change, lang)
+	case change < 0:
+		return "▼" + formatDuration(-change, lang)
+	default:
+		return "="
+	}
+}
+
+func monthName(month time.Month, lang string) string {
+	return i18n.T(lang, month.String()[:3])
+}
@@ -0,0 +1,271 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// configSettingPrefix prefixes setting keys of runtime config overrides.
+const configSettingPrefix = "config."
+
+const (
+	maxRestoreAdvisoryDelay = 24 * time.Hour
+	maxWideOutageWindow     = time.Hour
+	minChannelFlapWindow    = time.Minute
+	maxChannelFlapWindow    = 24 * time.Hour
+	// minOutageUpdateInterval keeps "still no power" updates from spamming, 0 still turns them off.
+	minOutageUpdateInterval = 15 * time.Minute
+	maxOutageUpdateInterval = 24 * time.Hour
+	maxNotificationBatch    = 10 * time.Minute
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+var errInvalidTunable = errors.New("invalid config value")
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Tunable is a config value admins read and change at runtime with /config, the name is its config path.
+type Tunable struct {
+	// Min and Max bound the value.
+	Min time.Duration
+	Max time.Duration
+	// Get returns the current value.
+	Get func() time.Duration
+	// Set applies the value, it may refuse values depending on other settings.
+	Set func(value time.Duration) error
+}
+
+// tunable is a registered Tunable with the value it had before overrides.
+type tunable struct {
+	Tunable
+	configured time.Duration
+}
+
+// atomicDuration is a duration changed at runtime while other goroutines read it.
+type atomicDuration struct {
+	value atomic.Int64
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// RegisterTunable makes the value available to /config and applies its stored override.
+func (bot *ElectroBot) RegisterTunable(name string, value Tunable) {
+	bot.tunableMutex.Lock()
+	defer bot.tunableMutex.Unlock()
+
+	bot.tunables[name] = tunable{Tunable: value, configured: value.Get()}
+
+	stored, err := bot.db.GetSetting(configSettingPrefix + name)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.WithField("name", name).Errorf("Failed to get config override: %s", err)
+		}
+
+		return
+	}
+
+	if err = setTunable(value, stored); err != nil {
+		log.WithFields(log.Fields{"name": name, "value": stored}).Errorf("Failed to apply config override: %s", err)
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (duration *atomicDuration) Load() time.Duration {
+	return time.Duration(duration.value.Load())
+}
+
+func (duration *atomicDuration) Store(value time.Duration) {
+	duration.value.Store(int64(value))
+}
+
+// registerTunables registers values of the bot itself.
+func (bot *ElectroBot) registerTunables() {
+	bot.RegisterTunable("restoreAdvisoryDelay", durationTunable(&bot.restoreAdvisoryDelay, 0, maxRestoreAdvisoryDelay))
+	bot.RegisterTunable("wideOutageWindow", durationTunable(&bot.wideOutageWindow, 0, maxWideOutageWindow))
+	bot.RegisterTunable("telegram.channelFlapWindow",
+		durationTunable(&bot.channelFlapWindow, minChannelFlapWindow, maxChannelFlapWindow))
+
+	outageUpdateInterval := durationTunable(&bot.outageUpdateInterval, 0, maxOutageUpdateInterval)
+	outageUpdateInterval.Set = func(value time.Duration) error {
+		if value > 0 && value < minOutageUpdateInterval {
+			return errInvalidTunable
+		}
+
+		bot.outageUpdateInterval.Store(value)
+
+		return nil
+	}
+
+	bot.RegisterTunable("outageUpdateInterval", outageUpdateInterval)
+	bot.RegisterTunable("notificationBatchWindow",
+		durationTunable(&bot.notificationBatchWindow, 0, maxNotificationBatch))
+}
+
+// handleConfigCommand shows and changes config values at runtime, overrides are stored and survive restarts.
+func (bot *ElectroBot) handleConfigCommand(chatID int64, arguments, lang string) string {
+	fields := strings.Fields(arguments)
+	if len(fields) == 0 {
+		fields = []string{"get"}
+	}
+
+	switch {
+	case fields[0] == "get" && len(fields) <= 2:
+		return bot.getConfig(fields[1:], lang)
+
+	case fields[0] == "set" && len(fields) == 3:
+		return bot.setConfig(chatID, fields[1], fields[2], lang)
+
+	case fields[0] == "reset" && len(fields) == 2:
+		return bot.resetConfig(chatID, fields[1], lang)
+
+	default:
+		return i18n.T(lang, "Usage:\n/config get [name] - show config values changeable at runtime"+
+			"\n/config set <name> <value> - change the value, e.g. /config set wideOutageWindow 2m"+
+			"\n/config reset <name> - return to the value of the config file")
+	}
+}
+
+func (bot *ElectroBot) getConfig(names []string, lang string) string {
+	bot.tunableMutex.Lock()
+	defer bot.tunableMutex.Unlock()
+
+	if len(names) == 0 {
+		for name := range bot.tunables {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+	}
+
+	text := i18n.T(lang, "Config values:")
+
+	for _, name := range names {
+		value, ok := bot.tunables[name]
+		if !ok {
+			return i18n.T(lang, "Unknown config value %q, see /config get", name)
+		}
+
+		current := value.Get()
+		text += fmt.Sprintf("\n%s = %s", name, current)
+
+		if current != value.configured {
+			text += i18n.T(lang, " (config file: %s)", value.configured)
+		}
+
+		text += i18n.T(lang, ", from %s to %s", value.Min, value.Max)
+	}
+
+	return text
+}
+
+func (bot *ElectroBot) setConfig(chatID int64, name, stored, lang string) string {
+	bot.tunableMutex.Lock()
+	defer bot.tunableMutex.Unlock()
+
+	value, ok := bot.tunables[name]
+	if !ok {
+		return i18n.T(lang, "Unknown config value %q, see /config get", name)
+	}
+
+	if err := setTunable(value.Tunable, stored); err != nil {
+		if errors.Is(err, errInvalidTunable) {
+			return i18n.T(lang, "%s must be a duration from %s to %s, e.g. 90s or 5m", name, value.Min, value.Max)
+		}
+
+		return i18n.T(lang, "Can't set %s: %s", name, err)
+	}
+
+	if err := bot.db.SetSetting(configSettingPrefix+name, value.Get().String()); err != nil {
+		log.Errorf("Failed to store config override: %s", err)
+
+		return i18n.T(lang, "%s is %s until restart, failed to store it", name, value.Get())
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "name": name, "value": value.Get()}).Info("Config value changed")
+
+	return i18n.T(lang, "%s is %s now", name, value.Get())
+}
+
+func (bot *ElectroBot) resetConfig(chatID int64, name, lang string) string {
+	bot.tunableMutex.Lock()
+	defer bot.tunableMutex.Unlock()
+
+	value, ok := bot.tunables[name]
+	if !ok {
+		return i18n.T(lang, "Unknown config value %q, see /config get", name)
+	}
+
+	if err := bot.db.DeleteSetting(configSettingPrefix + name); err != nil {
+		log.Errorf("Failed to remove config override: %s", err)
+
+		return i18n.T(lang, "Failed to reset %s. Please try again later", name)
+	}
+
+	if err := value.Set(value.configured); err != nil {
+		log.WithField("name", name).Errorf("Failed to restore configured value: %s", err)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "name": name, "value": value.Get()}).Info("Config value reset")
+
+	return i18n.T(lang, "%s is %s now", name, value.Get())
+}
+
+// durationTunable returns Tunable of a bot duration.
+func durationTunable(duration *atomicDuration, minValue, maxValue time.Duration) Tunable {
+	return Tunable{
+		Min: minValue, Max: maxValue, Get: duration.Load,
+		Set: func(value time.Duration) error {
+			duration.Store(value)
+
+			return nil
+		},
+	}
+}
+
+// setTunable parses the value and applies it if it is within the bounds.
+func setTunable(value Tunable, stored string) error {
+	duration, err := time.ParseDuration(stored)
+	if err != nil || duration < value.Min || duration > value.Max {
+		return errInvalidTunable
+	}
+
+	return value.Set(duration)
+}
@@ -0,0 +1,341 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+	"electrobot/schedule"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultScheduleChangesLimit = 10
+	maxScheduleGroupLength      = 16
+	maxScheduleNoteLength       = 100
+	scheduleAllGroups           = "*"
+	scheduleNormalWindows       = "normal"
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// isScheduleEditCommand returns true for /schedule subcommands which require admin permissions.
+func isScheduleEditCommand(arguments string) bool {
+	subcommand, _, _ := strings.Cut(strings.TrimSpace(arguments), " ")
+
+	return subcommand == "set" || subcommand == "except" || subcommand == "history"
+}
+
+func (bot *ElectroBot) handleScheduleCommand(lang string, location *time.Location) string {
+	days, err := bot.db.GetSchedule()
+	if err != nil {
+		log.Errorf("Failed to get schedule: %s", err)
+
+		return i18n.T(lang, "Failed to get outage schedule. Please try again later")
+	}
+
+	today := time.Now().In(location).Format(database.DateFormat)
+
+	exceptions, err := bot.db.GetScheduleExceptions(today)
+	if err != nil {
+		log.Errorf("Failed to get schedule exceptions: %s", err)
+
+		return i18n.T(lang, "Failed to get outage schedule. Please try again later")
+	}
+
+	planned, err := bot.db.GetPlannedSchedules(today)
+	if err != nil {
+		log.Errorf("Failed to get planned schedules: %s", err)
+
+		return i18n.T(lang, "Failed to get outage schedule. Please try again later")
+	}
+
+	if len(days) == 0 && len(exceptions) == 0 && len(planned) == 0 {
+		return i18n.T(lang, "Outage schedule is not set")
+	}
+
+	text := i18n.T(lang, "Outage schedule:")
+	group := ""
+
+	for _, day := range days {
+		if day.Group != group {
+			group = day.Group
+			text += "\n\n" + i18n.T(lang, "Group %s:", group)
+		}
+
+		text += fmt.Sprintf("\n%s: %s", weekdayName(day.Weekday, lang), windowsText(day.Windows, lang))
+	}
+
+	if len(exceptions) != 0 {
+		text += "\n\n" + i18n.T(lang, "Exceptions:")
+	}
+
+	for _, exception := range exceptions {
+		group := exception.Group
+		if group == scheduleAllGroups {
+			group = i18n.T(lang, "all groups")
+		}
+
+		text += fmt.Sprintf("\n%s %s: %s", exception.Date, group, windowsText(exception.Windows, lang))
+
+		if exception.Note != "" {
+			text += " (" + exception.Note + ")"
+		}
+	}
+
+	if len(planned) != 0 {
+		text += "\n\n" + i18n.T(lang, "Planned by the grid operator:")
+	}
+
+	for _, day := range planned {
+		text += fmt.Sprintf("\n%s %s: %s", day.Date, day.Group, windowsText(day.Windows, lang))
+	}
+
+	return text
+}
+
+// handleGroupCommand shows or sets the outage schedule group of the chat, "off" returns the chat to the bot group.
+func (bot *ElectroBot) handleGroupCommand(chatID int64, arguments, lang string) string {
+	group := strings.TrimSpace(arguments)
+	if group == "" {
+		if group = bot.chatScheduleGroup(chatID); group == "" {
+			return i18n.T(lang, "Your outage schedule group is not set\nUsage: /group <group>|off")
+		}
+
+		return i18n.T(lang, "Your outage schedule group is %s\nUsage: /group <group>|off", group)
+	}
+
+	if len(group) > maxScheduleGroupLength || strings.ContainsAny(group, " \n") || group == scheduleAllGroups {
+		return i18n.T(lang, "Usage: /group <group>|off, group names are up to %s without spaces",
+			i18n.N(lang, maxScheduleGroupLength, "%d character|%d characters"))
+	}
+
+	if strings.EqualFold(group, settingOff) {
+		group = ""
+	}
+
+	if !bot.db.UserExists(chatID) {
+		return i18n.T(lang, "Please register with /start first")
+	}
+
+	if err := bot.db.SetUserScheduleGroup(chatID, group); err != nil {
+		log.Errorf("Failed to store schedule group: %s", err)
+
+		return i18n.T(lang, "Failed to change your outage schedule group. Please try again later")
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "group": group}).Info("User schedule group changed")
+
+	if group == "" {
+		if group = bot.defaultScheduleGroup(); group == "" {
+			return i18n.T(lang, "Your outage schedule group is removed")
+		}
+
+		return i18n.T(lang, "You follow the bot outage schedule group %s", group)
+	}
+
+	return i18n.T(lang, "Your outage schedule group is %s", group)
+}
+
+// chatScheduleGroup returns the outage schedule group of the chat, the bot group if the chat has no own one.
+func (bot *ElectroBot) chatScheduleGroup(chatID int64) string {
+	group, err := bot.db.GetUserScheduleGroup(chatID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.WithField("chatID", chatID).Errorf("Failed to get schedule group: %s", err)
+	}
+
+	if group == "" {
+		return bot.defaultScheduleGroup()
+	}
+
+	return group
+}
+
+// defaultScheduleGroup returns the group of the planned schedule import set in the config or chosen in the setup
+// wizard, empty if there is none.
+func (bot *ElectroBot) defaultScheduleGroup() string {
+	if bot.scheduleGroup != "" {
+		return bot.scheduleGroup
+	}
+
+	return bot.setting(scheduleGroupSettingKey)
+}
+
+// handleScheduleEditCommand handles admin /schedule subcommands.
+func (bot *ElectroBot) handleScheduleEditCommand(chatID int64, arguments, lang string) string {
+	fields := strings.Fields(arguments)
+
+	switch {
+	case len(fields) == 4 && fields[0] == "set": //nolint:gomnd
+		return bot.setScheduleDay(chatID, fields[1], fields[2], fields[3], lang)
+
+	case len(fields) >= 4 && fields[0] == "except": //nolint:gomnd
+		return bot.setScheduleException(chatID, fields[1], fields[2], fields[3], strings.Join(fields[4:], " "), lang)
+
+	case len(fields) <= 2 && len(fields) > 0 && fields[0] == "history":
+		limit := defaultScheduleChangesLimit
+
+		if len(fields) == 2 { //nolint:gomnd
+			value, err := strconv.Atoi(fields[1])
+			if err != nil || value <= 0 {
+				return i18n.T(lang, "Usage: /schedule history [N], where N is a positive number of changes")
+			}
+
+			limit = min(value, maxHistoryLimit)
+		}
+
+		return bot.listScheduleChanges(limit, lang)
+
+	default:
+		return i18n.T(lang, "Usage:\n/schedule - show outage schedule"+
+			"\n/schedule set <group> <weekday> <HH:MM-HH:MM,...|off> - set outage windows"+
+			"\n/schedule except <YYYY-MM-DD> <group|*> <HH:MM-HH:MM,...|off|normal> [note] - override a date"+
+			"\n/schedule history [N] - show last schedule changes")
+	}
+}
+
+func (bot *ElectroBot) setScheduleDay(chatID int64, group, weekdayStr, windowsStr, lang string) string {
+	if len(group) > maxScheduleGroupLength {
+		return i18n.T(lang, "Group name is too long, please keep it under %s",
+			i18n.N(lang, maxScheduleGroupLength, "%d character|%d characters"))
+	}
+
+	weekday, err := schedule.ParseWeekday(weekdayStr)
+	if err != nil {
+		return i18n.T(lang, "Unknown weekday %q, use mon-sun or 1-7", weekdayStr)
+	}
+
+	windows, err := schedule.ParseWindows(windowsStr)
+	if err != nil {
+		return i18n.T(lang, "Invalid outage windows: %s", err)
+	}
+
+	version, err := bot.db.SetScheduleDay(group, weekday, schedule.FormatWindows(windows), chatID)
+	if err != nil {
+		log.Errorf("Failed to store schedule: %s", err)
+
+		return i18n.T(lang, "Failed to change outage schedule. Please try again later")
+	}
+
+	log.WithFields(log.Fields{
+		"chatID": chatID, "group": group, "weekday": weekday, "windows": windows, "version": version,
+	}).Info("Outage schedule changed")
+
+	if len(windows) == 0 {
+		return i18n.T(lang, "Schedule version %d: group %s has no outages on %s", version, group,
+			weekdayName(weekday, lang))
+	}
+
+	return i18n.T(lang, "Schedule version %d: group %s on %s: %s", version, group, weekdayName(weekday, lang),
+		schedule.FormatWindows(windows))
+}
+
+// setScheduleException overrides the weekly schedule on the date, "normal" windows remove the override.
+func (bot *ElectroBot) setScheduleException(chatID int64, dateStr, group, windowsStr, note, lang string) string {
+	date, err := time.Parse(database.DateFormat, dateStr)
+	if err != nil {
+		return i18n.T(lang, "Invalid date %q, use YYYY-MM-DD", dateStr)
+	}
+
+	if len(group) > maxScheduleGroupLength {
+		return i18n.T(lang, "Group name is too long, please keep it under %s",
+			i18n.N(lang, maxScheduleGroupLength, "%d character|%d characters"))
+	}
+
+	if len([]rune(note)) > maxScheduleNoteLength {
+		return i18n.T(lang, "Note is too long, please keep it under %s",
+			i18n.N(lang, maxScheduleNoteLength, "%d character|%d characters"))
+	}
+
+	var windows *string
+
+	if !strings.EqualFold(windowsStr, scheduleNormalWindows) {
+		parsed, err := schedule.ParseWindows(windowsStr)
+		if err != nil {
+			return i18n.T(lang, "Invalid outage windows: %s", err)
+		}
+
+		formatted := schedule.FormatWindows(parsed)
+		windows = &formatted
+	}
+
+	dateStr = date.Format(database.DateFormat)
+
+	if err = bot.db.SetScheduleException(group, dateStr, windows, note, chatID); err != nil {
+		log.Errorf("Failed to store schedule exception: %s", err)
+
+		return i18n.T(lang, "Failed to change outage schedule. Please try again later")
+	}
+
+	log.WithFields(log.Fields{
+		"chatID": chatID, "group": group, "date": dateStr, "windows": windows, "note": note,
+	}).Info("Outage schedule exception changed")
+
+	if windows == nil {
+		return i18n.T(lang, "Weekly schedule restored for group %s on %s", group, dateStr)
+	}
+
+	return i18n.T(lang, "Schedule exception for group %s on %s: %s", group, dateStr, windowsText(*windows, lang))
+}
+
+func (bot *ElectroBot) listScheduleChanges(limit int, lang string) string {
+	changes, err := bot.db.GetScheduleChanges(limit)
+	if err != nil {
+		log.Errorf("Failed to get schedule changes: %s", err)
+
+		return i18n.T(lang, "Failed to get schedule changes. Please try again later")
+	}
+
+	if len(changes) == 0 {
+		return i18n.T(lang, "Outage schedule has never been changed")
+	}
+
+	text := i18n.T(lang, "Schedule changes:")
+
+	for _, change := range changes {
+		text += fmt.Sprintf("\n#%d %s %d: %s %s %s", change.Version,
+			i18n.DateTime(lang, change.CreatedAt.In(bot.defaultLocation)), change.ChangedBy, change.Group,
+			weekdayName(change.Weekday, lang), windowsText(change.Windows, lang))
+	}
+
+	return text
+}
+
+func windowsText(windows, lang string) string {
+	if windows == "" {
+		return i18n.T(lang, "no outages")
+	}
+
+	return strings.ReplaceAll(windows, ",", ", ")
+}
+
+func weekdayName(weekday time.Weekday, lang string) string {
+	return i18n.T(lang, weekday.String()[:3])
+}
@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"electrobot/database"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// testScheduleStorage keeps the schedule in memory, other storage methods are not expected to be called.
+type testScheduleStorage struct {
+	Storage
+	days       []database.ScheduleDay
+	exceptions []database.ScheduleException
+	planned    []database.PlannedSchedule
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestScheduledWindows(t *testing.T) {
+	storage := &testScheduleStorage{
+		days: []database.ScheduleDay{
+			{Group: "1", Weekday: time.Monday, Windows: "08:00-12:00"},
+			{Group: "2", Weekday: time.Monday, Windows: "12:00-16:00"},
+			{Group: "1", Weekday: time.Tuesday, Windows: "00:00-04:00"},
+		},
+		exceptions: []database.ScheduleException{
+			{Group: "*", Date: "2024-03-04", Windows: ""},
+			{Group: "*", Date: "2024-03-11", Windows: ""},
+			{Group: "2", Date: "2024-03-11", Windows: "18:00-20:00"},
+			{Group: "1", Date: "2024-03-18", Windows: "10:00-11:00"},
+			{Group: "2", Date: "2024-03-18", Windows: "10:00-11:00"},
+			{Group: "3", Date: "2024-03-25", Windows: "20:00-24:00"},
+		},
+		planned: []database.PlannedSchedule{
+			{Group: "1", Date: "2024-03-18", Windows: "14:00-16:00"},
+			{Group: "2", Date: "2024-03-19", Windows: ""},
+		},
+	}
+
+	testData := []struct {
+		name   string
+		date   string
+		groups map[string]string
+	}{
+		{name: "weekly", date: "2024-02-26", groups: map[string]string{"1": "08:00-12:00", "2": "12:00-16:00"}},
+		{name: "other weekday", date: "2024-02-27", groups: map[string]string{"1": "00:00-04:00"}},
+		{name: "no schedule", date: "2024-02-28", groups: map[string]string{}},
+		{name: "all groups off", date: "2024-03-04", groups: map[string]string{"1": "", "2": ""}},
+		{name: "group over all groups", date: "2024-03-11", groups: map[string]string{"1": "", "2": "18:00-20:00"}},
+		{name: "planned over exception", date: "2024-03-18", groups: map[string]string{
+			"1": "14:00-16:00", "2": "10:00-11:00",
+		}},
+		{name: "planned over weekly", date: "2024-03-19", groups: map[string]string{"1": "00:00-04:00", "2": ""}},
+		{name: "new group", date: "2024-03-25", groups: map[string]string{
+			"1": "08:00-12:00", "2": "12:00-16:00", "3": "20:00-24:00",
+		}},
+	}
+
+	bot := &ElectroBot{db: storage}
+
+	for _, item := range testData {
+		day, err := time.Parse(database.DateFormat, item.date)
+		if err != nil {
+			t.Fatalf("Can't parse date: %s", err)
+		}
+
+		groups, err := bot.scheduledWindows(day)
+		if err != nil {
+			t.Fatalf("Can't get scheduled windows: %s", err)
+		}
+
+		if !reflect.DeepEqual(groups, item.groups) {
+			t.Errorf("Wrong %s windows: %v", item.name, groups)
+		}
+	}
+}
+
+func TestSetScheduleException(t *testing.T) {
+	storage := &testScheduleStorage{}
+
+	testData := []struct {
+		arguments  string
+		exceptions string
+	}{
+		{
+			arguments:  "except 2024-03-04 1 20:00-24:00,10:00-11:00",
+			exceptions: "2024-03-04 1 10:00-11:00,20:00-24:00",
+		},
+		{arguments: "except 2024-03-04 1 off Independence day", exceptions: "2024-03-04 1 off (Independence day)"},
+		{
+			arguments:  "except 2024-03-04 * off",
+			exceptions: "2024-03-04 * off; 2024-03-04 1 off (Independence day)",
+		},
+		{arguments: "except 2024-03-04 1 normal", exceptions: "2024-03-04 * off"},
+		{arguments: "except 2024-3-4 1 off", exceptions: "2024-03-04 * off"},
+		{arguments: "except 2024-02-30 1 off", exceptions: "2024-03-04 * off"},
+		{arguments: "except 2024-03-04 1 22:00-02:00", exceptions: "2024-03-04 * off"},
+		{arguments: "except 2024-03-04 1 08:00-12:00,11:00-13:00", exceptions: "2024-03-04 * off"},
+		{
+			arguments:  "except 2024-03-04 " + strings.Repeat("1", maxScheduleGroupLength+1) + " off",
+			exceptions: "2024-03-04 * off",
+		},
+		{
+			arguments:  "except 2024-03-04 1 off " + strings.Repeat("a", maxScheduleNoteLength+1),
+			exceptions: "2024-03-04 * off",
+		},
+		{arguments: "except 2024-03-04 * NORMAL", exceptions: ""},
+	}
+
+	bot := &ElectroBot{db: storage}
+
+	for _, item := range testData {
+		reply := bot.handleScheduleEditCommand(1, item.arguments, "en")
+
+		if exceptions := exceptionsText(storage.exceptions); exceptions != item.exceptions {
+			t.Errorf("Wrong exceptions %q after %q: %s", exceptions, item.arguments, reply)
+		}
+	}
+}
+
+func TestOutageScheduleText(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Fatalf("Can't load location: %s", err)
+	}
+
+	storage := &testScheduleStorage{
+		days: []database.ScheduleDay{
+			{Group: "1", Weekday: time.Saturday, Windows: "20:00-24:00"},
+			{Group: "1", Weekday: time.Sunday, Windows: "08:00-12:00"},
+		},
+	}
+
+	testData := []struct {
+		name    string
+		start   time.Time
+		end     time.Time
+		matched bool
+	}{
+		{
+			name:  "inside window",
+			start: time.Date(2024, 3, 10, 9, 0, 0, 0, location), end: time.Date(2024, 3, 10, 10, 0, 0, 0, location),
+			matched: true,
+		},
+		{
+			name:  "after window",
+			start: time.Date(2024, 3, 10, 12, 30, 0, 0, location), end: time.Date(2024, 3, 10, 12, 55, 0, 0, location),
+		},
+		{
+			name:  "before window",
+			start: time.Date(2024, 3, 10, 7, 0, 0, 0, location), end: time.Date(2024, 3, 10, 7, 55, 0, 0, location),
+		},
+		{
+			name:  "across midnight",
+			start: time.Date(2024, 3, 9, 23, 30, 0, 0, location), end: time.Date(2024, 3, 10, 1, 0, 0, 0, location),
+			matched: true,
+		},
+		{
+			name:  "after midnight",
+			start: time.Date(2024, 3, 10, 0, 30, 0, 0, location), end: time.Date(2024, 3, 10, 1, 0, 0, 0, location),
+		},
+		{
+			name:  "spring forward inside window",
+			start: time.Date(2024, 3, 31, 9, 0, 0, 0, location), end: time.Date(2024, 3, 31, 10, 0, 0, 0, location),
+			matched: true,
+		},
+		{
+			name:  "spring forward after window",
+			start: time.Date(2024, 3, 31, 12, 30, 0, 0, location), end: time.Date(2024, 3, 31, 12, 55, 0, 0, location),
+		},
+		{
+			name:  "fall back before window",
+			start: time.Date(2024, 10, 27, 7, 0, 0, 0, location), end: time.Date(2024, 10, 27, 7, 55, 0, 0, location),
+		},
+	}
+
+	bot := &ElectroBot{db: storage}
+
+	for _, item := range testData {
+		text := bot.outageScheduleText(database.Outage{Start: item.start, End: item.end}, "en", location)
+
+		if matched := strings.HasPrefix(text, "Matches scheduled outages:"); matched != item.matched {
+			t.Errorf("Wrong %s schedule match: %s", item.name, text)
+		}
+	}
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (storage *testScheduleStorage) GetSchedule() ([]database.ScheduleDay, error) {
+	return storage.days, nil
+}
+
+func (storage *testScheduleStorage) SetScheduleException(
+	group, date string, windows *string, note string, changedBy int64,
+) error {
+	exceptions := storage.exceptions[:0]
+
+	for _, exception := range storage.exceptions {
+		if exception.Group != group || exception.Date != date {
+			exceptions = append(exceptions, exception)
+		}
+	}
+
+	if windows != nil {
+		exceptions = append(exceptions, database.ScheduleException{
+			Group: group, Date: date, Windows: *windows, Note: note, ChangedBy: changedBy,
+		})
+	}
+
+	sort.Slice(exceptions, func(i, j int) bool {
+		if exceptions[i].Date != exceptions[j].Date {
+			return exceptions[i].Date < exceptions[j].Date
+		}
+
+		return exceptions[i].Group < exceptions[j].Group
+	})
+
+	storage.exceptions = exceptions
+
+	return nil
+}
+
+func (storage *testScheduleStorage) GetScheduleExceptions(from string) ([]database.ScheduleException, error) {
+	var exceptions []database.ScheduleException
+
+	for _, exception := range storage.exceptions {
+		if exception.Date >= from {
+			exceptions = append(exceptions, exception)
+		}
+	}
+
+	return exceptions, nil
+}
+
+func (storage *testScheduleStorage) GetPlannedSchedules(from string) ([]database.PlannedSchedule, error) {
+	var planned []database.PlannedSchedule
+
+	for _, day := range storage.planned {
+		if day.Date >= from {
+			planned = append(planned, day)
+		}
+	}
+
+	return planned, nil
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func exceptionsText(exceptions []database.ScheduleException) string {
+	items := make([]string, 0, len(exceptions))
+
+	for _, exception := range exceptions {
+		windows := exception.Windows
+		if windows == "" {
+			windows = "off"
+		}
+
+		item := fmt.Sprintf("%s %s %s", exception.Date, exception.Group, windows)
+
+		if exception.Note != "" {
+			item += " (" + exception.Note + ")"
+		}
+
+		items = append(items, item)
+	}
+
+	return strings.Join(items, "; ")
+}
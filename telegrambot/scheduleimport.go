@@ -0,0 +1,274 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"electrobot/i18n"
+	"electrobot/schedule"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	scheduleImportCallbackPrefix = "schedule_import:"
+	scheduleImportApply          = "apply"
+	scheduleImportCancel         = "cancel"
+	scheduleOCRTimeout           = 2 * time.Minute
+	maxScheduleImageSize         = 20 * 1024 * 1024
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// scheduleChange is a single schedule day change parsed from the OCR command output.
+type scheduleChange struct {
+	group   string
+	weekday time.Weekday
+	windows string
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// scheduleImageFileID returns file ID of the largest photo or of an image document, empty if there is no image.
+func scheduleImageFileID(message *botApi.Message) string {
+	if len(message.Photo) != 0 {
+		return message.Photo[len(message.Photo)-1].FileID
+	}
+
+	if message.Document != nil && strings.HasPrefix(message.Document.MimeType, "image/") {
+		return message.Document.FileID
+	}
+
+	return ""
+}
+
+// handleScheduleImage runs the OCR command on a schedule image sent by an admin and asks to confirm the changes.
+func (bot *ElectroBot) handleScheduleImage(message *botApi.Message) {
+	chatID := message.Chat.ID
+
+	if bot.scheduleOCRCommand == "" || !bot.isAdmin(senderID(message.From)) || !message.Chat.IsPrivate() {
+		return
+	}
+
+	lang := bot.userLanguage(chatID, message.From)
+	reply := botApi.NewMessage(chatID, "")
+	reply.ReplyToMessageID = message.MessageID
+
+	log.WithField("chatID", chatID).Info("Schedule image received")
+
+	changes, err := bot.recognizeSchedule(scheduleImageFileID(message))
+
+	switch {
+	case err != nil:
+		log.Errorf("Failed to recognize schedule: %s", err)
+
+		reply.Text = i18n.T(lang, "Failed to recognize the schedule: %s", err)
+
+	case len(changes) == 0:
+		reply.Text = i18n.T(lang, "The recognized schedule matches the current one")
+
+	default:
+		bot.stateMutex.Lock()
+		bot.scheduleImports[chatID] = changes
+		bot.stateMutex.Unlock()
+
+		reply.Text = bot.scheduleChangesText(changes, lang)
+		reply.ReplyMarkup = botApi.NewInlineKeyboardMarkup(botApi.NewInlineKeyboardRow(
+			botApi.NewInlineKeyboardButtonData(i18n.T(lang, "Apply"),
+				scheduleImportCallbackPrefix+scheduleImportApply),
+			botApi.NewInlineKeyboardButtonData(i18n.T(lang, "Cancel"),
+				scheduleImportCallbackPrefix+scheduleImportCancel)))
+	}
+
+	if _, err = bot.send(reply); err != nil {
+		log.Errorf("Failed to send message: %s", err)
+	}
+}
+
+// recognizeSchedule downloads the image and runs the OCR command with the image path as the only argument.
+// The command prints "<group> <weekday> <HH:MM-HH:MM,...|off>" lines, only days that differ are returned.
+func (bot *ElectroBot) recognizeSchedule(fileID string) (changes []scheduleChange, err error) {
+	imageFile, err := bot.downloadFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.Remove(imageFile)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), scheduleOCRTimeout)
+	defer cancelFunc()
+
+	output, err := exec.CommandContext(ctx, bot.scheduleOCRCommand, imageFile).Output() //nolint:gosec
+	if err != nil {
+		var exitErr *exec.ExitError
+
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) != 0 {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+
+		return nil, err
+	}
+
+	current, err := bot.db.GetSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	currentWindows := make(map[string]string)
+
+	for _, day := range current {
+		currentWindows[day.Group+" "+day.Weekday.String()] = day.Windows
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		change, err := parseScheduleLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		if currentWindows[change.group+" "+change.weekday.String()] != change.windows {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+func parseScheduleLine(line string) (change scheduleChange, err error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 { //nolint:gomnd
+		return change, fmt.Errorf("invalid schedule line %q", line)
+	}
+
+	change.group = fields[0]
+
+	if change.weekday, err = schedule.ParseWeekday(fields[1]); err != nil {
+		return change, err
+	}
+
+	windows, err := schedule.ParseWindows(fields[2])
+	if err != nil {
+		return change, err
+	}
+
+	change.windows = schedule.FormatWindows(windows)
+
+	return change, nil
+}
+
+func (bot *ElectroBot) downloadFile(fileID string) (fileName string, err error) {
+	url, err := bot.botApi.GetFileDirectURL(fileID)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := http.Get(url) //nolint:gosec,noctx // URL is built by the Bot API library
+	if err != nil {
+		return "", err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download file: %s", response.Status)
+	}
+
+	file, err := os.CreateTemp("", "electrobot-schedule-*")
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	if _, err = io.Copy(file, io.LimitReader(response.Body, maxScheduleImageSize)); err != nil {
+		os.Remove(file.Name())
+
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+func (bot *ElectroBot) scheduleChangesText(changes []scheduleChange, lang string) string {
+	text := i18n.T(lang, "Recognized schedule changes:")
+
+	for _, change := range changes {
+		text += fmt.Sprintf("\n%s %s: %s", change.group, weekdayName(change.weekday, lang),
+			windowsText(change.windows, lang))
+	}
+
+	return text + "\n\n" + i18n.T(lang, "Days not listed are left unchanged. Apply the changes?")
+}
+
+func (bot *ElectroBot) handleScheduleImportCallback(query *botApi.CallbackQuery, action string) string {
+	chatID := query.Message.Chat.ID
+	lang := bot.userLanguage(chatID, query.From)
+
+	if !bot.isAdmin(senderID(query.From)) {
+		return i18n.T(lang, "Sorry, this command is available to admins only")
+	}
+
+	bot.stateMutex.Lock()
+	changes, ok := bot.scheduleImports[chatID]
+	delete(bot.scheduleImports, chatID)
+	bot.stateMutex.Unlock()
+
+	if !ok {
+		return i18n.T(lang, "There is no schedule import to confirm")
+	}
+
+	if action != scheduleImportApply {
+		return i18n.T(lang, "Schedule import cancelled")
+	}
+
+	for _, change := range changes {
+		version, err := bot.db.SetScheduleDay(change.group, change.weekday, change.windows, chatID)
+		if err != nil {
+			log.Errorf("Failed to store schedule: %s", err)
+
+			return i18n.T(lang, "Failed to change outage schedule. Please try again later")
+		}
+
+		log.WithFields(log.Fields{
+			"chatID": chatID, "group": change.group, "weekday": change.weekday, "windows": change.windows,
+			"version": version,
+		}).Info("Outage schedule imported")
+	}
+
+	return i18n.T(lang, "Schedule import applied, %s changed", i18n.N(lang, len(changes), "%d day|%d days"))
+}
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"electrobot/chaos"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultSendAttempts = 5
+	sendInitialBackoff  = time.Second
+	sendMaxBackoff      = 30 * time.Second
+	// maxMessagesPerSecond keeps all sends together below the Telegram limit of about 30 messages per second.
+	maxMessagesPerSecond = 25
+	// maxMessageLength is the Bot API limit of message text length in UTF-16 code units.
+	maxMessageLength = 4096
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// send sends the message retrying transient failures with exponential backoff,
+// Telegram flood control RetryAfter is honoured instead of the backoff delay. Every attempt is throttled by the rate
+// shared by all senders: notifications, the queue, broadcasts and replies.
+func (bot *ElectroBot) send(chattable botApi.Chattable) (message botApi.Message, err error) {
+	chattable = bot.render(chattable)
+	backoff := sendInitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		if err = bot.waitSendSlot(); err != nil {
+			return message, err
+		}
+
+		if chaos.Fire(chaos.Telegram) {
+			err = chaos.ErrInjected
+		} else if message, err = bot.botApi.Send(chattable); err == nil {
+			return message, nil
+		}
+
+		if attempt >= bot.sendAttempts || !isTransientError(err) {
+			return message, err
+		}
+
+		delay := backoff
+
+		var apiErr *botApi.Error
+
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			delay = time.Duration(apiErr.RetryAfter) * time.Second
+		}
+
+		log.WithFields(log.Fields{"attempt": attempt, "delay": delay}).Warnf("Failed to send message, retrying: %s", err)
+
+		select {
+		case <-time.After(delay):
+		case <-bot.ctx.Done():
+			return message, err
+		}
+
+		backoff = min(2*backoff, sendMaxBackoff) //nolint:gomnd
+	}
+}
+
+// reply queues a reply to a user action, replies are sent in order by runReplies so slow sends and retries don't hold
+// the update loop, which also serves watchdog notifications.
+func (bot *ElectroBot) reply(chattable botApi.Chattable) {
+	select {
+	case bot.replies <- chattable:
+	case <-bot.ctx.Done():
+	}
+}
+
+func (bot *ElectroBot) runReplies(ctx context.Context) {
+	for {
+		select {
+		case chattable := <-bot.replies:
+			if _, err := bot.send(chattable); err != nil {
+				log.Errorf("Failed to send reply: %s", err)
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// waitSendSlot reserves the next free send slot and waits for it, slots are maxMessagesPerSecond apart.
+func (bot *ElectroBot) waitSendSlot() error {
+	bot.sendMutex.Lock()
+
+	now := time.Now()
+
+	slot := now
+	if bot.nextSendTime.After(now) {
+		slot = bot.nextSendTime
+	}
+
+	bot.nextSendTime = slot.Add(time.Second / maxMessagesPerSecond)
+
+	bot.sendMutex.Unlock()
+
+	if slot.Equal(now) {
+		return nil
+	}
+
+	timer := time.NewTimer(slot.Sub(now))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+
+	case <-bot.ctx.Done():
+		return bot.ctx.Err()
+	}
+}
+
+// splitText splits the text at line breaks into parts within maxMessageLength, lines longer than that are split
+// anywhere.
+func splitText(text string) (parts []string) {
+	var (
+		part   strings.Builder
+		length int
+	)
+
+	flush := func() {
+		parts = append(parts, part.String())
+		part.Reset()
+
+		length = 0
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if length > 0 && length+1+utf16Length(line) > maxMessageLength {
+			flush()
+		}
+
+		if length > 0 {
+			part.WriteByte('\n')
+			length++
+		}
+
+		for _, char := range line {
+			if length+utf16Length(string(char)) > maxMessageLength {
+				flush()
+			}
+
+			part.WriteRune(char)
+			length += utf16Length(string(char))
+		}
+	}
+
+	return append(parts, part.String())
+}
+
+// utf16Length returns the text length in UTF-16 code units, Telegram measures text length in them.
+func utf16Length(text string) (length int) {
+	for _, char := range text {
+		length++
+
+		if char >= 0x10000 { //nolint:gomnd // encoded as a surrogate pair
+			length++
+		}
+	}
+
+	return length
+}
+
+// isTransientError returns true for network errors, flood control and Telegram server errors.
+func isTransientError(err error) bool {
+	var apiErr *botApi.Error
+
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+
+	return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+	"testing"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestSplitText(t *testing.T) {
+	line := strings.Repeat("a", 100)
+	lines := strings.Repeat(line+"\n", 100)
+	emoji := strings.Repeat("🟢", maxMessageLength/2+1)
+
+	// parts split at line breaks lose the break
+	testData := []struct {
+		name      string
+		text      string
+		parts     int
+		separator string
+	}{
+		{name: "short", text: "a\nb", parts: 1},
+		{name: "empty", text: "", parts: 1},
+		{name: "lines", text: lines, parts: 3, separator: "\n"},
+		{name: "long line", text: strings.Repeat("a", 2*maxMessageLength+1), parts: 3},
+		{name: "surrogate pairs", text: emoji, parts: 2},
+	}
+
+	for _, item := range testData {
+		t.Run(item.name, func(t *testing.T) {
+			parts := splitText(item.text)
+
+			if len(parts) != item.parts {
+				t.Fatalf("Wrong parts count: %d", len(parts))
+			}
+
+			for _, part := range parts {
+				if length := utf16Length(part); length > maxMessageLength {
+					t.Errorf("Part length %d exceeds the limit", length)
+				}
+			}
+
+			if strings.Join(parts, item.separator) != item.text {
+				t.Errorf("Parts don't make up the text")
+			}
+		})
+	}
+}
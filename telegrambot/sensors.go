@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// phases are names of three-phase supply phases sensors may monitor.
+var phases = []string{"A", "B", "C"}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// SensorBatteryLow alerts admins that the heartbeat device of the location runs out of battery, its silence would
+// be reported as a power outage.
+func (bot *ElectroBot) SensorBatteryLow(name string, battery int) {
+	bot.notifyAdmins(func(lang string, _ *time.Location) string {
+		return i18n.T(lang, "🪫 Sensor %s battery is low: %d%%. Charge or replace it, otherwise its silence will be "+
+			"reported as a power outage", name, battery)
+	})
+}
+
+// VoltageAbnormal warns users subscribed to the location that the mains voltage is out of bounds, unlike outages the
+// power is still on but may damage sensitive devices.
+func (bot *ElectroBot) VoltageAbnormal(name string, voltage float64, high bool) {
+	bot.notifyVoltage(name, func(lang string, _ *time.Location) string {
+		if high {
+			return i18n.T(lang, "⚡️ Voltage is too high: %d V. Consider unplugging sensitive devices",
+				int(math.Round(voltage)))
+		}
+
+		return i18n.T(lang, "⚡️ Voltage is too low: %d V. Consider unplugging sensitive devices",
+			int(math.Round(voltage)))
+	})
+}
+
+// VoltageNormal tells users subscribed to the location that the mains voltage is back to normal.
+func (bot *ElectroBot) VoltageNormal(name string, voltage float64) {
+	bot.notifyVoltage(name, func(lang string, _ *time.Location) string {
+		return i18n.T(lang, "✅ Voltage is back to normal: %d V", int(math.Round(voltage)))
+	})
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleSensorsCommand manages heartbeat devices, sensors are locations with heartbeat tokens.
+func (bot *ElectroBot) handleSensorsCommand(chatID int64, arguments, lang string) string {
+	fields := strings.Fields(arguments)
+
+	switch {
+	case len(fields) == 0:
+		return bot.listSensors(chatID, lang)
+
+	case len(fields) == 2 && fields[0] == "add":
+		return bot.addSensor(chatID, fields[1], lang)
+
+	case len(fields) == 3 && fields[0] == "rename":
+		return bot.renameSensor(chatID, fields[1], fields[2], lang)
+
+	case len(fields) == 2 && fields[0] == "token":
+		if found, response := bot.findSensor(fields[1], lang); !found {
+			return response
+		}
+
+		return bot.setHeartbeatToken(chatID, fields[1], lang)
+
+	case len(fields) == 4 && fields[0] == "phase":
+		return bot.setSensorPhase(chatID, fields[1], fields[2], fields[3], lang)
+
+	case len(fields) == 3 && fields[0] == "phase" && fields[2] == "none":
+		return bot.setSensorPhase(chatID, fields[1], "", "", lang)
+
+	case len(fields) == 2 && (fields[0] == "pause" || fields[0] == "resume"):
+		return bot.pauseSensor(chatID, fields[1], fields[0] == "pause", lang)
+
+	case len(fields) == 2 && fields[0] == "delete":
+		if err := bot.db.RemoveHeartbeatToken(fields[1]); err != nil {
+			log.Errorf("Failed to delete sensor: %s", err)
+
+			return i18n.T(lang, "Unknown sensor %q, see /sensors", fields[1])
+		}
+
+		log.WithFields(log.Fields{"chatID": chatID, "sensor": fields[1]}).Info("Sensor deleted")
+
+		return i18n.T(lang, "Sensor %q deleted, its token stopped working. The location is kept, remove it with "+
+			"/location remove", fields[1])
+
+	default:
+		return i18n.T(lang, "Usage:\n/sensors - list sensors\n/sensors add <name> - register a sensor and get its token"+
+			"\n/sensors rename <name> <new name> - rename sensor\n/sensors token <name> - replace the sensor token"+
+			"\n/sensors phase <name> <location> <A|B|C> - the sensor monitors a phase of the location"+
+			"\n/sensors phase <name> none - the sensor monitors its own location"+
+			"\n/sensors pause <name> - ignore the sensor heartbeats\n/sensors resume <name> - follow the sensor "+
+			"heartbeats again\n/sensors delete <name> - delete the sensor, its location is kept")
+	}
+}
+
+// listSensors lists heartbeat devices with their last heartbeat and telemetry.
+func (bot *ElectroBot) listSensors(chatID int64, lang string) string {
+	sensors, err := bot.db.GetHeartbeatLocations()
+	if err != nil {
+		log.Errorf("Failed to get sensors: %s", err)
+
+		return i18n.T(lang, "Failed to get sensors. Please try again later")
+	}
+
+	if len(sensors) == 0 {
+		return i18n.T(lang, "There are no sensors, register one with /sensors add <name>")
+	}
+
+	location := bot.userLocation(chatID)
+	lines := []string{i18n.T(lang, "Sensors (%d):", len(sensors))}
+
+	for _, sensor := range sensors {
+		lines = append(lines, sensor.Name+": "+strings.Join(sensorState(sensor, lang, location), ", "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// addSensor registers a heartbeat device on the location premises, the location is created if it doesn't exist.
+func (bot *ElectroBot) addSensor(chatID int64, name, lang string) string {
+	if len(name) > maxLocationNameLength {
+		return i18n.T(lang, "Location name is too long, please keep it under %s",
+			i18n.N(lang, maxLocationNameLength, "%d character|%d characters"))
+	}
+
+	if found, _ := bot.findSensor(name, lang); found {
+		return i18n.T(lang, "Sensor %q already exists, replace its token with /sensors token %s", name, name)
+	}
+
+	if _, err := bot.db.EnsureLocation(name); err != nil {
+		log.Errorf("Failed to add sensor location: %s", err)
+
+		return i18n.T(lang, "Failed to add sensor %q. Please try again later", name)
+	}
+
+	return bot.setHeartbeatToken(chatID, name, lang)
+}
+
+func (bot *ElectroBot) renameSensor(chatID int64, name, newName, lang string) string {
+	if len(newName) > maxLocationNameLength {
+		return i18n.T(lang, "Location name is too long, please keep it under %s",
+			i18n.N(lang, maxLocationNameLength, "%d character|%d characters"))
+	}
+
+	if found, response := bot.findSensor(name, lang); !found {
+		return response
+	}
+
+	if err := bot.db.RenameLocation(name, newName); err != nil {
+		log.Errorf("Failed to rename sensor: %s", err)
+
+		return i18n.T(lang, "Failed to rename sensor %q, the new name is taken", name)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "sensor": name, "name": newName}).Info("Sensor renamed")
+
+	return i18n.T(lang, "Sensor %q renamed to %q", name, newName)
+}
+
+// setSensorPhase makes the sensor monitor a phase of the three-phase location, the location is off while all its
+// phases are. Empty location makes the sensor monitor its own location again.
+func (bot *ElectroBot) setSensorPhase(chatID int64, name, location, phase, lang string) string {
+	phase = strings.ToUpper(phase)
+
+	if location != "" && !slices.Contains(phases, phase) {
+		return i18n.T(lang, "Unknown phase %q, use %s", phase, strings.Join(phases, ", "))
+	}
+
+	if err := bot.db.SetLocationParent(name, location, phase); err != nil {
+		log.Errorf("Failed to set sensor phase: %s", err)
+
+		if location == "" {
+			return i18n.T(lang, "Unknown sensor %q, see /sensors", name)
+		}
+
+		return i18n.T(lang, "Failed to set phase of sensor %q. The location must exist without a sensor of its own "+
+			"and the phase must be free", name)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "sensor": name, "location": location, "phase": phase}).Info(
+		"Sensor phase set")
+
+	if location == "" {
+		return i18n.T(lang, "Sensor %q monitors its own location", name)
+	}
+
+	return i18n.T(lang, "Sensor %q monitors phase %s of %s", name, phase, location)
+}
+
+// pauseSensor stops or resumes following the sensor heartbeats, e.g. while the device is serviced.
+func (bot *ElectroBot) pauseSensor(chatID int64, name string, pause bool, lang string) string {
+	if err := bot.db.SetHeartbeatPaused(name, pause); err != nil {
+		log.Errorf("Failed to pause sensor: %s", err)
+
+		return i18n.T(lang, "Unknown sensor %q, see /sensors", name)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "sensor": name, "paused": pause}).Info("Sensor pause changed")
+
+	if pause {
+		return i18n.T(lang, "Sensor %q paused, its heartbeats and silence don't change the power state", name)
+	}
+
+	return i18n.T(lang, "Sensor %q resumed, the power state follows its next heartbeat", name)
+}
+
+// findSensor returns true if the location has a heartbeat token, otherwise the response explains why not.
+func (bot *ElectroBot) findSensor(name, lang string) (found bool, response string) {
+	sensors, err := bot.db.GetHeartbeatLocations()
+	if err != nil {
+		log.Errorf("Failed to get sensors: %s", err)
+
+		return false, i18n.T(lang, "Failed to get sensors. Please try again later")
+	}
+
+	for _, sensor := range sensors {
+		if sensor.Name == name {
+			return true, ""
+		}
+	}
+
+	return false, i18n.T(lang, "Unknown sensor %q, see /sensors", name)
+}
+
+func sensorState(sensor database.HeartbeatLocation, lang string, location *time.Location) (state []string) {
+	switch {
+	case sensor.Phase != "":
+		state = append(state, i18n.T(lang, "phase %s of %s", sensor.Phase, sensor.ParentName))
+
+	case sensor.ParentID != 0:
+		state = append(state, i18n.T(lang, "part of %s", sensor.ParentName))
+	}
+
+	if sensor.Paused {
+		state = append(state, i18n.T(lang, "paused"))
+	}
+
+	if sensor.LastHeartbeat.IsZero() {
+		state = append(state, i18n.T(lang, "no heartbeats yet"))
+	} else {
+		state = append(state, i18n.T(lang, "last heartbeat %s", i18n.DateTime(lang, sensor.LastHeartbeat.In(location))))
+	}
+
+	if !sensor.OffSince.IsZero() {
+		state = append(state, i18n.T(lang, "no power since %s", i18n.DateTime(lang, sensor.OffSince.In(location))))
+	}
+
+	if sensor.Battery != database.UnknownBattery {
+		state = append(state, i18n.T(lang, "battery %s", fmt.Sprintf("%d%%", sensor.Battery)))
+	}
+
+	if sensor.Signal != database.UnknownSignal {
+		state = append(state, i18n.T(lang, "signal %d dBm", sensor.Signal))
+	}
+
+	return state
+}
+
+func (bot *ElectroBot) notifyVoltage(name string, text func(lang string, location *time.Location) string) {
+	locationID, err := bot.db.EnsureLocation(name)
+	if err != nil {
+		log.WithField("location", name).Errorf("Failed to get location: %s", err)
+
+		return
+	}
+
+	bot.notifyLocation(locationID, text, 0, nil)
+}
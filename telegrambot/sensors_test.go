@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"electrobot/telegrambot"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestSensors(t *testing.T) {
+	server, bot, db := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(adminID, "/sensors")
+	checkReply(t, server, adminID, "There are no sensors")
+
+	server.SendMessage(adminID, "/sensors add garage")
+	checkReply(t, server, adminID, `Heartbeat token of "garage" set`)
+
+	server.SendMessage(adminID, "/sensors")
+	checkReply(t, server, adminID, "Sensors (1):\ngarage: no heartbeats yet")
+
+	location, err := db.GetLocation("garage")
+	if err != nil {
+		t.Fatalf("Can't get location: %s", err)
+	}
+
+	if err = db.RecordLocationHeartbeat(location.ID, time.Now(), time.Time{}); err != nil {
+		t.Fatalf("Can't record heartbeat: %s", err)
+	}
+
+	if err = db.RecordSensorTelemetry(location.ID, 15, -70); err != nil {
+		t.Fatalf("Can't record telemetry: %s", err)
+	}
+
+	server.SendMessage(adminID, "/sensors")
+
+	if message := checkReply(t, server, adminID, "Sensors (1):\ngarage: last heartbeat"); !strings.HasSuffix(
+		message.Text, ", battery 15%, signal -70 dBm") {
+		t.Errorf("Wrong sensor telemetry: %q", message.Text)
+	}
+
+	bot.SensorBatteryLow("garage", 15)
+	checkReply(t, server, adminID, "🪫 Sensor garage battery is low: 15%")
+}
+
+func TestSensorPhases(t *testing.T) {
+	server, bot, db := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(adminID, "/sensors add garage")
+	checkReply(t, server, adminID, `Heartbeat token of "garage" set`)
+
+	server.SendMessage(adminID, "/sensors add l2")
+	checkReply(t, server, adminID, `Heartbeat token of "l2" set`)
+
+	testData := []struct {
+		command string
+		reply   string
+		phases  string
+	}{
+		{command: "/sensors phase l2 home b", reply: `Sensor "l2" monitors phase B of home`, phases: "l2:B"},
+		{command: "/sensors phase l2 home D", reply: `Unknown phase "D", use A, B, C`, phases: "l2:B"},
+		{command: "/sensors phase l2 garage A", reply: `Failed to set phase of sensor "l2"`, phases: "l2:B"},
+		{command: "/sensors phase garage home B", reply: `Failed to set phase of sensor "garage"`, phases: "l2:B"},
+		{command: "/sensors phase garage home A", reply: `Sensor "garage" monitors phase A of home`,
+			phases: "garage:A l2:B"},
+		{command: "/sensors phase garage none", reply: `Sensor "garage" monitors its own location`, phases: "l2:B"},
+		{command: "/sensors phase shed none", reply: `Unknown sensor "shed"`, phases: "l2:B"},
+	}
+
+	for _, item := range testData {
+		server.SendMessage(adminID, item.command)
+		checkReply(t, server, adminID, item.reply)
+
+		sensors, err := db.GetHeartbeatLocations()
+		if err != nil {
+			t.Fatalf("Can't get sensors: %s", err)
+		}
+
+		var phases []string
+
+		for _, sensor := range sensors {
+			if sensor.ParentID != 0 {
+				phases = append(phases, sensor.Name+":"+sensor.Phase)
+			}
+		}
+
+		if strings.Join(phases, " ") != item.phases {
+			t.Errorf("Wrong phases after %q: %v", item.command, phases)
+		}
+	}
+
+	server.SendMessage(adminID, "/sensors")
+
+	if message := checkReply(t, server, adminID, "Sensors (2):"); !strings.Contains(message.Text,
+		"\nl2: phase B of home, no heartbeats yet") {
+		t.Errorf("Wrong sensor phase: %q", message.Text)
+	}
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	start := time.Now()
+
+	bot.PhasePowerOff("home", "B", start)
+	checkReply(t, server, userID, "home: 🟡 Phase B went off at")
+
+	bot.PhasePowerOn("home", "B", start, start.Add(time.Hour))
+	checkReply(t, server, userID, "home: Phase B is back at")
+}
+
+func TestVoltageAlerts(t *testing.T) {
+	server, bot, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(adminID, "/sensors add garage")
+	checkReply(t, server, adminID, `Heartbeat token of "garage" set`)
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.SendMessage(userID, "/subscribe garage")
+	checkReply(t, server, userID, "You're subscribed to garage")
+
+	bot.VoltageAbnormal("garage", 250.6, true)
+	checkReply(t, server, userID, "garage: ⚡️ Voltage is too high: 251 V. Consider unplugging sensitive devices")
+
+	bot.VoltageAbnormal("garage", 190, false)
+	checkReply(t, server, userID, "garage: ⚡️ Voltage is too low: 190 V")
+
+	bot.VoltageNormal("garage", 229.4)
+	checkReply(t, server, userID, "garage: ✅ Voltage is back to normal: 229 V")
+}
+
+func TestSensorCommands(t *testing.T) {
+	server, _, db := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(adminID, "/location add shed")
+	checkReply(t, server, adminID, `Location "shed" added`)
+
+	testData := []struct {
+		command string
+		reply   string
+		sensors string
+	}{
+		{command: "/sensors add garage", reply: `Heartbeat token of "garage" set`, sensors: "garage"},
+		{command: "/sensors add garage", reply: `Sensor "garage" already exists`, sensors: "garage"},
+		{command: "/sensors add shed", reply: `Heartbeat token of "shed" set`, sensors: "shed garage"},
+		{command: "/sensors rename garage shed", reply: `Failed to rename sensor "garage"`, sensors: "shed garage"},
+		{command: "/sensors rename garage barn", reply: `Sensor "garage" renamed to "barn"`, sensors: "shed barn"},
+		{command: "/sensors rename garage yard", reply: `Unknown sensor "garage"`, sensors: "shed barn"},
+		{command: "/sensors token barn", reply: `Heartbeat token of "barn" set`, sensors: "shed barn"},
+		{command: "/sensors token garage", reply: `Unknown sensor "garage"`, sensors: "shed barn"},
+		{command: "/sensors pause barn", reply: `Sensor "barn" paused`, sensors: "shed barn(paused)"},
+		{command: "/sensors resume barn", reply: `Sensor "barn" resumed`, sensors: "shed barn"},
+		{command: "/sensors pause garage", reply: `Unknown sensor "garage"`, sensors: "shed barn"},
+		{command: "/sensors delete shed", reply: `Sensor "shed" deleted`, sensors: "barn"},
+		{command: "/sensors delete shed", reply: `Unknown sensor "shed"`, sensors: "barn"},
+		{command: "/sensors remove barn", reply: "Usage:", sensors: "barn"},
+	}
+
+	for _, item := range testData {
+		server.SendMessage(adminID, item.command)
+		checkReply(t, server, adminID, item.reply)
+
+		sensors, err := db.GetHeartbeatLocations()
+		if err != nil {
+			t.Fatalf("Can't get sensors: %s", err)
+		}
+
+		names := make([]string, 0, len(sensors))
+
+		for _, sensor := range sensors {
+			if sensor.Paused {
+				sensor.Name += "(paused)"
+			}
+
+			names = append(names, sensor.Name)
+		}
+
+		if strings.Join(names, " ") != item.sensors {
+			t.Errorf("Wrong sensors after %q: %v", item.command, names)
+		}
+	}
+
+	if _, err := db.GetLocation("shed"); err != nil {
+		t.Errorf("Location of deleted sensor removed: %s", err)
+	}
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+	"time"
+
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// ServiceMonitor provides liveness of local services sharing the bot host.
+type ServiceMonitor interface {
+	// Services returns names of services with heartbeats since the time and without them.
+	Services(since time.Time) (alive, missing []string)
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// SetServiceMonitor makes the bot report to admins the services which didn't survive outages of the main location,
+// services get the delay after the restoration to start and report.
+func (bot *ElectroBot) SetServiceMonitor(monitor ServiceMonitor, delay time.Duration) {
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	bot.serviceMonitor, bot.serviceReportDelay = monitor, delay
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// scheduleServicesReport sends the restore report after services had time to come back.
+func (bot *ElectroBot) scheduleServicesReport(end time.Time) {
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	if bot.serviceMonitor == nil {
+		return
+	}
+
+	if bot.serviceReportTimer != nil {
+		bot.serviceReportTimer.Stop()
+	}
+
+	bot.serviceReportTimer = time.AfterFunc(bot.serviceReportDelay, func() { bot.sendServicesReport(end) })
+}
+
+// sendServicesReport lists services without heartbeats since the restoration, they didn't survive the outage.
+func (bot *ElectroBot) sendServicesReport(end time.Time) {
+	// the next outage makes the report useless
+	if powerOn, _, _ := bot.PowerState(); !powerOn {
+		return
+	}
+
+	bot.stateMutex.Lock()
+	monitor := bot.serviceMonitor
+	bot.stateMutex.Unlock()
+
+	alive, missing := monitor.Services(end)
+	if len(alive) == 0 && len(missing) == 0 {
+		return
+	}
+
+	log.WithFields(log.Fields{"alive": alive, "missing": missing}).Info("Services checked after restoration")
+
+	bot.notifyAdmins(func(lang string, location *time.Location) string {
+		if len(missing) == 0 {
+			return i18n.T(lang, "✅ Restore report: all services are back after the outage: %s",
+				strings.Join(alive, ", "))
+		}
+
+		return i18n.T(lang, "⚠️ Restore report: services that didn't survive the outage: %s\nNo heartbeats since "+
+			"power was restored at %s", strings.Join(missing, ", "), i18n.DateTime(lang, end.In(location)))
+	})
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"testing"
+	"time"
+
+	"electrobot/telegrambot"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type testServiceMonitor struct {
+	alive, missing []string
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestServicesReport(t *testing.T) {
+	server, bot, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+	start := time.Now().Add(-time.Hour)
+
+	bot.SetServiceMonitor(&testServiceMonitor{alive: []string{"nas"}, missing: []string{"backup"}},
+		10*time.Millisecond)
+
+	bot.PowerOff(start)
+	bot.PowerOn(start, start.Add(time.Minute))
+	checkReply(t, server, adminID, "⚠️ Restore report: services that didn't survive the outage: backup\n")
+
+	bot.SetServiceMonitor(&testServiceMonitor{alive: []string{"backup", "nas"}}, 10*time.Millisecond)
+
+	bot.PowerOff(start.Add(2 * time.Minute))
+	bot.PowerOn(start.Add(2*time.Minute), start.Add(3*time.Minute))
+	checkReply(t, server, adminID, "✅ Restore report: all services are back after the outage: backup, nas")
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (monitor *testServiceMonitor) Services(since time.Time) (alive, missing []string) {
+	return monitor.alive, monitor.missing
+}
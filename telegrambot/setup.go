@@ -0,0 +1,332 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+
+	"electrobot/i18n"
+	"electrobot/scheduler"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const setupCallbackPrefix = "setup:"
+
+// Settings chosen in the setup wizard.
+const (
+	scheduleRegionSettingKey      = "schedule_import_region"
+	scheduleGroupSettingKey       = "schedule_import_group"
+	lowBandwidthSettingKey        = "low_bandwidth"
+	yearlyReportEnabledSettingKey = "yearly_report_enabled"
+	settingOn                     = "on"
+	settingOff                    = "off"
+)
+
+// Setup wizard actions.
+const (
+	setupRegion       = "region"
+	setupGroup        = "group"
+	setupSkip         = "skip"
+	setupLowBandwidth = "lowbandwidth"
+	setupYearlyReport = "yearlyreport"
+	setupDone         = "done"
+)
+
+const setupGroupsPerRow = 4
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// ScheduleImporter starts the planned schedule import of the group, empty group stops it.
+type ScheduleImporter interface {
+	SetScheduleImport(region, group string) error
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// applySetupSettings applies settings chosen in the setup wizard before the last restart.
+func (bot *ElectroBot) applySetupSettings() {
+	if bot.setting(lowBandwidthSettingKey) == settingOn {
+		bot.forceLowBandwidth.Store(true)
+		bot.lowBandwidth.Store(true)
+	}
+
+	if bot.scheduleImporter == nil {
+		return
+	}
+
+	if group := bot.setting(scheduleGroupSettingKey); group != "" {
+		if err := bot.scheduleImporter.SetScheduleImport(bot.setting(scheduleRegionSettingKey), group); err != nil {
+			log.Errorf("Failed to start planned schedule import: %s", err)
+		}
+	}
+}
+
+// setting returns the stored setting value, empty if it is not set.
+func (bot *ElectroBot) setting(key string) string {
+	value, err := bot.db.GetSetting(key)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.WithField("key", key).Errorf("Failed to get setting: %s", err)
+	}
+
+	return value
+}
+
+// isOwnerChat returns true for the bot owner in the private chat with the bot, the wizard changes settings of the
+// whole bot and is not available to other admins.
+func (bot *ElectroBot) isOwnerChat(user *botApi.User, chat *botApi.Chat) bool {
+	userID := senderID(user)
+
+	return userID != 0 && userID == bot.OwnerChatID() && chat != nil && chat.IsPrivate()
+}
+
+func (bot *ElectroBot) handleSetupCommand(message *botApi.Message, lang string,
+) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	if !bot.isOwnerChat(message.From, message.Chat) {
+		return i18n.T(lang, "Setup is available to the bot owner in a private chat with the bot only"), nil
+	}
+
+	return bot.setupStart(lang)
+}
+
+// setupStart returns the first setup step, the schedule steps are skipped when the import is set in the config.
+func (bot *ElectroBot) setupStart(lang string) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	if bot.scheduleImporter == nil {
+		return bot.setupAdminStep(lang)
+	}
+
+	buttons := make([]botApi.InlineKeyboardButton, 0, len(scheduler.Regions))
+
+	for _, region := range scheduler.Regions {
+		buttons = append(buttons, setupButton(regionName(region, lang), setupRegion, region))
+	}
+
+	regions := botApi.NewInlineKeyboardMarkup(buttons,
+		botApi.NewInlineKeyboardRow(setupButton(i18n.T(lang, "Skip"), setupSkip, "")))
+
+	return i18n.T(lang, "Let's set up the bot. Choose the region to import the planned outage schedule of:"), &regions
+}
+
+func (bot *ElectroBot) setupGroupStep(region, lang string) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	var rows [][]botApi.InlineKeyboardButton
+
+	for i, group := range scheduler.Groups {
+		if i%setupGroupsPerRow == 0 {
+			rows = append(rows, nil)
+		}
+
+		rows[len(rows)-1] = append(rows[len(rows)-1], setupButton(group, setupGroup, region+":"+group))
+	}
+
+	rows = append(rows, botApi.NewInlineKeyboardRow(setupButton(i18n.T(lang, "Skip"), setupSkip, "")))
+	groups := botApi.NewInlineKeyboardMarkup(rows...)
+
+	return i18n.T(lang, "Choose your outage schedule group in %s:", regionName(region, lang)), &groups
+}
+
+func (bot *ElectroBot) setupAdminStep(lang string) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	lowBandwidth := bot.forceLowBandwidth.Load()
+	yearlyReport := bot.yearlyReport && bot.setting(yearlyReportEnabledSettingKey) != settingOff
+
+	text = i18n.T(lang, "Admin settings:") + "\n" + i18n.T(lang, "Low-bandwidth mode: %s", onOffText(lowBandwidth, lang))
+
+	if bot.yearlyReport {
+		text += "\n" + i18n.T(lang, "Yearly report to all users: %s", onOffText(yearlyReport, lang))
+	}
+
+	var rows [][]botApi.InlineKeyboardButton
+
+	// modes forced in the config can't be changed here
+	if !bot.configLowBandwidth {
+		rows = append(rows, botApi.NewInlineKeyboardRow(setupToggleButton(i18n.T(lang, "Low-bandwidth mode"),
+			setupLowBandwidth, lowBandwidth, lang)))
+	}
+
+	if bot.yearlyReport {
+		rows = append(rows, botApi.NewInlineKeyboardRow(setupToggleButton(i18n.T(lang, "Yearly report"),
+			setupYearlyReport, yearlyReport, lang)))
+	}
+
+	rows = append(rows, botApi.NewInlineKeyboardRow(setupButton(i18n.T(lang, "Done"), setupDone, "")))
+	settings := botApi.NewInlineKeyboardMarkup(rows...)
+
+	return text, &settings
+}
+
+// handleSetupCallback handles setup wizard buttons and returns the next step.
+func (bot *ElectroBot) handleSetupCallback(query *botApi.CallbackQuery, data string,
+) (text string, keyboard *botApi.InlineKeyboardMarkup) {
+	lang := bot.userLanguage(query.Message.Chat.ID, query.From)
+
+	if !bot.isOwnerChat(query.From, query.Message.Chat) {
+		return i18n.T(lang, "Setup is available to the bot owner in a private chat with the bot only"), nil
+	}
+
+	action, argument, _ := strings.Cut(data, ":")
+
+	switch action {
+	case setupRegion:
+		if bot.scheduleImporter == nil || !slices.Contains(scheduler.Regions, argument) {
+			break
+		}
+
+		return bot.setupGroupStep(argument, lang)
+
+	case setupGroup:
+		region, group, _ := strings.Cut(argument, ":")
+
+		if bot.scheduleImporter == nil || !slices.Contains(scheduler.Regions, region) ||
+			!slices.Contains(scheduler.Groups, group) {
+			break
+		}
+
+		if err := bot.setScheduleImport(region, group); err != nil {
+			log.Errorf("Failed to change planned schedule import: %s", err)
+
+			return i18n.T(lang, "Failed to change settings. Please try again later"), nil
+		}
+
+		text, keyboard = bot.setupAdminStep(lang)
+
+		return i18n.T(lang, "Planned schedule of group %s in %s will be imported", group, regionName(region, lang)) +
+			"\n\n" + text, keyboard
+
+	case setupSkip:
+		return bot.setupAdminStep(lang)
+
+	case setupLowBandwidth, setupYearlyReport:
+		if argument != settingOn && argument != settingOff {
+			break
+		}
+
+		if err := bot.setSetupToggle(action, argument == settingOn); err != nil {
+			log.Errorf("Failed to change setting: %s", err)
+
+			return i18n.T(lang, "Failed to change settings. Please try again later"), nil
+		}
+
+		return bot.setupAdminStep(lang)
+
+	case setupDone:
+		return i18n.T(lang, "Setup is complete. Use /setup to change these settings later"), nil
+	}
+
+	log.WithField("data", data).Warn("Unknown setup action")
+
+	return "", nil
+}
+
+// setScheduleImport starts the planned schedule import and keeps the choice for restarts.
+func (bot *ElectroBot) setScheduleImport(region, group string) error {
+	if err := bot.scheduleImporter.SetScheduleImport(region, group); err != nil {
+		return err
+	}
+
+	if err := bot.db.SetSetting(scheduleRegionSettingKey, region); err != nil {
+		return err
+	}
+
+	if err := bot.db.SetSetting(scheduleGroupSettingKey, group); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{"region": region, "group": group}).Info("Planned schedule import changed")
+
+	return nil
+}
+
+func (bot *ElectroBot) setSetupToggle(action string, enabled bool) error {
+	value := settingOff
+	if enabled {
+		value = settingOn
+	}
+
+	switch action {
+	case setupLowBandwidth:
+		if bot.configLowBandwidth {
+			return nil
+		}
+
+		if err := bot.db.SetSetting(lowBandwidthSettingKey, value); err != nil {
+			return err
+		}
+
+		bot.stateMutex.Lock()
+		backup := bot.uplinkBackup
+		bot.stateMutex.Unlock()
+
+		bot.forceLowBandwidth.Store(enabled)
+		bot.SetLowBandwidth(enabled || backup)
+
+	case setupYearlyReport:
+		if err := bot.db.SetSetting(yearlyReportEnabledSettingKey, value); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{"setting": action, "value": value}).Info("Setting changed")
+
+	return nil
+}
+
+func setupButton(text, action, argument string) botApi.InlineKeyboardButton {
+	data := setupCallbackPrefix + action
+	if argument != "" {
+		data += ":" + argument
+	}
+
+	return botApi.NewInlineKeyboardButtonData(text, data)
+}
+
+// setupToggleButton returns the button switching the setting to the opposite state.
+func setupToggleButton(name, action string, enabled bool, lang string) botApi.InlineKeyboardButton {
+	if enabled {
+		return setupButton(i18n.T(lang, "Turn off: %s", name), action, settingOff)
+	}
+
+	return setupButton(i18n.T(lang, "Turn on: %s", name), action, settingOn)
+}
+
+func onOffText(enabled bool, lang string) string {
+	if enabled {
+		return i18n.T(lang, "on")
+	}
+
+	return i18n.T(lang, "off")
+}
+
+func regionName(region, lang string) string {
+	switch region {
+	case "kiev":
+		return i18n.T(lang, "Kyiv")
+	case "dnipro":
+		return i18n.T(lang, "Dnipro")
+	default:
+		return region
+	}
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"electrobot/telegrambot"
+	"electrobot/telegramtest"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type testScheduleImporter struct {
+	sync.Mutex
+	region string
+	group  string
+}
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestSetupWizard(t *testing.T) {
+	importer := &testScheduleImporter{}
+	db := newTestDatabase(t)
+	server, bot := startTestBot(t, telegrambot.Config{ScheduleImporter: importer}, db)
+
+	if err := db.SetSetting("owner_chat_id", strconv.Itoa(adminID)); err != nil {
+		t.Fatalf("Can't set owner: %s", err)
+	}
+
+	server.SendMessage(userID, "/setup")
+	checkReply(t, server, userID, "Setup is available to the bot owner")
+
+	server.SendMessage(adminID, "/setup")
+	message := checkReply(t, server, adminID, "Let's set up the bot")
+
+	server.PressButton(userID, message.ID, "setup:region:kiev")
+	checkReply(t, server, userID, "Setup is available to the bot owner")
+
+	pressButton(t, server, message.ID, "setup:region:kiev", "Choose your outage schedule group in Kyiv:")
+	pressButton(t, server, message.ID, "setup:group:kiev:9.9", "")
+	pressButton(t, server, message.ID, "setup:group:kiev:1.2", "Planned schedule of group 1.2 in Kyiv")
+
+	if region, group := importer.get(); region != "kiev" || group != "1.2" {
+		t.Errorf("Wrong schedule import: %s %s", region, group)
+	}
+
+	message = pressButton(t, server, message.ID, "setup:lowbandwidth:on", "Admin settings:\nLow-bandwidth mode: on")
+
+	if !bot.LowBandwidth() {
+		t.Error("Low-bandwidth mode is not enabled")
+	}
+
+	if !strings.Contains(message.ReplyMarkup, "setup:lowbandwidth:off") {
+		t.Errorf("Wrong admin settings keyboard: %s", message.ReplyMarkup)
+	}
+
+	pressButton(t, server, message.ID, "setup:yearlyreport:off", "Admin settings:\nLow-bandwidth mode: on\n"+
+		"Yearly report to all users: off")
+	pressButton(t, server, message.ID, "setup:done", "Setup is complete")
+
+	// settings are applied again after restart
+	restartedImporter := &testScheduleImporter{}
+	_, restartedBot := startTestBot(t, telegrambot.Config{ScheduleImporter: restartedImporter}, db)
+
+	if region, group := restartedImporter.get(); region != "kiev" || group != "1.2" {
+		t.Errorf("Wrong schedule import after restart: %s %s", region, group)
+	}
+
+	if !restartedBot.LowBandwidth() {
+		t.Error("Low-bandwidth mode is not enabled after restart")
+	}
+}
+
+func TestSetupWizardWithoutScheduleImport(t *testing.T) {
+	db := newTestDatabase(t)
+	server, _ := startTestBot(t, telegrambot.Config{}, db)
+
+	if err := db.SetSetting("owner_chat_id", strconv.Itoa(adminID)); err != nil {
+		t.Fatalf("Can't set owner: %s", err)
+	}
+
+	server.SendMessage(adminID, "/setup")
+	message := checkReply(t, server, adminID, "Admin settings:")
+
+	// schedule import set in the config can't be changed
+	pressButton(t, server, message.ID, "setup:group:kiev:1.2", "")
+}
+
+/***********************************************************************************************************************
+ * Interfaces
+ **********************************************************************************************************************/
+
+func (importer *testScheduleImporter) SetScheduleImport(region, group string) error {
+	importer.Lock()
+	defer importer.Unlock()
+
+	importer.region, importer.group = region, group
+
+	return nil
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (importer *testScheduleImporter) get() (region, group string) {
+	importer.Lock()
+	defer importer.Unlock()
+
+	return importer.region, importer.group
+}
+
+// pressButton presses the owner's setup button and checks the message is edited to the text, empty text means the
+// button is ignored.
+func pressButton(t *testing.T, server *telegramtest.Server, messageID int, data, text string) telegramtest.Message {
+	t.Helper()
+
+	server.PressButton(adminID, messageID, data)
+
+	if text == "" {
+		if message, err := server.NextMessage(adminID, waitTimeout/10); err == nil {
+			t.Errorf("Button %s is not ignored: %q", data, message.Text)
+		}
+
+		return telegramtest.Message{}
+	}
+
+	message := checkReply(t, server, adminID, text)
+
+	if message.Method != "editMessageText" || message.ID != messageID {
+		t.Errorf("Wrong reply to button %s: %s %d", data, message.Method, message.ID)
+	}
+
+	return message
+}
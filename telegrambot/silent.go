@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// minorEvent is a notification type the chat may get without sound.
+type minorEvent struct {
+	extra notificationExtras
+	name  string
+	// description is the translatable event description shown by /silent.
+	description string
+	// silent is the default for chats without a preference.
+	silent bool
+}
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// minorEvents lists notifications which may come without sound, power off and on notifications always make sound.
+//
+//nolint:gochecknoglobals
+var minorEvents = []minorEvent{
+	{minorAdvisory, "advisory", "power has been stable long enough to turn appliances on", true},
+	{minorElevator, "elevator", "warnings not to take the elevator before planned outages", false},
+	{minorReport, "report", "the yearly outage report", true},
+	{minorOngoing, "ongoing", "updates that power is still off during long outages", true},
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleSilentCommand lists or changes sound preferences of minor notifications of the chat.
+func (bot *ElectroBot) handleSilentCommand(chatID int64, arguments, lang string) string {
+	if !bot.db.UserExists(chatID) {
+		return i18n.T(lang, "Please register with /start first")
+	}
+
+	name, value, _ := strings.Cut(strings.ToLower(strings.TrimSpace(arguments)), " ")
+	if name == "" {
+		return bot.silentNotificationsText(chatID, lang)
+	}
+
+	value = strings.TrimSpace(value)
+
+	event, ok := findMinorEvent(name)
+	if !ok || (value != "on" && value != "off") {
+		return i18n.T(lang, "Usage: /silent <event> on|off, events: %s", strings.Join(minorEventNames(), ", "))
+	}
+
+	if err := bot.db.SetSilentNotification(chatID, event.name, value == "on"); err != nil {
+		log.Errorf("Failed to store silent notification preference: %s", err)
+
+		return i18n.T(lang, "Failed to change settings. Please try again later")
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "event": event.name, "silent": value}).Info("Silent notification changed")
+
+	return bot.silentNotificationsText(chatID, lang)
+}
+
+// silentNotificationsText lists minor events with their sound in the chat.
+func (bot *ElectroBot) silentNotificationsText(chatID int64, lang string) string {
+	preferences, err := bot.db.GetSilentNotifications(chatID)
+	if err != nil {
+		log.Errorf("Failed to get silent notification preferences: %s", err)
+
+		return i18n.T(lang, "Failed to get settings. Please try again later")
+	}
+
+	text := i18n.T(lang, "Minor notifications:")
+
+	for _, event := range minorEvents {
+		silent, ok := preferences[event.name]
+		if !ok {
+			silent = event.silent
+		}
+
+		sound := i18n.T(lang, "with sound")
+		if silent {
+			sound = i18n.T(lang, "silent")
+		}
+
+		text += "\n" + event.name + " - " + i18n.T(lang, event.description) + ": " + sound
+	}
+
+	return text + "\n\n" + i18n.T(lang, "Power off and on notifications always come with sound. "+
+		"Use /silent <event> on|off to change")
+}
+
+// silentNotification returns true if the notification with the extras is sent to the chat without sound.
+func (bot *ElectroBot) silentNotification(chatID int64, extras notificationExtras) bool {
+	for _, event := range minorEvents {
+		if extras&event.extra == 0 {
+			continue
+		}
+
+		preferences, err := bot.db.GetSilentNotifications(chatID)
+		if err != nil {
+			log.Errorf("Failed to get silent notification preferences: %s", err)
+
+			return event.silent
+		}
+
+		if silent, ok := preferences[event.name]; ok {
+			return silent
+		}
+
+		return event.silent
+	}
+
+	return false
+}
+
+func findMinorEvent(name string) (event minorEvent, ok bool) {
+	for _, event := range minorEvents {
+		if event.name == name {
+			return event, true
+		}
+	}
+
+	return minorEvent{}, false
+}
+
+func minorEventNames() (names []string) {
+	for _, event := range minorEvents {
+		names = append(names, event.name)
+	}
+
+	return names
+}
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	// adherenceTolerance is how far from the planned window an outage may start or end and still be on time.
+	adherenceTolerance = 15 * time.Minute
+	defaultSLAWeeks    = 4
+	maxSLAWeeks        = 12
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// scheduleAdherence counts how the recorded outages followed planned ones.
+type scheduleAdherence struct {
+	Planned       int
+	StartedOnTime int
+	EndedOnTime   int
+	// Skipped planned outages had no recorded outage within their windows.
+	Skipped int
+	// Unplanned recorded outages didn't overlap any planned window.
+	Unplanned int
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleSLACommand reports weekly schedule adherence of the main location to the default schedule group, the current
+// week first.
+func (bot *ElectroBot) handleSLACommand(arguments, lang string, location *time.Location) string {
+	weeks := defaultSLAWeeks
+
+	if arguments = strings.TrimSpace(arguments); arguments != "" {
+		var err error
+
+		if weeks, err = strconv.Atoi(arguments); err != nil || weeks <= 0 || weeks > maxSLAWeeks {
+			return i18n.T(lang, "Usage: /sla [N], where N is the number of weeks up to %d", maxSLAWeeks)
+		}
+	}
+
+	group := bot.defaultScheduleGroup()
+	if group == "" {
+		return i18n.T(lang, "The schedule group of the bot is not set, the owner can choose it in /setup")
+	}
+
+	now := time.Now()
+	weekStart := statsPeriods(now.In(bot.defaultLocation))[1].from
+	text := i18n.T(lang, "Schedule adherence of group %s, on time is within %s:", group,
+		formatDuration(adherenceTolerance, lang))
+
+	for week := 0; week < weeks; week++ {
+		from, to := weekStart.AddDate(0, 0, -7*week), weekStart.AddDate(0, 0, 7-7*week)
+		if to.After(now) {
+			to = now
+		}
+
+		adherence, err := bot.weekAdherence(group, from, to)
+		if err != nil {
+			log.Errorf("Failed to get schedule adherence: %s", err)
+
+			return i18n.T(lang, "Failed to get outage statistics. Please try again later")
+		}
+
+		text += "\n\n" + i18n.T(lang, "Week of %s:", i18n.Date(lang, from.In(location))) + "\n" +
+			adherenceText(adherence, lang)
+	}
+
+	return text
+}
+
+// weekAdherence compares outages recorded within [from, to) with the planned outages of the group which ended
+// by then.
+func (bot *ElectroBot) weekAdherence(group string, from, to time.Time) (adherence scheduleAdherence, err error) {
+	outages, err := bot.scheduledOutages(from, to)
+	if err != nil {
+		return adherence, err
+	}
+
+	var planned []scheduledOutage
+
+	for _, outage := range outages {
+		if outage.Group == group && !outage.End.After(to) {
+			planned = append(planned, outage)
+		}
+	}
+
+	recorded, err := bot.db.GetOutagesBetween(from, to)
+	if err != nil {
+		return adherence, err
+	}
+
+	return compareWithSchedule(planned, recorded, adherenceTolerance), nil
+}
+
+// compareWithSchedule matches each planned outage with the first recorded outage overlapping its window.
+func compareWithSchedule(
+	planned []scheduledOutage, recorded []database.Outage, tolerance time.Duration,
+) (adherence scheduleAdherence) {
+	matched := make([]bool, len(recorded))
+
+	for _, plannedOutage := range planned {
+		adherence.Planned++
+
+		found := false
+
+		for i, outage := range recorded {
+			if !outage.Start.Before(plannedOutage.End) || !outage.End.After(plannedOutage.Start) {
+				continue
+			}
+
+			matched[i] = true
+
+			if found {
+				continue
+			}
+
+			found = true
+
+			if absDuration(outage.Start.Sub(plannedOutage.Start)) <= tolerance {
+				adherence.StartedOnTime++
+			}
+
+			if absDuration(outage.End.Sub(plannedOutage.End)) <= tolerance {
+				adherence.EndedOnTime++
+			}
+		}
+
+		if !found {
+			adherence.Skipped++
+		}
+	}
+
+	for _, ok := range matched {
+		if !ok {
+			adherence.Unplanned++
+		}
+	}
+
+	return adherence
+}
+
+func adherenceText(adherence scheduleAdherence, lang string) string {
+	if adherence.Planned == 0 && adherence.Unplanned == 0 {
+		return i18n.T(lang, "No planned or recorded outages")
+	}
+
+	return i18n.T(lang, "Planned: %d\nStarted on time: %s\nEnded on time: %s\nSkipped: %d\nUnplanned: %d",
+		adherence.Planned, adherencePercent(adherence.StartedOnTime, adherence.Planned),
+		adherencePercent(adherence.EndedOnTime, adherence.Planned), adherence.Skipped, adherence.Unplanned)
+}
+
+func adherencePercent(count, total int) string {
+	if total == 0 {
+		return "0"
+	}
+
+	return fmt.Sprintf("%d (%d%%)", count, count*100/total) //nolint:gomnd // percent
+}
+
+func absDuration(duration time.Duration) time.Duration {
+	if duration < 0 {
+		return -duration
+	}
+
+	return duration
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"testing"
+	"time"
+
+	"electrobot/database"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestCompareWithSchedule(t *testing.T) {
+	base := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+
+	planned := []scheduledOutage{
+		{Group: "1.1", Start: base, End: base.Add(4 * time.Hour)},
+		{Group: "1.1", Start: base.Add(8 * time.Hour), End: base.Add(12 * time.Hour)},
+		{Group: "1.1", Start: base.Add(24 * time.Hour), End: base.Add(28 * time.Hour)},
+	}
+
+	recorded := []database.Outage{
+		// on time
+		{ID: 1, Start: base.Add(5 * time.Minute), End: base.Add(4*time.Hour - 10*time.Minute)},
+		// started late and ended early
+		{ID: 2, Start: base.Add(9 * time.Hour), End: base.Add(11 * time.Hour)},
+		{ID: 3, Start: base.Add(16 * time.Hour), End: base.Add(17 * time.Hour)},
+	}
+
+	expected := scheduleAdherence{Planned: 3, StartedOnTime: 1, EndedOnTime: 1, Skipped: 1, Unplanned: 1}
+
+	if adherence := compareWithSchedule(planned, recorded, adherenceTolerance); adherence != expected {
+		t.Errorf("Wrong adherence: %+v", adherence)
+	}
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"time"
+
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+type statsPeriod struct {
+	name string
+	from time.Time
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (bot *ElectroBot) handleStatsCommand(lang string, location *time.Location) string {
+	// periods start at the user's local midnight
+	now := time.Now().In(location)
+	text := i18n.T(lang, "Outage statistics:")
+
+	for _, period := range statsPeriods(now) {
+		stats, err := bot.db.GetOutageStats(period.from, now)
+		if err != nil {
+			log.Errorf("Failed to get outage stats: %s", err)
+
+			return i18n.T(lang, "Failed to get outage statistics. Please try again later")
+		}
+
+		text += "\n\n" + i18n.T(lang, period.name) + ":"
+
+		if stats.Count == 0 {
+			text += "\n" + i18n.T(lang, "No outages")
+
+			continue
+		}
+
+		text += "\n" + i18n.T(lang, "Outages: %d\nTotal: %s\nLongest: %s\nAverage: %s", stats.Count,
+			formatDuration(stats.Total, lang), formatDuration(stats.Longest, lang),
+			formatDuration(stats.Average(), lang))
+	}
+
+	return text
+}
+
+// statsPeriods returns today, this week (starting Monday) and this month periods.
+func statsPeriods(now time.Time) []statsPeriod {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekday := (int(today.Weekday()) + 6) % 7 //nolint:gomnd // Monday is the first day of week
+
+	return []statsPeriod{
+		{name: "Today", from: today},
+		{name: "This week", from: today.AddDate(0, 0, -weekday)},
+		{name: "This month", from: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())},
+	}
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"time"
+
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// Heartbeat records the time of the last power check.
+func (bot *ElectroBot) Heartbeat(at time.Time) {
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	bot.lastCheckTime = at
+}
+
+// PowerState returns whether power is on, the time it is on or off since and the time of the last power check, zero
+// if not checked yet.
+func (bot *ElectroBot) PowerState() (on bool, since, lastCheck time.Time) {
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	return !bot.powerOff, bot.powerSince, bot.lastCheckTime
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (bot *ElectroBot) setPowerState(on bool, since time.Time) {
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	bot.powerOff, bot.powerSince = !on, since
+}
+
+// lastPowerOnTime returns the end of the latest recorded outage, or the launch time if there is none.
+func (bot *ElectroBot) lastPowerOnTime() time.Time {
+	outages, err := bot.db.GetOutages(1)
+	if err != nil {
+		log.Errorf("Failed to get last outage: %s", err)
+	}
+
+	if len(outages) == 0 {
+		return bot.launchTime
+	}
+
+	return outages[0].End
+}
+
+func (bot *ElectroBot) handleStatusCommand(lang string, location *time.Location) string {
+	powerOn, powerSince, lastCheckTime := bot.PowerState()
+
+	uptime := i18n.T(lang, "🤖 Bot uptime: %s", formatDuration(time.Since(bot.launchTime), lang))
+
+	// power state is unknown until the power monitor finishes its startup check
+	if lastCheckTime.IsZero() {
+		return i18n.T(lang, "⚪ Power state is not detected yet") + "\n" + uptime
+	}
+
+	state := i18n.T(lang, "🟢 Power is on") + bot.restoreDisputeStatus(lang)
+	if !powerOn {
+		state = i18n.T(lang, "🔴 Power is off")
+	}
+
+	return state + "\n" +
+		i18n.T(lang, "⏱ For %s, since %s", formatDuration(time.Since(powerSince), lang),
+			i18n.DateTime(lang, powerSince.In(location))) + "\n" +
+		uptime + "\n" +
+		i18n.T(lang, "🔄 Last check: %s", i18n.DateTime(lang, lastCheckTime.In(location))) +
+		bot.uplinkStatus(lang)
+}
+
+// uplinkStatus returns the status line of the uplink in use, empty until the uplink is detected.
+func (bot *ElectroBot) uplinkStatus(lang string) string {
+	bot.stateMutex.Lock()
+	uplink, backup := bot.uplink, bot.uplinkBackup
+	bot.stateMutex.Unlock()
+
+	switch {
+	case uplink == "":
+		return ""
+
+	case backup:
+		return "\n" + i18n.T(lang, "📡 Uplink: %s (backup)", uplink)
+
+	default:
+		return "\n" + i18n.T(lang, "📡 Uplink: %s", uplink)
+	}
+}
@@ -18,138 +18,629 @@ package telegrambot
 import (
 	"context"
 	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"electrobot/database"
+	"electrobot/health"
+	"electrobot/i18n"
+
+	"github.com/coreos/go-systemd/daemon"
 	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	log "github.com/sirupsen/logrus"
 )
 
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultPollTimeout             = 60
+	defaultLowBandwidthPollTimeout = 300
+	updateChannelSize              = 100
+	pollRetryDelay                 = 3 * time.Second
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with telegram bot configuration.
+type Config struct {
+	Token string
+	// APIEndpoint is the Bot API URL format with the token and method placeholders, empty means Telegram.
+	APIEndpoint string
+	// PollTimeout is the long-poll timeout in seconds, 0 means default.
+	PollTimeout int
+	// PollLimit limits the number of updates per poll, 0 means Telegram default.
+	PollLimit int
+	// AllowedUpdates lists update types to receive, empty means the types handled by the bot.
+	AllowedUpdates []string
+	// LowBandwidth starts the bot in bandwidth-frugal mode.
+	LowBandwidth bool
+	// LowBandwidthPollTimeout is the long-poll timeout used in low-bandwidth mode, 0 means default.
+	LowBandwidthPollTimeout int
+	// RestoreAdvisoryDelay is the delay after restoration before the "safe to turn appliances on" advisory,
+	// 0 disables it.
+	RestoreAdvisoryDelay time.Duration
+	// WideOutageWindow is the time power-offs are collected to report power-offs at several locations as one wide
+	// outage, 0 disables it.
+	WideOutageWindow time.Duration
+	// NotificationBatchWindow is the time power state changes of several locations are combined into one message
+	// for chats subscribed to them, 0 disables it.
+	NotificationBatchWindow time.Duration
+	// OutageUpdateInterval is the interval of "still no power" updates during outages, 0 disables them.
+	OutageUpdateInterval time.Duration
+	// DefaultLanguage is used for users with unknown language, empty means i18n default.
+	DefaultLanguage string
+	// DefaultTimezone is the IANA timezone used for users without their own, empty means Europe/Kyiv.
+	DefaultTimezone string
+	// SendAttempts is the maximum number of attempts to send a message, 0 means default.
+	SendAttempts int
+	// ScheduleOCRCommand converts schedule images sent by admins to schedule lines, empty disables the import.
+	ScheduleOCRCommand string
+	// AliveInterval is the power monitor heartbeat interval, outage times are accurate to it.
+	AliveInterval time.Duration
+	// WatchdogInterval is the period of systemd watchdog notifications from the update handler loop, 0 disables them.
+	WatchdogInterval time.Duration
+	// Admins lists user IDs allowed to use admin commands in addition to the owner.
+	Admins []int64
+	// Health provides subsystem states for the /health command, optional.
+	Health HealthProvider
+	// DisableYearlyReport turns off the yearly report sent to all users in January.
+	DisableYearlyReport bool
+	// Channels lists channel IDs or @usernames power announcements are also published to.
+	Channels []string
+	// ChannelFlapWindow is the time after a channel announcement the next one is appended to it by an edit,
+	// 0 means default.
+	ChannelFlapWindow time.Duration
+	// ScheduleImporter applies the planned schedule import chosen in the setup wizard, nil hides the schedule steps.
+	ScheduleImporter ScheduleImporter
+	// ScheduleGroup is the outage schedule group of chats without their own, empty means the group chosen in the
+	// setup wizard.
+	ScheduleGroup string
+	// Updater installs releases with /update, nil disables the command.
+	Updater Updater
+	// Restarter restarts the bot with /restart and after /update, nil disables both.
+	Restarter Restarter
+	// Logs provides the latest log lines for /logs, nil disables the command.
+	Logs LogSource
+}
+
+// HealthProvider provides subsystem states.
+type HealthProvider interface {
+	States() []health.SubsystemState
+}
+
 type Storage interface {
-	UpdateEvent(eventType, event string) error
-	NewEvent(eventType, event string) error
+	RecordUplinkChange(iface string, backup bool) error
+	RecordUserUnregistered(userID int64, reason string) error
 	StoreUserInfo(botApi.Message) error
 	UserExists(int64) bool
 	RemoveUserInfo(int64) error
+	MigrateChat(oldChatID, newChatID int64) error
+	GetLocations() ([]database.Location, error)
+	GetLocation(name string) (database.Location, error)
+	AddLocation(name string) (id int64, err error)
+	EnsureLocation(name string) (id int64, err error)
+	RenameLocation(name, newName string) error
+	RemoveLocation(name string) error
+	SetHeartbeatToken(name, hash string) error
+	RemoveHeartbeatToken(name string) error
+	SetHeartbeatPaused(name string, paused bool) error
+	SetLocationParent(name, parent, phase string) error
+	SetHeartbeatClockSkew(name string, skew time.Duration) error
+	GetHeartbeatLocations() ([]database.HeartbeatLocation, error)
+	Subscribe(chatID, locationID int64) error
+	Unsubscribe(chatID, locationID int64) error
+	GetSubscriptions(chatID int64) ([]database.Location, error)
+	GetSubscribers(locationID int64) ([]int64, error)
 	GetAllUsers() ([]int64, error)
-	GetLatestEventDateTime(eventType string) (dateTime time.Time, err error)
+	GetUsers() ([]database.User, error)
+	GetStats() (database.Stats, error)
+	GetOutages(limit int) ([]database.Outage, error)
+	GetOutage(id int64) (database.Outage, error)
+	GetOutageStats(from, to time.Time) (database.OutageStats, error)
+	GetOutagesBetween(from, to time.Time) ([]database.Outage, error)
+	GetOutageArchives(from, to time.Time) ([]database.OutageArchive, error)
+	GetSetting(key string) (value string, err error)
+	SetSetting(key, value string) error
+	DeleteSetting(key string) error
+	GetDialog(chatID int64) (dialog database.Dialog, err error)
+	SetDialog(chatID int64, name, step string) error
+	RemoveDialog(chatID int64) error
+	SetUserNote(userID int64, note string) error
+	AddUserTag(userID int64, tag string) error
+	RemoveUserTag(userID int64, tag string) error
+	GetTaggedUsers(tag string) (users []int64, err error)
+	SetOutageCause(outageID int64, cause string) error
+	GetSilentNotifications(userID int64) (silent map[string]bool, err error)
+	SetSilentNotification(userID int64, event string, silent bool) error
+	GetFeatureFlags() (flags []database.FeatureFlag, err error)
+	SetFeatureFlag(name string, percent int) error
+	AddFeatureFlagChat(name string, chatID int64) error
+	RemoveFeatureFlagChat(name string, chatID int64) error
+	RecordVariantSent(variant string, count int) error
+	RecordVariantEngaged(variant string) error
+	GetVariantStats() (stats []database.VariantStats, err error)
+	AddReminder(userID int64, task string) (id int64, err error)
+	AddRecurringReminder(userID int64, task string, minutes int) (id int64, err error)
+	GetReminders(userID int64) ([]database.Reminder, error)
+	GetRecurringReminders() ([]database.RecurringReminder, error)
+	RemoveReminder(userID, id int64) error
+	RemoveReminders(userID int64, ids []int64) error
+	GetUserLanguage(userID int64) (language string, err error)
+	SetUserLanguage(userID int64, language string) error
+	GetUserTimezone(userID int64) (timezone string, err error)
+	SetUserTimezone(userID int64, timezone string) error
+	GetUserPlainText(userID int64) (enabled bool, err error)
+	SetUserPlainText(userID int64, enabled bool) error
+	GetUserScheduleGroup(userID int64) (group string, err error)
+	SetUserScheduleGroup(userID int64, group string) error
+	SetUserElevatorWarning(userID int64, minutes int) error
+	GetElevatorWarnings() ([]database.ElevatorWarning, error)
+	GetUserUtilities(userID int64) (database.Utilities, error)
+	SetUserUtilities(userID int64, utilities database.Utilities) error
+	GetUserChecklist(userID int64) (database.Checklist, error)
+	SetUserChecklist(userID int64, items string, enabled bool) error
+	GetChecklists() ([]database.Checklist, error)
+	SetScheduleDay(group string, weekday time.Weekday, windows string, changedBy int64) (version int64, err error)
+	GetSchedule() ([]database.ScheduleDay, error)
+	GetScheduleChanges(limit int) ([]database.ScheduleDay, error)
+	SetScheduleException(group, date string, windows *string, note string, changedBy int64) error
+	GetScheduleExceptions(from string) ([]database.ScheduleException, error)
+	GetPlannedSchedules(from string) ([]database.PlannedSchedule, error)
+	AddPendingMessage(message database.PendingMessage) error
+	HasPendingMessages(chatID int64, priority database.MessagePriority) (bool, error)
+	GetPendingMessages(limit int) ([]database.PendingMessage, error)
+	RemovePendingMessage(id int64) error
+	AddAPIToken(name, hash, scope string) error
+	GetAPITokens() ([]database.APIToken, error)
+	RemoveAPIToken(name string) error
+	SetWebhookSecret(source, secret string) error
+	GetWebhookSecrets() ([]database.WebhookSecret, error)
+	RemoveWebhookSecret(source string) error
+	Backup(fileName string) error
+}
+
+// SettingsStorage provides settings, it is enough to find the owner without starting the bot.
+type SettingsStorage interface {
+	GetSetting(key string) (value string, err error)
 }
 
 type ElectroBot struct {
-	botApi           *botApi.BotAPI
-	updateChannel    botApi.UpdatesChannel
-	updateConfig     botApi.UpdateConfig
-	db               Storage
-	cancelFunc       context.CancelFunc
-	launchTime       time.Time
-	lastShutdownTime time.Time
+	botApi                  *botApi.BotAPI
+	updateChannel           chan botApi.Update
+	replies                 chan botApi.Chattable
+	queueWakeup             chan struct{}
+	updateConfig            botApi.UpdateConfig
+	lowBandwidthPollTimeout int
+	lowBandwidth            atomic.Bool
+	forceLowBandwidth       atomic.Bool
+	configLowBandwidth      bool
+	yearlyReport            bool
+	scheduleImporter        ScheduleImporter
+	updater                 Updater
+	restarter               Restarter
+	logs                    LogSource
+	scheduleGroup           string
+	health                  HealthProvider
+	claimMutex              sync.Mutex
+	claimCode               string
+	claimFailures           int
+	claimBlockedUntil       time.Time
+	admins                  []int64
+	channelMutex            sync.Mutex
+	channels                []channel
+	channelFlapWindow       atomicDuration
+	defaultLanguage         string
+	defaultLocation         *time.Location
+	restoreAdvisoryDelay    atomicDuration
+	restoreAdvisoryTimer    *time.Timer
+	serviceMonitor          ServiceMonitor
+	serviceReportDelay      time.Duration
+	serviceReportTimer      *time.Timer
+	outageUpdateInterval    atomicDuration
+	outageUpdateTimer       *time.Timer
+	notificationBatchWindow atomicDuration
+	batchMutex              sync.Mutex
+	batches                 map[int64]*notificationBatch
+	wideOutageWindow        atomicDuration
+	powerOffMutex           sync.Mutex
+	pendingPowerOffs        []pendingPowerOff
+	powerOffTimer           *time.Timer
+	sendAttempts            int
+	scheduleOCRCommand      string
+	watchdogInterval        time.Duration
+	tunableMutex            sync.Mutex
+	tunables                map[string]tunable
+	dialogs                 map[string]dialog
+	flagMutex               sync.Mutex
+	featureFlags            map[string]database.FeatureFlag
+	aliveInterval           time.Duration
+	scheduleImports         map[int64][]scheduleChange
+	confirmationMutex       sync.Mutex
+	restoreConfirmations    map[int64]*restoreConfirmation
+	db                      Storage
+	ctx                     context.Context //nolint:containedctx // interrupts send retries on close
+	cancelFunc              context.CancelFunc
+	launchTime              time.Time
+	stateMutex              sync.Mutex
+	lastShutdownTime        time.Time
+	powerOff                bool
+	powerSince              time.Time
+	lastCheckTime           time.Time
+	uplink                  string
+	uplinkBackup            bool
+	uplinkDetected          bool
+	sendMutex               sync.Mutex
+	nextSendTime            time.Time
+	pollMutex               sync.Mutex
+	lastPollTime            time.Time
+	lastPollErr             error
 }
 
-func New(token string, storage Storage) (bot *ElectroBot, err error) {
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+func New(config Config, storage Storage) (bot *ElectroBot, err error) {
 	bot = &ElectroBot{
-		db:           storage,
-		updateConfig: botApi.UpdateConfig{Offset: 0, Timeout: 60},
-		launchTime:   time.Now().Local(),
+		db:                      storage,
+		updateConfig:            newUpdateConfig(config),
+		updateChannel:           make(chan botApi.Update, updateChannelSize),
+		replies:                 make(chan botApi.Chattable, updateChannelSize),
+		queueWakeup:             make(chan struct{}, 1),
+		lowBandwidthPollTimeout: config.LowBandwidthPollTimeout,
+		health:                  config.Health,
+		admins:                  config.Admins,
+		defaultLanguage:         config.DefaultLanguage,
+		defaultLocation:         loadDefaultLocation(config.DefaultTimezone),
+		sendAttempts:            config.SendAttempts,
+		scheduleOCRCommand:      config.ScheduleOCRCommand,
+		watchdogInterval:        config.WatchdogInterval,
+		aliveInterval:           config.AliveInterval,
+		scheduleImports:         make(map[int64][]scheduleChange),
+		restoreConfirmations:    make(map[int64]*restoreConfirmation),
+		batches:                 make(map[int64]*notificationBatch),
+		tunables:                make(map[string]tunable),
+		configLowBandwidth:      config.LowBandwidth,
+		yearlyReport:            !config.DisableYearlyReport,
+		scheduleImporter:        config.ScheduleImporter,
+		updater:                 config.Updater,
+		restarter:               config.Restarter,
+		logs:                    config.Logs,
+		scheduleGroup:           config.ScheduleGroup,
+		launchTime:              time.Now(),
 	}
 
-	bot.botApi, err = botApi.NewBotAPI(token)
-	if err != nil {
+	// updated by power monitor notifications
+	bot.lastShutdownTime = bot.launchTime
+	bot.lastPollTime = time.Now()
+
+	if !i18n.IsSupported(bot.defaultLanguage) {
+		bot.defaultLanguage = i18n.DefaultLanguage
+	}
+
+	if bot.channels, err = parseChannels(config.Channels); err != nil {
 		return nil, err
 	}
 
-	if bot.lastShutdownTime, err = bot.getLastAliveTime(); err != nil {
-		log.Warnf("Failed to get last alive time: %s", err)
+	bot.restoreAdvisoryDelay.Store(config.RestoreAdvisoryDelay)
+	bot.wideOutageWindow.Store(config.WideOutageWindow)
+	bot.outageUpdateInterval.Store(config.OutageUpdateInterval)
+	bot.notificationBatchWindow.Store(config.NotificationBatchWindow)
+	bot.channelFlapWindow.Store(config.ChannelFlapWindow)
 
-		bot.lastShutdownTime = time.Now().Local()
+	if config.ChannelFlapWindow <= 0 {
+		bot.channelFlapWindow.Store(defaultChannelFlapWindow)
 	}
 
-	if err = bot.notifyAllUsers(); err != nil {
-		log.Errorf("Failed to notify all users on start: %s", err)
+	if bot.sendAttempts <= 0 {
+		bot.sendAttempts = defaultSendAttempts
+	}
 
+	if bot.lowBandwidthPollTimeout <= 0 {
+		bot.lowBandwidthPollTimeout = defaultLowBandwidthPollTimeout
+	}
+
+	bot.forceLowBandwidth.Store(config.LowBandwidth)
+	bot.lowBandwidth.Store(config.LowBandwidth)
+
+	apiEndpoint := config.APIEndpoint
+	if apiEndpoint == "" {
+		apiEndpoint = botApi.APIEndpoint
+	}
+
+	bot.botApi, err = botApi.NewBotAPIWithAPIEndpoint(config.Token, apiEndpoint)
+	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancelFunction := context.WithCancel(context.Background())
-	bot.cancelFunc = cancelFunction
+	bot.initOwnershipClaim()
+	bot.applySetupSettings()
+	bot.registerTunables()
+	bot.loadFeatureFlags()
+	bot.registerDialogs()
 
-	bot.updateChannel = bot.botApi.GetUpdatesChan(bot.updateConfig)
+	bot.ctx, bot.cancelFunc = context.WithCancel(context.Background())
 
-	go bot.handler(ctx)
+	go bot.pollUpdates(bot.ctx)
+	go bot.handler(bot.ctx)
+	go bot.runReplies(bot.ctx)
+	go bot.runQueue(bot.ctx)
+	go bot.runUpcomingOutages(bot.ctx)
+
+	if bot.yearlyReport {
+		go bot.runYearlyReport(bot.ctx)
+	}
+
+	if len(bot.channels) != 0 {
+		go bot.runChannelSampling(bot.ctx)
+	}
 
 	return bot, nil
 }
 
-func (bot *ElectroBot) Close() {
-	bot.botApi.StopReceivingUpdates()
+// NotifyChat sends a single message without starting the bot, used to report fatal errors.
+func NotifyChat(token string, chatID int64, text string) error {
+	api, err := botApi.NewBotAPI(token)
+	if err != nil {
+		return err
+	}
+
+	_, err = api.Send(botApi.NewMessage(chatID, text))
 
+	return err
+}
+
+func (bot *ElectroBot) Close() {
+	// collected power-offs and batches are not lost on shutdown
+	bot.flushPowerOffs()
+	bot.flushBatches()
 	bot.cancelFunc()
+
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	if bot.restoreAdvisoryTimer != nil {
+		bot.restoreAdvisoryTimer.Stop()
+	}
+
+	if bot.outageUpdateTimer != nil {
+		bot.outageUpdateTimer.Stop()
+	}
+
+	if bot.serviceReportTimer != nil {
+		bot.serviceReportTimer.Stop()
+	}
 }
 
-func (bot *ElectroBot) getLastAliveTime() (time.Time, error) {
-	return bot.db.GetLatestEventDateTime("Bot is alive")
+// CheckTelegram verifies that Telegram Bot API is reachable and the token is valid.
+func (bot *ElectroBot) CheckTelegram() error {
+	_, err := bot.botApi.GetMe()
+
+	return err
 }
 
-func (bot *ElectroBot) notifyAllUsers() error {
-	text := "Bot started at " + bot.launchTime.Local().Format("2006-01-02 15:04:05") +
-		"\nLast alive time: " + bot.lastShutdownTime.Local().Format("2006-01-02 15:04:05")
+// SetLowBandwidth switches bandwidth-frugal mode on or off, takes effect on the next poll.
+func (bot *ElectroBot) SetLowBandwidth(enabled bool) {
+	if bot.lowBandwidth.Swap(enabled) != enabled {
+		log.WithField("enabled", enabled).Info("Low-bandwidth mode changed")
+	}
+}
 
-	users, err := bot.db.GetAllUsers()
-	if err != nil {
-		log.Errorf("Failed to get all users: %s", err)
+// LowBandwidth returns true if bandwidth-frugal mode is active.
+func (bot *ElectroBot) LowBandwidth() bool {
+	return bot.lowBandwidth.Load()
+}
 
-		return err
+// UplinkChanged records uplink change and enables low-bandwidth mode while on a backup uplink. Admins are alerted
+// when the uplink fails over to a backup one and back.
+func (bot *ElectroBot) UplinkChanged(iface string, backup bool) {
+	if err := bot.db.RecordUplinkChange(iface, backup); err != nil {
+		log.Errorf("Failed to store uplink event: %s", err)
+	}
+
+	// no default route is a transient state between uplinks, the failover is detected when the next one is up
+	if iface == "" {
+		return
 	}
 
-	for _, user := range users {
-		log.WithFields(log.Fields{"user": user}).Debug("Notifying user on start")
+	bot.SetLowBandwidth(backup || bot.forceLowBandwidth.Load())
 
-		msg := botApi.NewMessage(user, text)
+	bot.stateMutex.Lock()
+	previous, wasBackup, detected := bot.uplink, bot.uplinkBackup, bot.uplinkDetected
+	bot.uplink, bot.uplinkBackup, bot.uplinkDetected = iface, backup, true
+	bot.stateMutex.Unlock()
 
-		if _, err := bot.botApi.Send(msg); err != nil {
-			log.Errorf("Failed to send message to user %d: %s", user, err)
+	// the first detection after start is not a failover
+	if !detected || wasBackup == backup {
+		return
+	}
+
+	bot.notifyAdmins(func(lang string, _ *time.Location) string {
+		if backup {
+			return i18n.T(lang, "⚠️ Uplink failed over from %s to backup %s, low-bandwidth mode is on", previous, iface)
 		}
+
+		return i18n.T(lang, "✅ Uplink is back on %s", iface)
+	})
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func newUpdateConfig(config Config) botApi.UpdateConfig {
+	updateConfig := botApi.UpdateConfig{
+		Offset:         0,
+		Timeout:        config.PollTimeout,
+		Limit:          config.PollLimit,
+		AllowedUpdates: config.AllowedUpdates,
+	}
+
+	if updateConfig.Timeout <= 0 {
+		updateConfig.Timeout = defaultPollTimeout
+	}
+
+	// request only the update types the bot handles to save bandwidth
+	if len(updateConfig.AllowedUpdates) == 0 {
+		updateConfig.AllowedUpdates = []string{botApi.UpdateTypeMessage, botApi.UpdateTypeCallbackQuery}
 	}
 
-	return nil
+	return updateConfig
 }
 
-func (bot *ElectroBot) handleLastShutdownCommand() string {
-	return "Last shutdown time is " + bot.lastShutdownTime.Local().Format("2006-01-02 15:04:05")
+// notifyWatchdog pings systemd watchdog unless the update poll loop is stalled, so systemd restarts the bot
+// if either the handler or the poll loop hangs.
+func (bot *ElectroBot) notifyWatchdog() {
+	if err := bot.CheckUpdateLoop(); err != nil {
+		log.Errorf("Skipping watchdog notification: %s", err)
+
+		return
+	}
+
+	if _, err := daemon.SdNotify(false, daemon.SdNotifyWatchdog); err != nil {
+		log.Errorf("Can't notify systemd watchdog: %s", err)
+	}
+}
+
+func (bot *ElectroBot) handleLastShutdownCommand(lang string, location *time.Location) string {
+	bot.stateMutex.Lock()
+	defer bot.stateMutex.Unlock()
+
+	return i18n.T(lang, "Last shutdown time is %s", i18n.DateTime(lang, bot.lastShutdownTime.In(location)))
 }
 
-func (bot *ElectroBot) handleStartCommand(userID int64, messageBody *botApi.Message) string {
-	exists := bot.db.UserExists(userID)
+// handleStartCommand registers the chat and returns the menu, new private chats are onboarded first and get the menu
+// at the end of the onboarding.
+func (bot *ElectroBot) handleStartCommand(chatID int64, messageBody *botApi.Message, lang string,
+) (text string, keyboard botApi.InlineKeyboardMarkup) {
+	exists := bot.db.UserExists(chatID)
 	if exists {
-		return "You're already registered"
+		return i18n.T(lang, "You're already registered"), menuKeyboard(lang)
 	}
 
 	err := bot.db.StoreUserInfo(*messageBody)
 	if err != nil {
 		log.Errorf("Failed to store user info: %s", err)
 
-		return "Failed to register you. Please try again later"
+		return i18n.T(lang, "Failed to register you. Please try again later"), menuKeyboard(lang)
 	}
 
-	return "You've been successfully registered"
+	if err = bot.db.SetUserLanguage(chatID, lang); err != nil {
+		log.Errorf("Failed to store user language: %s", err)
+	}
+
+	text = i18n.T(lang, "You've been successfully registered")
+
+	if messageBody.Chat.IsPrivate() {
+		if stepText, stepKeyboard := bot.startOnboarding(chatID, lang); stepKeyboard != nil {
+			return text + "\n\n" + stepText, *stepKeyboard
+		}
+	}
+
+	return text, menuKeyboard(lang)
 }
 
-func (bot *ElectroBot) handleStopCommand(userID int64) string {
-	err := bot.db.RemoveUserInfo(userID)
+func (bot *ElectroBot) handleStopCommand(chatID int64, lang string) string {
+	err := bot.db.RemoveUserInfo(chatID)
 	if err != nil {
 		log.Errorf("Failed to remove user info: %s", err)
 
-		return "Failed to unregister you. Please try again later"
+		return i18n.T(lang, "Failed to unregister you. Please try again later")
+	}
+
+	return i18n.T(lang, "You've been successfully unregistered")
+}
+
+func (bot *ElectroBot) handleHelpCommand(userID int64, lang string) string {
+	lines := []string{
+		"Type /start to get started",
+		"Type /stop to stop receiving notifications",
+		"Type /menu to open the menu",
+		"Type /status to get the current power state",
+		"Type /lastshutdown to get the last shutdown time",
+		"Type /history [N] to get the last N outages",
+		"Type /stats to get outage statistics",
+		"Type /sla [N] to see how outages followed the schedule in the last N weeks",
+		"Type /forecast to get likely outage windows estimated from the outage history",
+		"Type /report [year] to get the yearly outage report",
+		"Type /chart [week|month] to get the outage chart",
+		"Type /remindme <task> to be reminded about it when power returns",
+		"Type /remindme before <minutes> <task> to be reminded about it before each planned outage",
+		"Type /language to change the language",
+		"Type /timezone <zone> to change the timezone",
+		"Type /plaintext on|off to get messages without emoji and buttons",
+		"Type /silent to choose minor notifications sent without sound",
+		"Type /locations to choose monitored locations",
+		"Type /schedule to get the outage schedule",
+		"Type /group <group> to set your outage schedule group",
+		"Type /elevator <minutes>|off to be warned not to take the elevator before planned outages",
+		"Type /utilities to warn about water and heating depending on electricity",
+		"Type /checklist to change the checklist sent before long planned outages",
+		"Type /feedback to send feedback or a complaint to admins",
+		"Type /health to get the bot subsystems state",
+	}
+
+	if bot.isAdmin(userID) {
+		lines = append(lines,
+			"Admin commands:",
+			"/users - list registered users",
+			"/broadcast [#tag] <text> - send an announcement to all or tagged users",
+			"/user - manage notes and tags of users",
+			"/dbstats - show database statistics",
+			"/schedule set|except|history - edit the outage schedule",
+			"/token - manage API tokens",
+			"/webhook - manage inbound webhook secrets",
+			"/location - manage monitored locations",
+			"/sensors - manage heartbeat sensors",
+			"/backup [force] - send a database backup",
+			"/update [force] - install the latest release and restart",
+			"/restart - restart the bot",
+			"/logs [N] - get the last N log lines",
+			"/config get|set|reset - change config values at runtime",
+			"/flags - roll out experimental features",
+			"/annotate <ID> <cause> - annotate an outage with its cause")
+	}
+
+	if userID != 0 && userID == bot.OwnerChatID() {
+		lines = append(lines, "/setup - change the bot setup")
 	}
 
-	return "You've been successfully unregistered"
+	for i, line := range lines {
+		lines[i] = i18n.T(lang, line)
+	}
+
+	return strings.Join(lines, "\n")
 }
 
-func (bot *ElectroBot) handleHelpCommand() string {
-	return "Type /start to get started" +
-		"\nType /stop to stop receiving notifications" +
-		"\nType /lastshutdown to get the last shutdown time"
+func (bot *ElectroBot) handleHealthCommand(lang string) string {
+	if bot.health == nil {
+		return i18n.T(lang, "Health information is not available")
+	}
+
+	text := i18n.T(lang, "Subsystems state:")
+
+	for _, state := range bot.health.States() {
+		if state.Err != nil {
+			text += "\n" + i18n.T(lang, "DEGRADED %s: %s", state.Name, state.Err.Error())
+		} else {
+			text += "\n" + i18n.T(lang, "OK %s", state.Name)
+		}
+	}
+
+	return text
 }
 
 func (bot *ElectroBot) handleTGMessageCommand(updateMessage *botApi.Message) {
+	if !bot.isAddressedToBot(updateMessage) {
+		return
+	}
+
 	chatStr, err := json.Marshal(updateMessage.Chat)
 	if err != nil {
 		log.Errorf("Failed to marshal chat info: %s", err)
@@ -160,43 +651,148 @@ func (bot *ElectroBot) handleTGMessageCommand(updateMessage *botApi.Message) {
 	msg := botApi.NewMessage(updateMessage.Chat.ID, "")
 	msg.ReplyToMessageID = updateMessage.MessageID
 
+	chatID := updateMessage.Chat.ID
+	lang := bot.userLanguage(chatID, updateMessage.From)
+	location := bot.userLocation(chatID)
+
 	switch updateMessage.Command() {
+	case "status":
+		msg.Text = bot.handleStatusCommand(lang, location)
 	case "lastshutdown":
-		msg.Text = bot.handleLastShutdownCommand()
+		msg.Text = bot.handleLastShutdownCommand(lang, location)
 	case "start":
-		msg.Text = bot.handleStartCommand(updateMessage.Chat.ID, updateMessage)
+		msg.Text, msg.ReplyMarkup = bot.handleStartCommand(chatID, updateMessage, lang)
+	case "menu":
+		msg.Text = i18n.T(lang, "Choose an option:")
+		msg.ReplyMarkup = menuKeyboard(lang)
 	case "stop":
-		msg.Text = bot.handleStopCommand(updateMessage.Chat.ID)
-	case "help":
+		msg.Text = bot.handleStopCommand(chatID, lang)
+	case "history":
+		msg.Text = bot.handleHistoryCommand(updateMessage.CommandArguments(), lang, location)
+	case "stats":
+		msg.Text = bot.handleStatsCommand(lang, location)
+	case "forecast":
+		msg.Text = bot.handleForecastCommand(chatID, lang, location)
+	case "chart":
+		var photo *botApi.PhotoConfig
+
+		if photo, msg.Text = bot.handleChartCommand(chatID, updateMessage.CommandArguments(), lang,
+			location); photo != nil {
+			photo.ReplyToMessageID = updateMessage.MessageID
+
+			bot.reply(*photo)
+
+			return
+		}
+	case "report":
+		msg.Text = bot.handleReportCommand(updateMessage.CommandArguments(), lang, location)
+	case "sla":
+		msg.Text = bot.handleSLACommand(updateMessage.CommandArguments(), lang, location)
+	case "health":
+		msg.Text = bot.handleHealthCommand(lang)
+	case "remindme":
+		msg.Text = bot.handleRemindMeCommand(updateMessage, lang)
+	case "claim":
+		var claimed bool
+
+		// the new owner goes straight to the setup wizard
+		if msg.Text, claimed = bot.handleClaimCommand(updateMessage, updateMessage.CommandArguments(),
+			lang); claimed {
+			text, keyboard := bot.setupStart(lang)
+
+			msg.Text += "\n\n" + text
+			msg.ReplyMarkup = *keyboard
+		}
+	case "setup":
+		var keyboard *botApi.InlineKeyboardMarkup
+
+		if msg.Text, keyboard = bot.handleSetupCommand(updateMessage, lang); keyboard != nil {
+			msg.ReplyMarkup = *keyboard
+		}
+	case "language":
+		msg.Text, msg.ReplyMarkup = bot.handleLanguageCommand(lang)
+	case "timezone":
+		msg.Text = bot.handleTimezoneCommand(chatID, updateMessage.CommandArguments(), lang, location)
+	case "plaintext":
+		msg.Text = bot.handlePlainTextCommand(chatID, updateMessage.CommandArguments(), lang)
+	case "silent":
+		msg.Text = bot.handleSilentCommand(chatID, updateMessage.CommandArguments(), lang)
+	case "locations":
+		msg.Text = bot.handleLocationsCommand(chatID, lang)
+	case "subscribe", "unsubscribe":
+		msg.Text = bot.handleSubscribeCommand(chatID, updateMessage.CommandArguments(), lang,
+			updateMessage.Command() == "subscribe")
+	case "schedule":
+		if isScheduleEditCommand(updateMessage.CommandArguments()) {
+			msg.Text = bot.handleAdminCommand(updateMessage, "schedule", updateMessage.CommandArguments(), lang)
+		} else {
+			msg.Text = bot.handleScheduleCommand(lang, location)
+		}
+	case "group":
+		msg.Text = bot.handleGroupCommand(chatID, updateMessage.CommandArguments(), lang)
+	case "elevator":
+		msg.Text = bot.handleElevatorCommand(chatID, updateMessage.CommandArguments(), lang)
+	case "utilities":
+		msg.Text = bot.handleUtilitiesCommand(updateMessage, updateMessage.CommandArguments(), lang)
+	case "checklist":
+		msg.Text = bot.handleChecklistCommand(updateMessage, updateMessage.CommandArguments(), lang)
+	case "feedback":
+		msg.Text = bot.handleFeedbackCommand(updateMessage, lang)
+	case "cancel":
+		msg.Text = bot.handleCancelCommand(chatID, lang)
+	case "users", "broadcast", "dbstats", "token", "webhook", "backup", "location", "sensors", "update",
+		"restart", "logs", "config", "flags", "user", "annotate":
+		msg.Text = bot.handleAdminCommand(updateMessage, updateMessage.Command(), updateMessage.CommandArguments(),
+			lang)
 	default:
-		msg.Text = bot.handleHelpCommand()
+		msg.Text = bot.handleHelpCommand(senderID(updateMessage.From), lang)
+		msg.ReplyMarkup = menuKeyboard(lang)
 	}
 
-	if _, err := bot.botApi.Send(msg); err != nil {
-		log.Errorf("Failed to send message: %s", err)
-	}
+	bot.reply(msg)
 }
 
 func (bot *ElectroBot) handler(ctx context.Context) {
-	log.WithField("Approximate lat shutdown time", bot.lastShutdownTime.Local().Format("2006-01-02 15:04:05")).Info("Bot was has been started")
+	log.Info("Bot has been started")
 
-	bot.updateIsAliveState()
+	// nil channel never fires when the watchdog is disabled
+	var watchdog <-chan time.Time
 
-	updateStateTicker := time.NewTicker(5 * time.Second)
-	defer updateStateTicker.Stop()
+	if bot.watchdogInterval > 0 {
+		ticker := time.NewTicker(bot.watchdogInterval)
+		defer ticker.Stop()
+
+		watchdog = ticker.C
+	}
 
 	for {
 		select {
-		case <-updateStateTicker.C:
-			bot.updateIsAliveState()
+		case <-watchdog:
+			bot.notifyWatchdog()
 
 		case update := <-bot.updateChannel:
+			if update.CallbackQuery != nil {
+				bot.handleCallbackQuery(update.CallbackQuery)
+
+				continue
+			}
+
 			if update.Message == nil {
 				continue
 			}
 
+			if update.Message.MigrateToChatID != 0 {
+				bot.migrateChat(update.Message.Chat.ID, update.Message.MigrateToChatID)
+
+				continue
+			}
+
 			if update.Message.IsCommand() {
 				bot.handleTGMessageCommand(update.Message)
+			} else if scheduleImageFileID(update.Message) != "" {
+				go bot.handleScheduleImage(update.Message)
+			} else if update.Message.Chat.IsPrivate() {
+				bot.handleDialogMessage(update.Message)
 			}
 
 		case <-ctx.Done():
@@ -206,17 +802,3 @@ func (bot *ElectroBot) handler(ctx context.Context) {
 		}
 	}
 }
-
-func (bot *ElectroBot) updateIsAliveState() {
-	log.Debug("Bot is alive")
-
-	err := bot.db.UpdateEvent("Bot is alive", "Bot is alive")
-	if err == nil {
-		return
-	}
-
-	err = bot.db.NewEvent("Bot is alive", "Bot is alive")
-	if err != nil {
-		log.Errorf("Failed to store event due to DB error: %s", err)
-	}
-}
@@ -0,0 +1,499 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"electrobot/database"
+	"electrobot/logtail"
+	"electrobot/telegrambot"
+	"electrobot/telegramtest"
+
+	log "github.com/sirupsen/logrus"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	adminID     = 1
+	userID      = 2
+	waitTimeout = 5 * time.Second
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestRegistration(t *testing.T) {
+	server, bot, db := newTestBot(t, telegrambot.Config{})
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	if !db.UserExists(userID) {
+		t.Error("User is not registered")
+	}
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You're already registered")
+
+	bot.PowerOff(time.Now())
+	checkReply(t, server, userID, "Power went off at")
+
+	server.SendMessage(userID, "/stop")
+	checkReply(t, server, userID, "You've been successfully unregistered")
+
+	if db.UserExists(userID) {
+		t.Error("User is still registered")
+	}
+}
+
+func TestAdminCommands(t *testing.T) {
+	server, _, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.SendMessage(userID, "/users")
+	checkReply(t, server, userID, "Sorry, this command is available to admins only")
+
+	server.SendMessage(adminID, "/users")
+	checkReply(t, server, adminID, "Registered users (1):")
+}
+
+func TestLogsCommand(t *testing.T) {
+	logs := logtail.New(10)
+	server, _, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}, Logs: logs})
+
+	logger := log.New()
+	logger.SetFormatter(&log.TextFormatter{DisableTimestamp: true})
+	logger.AddHook(logs)
+	logger.Info("first")
+	logger.Info("second")
+
+	server.SendMessage(adminID, "/logs 0")
+	checkReply(t, server, adminID, "Usage: /logs [N]")
+
+	server.SendMessage(adminID, "/logs 1")
+
+	if message := checkReply(t, server, adminID, "level=info"); !strings.Contains(message.Text, "second") ||
+		strings.Contains(message.Text, "first") {
+		t.Errorf("Wrong log lines: %s", message.Text)
+	}
+}
+
+func TestConfigCommand(t *testing.T) {
+	server, _, db := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(adminID, "/config set wideOutageWindow 2h")
+	checkReply(t, server, adminID, "wideOutageWindow must be a duration from 0s to 1h0m0s")
+
+	server.SendMessage(adminID, "/config set wideOutageWindow 2m")
+	checkReply(t, server, adminID, "wideOutageWindow is 2m0s now")
+
+	if value, err := db.GetSetting("config.wideOutageWindow"); err != nil || value != "2m0s" {
+		t.Errorf("Wrong stored override: %s %v", value, err)
+	}
+
+	server.SendMessage(adminID, "/config get wideOutageWindow")
+	checkReply(t, server, adminID, "Config values:\nwideOutageWindow = 2m0s (config file: 0s)")
+
+	server.SendMessage(adminID, "/config reset wideOutageWindow")
+	checkReply(t, server, adminID, "wideOutageWindow is 0s now")
+
+	if _, err := db.GetSetting("config.wideOutageWindow"); err == nil {
+		t.Error("Override is not removed")
+	}
+
+	server.SendMessage(adminID, "/config get unknown")
+	checkReply(t, server, adminID, "Unknown config value")
+}
+
+func TestFeatureFlags(t *testing.T) {
+	server, _, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.SendMessage(adminID, "/flags set forecast off")
+	checkReply(t, server, adminID, "forecast is enabled for 0% of chats")
+
+	server.SendMessage(userID, "/forecast")
+	checkReply(t, server, userID, "This feature is not available in this chat yet")
+
+	server.SendMessage(adminID, "/flags add forecast 2")
+	checkReply(t, server, adminID, "forecast is enabled for chat 2")
+
+	server.SendMessage(userID, "/forecast")
+
+	if message := checkReply(t, server, userID, ""); strings.HasPrefix(message.Text, "This feature") {
+		t.Errorf("Feature is not enabled for the added chat: %s", message.Text)
+	}
+
+	server.SendMessage(adminID, "/flags")
+	checkReply(t, server, adminID, "Experimental features:\ncompactwording: 0% of chats\nforecast: 0% of chats and chats 2")
+
+	server.SendMessage(adminID, "/flags set unknown on")
+	checkReply(t, server, adminID, "Unknown feature")
+}
+
+func TestAnnotateOutage(t *testing.T) {
+	server, _, db := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	start := time.Now().Add(-2 * time.Hour)
+
+	if err := db.RecordPowerOff(start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("Can't record outage: %s", err)
+	}
+
+	outages, err := db.GetOutages(1)
+	if err != nil || len(outages) != 1 {
+		t.Fatalf("Can't get outage: %v %v", outages, err)
+	}
+
+	id := strconv.FormatInt(outages[0].ID, 10)
+
+	server.SendMessage(adminID, "/annotate")
+	checkReply(t, server, adminID, "Usage: /annotate <ID> <cause>|off, e.g. /annotate 12 emergency repairs\n\n"+
+		"Last 1 outage:\n"+id+": ")
+
+	server.SendMessage(adminID, "/annotate "+id+" substation damage")
+	checkReply(t, server, adminID, "Cause of outage "+id+" set")
+
+	server.SendMessage(adminID, "/history")
+
+	if message := checkReply(t, server, adminID, "Last 1 outage:"); !strings.HasSuffix(message.Text,
+		"(1 hour) - substation damage") {
+		t.Errorf("Wrong history: %q", message.Text)
+	}
+
+	server.SendMessage(adminID, "/annotate 999 scheduled")
+	checkReply(t, server, adminID, "Outage not found")
+
+	server.SendMessage(adminID, "/annotate "+id+" off")
+	checkReply(t, server, adminID, "Cause of outage "+id+" removed")
+
+	server.SendMessage(userID, "/annotate "+id+" scheduled")
+
+	if message := checkReply(t, server, userID, ""); strings.HasPrefix(message.Text, "Cause") {
+		t.Errorf("Outage annotated by user: %q", message.Text)
+	}
+}
+
+func TestElevatorCommand(t *testing.T) {
+	server, _, db := newTestBot(t, telegrambot.Config{ScheduleGroup: "1.1"})
+
+	server.SendMessage(userID, "/elevator 15")
+	checkReply(t, server, userID, "Please register with /start first")
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.SendMessage(userID, "/elevator 15")
+	checkReply(t, server, userID, "Elevator warnings before planned outages of group 1.1 are on")
+
+	server.SendMessage(userID, "/group 2.1")
+	checkReply(t, server, userID, "Your outage schedule group is 2.1")
+
+	server.SendMessage(userID, "/elevator 1000")
+	checkReply(t, server, userID, "Usage: /elevator")
+
+	warnings, err := db.GetElevatorWarnings()
+	if err != nil {
+		t.Fatalf("Can't get elevator warnings: %s", err)
+	}
+
+	if len(warnings) != 1 || warnings[0].Group != "2.1" || warnings[0].Minutes != 15 {
+		t.Errorf("Wrong elevator warnings: %v", warnings)
+	}
+
+	server.SendMessage(userID, "/group off")
+	checkReply(t, server, userID, "You follow the bot outage schedule group 1.1")
+
+	server.SendMessage(userID, "/elevator off")
+	checkReply(t, server, userID, "Elevator warnings are off")
+
+	if warnings, err = db.GetElevatorWarnings(); err != nil || len(warnings) != 0 {
+		t.Errorf("Elevator warnings are not turned off: %v %v", warnings, err)
+	}
+}
+
+func TestChecklistCommand(t *testing.T) {
+	server, _, db := newTestBot(t, telegrambot.Config{})
+
+	server.SendMessage(userID, "/checklist")
+	checkReply(t, server, userID, "Please register with /start first")
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	server.SendMessage(userID, "/checklist")
+	checkReply(t, server, userID, "Before planned outages of 4 hours or longer this chat gets the checklist:\n"+
+		"- charge phones, power banks and laptops\n- fill water bottles\n- prepare flashlights")
+
+	server.SendMessage(userID, "/checklist set charge the station;  - fill the tank ;")
+	checkReply(t, server, userID, "Before planned outages of 4 hours or longer this chat gets the checklist:\n"+
+		"- charge the station\n- fill the tank")
+
+	server.SendMessage(userID, "/checklist off")
+	checkReply(t, server, userID, "Checklists before long planned outages are off")
+
+	checklist, err := db.GetUserChecklist(userID)
+	if err != nil {
+		t.Fatalf("Can't get checklist: %s", err)
+	}
+
+	if checklist.Enabled || checklist.Items != "charge the station\nfill the tank" {
+		t.Errorf("Wrong stored checklist: %+v", checklist)
+	}
+
+	server.SendMessage(userID, "/checklist reset")
+	checkReply(t, server, userID, "Checklists before long planned outages are off")
+
+	server.SendMessage(userID, "/checklist on")
+	checkReply(t, server, userID, "Before planned outages of 4 hours or longer this chat gets the checklist:\n"+
+		"- charge phones")
+
+	server.SendMessage(userID, "/checklist help")
+	checkReply(t, server, userID, "Usage:")
+}
+
+func TestUtilities(t *testing.T) {
+	server, bot, _ := newTestBot(t, telegrambot.Config{})
+
+	for _, chatID := range []int64{userID, adminID} {
+		server.SendMessage(chatID, "/start")
+		checkReply(t, server, chatID, "You've been successfully registered")
+	}
+
+	server.SendMessage(userID, "/utilities water on")
+	checkReply(t, server, userID, "Power off notifications of this chat warn about:\n- water")
+
+	server.SendMessage(userID, "/utilities note Floors 10+ have no water")
+	checkReply(t, server, userID, "Power off notifications of this chat warn about:\n- water\n- note:")
+
+	server.SendMessage(userID, "/utilities heating maybe")
+	checkReply(t, server, userID, "Usage:")
+
+	bot.PowerOff(time.Now())
+
+	if message := checkReply(t, server, userID, "Power went off at"); !strings.HasSuffix(message.Text,
+		"expect no water during the outage\nFloors 10+ have no water") {
+		t.Errorf("Wrong power off notification with utilities: %q", message.Text)
+	}
+
+	if message := checkReply(t, server, adminID, "Power went off at"); strings.Contains(message.Text, "\n") {
+		t.Errorf("Wrong power off notification without utilities: %q", message.Text)
+	}
+}
+
+func TestClaimOwnership(t *testing.T) {
+	hook := logTest.NewGlobal()
+	defer hook.Reset()
+
+	server, _, _ := newTestBot(t, telegrambot.Config{})
+
+	code := ""
+
+	for _, entry := range hook.AllEntries() {
+		if value, ok := entry.Data["code"].(string); ok {
+			code = value
+		}
+	}
+
+	if code == "" {
+		t.Fatal("Claim code is not logged")
+	}
+
+	server.SendMessage(userID, "/users")
+	checkReply(t, server, userID, "Sorry, this command is available to admins only")
+
+	server.SendMessage(userID, "/claim 0000")
+	checkReply(t, server, userID, "Invalid claim code")
+
+	// the next attempt is rate limited even with the right code
+	server.SendMessage(userID, "/claim "+code)
+	checkReply(t, server, userID, "Too many invalid claim attempts")
+
+	time.Sleep(time.Second)
+
+	server.SendMessage(userID, "/claim "+code)
+	// the new owner is taken to the setup wizard
+	if message := checkReply(t, server, userID, "You are now the owner of this bot"); !strings.Contains(
+		message.Text, "Admin settings:") || !strings.Contains(message.ReplyMarkup, "setup:done") {
+		t.Errorf("Setup wizard is not started: %q %s", message.Text, message.ReplyMarkup)
+	}
+
+	server.SendMessage(userID, "/users")
+	checkReply(t, server, userID, "There are no registered users")
+
+	server.SendMessage(adminID, "/claim "+code)
+	checkReply(t, server, adminID, "This bot already has an owner")
+}
+
+func TestSelfTestSummary(t *testing.T) {
+	server, bot, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	bot.SelfTestCompleted("OK   database writable\nFAIL schedule source: timeout")
+
+	if message := checkReply(t, server, adminID, "🩺 Self-test summary:"); !strings.Contains(
+		message.Text, "FAIL schedule source") {
+		t.Errorf("Wrong summary: %q", message.Text)
+	}
+
+	if message, err := server.NextMessage(userID, waitTimeout/10); err == nil {
+		t.Errorf("Summary is sent to user: %q", message.Text)
+	}
+}
+
+func TestUplinkFailover(t *testing.T) {
+	server, bot, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	server.SendMessage(adminID, "/start")
+	checkReply(t, server, adminID, "You've been successfully registered")
+
+	// the default route disappears for a moment while the modem comes up
+	bot.UplinkChanged("eth0", false)
+	bot.UplinkChanged("", false)
+	bot.UplinkChanged("wwan0", true)
+
+	checkReply(t, server, adminID, "⚠️ Uplink failed over from eth0 to backup wwan0")
+
+	if !bot.LowBandwidth() {
+		t.Error("Low-bandwidth mode is not enabled on backup uplink")
+	}
+
+	bot.UplinkChanged("eth0", false)
+	checkReply(t, server, adminID, "✅ Uplink is back on eth0")
+}
+
+func TestSendRetry(t *testing.T) {
+	config := telegrambot.Config{SendAttempts: 2}
+	server, _, _ := newTestBot(t, config)
+
+	server.FailNext("sendMessage", 429, "Too Many Requests: retry after 1", 1)
+
+	start := time.Now()
+
+	server.SendMessage(userID, "/start")
+	checkReply(t, server, userID, "You've been successfully registered")
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Retry after is not respected: %s", elapsed)
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// newTestBot starts the bot connected to a fake Bot API server with a fresh database.
+func newTestBot(
+	t *testing.T, config telegrambot.Config,
+) (*telegramtest.Server, *telegrambot.ElectroBot, *database.Database) {
+	t.Helper()
+
+	db := newTestDatabase(t)
+	server, bot := startTestBot(t, config, db)
+
+	return server, bot, db
+}
+
+func newTestDatabase(t *testing.T) *database.Database {
+	t.Helper()
+
+	log.SetLevel(log.WarnLevel)
+
+	db, err := database.New(database.Config{WorkingDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Can't create database: %s", err)
+	}
+
+	t.Cleanup(db.Close)
+
+	return db
+}
+
+// startTestBot starts the bot with the storage connected to a fake Bot API server.
+func startTestBot(
+	t *testing.T, config telegrambot.Config, storage telegrambot.Storage,
+) (*telegramtest.Server, *telegrambot.ElectroBot) {
+	t.Helper()
+
+	server := telegramtest.NewServer()
+	t.Cleanup(server.Close)
+
+	return server, startServerBot(t, server, config, storage)
+}
+
+// startServerBot starts the bot with the storage connected to the fake Bot API server prepared by the test.
+func startServerBot(
+	t *testing.T, server *telegramtest.Server, config telegrambot.Config, storage telegrambot.Storage,
+) *telegrambot.ElectroBot {
+	t.Helper()
+
+	botConfig := server.BotConfig()
+	botConfig.Admins = config.Admins
+	botConfig.ScheduleImporter = config.ScheduleImporter
+	botConfig.Channels = config.Channels
+	botConfig.DefaultLanguage = "en"
+	botConfig.ChannelFlapWindow = config.ChannelFlapWindow
+	botConfig.RestoreAdvisoryDelay = config.RestoreAdvisoryDelay
+	botConfig.WideOutageWindow = config.WideOutageWindow
+	botConfig.OutageUpdateInterval = config.OutageUpdateInterval
+	botConfig.NotificationBatchWindow = config.NotificationBatchWindow
+	botConfig.Logs = config.Logs
+	botConfig.ScheduleGroup = config.ScheduleGroup
+
+	if config.SendAttempts != 0 {
+		botConfig.SendAttempts = config.SendAttempts
+	}
+
+	bot, err := telegrambot.New(botConfig, storage)
+	if err != nil {
+		t.Fatalf("Can't create bot: %s", err)
+	}
+
+	t.Cleanup(bot.Close)
+
+	return bot
+}
+
+// checkReply checks the next message to the chat starts with the text and returns it.
+func checkReply(t *testing.T, server *telegramtest.Server, chatID int64, text string) telegramtest.Message {
+	t.Helper()
+
+	message, err := server.NextMessage(chatID, waitTimeout)
+	if err != nil {
+		t.Fatalf("Can't get reply: %s", err)
+	}
+
+	if !strings.HasPrefix(message.Text, text) {
+		t.Errorf("Wrong reply: %q", message.Text)
+	}
+
+	return message
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+	"time"
+	// embedded zone database, target devices may have no tzdata installed
+	_ "time/tzdata"
+
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const defaultTimezone = "Europe/Kyiv"
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// loadDefaultLocation returns configured default location, falls back to defaultTimezone if it is invalid.
+func loadDefaultLocation(timezone string) *time.Location {
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err == nil {
+			return location
+		}
+
+		log.Warnf("Invalid default timezone %q, using %s: %s", timezone, defaultTimezone, err)
+	}
+
+	location, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		log.Errorf("Failed to load timezone %s: %s", defaultTimezone, err)
+
+		return time.Local
+	}
+
+	return location
+}
+
+// userLocation returns stored user timezone or the default one.
+func (bot *ElectroBot) userLocation(chatID int64) *time.Location {
+	timezone, err := bot.db.GetUserTimezone(chatID)
+	if err != nil || timezone == "" {
+		return bot.defaultLocation
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Errorf("Failed to load user timezone %q: %s", timezone, err)
+
+		return bot.defaultLocation
+	}
+
+	return location
+}
+
+func (bot *ElectroBot) handleTimezoneCommand(chatID int64, arguments, lang string, location *time.Location) string {
+	timezone := strings.TrimSpace(arguments)
+	if timezone == "" {
+		return i18n.T(lang, "Your timezone is %s, local time is %s\nUsage: /timezone <zone>, e.g. /timezone %s",
+			location, i18n.DateTime(lang, time.Now().In(location)), defaultTimezone)
+	}
+
+	// "Local" and "" are valid for time.LoadLocation but mean the server zone
+	location, err := time.LoadLocation(timezone)
+	if err != nil || strings.EqualFold(timezone, "Local") {
+		return i18n.T(lang, "Unknown timezone %q, use a name like %s", timezone, defaultTimezone)
+	}
+
+	if !bot.db.UserExists(chatID) {
+		return i18n.T(lang, "Please register with /start first")
+	}
+
+	if err = bot.db.SetUserTimezone(chatID, location.String()); err != nil {
+		log.Errorf("Failed to store user timezone: %s", err)
+
+		return i18n.T(lang, "Failed to change timezone. Please try again later")
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "timezone": location}).Info("User timezone changed")
+
+	return i18n.T(lang, "Timezone has been set to %s, local time is %s",
+		location, i18n.DateTime(lang, time.Now().In(location)))
+}
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"electrobot/schedule"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	upcomingOutageCheckInterval = time.Minute
+	// upcomingOutageLookahead bounds lead times of warnings sent before planned outages.
+	upcomingOutageLookahead = 24 * time.Hour
+	clockFormat             = "15:04"
+	// nextPlannedOutageLookahead covers the weekly schedule.
+	nextPlannedOutageLookahead = 7 * 24 * time.Hour
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// scheduledOutage is a planned outage of the group, windows continuing each other over midnight are merged.
+type scheduledOutage struct {
+	Group string
+	Start time.Time
+	End   time.Time
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// NextPlannedOutage returns the planned outage of the bot schedule group in progress at now or the next one within a
+// week, false if there is none or the group is not set.
+func (bot *ElectroBot) NextPlannedOutage(now time.Time) (start, end time.Time, ok bool) {
+	group := bot.defaultScheduleGroup()
+	if group == "" {
+		return start, end, false
+	}
+
+	outages, err := bot.scheduledOutages(now.Add(-upcomingOutageLookahead), now.Add(nextPlannedOutageLookahead))
+	if err != nil {
+		log.Errorf("Failed to get planned outages: %s", err)
+
+		return start, end, false
+	}
+
+	for _, outage := range outages {
+		if outage.Group == group && outage.End.After(now) {
+			return outage.Start, outage.End, true
+		}
+	}
+
+	return start, end, false
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// runUpcomingOutages sends warnings before planned outages, warnings due while the bot was stopped are skipped.
+func (bot *ElectroBot) runUpcomingOutages(ctx context.Context) {
+	ticker := time.NewTicker(upcomingOutageCheckInterval)
+	defer ticker.Stop()
+
+	checked := time.Now()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			bot.warnUpcomingOutages(checked, now)
+			checked = now
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// warnUpcomingOutages sends warnings due after from up to to.
+func (bot *ElectroBot) warnUpcomingOutages(from, to time.Time) {
+	outages, err := bot.scheduledOutages(from, to.Add(upcomingOutageLookahead))
+	if err != nil {
+		log.Errorf("Failed to get upcoming outages: %s", err)
+
+		return
+	}
+
+	bot.sendElevatorWarnings(outages, from, to)
+	bot.sendRecurringReminders(outages, from, to)
+	bot.sendChecklists(outages, from, to)
+}
+
+// scheduledOutages returns planned outages of all groups starting within [from, to) ordered by start. Schedule
+// dates and windows are in the default timezone.
+func (bot *ElectroBot) scheduledOutages(from, to time.Time) (outages []scheduledOutage, err error) {
+	first := from.In(bot.defaultLocation)
+	// windows of the previous day tell whether an outage at midnight continues one started before
+	day := time.Date(first.Year(), first.Month(), first.Day()-1, 0, 0, 0, 0, bot.defaultLocation)
+
+	byGroup := make(map[string][]scheduledOutage)
+
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		groups, err := bot.scheduledWindows(day)
+		if err != nil {
+			return nil, err
+		}
+
+		for group, text := range groups {
+			windows, err := schedule.ParseWindows(text)
+			if err != nil {
+				log.WithField("group", group).Errorf("Invalid scheduled windows: %s", err)
+
+				continue
+			}
+
+			for _, window := range windows {
+				start, end := window.At(day)
+				byGroup[group] = append(byGroup[group], scheduledOutage{Group: group, Start: start, End: end})
+			}
+		}
+	}
+
+	for _, groupOutages := range byGroup {
+		for i, outage := range groupOutages {
+			if i > 0 && !outage.Start.After(groupOutages[i-1].End) {
+				continue
+			}
+
+			// windows of a day are sorted and don't overlap, only the next day windows may continue the outage
+			for _, next := range groupOutages[i+1:] {
+				if next.Start.After(outage.End) {
+					break
+				}
+
+				outage.End = next.End
+			}
+
+			if !outage.Start.Before(from) && outage.Start.Before(to) {
+				outages = append(outages, outage)
+			}
+		}
+	}
+
+	sort.Slice(outages, func(i, j int) bool {
+		if !outages[i].Start.Equal(outages[j].Start) {
+			return outages[i].Start.Before(outages[j].Start)
+		}
+
+		return outages[i].Group < outages[j].Group
+	})
+
+	return outages, nil
+}
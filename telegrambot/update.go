@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Updater stages a verified release of the bot, it is installed on restart.
+type Updater interface {
+	StageUpdate() error
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleUpdateCommand downloads the release in the background, the bot restarts when it is verified.
+func (bot *ElectroBot) handleUpdateCommand(chatID int64, arguments, lang string) string {
+	if bot.updater == nil || bot.restarter == nil {
+		return i18n.T(lang, "Self-update is not configured")
+	}
+
+	if bot.LowBandwidth() && strings.TrimSpace(arguments) != "force" {
+		return i18n.T(lang, "The bot is on a backup uplink, the update is not downloaded to save traffic. "+
+			"Use /update force to download it anyway")
+	}
+
+	go func() {
+		if err := bot.updater.StageUpdate(); err != nil {
+			log.Errorf("Failed to stage update: %s", err)
+
+			if _, err := bot.send(botApi.NewMessage(chatID, i18n.T(lang, "Update failed: %s", err))); err != nil {
+				log.Errorf("Failed to send update report: %s", err)
+			}
+
+			return
+		}
+
+		log.WithField("chatID", chatID).Info("Update staged, restarting")
+
+		if _, err := bot.send(botApi.NewMessage(chatID, i18n.T(lang,
+			"Update downloaded and verified, restarting to install it"))); err != nil {
+			log.Errorf("Failed to send update report: %s", err)
+		}
+
+		bot.restarter.Restart()
+	}()
+
+	return i18n.T(lang, "Downloading the update")
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// pollStallMargin is added to the poll timeout before the update loop is considered stalled.
+const pollStallMargin = 30 * time.Second
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// CheckUpdateLoop returns an error if update polling hasn't completed a poll for longer than expected.
+func (bot *ElectroBot) CheckUpdateLoop() error {
+	bot.pollMutex.Lock()
+	lastPollTime := bot.lastPollTime
+	bot.pollMutex.Unlock()
+
+	maxGap := time.Duration(bot.currentPollTimeout())*time.Second + pollStallMargin
+
+	if gap := time.Since(lastPollTime); gap > maxGap {
+		return fmt.Errorf("update loop stalled for %s", gap.Round(time.Second))
+	}
+
+	return nil
+}
+
+// CheckTelegramReachable returns the error of the last update poll, if any.
+func (bot *ElectroBot) CheckTelegramReachable() error {
+	bot.pollMutex.Lock()
+	defer bot.pollMutex.Unlock()
+
+	return bot.lastPollErr
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// pollUpdates is used instead of botApi.GetUpdatesChan so the poll parameters can change at runtime.
+func (bot *ElectroBot) pollUpdates(ctx context.Context) {
+	updateConfig := bot.updateConfig
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		default:
+		}
+
+		updateConfig.Timeout = bot.currentPollTimeout()
+
+		updates, err := bot.botApi.GetUpdates(updateConfig)
+
+		bot.setPollResult(err)
+
+		if err != nil {
+			log.Errorf("Failed to get updates: %s", err)
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-time.After(pollRetryDelay):
+			}
+
+			continue
+		}
+
+		for _, update := range updates {
+			if update.UpdateID < updateConfig.Offset {
+				continue
+			}
+
+			updateConfig.Offset = update.UpdateID + 1
+
+			select {
+			case bot.updateChannel <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (bot *ElectroBot) currentPollTimeout() int {
+	if bot.LowBandwidth() {
+		return bot.lowBandwidthPollTimeout
+	}
+
+	return bot.updateConfig.Timeout
+}
+
+func (bot *ElectroBot) setPollResult(err error) {
+	bot.pollMutex.Lock()
+	defer bot.pollMutex.Unlock()
+
+	// deliver queued messages as soon as Telegram is reachable again
+	if bot.lastPollErr != nil && err == nil {
+		bot.wakeQueue()
+	}
+
+	bot.lastPollTime = time.Now()
+	bot.lastPollErr = err
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	maxUserNoteLength = 200
+	maxUserTagLength  = 32
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleUserCommand manages admin notes and tags of users and finds users by them.
+func (bot *ElectroBot) handleUserCommand(chatID int64, arguments, lang string) string {
+	target, rest, _ := strings.Cut(strings.TrimSpace(arguments), " ")
+	action, value, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	value = strings.TrimSpace(value)
+
+	if target == "find" && rest != "" {
+		return bot.findUsers(chatID, strings.TrimSpace(rest), lang)
+	}
+
+	userID, err := strconv.ParseInt(target, 10, 64)
+	if err != nil || value == "" {
+		return i18n.T(lang, "Usage:\n/user find <text> - find users by name, note or tag"+
+			"\n/user <ID> note <text>|off - set the note of the user"+
+			"\n/user <ID> tag <tag> - tag the user, /broadcast #tag <text> sends to tagged users"+
+			"\n/user <ID> untag <tag> - remove the tag of the user")
+	}
+
+	switch action {
+	case "note":
+		if len([]rune(value)) > maxUserNoteLength {
+			return i18n.T(lang, "Note is too long, please keep it under %s",
+				i18n.N(lang, maxUserNoteLength, "%d character|%d characters"))
+		}
+
+		if value == settingOff {
+			value = ""
+		}
+
+		err = bot.db.SetUserNote(userID, value)
+
+	case "tag", "untag":
+		tag := strings.TrimPrefix(value, "#")
+		if tag == "" || len(tag) > maxUserTagLength || strings.ContainsAny(tag, " \n") {
+			return i18n.T(lang, "Tags are up to %s without spaces",
+				i18n.N(lang, maxUserTagLength, "%d character|%d characters"))
+		}
+
+		if action == "tag" {
+			err = bot.db.AddUserTag(userID, tag)
+		} else {
+			err = bot.db.RemoveUserTag(userID, tag)
+		}
+
+	default:
+		return bot.handleUserCommand(chatID, "", lang)
+	}
+
+	if err != nil {
+		log.WithField("userID", userID).Errorf("Failed to change user %s: %s", action, err)
+
+		return i18n.T(lang, "Failed to change user %d, check the ID with /users", userID)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "userID": userID, action: value}).Info("User changed")
+
+	return bot.findUsers(chatID, strconv.FormatInt(userID, 10), lang)
+}
+
+// findUsers lists users with the text in the ID, name, note or tags, a list longer than the message limit is sent
+// in several messages and the last part is returned.
+func (bot *ElectroBot) findUsers(chatID int64, text, lang string) string {
+	users, err := bot.db.GetUsers()
+	if err != nil {
+		log.Errorf("Failed to get users: %s", err)
+
+		return i18n.T(lang, "Failed to get users. Please try again later")
+	}
+
+	var found []string
+
+	text = strings.ToLower(strings.TrimPrefix(text, "#"))
+
+	for _, user := range users {
+		if line := userLine(user); strings.Contains(strings.ToLower(line), text) {
+			found = append(found, line)
+		}
+	}
+
+	if len(found) == 0 {
+		return i18n.T(lang, "No users found")
+	}
+
+	parts := splitText(i18n.T(lang, "Found users (%d):", len(found)) + "\n" + strings.Join(found, "\n"))
+
+	for _, part := range parts[:len(parts)-1] {
+		bot.reply(botApi.NewMessage(chatID, part))
+	}
+
+	return parts[len(parts)-1]
+}
+
+// userLine returns the user ID, name, chat type, tags and note.
+func userLine(user database.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+
+	if user.Username != "" {
+		name = strings.TrimSpace(name + " @" + user.Username)
+	}
+
+	if user.ChatType != "" && user.ChatType != "private" {
+		name = strings.TrimSpace(name + " (" + user.ChatType + ")")
+	}
+
+	for _, tag := range user.Tags {
+		name += " #" + tag
+	}
+
+	if user.Note != "" {
+		name += " - " + user.Note
+	}
+
+	return fmt.Sprintf("%d %s", user.ID, name)
+}
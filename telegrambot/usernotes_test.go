@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot_test
+
+import (
+	"strings"
+	"testing"
+
+	"electrobot/telegrambot"
+)
+
+/***********************************************************************************************************************
+ * Tests
+ **********************************************************************************************************************/
+
+func TestUserTags(t *testing.T) {
+	server, _, _ := newTestBot(t, telegrambot.Config{Admins: []int64{adminID}})
+
+	for _, chatID := range []int64{userID, adminID} {
+		server.SendMessage(chatID, "/start")
+		checkReply(t, server, chatID, "You've been successfully registered")
+	}
+
+	server.SendMessage(adminID, "/user 2 tag Podil")
+	checkReply(t, server, adminID, "Found users (1):\n2 ")
+
+	server.SendMessage(adminID, "/user 2 note pays for the generator")
+
+	if message := checkReply(t, server, adminID, "Found users (1):\n2 "); !strings.HasSuffix(message.Text,
+		"#Podil - pays for the generator") {
+		t.Errorf("Wrong user line: %q", message.Text)
+	}
+
+	server.SendMessage(adminID, "/user 99 tag Podil")
+	checkReply(t, server, adminID, "Failed to change user 99")
+
+	server.SendMessage(adminID, "/user find #podil")
+	checkReply(t, server, adminID, "Found users (1):\n2 ")
+
+	server.SendMessage(adminID, "/broadcast #obolon Hello")
+	checkReply(t, server, adminID, "No users are tagged #obolon")
+
+	server.SendMessage(adminID, "/broadcast #podil Hello")
+	checkReply(t, server, adminID, "Broadcast started")
+	checkReply(t, server, userID, "Hello")
+	checkReply(t, server, adminID, "Broadcast finished: 1 delivered")
+
+	server.SendMessage(adminID, "/user 2 untag podil")
+	checkReply(t, server, adminID, "Found users (1):\n2 ")
+
+	server.SendMessage(adminID, "/user find #podil")
+	checkReply(t, server, adminID, "No users found")
+}
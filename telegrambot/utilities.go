@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"strings"
+
+	"electrobot/database"
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	utilityWater        = "water"
+	utilityHeating      = "heating"
+	utilityNote         = "note"
+	maxOutageNoteLength = 200
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleUtilitiesCommand shows or changes utilities of the chat building depending on electricity, in groups only
+// administrators may change them.
+func (bot *ElectroBot) handleUtilitiesCommand(message *botApi.Message, arguments, lang string) string {
+	chatID := message.Chat.ID
+
+	if !bot.db.UserExists(chatID) {
+		return i18n.T(lang, "Please register with /start first")
+	}
+
+	utilities, err := bot.db.GetUserUtilities(chatID)
+	if err != nil {
+		log.Errorf("Failed to get utilities: %s", err)
+
+		return i18n.T(lang, "Failed to change utilities. Please try again later")
+	}
+
+	name, value, _ := strings.Cut(strings.TrimSpace(arguments), " ")
+	value = strings.TrimSpace(value)
+
+	switch {
+	case name == "":
+		return bot.utilitiesSettingsText(utilities, lang)
+
+	case (name == utilityWater || name == utilityHeating) && (value == settingOn || value == settingOff):
+		if name == utilityWater {
+			utilities.Water = value == settingOn
+		} else {
+			utilities.Heating = value == settingOn
+		}
+
+	case name == utilityNote && value != "":
+		if len([]rune(value)) > maxOutageNoteLength {
+			return i18n.T(lang, "Note is too long, please keep it under %s",
+				i18n.N(lang, maxOutageNoteLength, "%d character|%d characters"))
+		}
+
+		if value == settingOff {
+			value = ""
+		}
+
+		utilities.Note = value
+
+	default:
+		return i18n.T(lang, "Usage:\n/utilities - show utilities depending on electricity"+
+			"\n/utilities water on|off - water pumps depend on electricity"+
+			"\n/utilities heating on|off - heating depends on electricity"+
+			"\n/utilities note <text>|off - note added to power off notifications")
+	}
+
+	if !bot.canConfigureChat(message.From, message.Chat) {
+		return i18n.T(lang, "Only group administrators can change utilities of the group")
+	}
+
+	if err = bot.db.SetUserUtilities(chatID, utilities); err != nil {
+		log.Errorf("Failed to store utilities: %s", err)
+
+		return i18n.T(lang, "Failed to change utilities. Please try again later")
+	}
+
+	log.WithFields(log.Fields{
+		"chatID": chatID, "water": utilities.Water, "heating": utilities.Heating, "note": utilities.Note,
+	}).Info("Chat utilities changed")
+
+	return bot.utilitiesSettingsText(utilities, lang)
+}
+
+func (bot *ElectroBot) utilitiesSettingsText(utilities database.Utilities, lang string) string {
+	text := i18n.T(lang, "Power off notifications of this chat warn about:")
+
+	if utilities.Water {
+		text += "\n" + i18n.T(lang, "- water")
+	}
+
+	if utilities.Heating {
+		text += "\n" + i18n.T(lang, "- heating")
+	}
+
+	if utilities.Note != "" {
+		text += "\n" + i18n.T(lang, "- note: %s", utilities.Note)
+	}
+
+	if !utilities.Water && !utilities.Heating && utilities.Note == "" {
+		return i18n.T(lang, "Power off notifications of this chat don't warn about utilities, see /utilities help")
+	}
+
+	return text
+}
+
+// utilitiesText returns warnings about utilities of the chat building to append to the power off notification.
+func (bot *ElectroBot) utilitiesText(chatID int64, lang string) string {
+	utilities, err := bot.db.GetUserUtilities(chatID)
+	if err != nil {
+		log.WithField("chatID", chatID).Errorf("Failed to get utilities: %s", err)
+
+		return ""
+	}
+
+	text := ""
+
+	if utilities.Water {
+		text += "\n" + i18n.T(lang, "🚱 Water pumps depend on electricity, expect no water during the outage")
+	}
+
+	if utilities.Heating {
+		text += "\n" + i18n.T(lang, "🌡 Heating depends on electricity, expect no heating during the outage")
+	}
+
+	if utilities.Note != "" {
+		text += "\n" + utilities.Note
+	}
+
+	if text == "" {
+		return ""
+	}
+
+	return "\n" + text
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"time"
+
+	"electrobot/i18n"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Wording variants of power notifications, chats with the compact wording feature get the compact variant.
+const (
+	variantControl = "control"
+	variantCompact = "compact"
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// notificationVariant returns the wording variant of the chat.
+func (bot *ElectroBot) notificationVariant(chatID int64) string {
+	if bot.featureEnabled(featureCompactWording, chatID) {
+		return variantCompact
+	}
+
+	return variantControl
+}
+
+// notifyLocationVariants sends each subscriber of the location the text of its wording variant, see notifyLocation,
+// and counts notifications sent per variant.
+func (bot *ElectroBot) notifyLocationVariants(locationID int64,
+	texts map[string]func(lang string, location *time.Location) string,
+	extras notificationExtras, keyboard func(lang string) *botApi.InlineKeyboardMarkup,
+) (delivered, queued, failed int) {
+	users, err := bot.db.GetSubscribers(locationID)
+	if err != nil {
+		log.WithField("location", locationID).Errorf("Failed to get location subscribers: %s", err)
+
+		return 0, 0, 0
+	}
+
+	variantUsers := make(map[string][]int64)
+
+	for _, user := range users {
+		variant := bot.notificationVariant(user)
+		variantUsers[variant] = append(variantUsers[variant], user)
+	}
+
+	for variant, chats := range variantUsers {
+		variantDelivered, variantQueued, variantFailed := bot.notifyUsers(chats,
+			bot.locationText(locationID, texts[variant]), extras, keyboard)
+
+		delivered, queued, failed = delivered+variantDelivered, queued+variantQueued, failed+variantFailed
+
+		if err = bot.db.RecordVariantSent(variant, variantDelivered+variantQueued); err != nil {
+			log.WithField("variant", variant).Errorf("Failed to record notifications sent: %s", err)
+		}
+	}
+
+	return delivered, queued, failed
+}
+
+// recordVariantEngaged counts engagement with a notification in the wording variant of the chat.
+func (bot *ElectroBot) recordVariantEngaged(chatID int64) {
+	if err := bot.db.RecordVariantEngaged(bot.notificationVariant(chatID)); err != nil {
+		log.WithField("chatID", chatID).Errorf("Failed to record notification engagement: %s", err)
+	}
+}
+
+// variantStatsText returns notifications sent per wording variant and the share of them engaged with.
+func (bot *ElectroBot) variantStatsText(lang string) string {
+	stats, err := bot.db.GetVariantStats()
+	if err != nil {
+		log.Errorf("Failed to get notification variant stats: %s", err)
+
+		return ""
+	}
+
+	var text string
+
+	for _, variant := range stats {
+		var engaged float64
+		if variant.Sent > 0 {
+			engaged = float64(variant.Engaged) / float64(variant.Sent) * 100 //nolint:gomnd
+		}
+
+		text += "\n" + i18n.T(lang, "%s wording: %d sent, details opened %d times (%.0f%%)",
+			variant.Variant, variant.Sent, variant.Engaged, engaged)
+	}
+
+	return text
+}
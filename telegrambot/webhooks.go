@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrambot
+
+import (
+	"fmt"
+	"strings"
+
+	"electrobot/apitoken"
+	"electrobot/i18n"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+// handleWebhookCommand manages secrets verifying inbound webhooks, the source is the HTTP feature name.
+func (bot *ElectroBot) handleWebhookCommand(chatID int64, arguments, lang string) string {
+	fields := strings.Fields(arguments)
+	if len(fields) == 0 {
+		fields = []string{"list"}
+	}
+
+	switch {
+	case fields[0] == "list" && len(fields) == 1:
+		return bot.listWebhookSecrets(lang)
+
+	case fields[0] == "set" && len(fields) == 2:
+		return bot.setWebhookSecret(chatID, fields[1], lang)
+
+	case fields[0] == "remove" && len(fields) == 2:
+		return bot.removeWebhookSecret(chatID, fields[1], lang)
+
+	default:
+		return i18n.T(lang, "Usage:\n/webhook list - show webhook sources with secrets"+
+			"\n/webhook set <source> - generate a new secret of the source"+
+			"\n/webhook remove <source> - remove the secret of the source")
+	}
+}
+
+func (bot *ElectroBot) listWebhookSecrets(lang string) string {
+	secrets, err := bot.db.GetWebhookSecrets()
+	if err != nil {
+		log.Errorf("Failed to get webhook secrets: %s", err)
+
+		return i18n.T(lang, "Failed to get webhook secrets. Please try again later")
+	}
+
+	if len(secrets) == 0 {
+		return i18n.T(lang, "There are no webhook secrets")
+	}
+
+	text := i18n.T(lang, "Webhook secrets:")
+
+	for _, secret := range secrets {
+		text += fmt.Sprintf("\n%s, %s", secret.Source, i18n.DateTime(lang, secret.CreatedAt.In(bot.defaultLocation)))
+	}
+
+	return text
+}
+
+func (bot *ElectroBot) setWebhookSecret(chatID int64, source, lang string) string {
+	if len(source) > maxTokenNameLength {
+		return i18n.T(lang, "Source name is too long, please keep it under %s",
+			i18n.N(lang, maxTokenNameLength, "%d character|%d characters"))
+	}
+
+	secret, _, err := apitoken.Generate()
+	if err != nil {
+		log.Errorf("Failed to generate webhook secret: %s", err)
+
+		return i18n.T(lang, "Failed to set webhook secret. Please try again later")
+	}
+
+	if err = bot.db.SetWebhookSecret(source, secret); err != nil {
+		log.Errorf("Failed to store webhook secret: %s", err)
+
+		return i18n.T(lang, "Failed to set webhook secret. Please try again later")
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "source": source}).Info("Webhook secret set")
+
+	return i18n.T(lang, "Webhook secret of %q set:\n%s\nSign request bodies with HMAC-SHA256 in the %s header "+
+		"or pass the secret as a bearer token. Delete this message after saving the secret",
+		source, secret, "X-Electrobot-Signature")
+}
+
+func (bot *ElectroBot) removeWebhookSecret(chatID int64, source, lang string) string {
+	if err := bot.db.RemoveWebhookSecret(source); err != nil {
+		log.Errorf("Failed to remove webhook secret: %s", err)
+
+		return i18n.T(lang, "Webhook secret of %q not found", source)
+	}
+
+	log.WithFields(log.Fields{"chatID": chatID, "source": source}).Info("Webhook secret removed")
+
+	return i18n.T(lang, "Webhook secret of %q removed", source)
+}
@@ -0,0 +1,421 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package telegramtest provides a fake Telegram Bot API server to drive the bot in integration tests without a real
+// bot token: tests inject user messages and button presses, and check messages sent by the bot.
+package telegramtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"electrobot/telegrambot"
+
+	botApi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+// Fake bot identity.
+const (
+	Token       = "123456:electrobot-test-token"
+	BotID       = 123456
+	BotUsername = "electrobot_test_bot"
+)
+
+const (
+	// maxPollWait limits getUpdates long polling so the bot under test stops quickly.
+	maxPollWait      = time.Second
+	maxMultipartSize = 32 << 20
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// ErrTimeout is returned when the expected messages are not sent in time.
+var ErrTimeout = errors.New("timeout waiting for messages")
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Message is a message sent or edited by the bot.
+type Message struct {
+	ID     int
+	Method string
+	ChatID int64
+	// Text is the message text or the caption of a photo or document.
+	Text        string
+	ReplyMarkup string
+	// Silent is true if the message is sent without sound.
+	Silent bool
+	SentAt time.Time
+}
+
+// periodicFailure fails every n-th call of a method.
+type periodicFailure struct {
+	every    int
+	response botApi.APIResponse
+}
+
+// Server is a fake Telegram Bot API server.
+type Server struct {
+	sync.Mutex
+
+	httpServer    *httptest.Server
+	updates       []botApi.Update
+	messages      []Message
+	read          map[int64]int
+	failures      map[string][]botApi.APIResponse
+	periodic      map[string]periodicFailure
+	calls         map[string]int
+	latency       time.Duration
+	memberCounts  map[string]int
+	nextMessageID int
+	// changed is closed and replaced on every new update or message to wake up waiters.
+	changed chan struct{}
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// NewServer creates and starts fake Bot API server.
+func NewServer() *Server {
+	server := &Server{
+		read: make(map[int64]int), failures: make(map[string][]botApi.APIResponse),
+		periodic: make(map[string]periodicFailure), calls: make(map[string]int), memberCounts: make(map[string]int),
+		changed: make(chan struct{}),
+	}
+
+	server.httpServer = httptest.NewServer(http.HandlerFunc(server.handle))
+
+	return server
+}
+
+// Close stops the server.
+func (server *Server) Close() {
+	server.httpServer.Close()
+}
+
+// Endpoint returns the Bot API URL format of the server.
+func (server *Server) Endpoint() string {
+	return server.httpServer.URL + "/bot%s/%s"
+}
+
+// BotConfig returns bot configuration connected to the server with short polling.
+func (server *Server) BotConfig() telegrambot.Config {
+	return telegrambot.Config{Token: Token, APIEndpoint: server.Endpoint(), PollTimeout: 1, SendAttempts: 1}
+}
+
+// SetLatency delays every Bot API response.
+func (server *Server) SetLatency(latency time.Duration) {
+	server.Lock()
+	defer server.Unlock()
+
+	server.latency = latency
+}
+
+// SetMemberCount sets the member count of the chat identified by ID or @username.
+func (server *Server) SetMemberCount(chat string, count int) {
+	server.Lock()
+	defer server.Unlock()
+
+	server.memberCounts[chat] = count
+}
+
+// FailNext makes the next call of the method fail with the error code, retryAfter sets flood control delay.
+func (server *Server) FailNext(method string, code int, description string, retryAfter int) {
+	server.Lock()
+	defer server.Unlock()
+
+	server.failures[method] = append(server.failures[method], errorResponse(code, description, retryAfter))
+}
+
+// FailEvery makes every n-th call of the method fail with the error code, zero n stops failing.
+func (server *Server) FailEvery(method string, n int, code int, description string, retryAfter int) {
+	server.Lock()
+	defer server.Unlock()
+
+	if n <= 0 {
+		delete(server.periodic, method)
+
+		return
+	}
+
+	server.periodic[method] = periodicFailure{every: n, response: errorResponse(code, description, retryAfter)}
+}
+
+// SendMessage sends a text message from the user to the bot, texts starting with "/" are commands.
+func (server *Server) SendMessage(chatID int64, text string) {
+	message := &botApi.Message{
+		Date: int(time.Now().Unix()), Text: text,
+		From: &botApi.User{ID: chatID, FirstName: "Test", LanguageCode: "en"},
+		Chat: &botApi.Chat{ID: chatID, Type: "private", FirstName: "Test"},
+	}
+
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		message.Entities = []botApi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+
+	server.pushUpdate(botApi.Update{Message: message})
+}
+
+// PressButton presses inline keyboard button with the data under the bot message.
+func (server *Server) PressButton(chatID int64, messageID int, data string) {
+	server.pushUpdate(botApi.Update{CallbackQuery: &botApi.CallbackQuery{
+		ID:   strconv.FormatInt(time.Now().UnixNano(), 10),
+		From: &botApi.User{ID: chatID, FirstName: "Test", LanguageCode: "en"},
+		Message: &botApi.Message{
+			MessageID: messageID, Chat: &botApi.Chat{ID: chatID, Type: "private"},
+		},
+		Data: data,
+	}})
+}
+
+// Messages returns all messages sent by the bot in sending order.
+func (server *Server) Messages() []Message {
+	server.Lock()
+	defer server.Unlock()
+
+	return append([]Message(nil), server.messages...)
+}
+
+// WaitForMessages waits until the bot sends at least count messages in total and returns all of them.
+func (server *Server) WaitForMessages(count int, timeout time.Duration) ([]Message, error) {
+	deadline := time.After(timeout)
+
+	for {
+		server.Lock()
+		messages, changed := append([]Message(nil), server.messages...), server.changed
+		server.Unlock()
+
+		if len(messages) >= count {
+			return messages, nil
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return messages, fmt.Errorf("%w: %d of %d sent", ErrTimeout, len(messages), count)
+		}
+	}
+}
+
+// NextMessage returns the next message to the chat not returned before, waiting for it up to timeout.
+func (server *Server) NextMessage(chatID int64, timeout time.Duration) (Message, error) {
+	deadline := time.After(timeout)
+
+	for {
+		server.Lock()
+
+		for i := server.read[chatID]; i < len(server.messages); i++ {
+			if server.messages[i].ChatID == chatID {
+				server.read[chatID] = i + 1
+				message := server.messages[i]
+
+				server.Unlock()
+
+				return message, nil
+			}
+		}
+
+		changed := server.changed
+		server.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return Message{}, fmt.Errorf("%w to chat %d", ErrTimeout, chatID)
+		}
+	}
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (server *Server) pushUpdate(update botApi.Update) {
+	server.Lock()
+	defer server.Unlock()
+
+	update.UpdateID = len(server.updates) + 1
+	server.updates = append(server.updates, update)
+
+	server.notify()
+}
+
+// notify wakes up waiters, must be called with the lock held.
+func (server *Server) notify() {
+	close(server.changed)
+	server.changed = make(chan struct{})
+}
+
+func (server *Server) handle(w http.ResponseWriter, r *http.Request) {
+	token, method, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/bot"), "/")
+	if !ok || token != Token {
+		writeResponse(w, botApi.APIResponse{Ok: false, ErrorCode: http.StatusUnauthorized, Description: "Unauthorized"})
+
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxMultipartSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		writeResponse(w, botApi.APIResponse{Ok: false, ErrorCode: http.StatusBadRequest, Description: err.Error()})
+
+		return
+	}
+
+	server.Lock()
+	latency := server.latency
+
+	var failure *botApi.APIResponse
+
+	server.calls[method]++
+
+	if failures := server.failures[method]; len(failures) != 0 {
+		failure, server.failures[method] = &failures[0], failures[1:]
+	} else if periodic, ok := server.periodic[method]; ok && server.calls[method]%periodic.every == 0 {
+		failure = &periodic.response
+	}
+
+	server.Unlock()
+
+	time.Sleep(latency)
+
+	if failure != nil {
+		writeResponse(w, *failure)
+
+		return
+	}
+
+	var result interface{}
+
+	switch method {
+	case "getMe":
+		result = botApi.User{ID: BotID, IsBot: true, FirstName: "Electrobot", UserName: BotUsername}
+
+	case "getUpdates":
+		result = server.getUpdates(r)
+
+	case "sendMessage", "editMessageText":
+		result = server.record(method, r, r.FormValue("text"))
+
+	case "sendPhoto", "sendDocument":
+		result = server.record(method, r, r.FormValue("caption"))
+
+	case "getChatMembersCount":
+		server.Lock()
+		result = server.memberCounts[r.FormValue("chat_id")]
+		server.Unlock()
+
+	default:
+		result = true
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeResponse(w, botApi.APIResponse{Ok: false, ErrorCode: http.StatusInternalServerError, Description: err.Error()})
+
+		return
+	}
+
+	writeResponse(w, botApi.APIResponse{Ok: true, Result: data})
+}
+
+// getUpdates returns updates starting from the offset, waiting for new ones up to the poll timeout.
+func (server *Server) getUpdates(r *http.Request) []botApi.Update {
+	offset, _ := strconv.Atoi(r.FormValue("offset"))
+	limit, _ := strconv.Atoi(r.FormValue("limit"))
+	timeout, _ := strconv.Atoi(r.FormValue("timeout"))
+
+	deadline := time.After(min(time.Duration(timeout)*time.Second, maxPollWait))
+
+	for {
+		server.Lock()
+
+		var updates []botApi.Update
+
+		for _, update := range server.updates {
+			if update.UpdateID >= offset && (limit <= 0 || len(updates) < limit) {
+				updates = append(updates, update)
+			}
+		}
+
+		changed := server.changed
+		server.Unlock()
+
+		if len(updates) != 0 {
+			return updates
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return []botApi.Update{}
+		case <-r.Context().Done():
+			return []botApi.Update{}
+		}
+	}
+}
+
+func (server *Server) record(method string, r *http.Request, text string) botApi.Message {
+	chatID, _ := strconv.ParseInt(r.FormValue("chat_id"), 10, 64)
+
+	server.Lock()
+	defer server.Unlock()
+
+	messageID, _ := strconv.Atoi(r.FormValue("message_id"))
+	if method != "editMessageText" {
+		server.nextMessageID++
+		messageID = server.nextMessageID
+	}
+
+	server.messages = append(server.messages, Message{
+		ID: messageID, Method: method, ChatID: chatID, Text: text, ReplyMarkup: r.FormValue("reply_markup"),
+		Silent: r.FormValue("disable_notification") == "true", SentAt: time.Now(),
+	})
+
+	server.notify()
+
+	return botApi.Message{
+		MessageID: messageID, Date: int(time.Now().Unix()), Text: text, Chat: &botApi.Chat{ID: chatID},
+	}
+}
+
+func errorResponse(code int, description string, retryAfter int) botApi.APIResponse {
+	response := botApi.APIResponse{Ok: false, ErrorCode: code, Description: description}
+	if retryAfter > 0 {
+		response.Parameters = &botApi.ResponseParameters{RetryAfter: retryAfter}
+	}
+
+	return response
+}
+
+func writeResponse(w http.ResponseWriter, response botApi.APIResponse) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uplink
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/***********************************************************************************************************************
+ * Consts
+ **********************************************************************************************************************/
+
+const (
+	defaultRouteFile     = "/proc/net/route"
+	defaultCheckInterval = 30 * time.Second
+	routeFlagUp          = 0x1
+	defaultDestination   = "00000000"
+	subsystemName        = "uplink monitor"
+)
+
+/***********************************************************************************************************************
+ * Vars
+ **********************************************************************************************************************/
+
+// ErrNoDefaultRoute is returned when no default route is found.
+var ErrNoDefaultRoute = errors.New("no default route")
+
+/***********************************************************************************************************************
+ * Types
+ **********************************************************************************************************************/
+
+// Config structure with uplink monitor configuration.
+type Config struct {
+	// BackupInterfaces lists interfaces considered as backup (e.g. LTE) uplinks.
+	BackupInterfaces []string
+	CheckInterval    time.Duration
+	RouteFile        string
+	// Reporter receives uplink detection state, optional.
+	Reporter StateReporter
+}
+
+// StateReporter receives subsystem state changes.
+type StateReporter interface {
+	SetSubsystemState(name string, err error)
+}
+
+// Uplink describes the uplink currently in use.
+type Uplink struct {
+	Interface string
+	Backup    bool
+}
+
+// Listener is notified when the uplink changes.
+type Listener interface {
+	UplinkChanged(iface string, backup bool)
+}
+
+// Monitor periodically detects the uplink in use.
+type Monitor struct {
+	sync.Mutex
+
+	config     Config
+	listener   Listener
+	current    Uplink
+	cancelFunc context.CancelFunc
+}
+
+/***********************************************************************************************************************
+ * Public
+ **********************************************************************************************************************/
+
+// New creates and starts uplink monitor.
+func New(config Config, listener Listener) (monitor *Monitor, err error) {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaultCheckInterval
+	}
+
+	if config.RouteFile == "" {
+		config.RouteFile = defaultRouteFile
+	}
+
+	monitor = &Monitor{config: config, listener: listener}
+
+	if _, err = os.Stat(config.RouteFile); err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFunction := context.WithCancel(context.Background())
+	monitor.cancelFunc = cancelFunction
+
+	monitor.check()
+
+	go monitor.run(ctx)
+
+	return monitor, nil
+}
+
+// Close stops uplink monitor.
+func (monitor *Monitor) Close() {
+	monitor.cancelFunc()
+}
+
+// Current returns the uplink detected on the last check.
+func (monitor *Monitor) Current() Uplink {
+	monitor.Lock()
+	defer monitor.Unlock()
+
+	return monitor.current
+}
+
+// String returns human readable uplink description.
+func (uplink Uplink) String() string {
+	if uplink.Interface == "" {
+		return "unknown"
+	}
+
+	if uplink.Backup {
+		return uplink.Interface + " (backup)"
+	}
+
+	return uplink.Interface
+}
+
+/***********************************************************************************************************************
+ * Private
+ **********************************************************************************************************************/
+
+func (monitor *Monitor) run(ctx context.Context) {
+	ticker := time.NewTicker(monitor.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			monitor.check()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (monitor *Monitor) check() {
+	iface, err := defaultRouteInterface(monitor.config.RouteFile)
+	if err != nil && !errors.Is(err, ErrNoDefaultRoute) {
+		log.Errorf("Failed to detect uplink: %s", err)
+
+		monitor.reportState(err)
+
+		return
+	}
+
+	monitor.reportState(nil)
+
+	detected := Uplink{Interface: iface, Backup: slices.Contains(monitor.config.BackupInterfaces, iface)}
+
+	monitor.Lock()
+	changed := detected != monitor.current
+	monitor.current = detected
+	monitor.Unlock()
+
+	if !changed {
+		return
+	}
+
+	log.WithField("uplink", detected).Info("Uplink changed")
+
+	if monitor.listener != nil {
+		monitor.listener.UplinkChanged(detected.Interface, detected.Backup)
+	}
+}
+
+// defaultRouteInterface returns the interface of the default route with the lowest metric.
+func defaultRouteInterface(routeFile string) (iface string, err error) {
+	file, err := os.Open(routeFile)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	bestMetric := -1
+	scanner := bufio.NewScanner(file)
+
+	// skip header
+	scanner.Scan()
+
+	for scanner.Scan() {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[1] != defaultDestination || fields[7] != defaultDestination {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&routeFlagUp == 0 {
+			continue
+		}
+
+		metric, err := strconv.Atoi(fields[6])
+		if err != nil {
+			continue
+		}
+
+		if bestMetric == -1 || metric < bestMetric {
+			bestMetric = metric
+			iface = fields[0]
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return "", err
+	}
+
+	if iface == "" {
+		return "", ErrNoDefaultRoute
+	}
+
+	return iface, nil
+}
+
+func (monitor *Monitor) reportState(err error) {
+	if monitor.config.Reporter != nil {
+		monitor.config.Reporter.SetSubsystemState(subsystemName, err)
+	}
+}